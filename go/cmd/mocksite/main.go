@@ -0,0 +1,49 @@
+// mocksite 在本地运行模拟票务网站，把配置中的站点地址指向它即可离线演练完整的抢票流程
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"tickgrabber/pkg/mocksite"
+)
+
+var (
+	listen   = flag.String("listen", "127.0.0.1:8090", "监听地址")
+	captcha  = flag.Bool("captcha", false, "下单前要求输入验证码")
+	sellOut  = flag.Float64("sellout", 0, "下单时座位被抢走的概率(0-1)")
+	queue    = flag.Duration("queue", 0, "排队等待时间")
+	openIn   = flag.Duration("open-in", 0, "多久后开售")
+	username = flag.String("username", "test", "测试账号")
+	password = flag.String("password", "test", "测试密码")
+)
+
+func main() {
+	flag.Parse()
+
+	opts := mocksite.Options{
+		Username:    *username,
+		Password:    *password,
+		QueueDelay:  *queue,
+		SellOutRate: *sellOut,
+		Captcha:     *captcha,
+	}
+	if *openIn > 0 {
+		opts.SaleOpen = time.Now().Add(*openIn)
+	}
+	site := mocksite.New(opts)
+
+	baseURL := "http://" + *listen
+	log.Printf("模拟票务网站: %s", baseURL)
+	log.Printf("演唱会页面: %s", mocksite.ConcertURL(baseURL, "mock_001"))
+	log.Println("站点配置:")
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetIndent("", "  ")
+	enc.Encode(mocksite.SiteConfig(baseURL))
+
+	log.Fatal(http.ListenAndServe(*listen, site))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/alert"
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/history"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/selectors"
+)
+
+// runNotifyOnly 只提醒模式：用HTTP轮询演出页面，有票时发送通知，由用户手动购买
+// 指定 --concert 时只监控该演出，否则监控所有未停用的演出
+func runNotifyOnly(config *models.Config) error {
+	var concerts []models.Concert
+	if *concertID != "" {
+		concert := findConcertByID(config.Concerts, *concertID)
+		if concert == nil {
+			return fmt.Errorf("找不到ID为 %s 的演唱会", *concertID)
+		}
+		concerts = append(concerts, *concert)
+	} else {
+		for _, concert := range config.Concerts {
+			if !inactiveStatuses[concert.Status] {
+				concerts = append(concerts, concert)
+			}
+		}
+	}
+	if len(concerts) == 0 {
+		return fmt.Errorf("配置中没有需要监控的演唱会")
+	}
+
+	var targets []alert.Target
+	for _, concert := range concerts {
+		targets = append(targets, alert.Target{Concert: concert, Site: grabber.DetectSite(config, &concert)})
+	}
+
+	client := api.NewClient(api.WithConfig(config))
+	if *debug {
+		client.Use(api.LoggingMiddleware(client.Logger()))
+	}
+	notifier := notify.NewManager(config.Notification)
+	defer closeNotifier(notifier)
+
+	monitor := alert.NewMonitor(client, selectors.NewRegistry(config.Ticketing.SelectorDir), notifier)
+	if config.Ticketing.AlertInterval > 0 {
+		monitor.Interval = time.Duration(config.Ticketing.AlertInterval * float64(time.Second))
+	}
+	if config.Ticketing.AlertCooldown > 0 {
+		monitor.Cooldown = time.Duration(config.Ticketing.AlertCooldown * float64(time.Second))
+	}
+	monitor.History = history.Open(config.Ticketing.HistoryFile)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	for i := range concerts {
+		grabber.WatchResale(ctx, config, client, notifier, &concerts[i])
+	}
+
+	log.Printf("只提醒模式：监控 %d 场演出，每场每 %s 检查一次", len(targets), monitor.Interval)
+	return monitor.Run(ctx, targets)
+}
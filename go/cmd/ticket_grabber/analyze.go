@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"tickgrabber/pkg/history"
+	"tickgrabber/pkg/models"
+)
+
+// analyzeCommand 根据余票状态记录分析放票规律
+// 用法: analyze [--concert ID] [--tz 时区]，不指定演出时分析记录中的所有演出
+func analyzeCommand(config *models.Config, args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	id := fs.String("concert", *concertID, "只分析指定ID的演唱会")
+	tz := fs.String("tz", "Asia/Seoul", "按哪个时区统计小时和星期")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := config.Ticketing.HistoryFile
+	if path == "" {
+		return fmt.Errorf("没有配置 ticketing.history_file，没有余票状态记录")
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("无效的时区 %s: %w", *tz, err)
+	}
+
+	observations, err := history.Load(path, *id)
+	if err != nil {
+		return fmt.Errorf("读取余票状态记录失败: %w", err)
+	}
+	if len(observations) == 0 {
+		return fmt.Errorf("%s 中没有相关记录，监控运行一段时间后再分析", path)
+	}
+
+	byConcert := make(map[string][]history.Observation)
+	var ids []string
+	for _, o := range observations {
+		if _, ok := byConcert[o.ConcertID]; !ok {
+			ids = append(ids, o.ConcertID)
+		}
+		byConcert[o.ConcertID] = append(byConcert[o.ConcertID], o)
+	}
+	for i, concert := range ids {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(history.Analyze(concert, byConcert[concert], loc))
+	}
+	return nil
+}
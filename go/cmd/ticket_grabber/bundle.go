@@ -0,0 +1,181 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tickgrabber/pkg/crypt"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/redact"
+)
+
+// bundleCommand 把一次任务的产物目录打包成zip: bundle [任务目录] [其他文件...]
+// 没有指定目录时使用 artifact_dir 中最新的任务，其他文件(如 --record 的录制文件)一并打包，
+// 同时附上脱敏后的配置。加密的产物在设置了口令环境变量时解密后打包，否则原样打包
+func bundleCommand(config *models.Config, args []string) error {
+	key := os.Getenv(grabber.ArtifactKeyEnv)
+	var dir string
+	if len(args) > 0 {
+		dir, args = args[0], args[1:]
+	} else {
+		latest, err := latestArtifacts(config.Ticketing.ArtifactDir)
+		if err != nil {
+			return err
+		}
+		dir = latest
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("任务产物目录不存在: %s", dir)
+	}
+
+	out := filepath.Clean(dir) + ".zip"
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return addToZip(zw, filepath.ToSlash(rel), path, key)
+	})
+	for _, extra := range args {
+		if err != nil {
+			break
+		}
+		err = addToZip(zw, filepath.Base(extra), extra, key)
+	}
+	if err == nil {
+		err = writeRedactedConfig(zw, config)
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out)
+		return fmt.Errorf("打包任务产物失败: %w", err)
+	}
+
+	log.Printf("任务产物已打包到 %s", out)
+	return nil
+}
+
+// latestArtifacts 返回产物目录中最近修改的任务目录
+func latestArtifacts(root string) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("没有配置 ticketing.artifact_dir，请指定任务目录")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestMod int64
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); latest == "" || mod > latestMod {
+			latest, latestMod = filepath.Join(root, e.Name()), mod
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%s 中没有任务产物", root)
+	}
+	return latest, nil
+}
+
+// addToZip 把文件以name写入zip，有口令时加密的文件解密后去掉.enc写入
+func addToZip(zw *zip.Writer, name, path, key string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	if key != "" && strings.HasSuffix(name, crypt.FileExt) {
+		data, err := crypt.ReadFile(key, path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		header.Name = strings.TrimSuffix(name, crypt.FileExt)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// openArtifact 输出任务产物文件的内容，加密的文件用环境变量中的口令解密: open-artifact <文件> [输出文件]
+func openArtifact(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: open-artifact <文件> [输出文件]")
+	}
+	data, err := crypt.ReadFile(os.Getenv(grabber.ArtifactKeyEnv), args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return os.WriteFile(args[1], data, 0600)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// writeRedactedConfig 把配置脱敏后写入zip，密码、令牌、Webhook和网关地址等敏感值按日志脱敏规则替换
+func writeRedactedConfig(zw *zip.Writer, config *models.Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create("config.json")
+	if err != nil {
+		return err
+	}
+	// 不依赖启动时是否已设置日志脱敏，直接按这份配置中的敏感值替换
+	masker := redact.New()
+	masker.AddSecrets(configSecrets(config)...)
+	_, err = io.WriteString(w, masker.String(redact.String(string(data))))
+	return err
+}
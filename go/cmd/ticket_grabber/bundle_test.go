@@ -0,0 +1,53 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"tickgrabber/pkg/models"
+)
+
+func TestWriteRedactedConfig(t *testing.T) {
+	config := &models.Config{}
+	config.User.Password = "pa55word"
+	config.Notification.Discord = []models.WebhookTarget{{WebhookURL: "https://discord.com/api/webhooks/1/discord-token"}}
+	config.Notification.Slack = []models.WebhookTarget{{WebhookURL: "https://hooks.slack.com/services/T0/B0/slack-token"}}
+	config.Notification.Gateways = []models.GatewayConfig{{
+		URL:     "https://sms.example.com/send?key=gw-key&to={{.Concert}}",
+		Headers: map[string]string{"X-Api-Key": "gateway-header-key"},
+	}}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeRedactedConfig(zw, config); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"pa55word", "discord-token", "slack-token", "gw-key", "gateway-header-key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("脱敏后的配置中仍有 %q", secret)
+		}
+	}
+	if !strings.Contains(string(data), `"X-Api-Key"`) {
+		t.Error("请求头的名字不应被替换")
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/ical"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/order"
+)
+
+const (
+	// saleEventLength 导出的开售事件的时长
+	saleEventLength = 30 * time.Minute
+	// saleEventAlarm 开售前多久提醒
+	saleEventAlarm = 10 * time.Minute
+	// paymentEventAlarm 付款期限前多久提醒
+	paymentEventAlarm = 2 * time.Hour
+)
+
+// saleUID 导出的开售事件的UID，导入时据此对应演唱会
+func saleUID(concertID string) string {
+	return "sale-" + concertID + "@tickgrabber"
+}
+
+// exportCalendar 导出开售时间和未付款订单的付款期限为.ics文件: export-calendar [文件]
+func exportCalendar(config *models.Config, args []string) error {
+	path := "concerts.ics"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	var events []ical.Event
+	for _, concert := range config.Concerts {
+		if concert.SaleOpenTime.IsZero() {
+			continue
+		}
+		events = append(events, ical.Event{
+			UID:         saleUID(concert.ID),
+			Summary:     fmt.Sprintf("[티켓오픈] %s", concert.Name),
+			Description: fmt.Sprintf("演唱会ID: %s\n网站: %s\n演出: %s %s", concert.ID, concert.Site, concert.Date, concert.Time),
+			Location:    concert.Venue,
+			URL:         concert.URL,
+			Start:       concert.SaleOpenTime,
+			End:         concert.SaleOpenTime.Add(saleEventLength),
+			Alarm:       saleEventAlarm,
+		})
+	}
+
+	if config.Ticketing.OrdersFile != "" {
+		store, err := order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config))
+		if err != nil {
+			return err
+		}
+		for _, o := range store.List() {
+			if o.Paid || o.PaymentDeadline.IsZero() {
+				continue
+			}
+			events = append(events, ical.Event{
+				UID:         "payment-" + o.ID + "@tickgrabber",
+				Summary:     fmt.Sprintf("[입금마감] %s", o.ConcertName),
+				Description: fmt.Sprintf("订单号: %s\n座位: %s\n金额: %d원", o.ID, strings.Join(o.Seats, ", "), o.Total),
+				URL:         o.URL,
+				Start:       o.PaymentDeadline,
+				End:         o.PaymentDeadline,
+				Alarm:       paymentEventAlarm,
+			})
+		}
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("没有配置开售时间的演唱会，也没有待付款的订单")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ical.Encode(f, "抢票日程", events); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("已导出 %d 个日程: %s", len(events), path)
+	return nil
+}
+
+// importCalendar 对比日历中的开售时间和配置: import-calendar [文件或URL]，默认使用 ticketing.calendar_feed
+func importCalendar(config *models.Config, args []string) error {
+	source := config.Ticketing.CalendarFeed
+	if len(args) > 0 {
+		source = args[0]
+	}
+	if source == "" {
+		return fmt.Errorf("用法: import-calendar <文件或URL>，或在 ticketing.calendar_feed 中配置共享日历")
+	}
+
+	events, err := readCalendar(source)
+	if err != nil {
+		return err
+	}
+
+	matched := 0
+	for _, concert := range config.Concerts {
+		open, ok := calendarSaleTime(concert, events)
+		if !ok {
+			continue
+		}
+		matched++
+		switch {
+		case concert.SaleOpenTime.IsZero():
+			fmt.Printf("%-16s 未配置 → %s\n", concert.ID, open.In(time.Local).Format("2006-01-02 15:04"))
+		case !concert.SaleOpenTime.Equal(open):
+			fmt.Printf("%-16s %s → %s\n", concert.ID,
+				concert.SaleOpenTime.In(time.Local).Format("2006-01-02 15:04"), open.In(time.Local).Format("2006-01-02 15:04"))
+		default:
+			fmt.Printf("%-16s 一致 %s\n", concert.ID, open.In(time.Local).Format("2006-01-02 15:04"))
+		}
+	}
+
+	if matched == 0 {
+		return fmt.Errorf("日历的 %d 个事件都没有对应的演唱会", len(events))
+	}
+	if config.Ticketing.CalendarFeed != source {
+		log.Printf("在 ticketing.calendar_feed 中配置 %s 后，每次启动时自动使用日历中的开售时间", source)
+	}
+	return nil
+}
+
+// syncCalendar 用 ticketing.calendar_feed 中的开售时间更新演唱会配置，读取失败时保留配置中的时间
+func syncCalendar(config *models.Config) {
+	source := config.Ticketing.CalendarFeed
+	if source == "" {
+		return
+	}
+
+	events, err := readCalendar(source)
+	if err != nil {
+		log.Printf("读取共享日历失败，使用配置中的开售时间: %v", err)
+		return
+	}
+
+	for i := range config.Concerts {
+		concert := &config.Concerts[i]
+		open, ok := calendarSaleTime(*concert, events)
+		if !ok || concert.SaleOpenTime.Equal(open) {
+			continue
+		}
+		log.Printf("[%s] 共享日历中的开售时间为 %s，替换配置中的时间", concert.ID, open.In(time.Local).Format("2006-01-02 15:04"))
+		concert.SaleOpenTime = open
+	}
+}
+
+// calendarSaleTime 查找演唱会在日历中的开售时间
+// 事件按本程序导出的UID、相同的演出页面地址或标题中的演唱会ID对应演唱会；
+// 有多个事件时(如先行预售和一般预售)使用最早的尚未开始的时间，都已开始时使用最晚的
+func calendarSaleTime(concert models.Concert, events []ical.Event) (time.Time, bool) {
+	now := time.Now()
+	var upcoming, past time.Time
+	for _, e := range events {
+		if strings.HasPrefix(e.UID, "payment-") {
+			continue
+		}
+		match := e.UID == saleUID(concert.ID) ||
+			concert.URL != "" && e.URL == concert.URL ||
+			strings.Contains(e.Summary, concert.ID)
+		if !match {
+			continue
+		}
+		if e.Start.After(now) {
+			if upcoming.IsZero() || e.Start.Before(upcoming) {
+				upcoming = e.Start
+			}
+		} else if e.Start.After(past) {
+			past = e.Start
+		}
+	}
+
+	if !upcoming.IsZero() {
+		return upcoming, true
+	}
+	return past, !past.IsZero()
+}
+
+// readCalendar 读取本地.ics文件或下载日历订阅地址(http、https、webcal)
+func readCalendar(source string) ([]ical.Event, error) {
+	if strings.HasPrefix(source, "webcal://") {
+		source = "https://" + strings.TrimPrefix(source, "webcal://")
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ical.Decode(f)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载日历失败: HTTP %d", resp.StatusCode)
+	}
+	return ical.Decode(resp.Body)
+}
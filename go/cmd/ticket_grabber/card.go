@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"tickgrabber/pkg/crypt"
+	"tickgrabber/pkg/gateway"
+	"tickgrabber/pkg/grabber"
+)
+
+// encryptCard 从标准输入读取卡号和有效期(MM/YY)，用环境变量中的口令加密，
+// 输出的密文填入 ticketing.payment.card.encrypted: encrypt-card
+func encryptCard() error {
+	passphrase := os.Getenv(grabber.CardKeyEnv)
+	if passphrase == "" {
+		return fmt.Errorf("请先在环境变量 %s 中设置口令", grabber.CardKeyEnv)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprint(os.Stderr, "卡号: ")
+	number, _ := reader.ReadString('\n')
+	fmt.Fprint(os.Stderr, "有效期(MM/YY): ")
+	expiry, _ := reader.ReadString('\n')
+
+	card, err := gateway.ParseCard(number, expiry)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	sealed, err := crypt.Seal(passphrase, data)
+	if err != nil {
+		return err
+	}
+	fmt.Println(sealed)
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/selectors"
+)
+
+// runCommand 执行子命令
+func runCommand(name string, args []string, config *models.Config) error {
+	switch name {
+	case "test-notify":
+		return testNotify(config)
+	case "orders":
+		return listOrders(config)
+	case "export-receipt":
+		return exportReceipt(config, args)
+	case "mark-paid":
+		return markPaid(config, args)
+	case "import-cookies":
+		return importCookiesCommand(config, args)
+	case "export-cookies":
+		return exportCookiesCommand(config, args)
+	case "profiles":
+		return listProfiles()
+	case "concert":
+		return concertCommand(config, args)
+	case "analyze":
+		return analyzeCommand(config, args)
+	case "encrypt-card":
+		return encryptCard()
+	case "export-calendar":
+		return exportCalendar(config, args)
+	case "import-calendar":
+		return importCalendar(config, args)
+	case "login":
+		return loginCommand(config, args)
+	case "doctor":
+		return doctorCommand(config, args)
+	case "verify-selectors":
+		return verifySelectors(config, args)
+	case "selftest":
+		return selftestCommand(config, args)
+	case "rehearse":
+		return rehearse(config, args)
+	case "run-flow":
+		return runFlowCommand(config, args)
+	case "bundle":
+		return bundleCommand(config, args)
+	case "open-artifact":
+		return openArtifact(args)
+	case "migrate-config":
+		return migrateConfigCommand(args)
+	case "serve":
+		return runService(config)
+	case "status":
+		return statusCommand(config, args)
+	case "worker":
+		return runWorker(config)
+	case "install-service":
+		return installService(config)
+	case "uninstall-service":
+		return uninstallService()
+	default:
+		return fmt.Errorf("未知命令: %s", name)
+	}
+}
+
+// testNotify 向所有启用的通知渠道发送一条测试消息，用于验证配置
+func testNotify(config *models.Config) error {
+	manager := notify.NewManager(config.Notification)
+	if len(manager.Notifiers()) == 0 {
+		return fmt.Errorf("没有启用任何通知渠道")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := manager.NotifySync(ctx, notify.Event{
+		Type:    notify.EventTest,
+		Title:   "抢票系统测试通知",
+		Message: "收到这条消息说明通知配置正确",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, n := range manager.Notifiers() {
+		log.Printf("%s 测试通知发送成功", n.Name())
+	}
+	return nil
+}
+
+// listOrders 列出已记录的订单
+func listOrders(config *models.Config) error {
+	store, err := order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config))
+	if err != nil {
+		return err
+	}
+
+	for _, o := range store.List() {
+		line := fmt.Sprintf("%s  %s  %s  %d원", o.ID, o.ConcertName, strings.Join(o.Seats, ", "), o.Total)
+		if !o.PaymentDeadline.IsZero() {
+			line += "  付款期限 " + o.PaymentDeadline.Format("2006-01-02 15:04")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// exportReceipt 将订单导出为JSON收据: export-receipt <订单号> [目录]
+func exportReceipt(config *models.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: export-receipt <订单号> [目录]")
+	}
+	dir := config.Ticketing.ReceiptDir
+	if len(args) > 1 {
+		dir = args[1]
+	}
+
+	store, err := order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config))
+	if err != nil {
+		return err
+	}
+	o, ok := store.Get(args[0])
+	if !ok {
+		return fmt.Errorf("找不到订单 %s", args[0])
+	}
+
+	// 只导出JSON，不覆盖记录中的文件列表
+	receipt := *o
+	receipt.ReceiptFiles = nil
+	if err := order.WriteReceipt(dir, &receipt, nil, grabber.ArtifactKey(config)); err != nil {
+		return err
+	}
+	log.Printf("收据已导出: %s", strings.Join(receipt.ReceiptFiles, ", "))
+	return nil
+}
+
+// verifySelectors 打开页面，逐个检查站点选择器链能否匹配: verify-selectors <站点> <URL>
+func verifySelectors(config *models.Config, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("用法: verify-selectors <站点> <URL>")
+	}
+	site, url := args[0], args[1]
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	b, err := newDriver(ctx, config, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	if err := b.Navigate(ctx, url); err != nil {
+		return err
+	}
+
+	registry := selectors.NewRegistry(config.Ticketing.SelectorDir)
+	missing := 0
+	for _, key := range registry.Keys(site) {
+		matched := locateChain(ctx, b, registry.Get(site, key))
+		if matched == "" {
+			matched = "无匹配"
+			missing++
+		}
+		fmt.Printf("%-18s %s\n", key, matched)
+	}
+
+	// 一个页面通常只包含部分元素，这里只报告不作为错误
+	if missing > 0 {
+		log.Printf("%d 个元素在此页面没有匹配的选择器，可在 %s 中调整", missing, registry.Path(site))
+	}
+	return nil
+}
+
+// locateChain 返回选择器链中第一个在页面上匹配的选择器，都没有匹配时返回空字符串
+// 含%s占位符的选择器需要运行时的参数，不检查
+func locateChain(ctx context.Context, b browser.Driver, chain selectors.Chain) string {
+	for _, selector := range chain {
+		if strings.Contains(selector, "%s") {
+			return selector + " (含占位符，未检查)"
+		}
+		if _, ok := selectors.Locate(ctx, b, selector); ok {
+			return selector
+		}
+		// 登录字段可以只写name或id，按CSS匹配不到时再按id和name检查
+		if _, _, text := selectors.Text(selector); !text {
+			if check := selectors.IDOrName(selector); check != "" {
+				if _, ok := selectors.Locate(ctx, b, check); ok {
+					return selector
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/saleopen"
+)
+
+// concertCommand 编辑配置文件中的演唱会列表，不需要手动修改JSON:
+// concert list | concert add <地址> [选项] | concert update <ID> [选项] | concert remove <ID>
+func concertCommand(config *models.Config, args []string) error {
+	usage := fmt.Errorf("用法: concert list | add <地址> [选项] | update <ID> [选项] | remove <ID>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "list":
+		listConcerts(config.Concerts)
+		return nil
+	case "add":
+		return addConcert(config, args[1:])
+	case "update":
+		return updateConcert(config, args[1:])
+	case "remove":
+		if len(args) < 2 {
+			return usage
+		}
+		return removeConcert(config, args[1])
+	default:
+		return usage
+	}
+}
+
+// listConcerts 列出演唱会
+func listConcerts(concerts []models.Concert) {
+	for _, c := range concerts {
+		parts := []string{c.ID, c.Name, c.Site, strings.TrimSpace(c.Date + " " + c.Time)}
+		if !c.SaleOpenTime.IsZero() {
+			parts = append(parts, "开售 "+c.SaleOpenTime.Format("2006-01-02 15:04"))
+		}
+		parts = append(parts, c.URL)
+
+		var fields []string
+		for _, p := range parts {
+			if p != "" {
+				fields = append(fields, p)
+			}
+		}
+		fmt.Println(strings.Join(fields, "  "))
+	}
+}
+
+// concertFlags 演唱会字段的命令行选项，只有设置了的选项会写入
+type concertFlags struct {
+	set      *flag.FlagSet
+	id       string
+	name     string
+	artist   string
+	venue    string
+	date     string
+	time     string
+	site     string
+	url      string
+	maxPrice int
+	seats    string
+	priority int
+	saleOpen string
+	noFetch  bool
+}
+
+// newConcertFlags 定义选项
+func newConcertFlags(name string) *concertFlags {
+	f := &concertFlags{set: flag.NewFlagSet("concert "+name, flag.ContinueOnError)}
+	f.set.StringVar(&f.id, "id", "", "演唱会ID，add时默认按地址生成")
+	f.set.StringVar(&f.name, "name", "", "名称")
+	f.set.StringVar(&f.artist, "artist", "", "艺人")
+	f.set.StringVar(&f.venue, "venue", "", "场馆")
+	f.set.StringVar(&f.date, "date", "", "演出日期")
+	f.set.StringVar(&f.time, "time", "", "演出时间")
+	f.set.StringVar(&f.site, "site", "", "票务网站，默认按地址识别")
+	f.set.StringVar(&f.url, "url", "", "演出页面地址")
+	f.set.IntVar(&f.maxPrice, "max-price", 0, "最高票价")
+	f.set.StringVar(&f.seats, "seats", "", "偏好座位，用逗号分隔")
+	f.set.IntVar(&f.priority, "priority", 0, "优先级")
+	f.set.StringVar(&f.saleOpen, "sale-open", "", "开售时间，如 2026-11-20T20:00:00+09:00 或 2026-11-20 20:00(韩国时间)")
+	f.set.BoolVar(&f.noFetch, "no-fetch", false, "不访问演出页面自动填写名称和开售时间")
+	return f
+}
+
+// parse 解析选项，位置参数可以出现在选项之前或之后
+func (f *concertFlags) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := f.set.Parse(args); err != nil {
+			return nil, err
+		}
+		if f.set.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, f.set.Arg(0))
+		args = f.set.Args()[1:]
+	}
+}
+
+// apply 把设置了的选项写入演唱会
+func (f *concertFlags) apply(c *models.Concert) error {
+	var err error
+	f.set.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "id":
+			c.ID = f.id
+		case "name":
+			c.Name = f.name
+		case "artist":
+			c.Artist = f.artist
+		case "venue":
+			c.Venue = f.venue
+		case "date":
+			c.Date = f.date
+		case "time":
+			c.Time = f.time
+		case "site":
+			c.Site = f.site
+		case "url":
+			c.URL = f.url
+		case "max-price":
+			c.MaxPrice = f.maxPrice
+		case "priority":
+			c.Priority = f.priority
+		case "seats":
+			c.PreferredSeats = nil
+			for _, s := range strings.Split(f.seats, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					c.PreferredSeats = append(c.PreferredSeats, s)
+				}
+			}
+		case "sale-open":
+			c.SaleOpenTime, err = parseSaleOpen(f.saleOpen)
+		}
+	})
+	return err
+}
+
+// parseSaleOpen 解析开售时间，没有时区时按韩国时间
+func parseSaleOpen(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	kst := time.FixedZone("KST", 9*60*60)
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006.01.02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, kst); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析开售时间: %s", s)
+}
+
+// addConcert 添加演唱会: concert add <地址> [选项]
+func addConcert(config *models.Config, args []string) error {
+	f := newConcertFlags("add")
+	positional, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 && f.url == "" {
+		f.url = positional[0]
+	}
+	if f.url == "" && f.id == "" {
+		return fmt.Errorf("用法: concert add <地址> [--id ID] [--name 名称] ...")
+	}
+
+	var concert models.Concert
+	if f.url != "" {
+		concert = concertFromURL(config, f.url, !f.noFetch)
+	}
+	if err := f.apply(&concert); err != nil {
+		return err
+	}
+	if concert.ID == "" {
+		return fmt.Errorf("无法从地址生成ID，请用 --id 指定")
+	}
+	if findConcertByID(config.Concerts, concert.ID) != nil {
+		return fmt.Errorf("ID为 %s 的演唱会已存在，修改请用 concert update", concert.ID)
+	}
+	concert.CreatedAt = time.Now().Truncate(time.Second)
+	concert.UpdatedAt = concert.CreatedAt
+
+	if err := saveConcerts(append(config.Concerts, concert), concert.ID); err != nil {
+		return err
+	}
+	log.Printf("已添加演唱会 %s", concert.ID)
+	listConcerts([]models.Concert{concert})
+	return nil
+}
+
+// updateConcert 修改演唱会的字段: concert update <ID> [选项]
+func updateConcert(config *models.Config, args []string) error {
+	f := newConcertFlags("update")
+	positional, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("用法: concert update <ID> [选项]")
+	}
+	if f.set.NFlag() == 0 {
+		return fmt.Errorf("没有要修改的字段")
+	}
+
+	concerts := append([]models.Concert(nil), config.Concerts...)
+	for i := range concerts {
+		if concerts[i].ID != positional[0] {
+			continue
+		}
+		if err := f.apply(&concerts[i]); err != nil {
+			return err
+		}
+		concerts[i].UpdatedAt = time.Now().Truncate(time.Second)
+		if err := saveConcerts(concerts, concerts[i].ID); err != nil {
+			return err
+		}
+		log.Printf("已修改演唱会 %s", concerts[i].ID)
+		listConcerts(concerts[i : i+1])
+		return nil
+	}
+	return fmt.Errorf("找不到ID为 %s 的演唱会", positional[0])
+}
+
+// removeConcert 删除演唱会: concert remove <ID>
+func removeConcert(config *models.Config, id string) error {
+	var concerts []models.Concert
+	for _, c := range config.Concerts {
+		if c.ID != id {
+			concerts = append(concerts, c)
+		}
+	}
+	if len(concerts) == len(config.Concerts) {
+		return fmt.Errorf("找不到ID为 %s 的演唱会", id)
+	}
+
+	if err := saveConcerts(concerts, ""); err != nil {
+		return err
+	}
+	log.Printf("已删除演唱会 %s", id)
+	return nil
+}
+
+// saveConcerts 把演唱会列表写回配置文件，只替换 concerts 字段；
+// changed 为新增或修改的演唱会ID，它的空字段不写入，其他演唱会保持文件中原有的内容
+func saveConcerts(concerts []models.Concert, changed string) error {
+	data, err := os.ReadFile(*configFile)
+	if err != nil {
+		return err
+	}
+	var file struct {
+		Concerts []json.RawMessage `json:"concerts"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("配置文件格式错误: %w", err)
+	}
+	existing := make(map[string]json.RawMessage)
+	for _, raw := range file.Concerts {
+		var c struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(raw, &c) == nil {
+			existing[c.ID] = raw
+		}
+	}
+
+	items := make([]json.RawMessage, 0, len(concerts))
+	for _, c := range concerts {
+		if raw, ok := existing[c.ID]; ok && c.ID != changed {
+			items = append(items, raw)
+			continue
+		}
+		raw, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		raw, _ = pruneJSON(raw)
+		items = append(items, raw)
+	}
+
+	value, err := json.MarshalIndent(items, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		value = []byte("[]")
+	}
+	data, err = replaceConfigKey(data, "concerts", value)
+	if err != nil {
+		return err
+	}
+
+	// 写入前确认结果仍能被加载，不把格式错误的配置留在磁盘上
+	var check models.Config
+	if err := json.Unmarshal(data, &check); err != nil {
+		return fmt.Errorf("生成的配置无效: %w", err)
+	}
+	return writeConfigFile(*configFile, data)
+}
+
+// concertIDParams 常见票务网站地址中表示演出编号的参数
+var concertIDParams = []string{"GoodsCode", "goodsCode", "prodId", "productId", "perfId", "performanceId", "idPerf", "id"}
+
+// concertCodePattern 路径中的演出编号
+var concertCodePattern = regexp.MustCompile(`^[A-Za-z]?\d{4,}$`)
+
+// concertFromURL 根据演出页面地址生成演唱会：按域名识别站点，从地址中取演出编号作为ID，
+// fetch为true时读取页面标题和公告的开售时间，读取失败时只使用地址中的信息
+func concertFromURL(config *models.Config, rawURL string, fetch bool) models.Concert {
+	concert := models.Concert{URL: rawURL, Site: grabber.SiteForURL(config, rawURL)}
+
+	if u, err := url.Parse(rawURL); err == nil {
+		code := ""
+		for _, name := range concertIDParams {
+			if v := u.Query().Get(name); v != "" {
+				code = v
+				break
+			}
+		}
+		if code == "" {
+			if last := path.Base(strings.TrimRight(u.Path, "/")); concertCodePattern.MatchString(last) {
+				code = last
+			}
+		}
+		switch {
+		case code != "" && concert.Site != "":
+			concert.ID = concert.Site + "_" + code
+		case code != "":
+			concert.ID = code
+		}
+	}
+
+	if !fetch {
+		return concert
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	doc, err := api.NewClient(api.WithConfig(config)).GetDocument(ctx, rawURL)
+	if err != nil {
+		log.Printf("读取演出页面失败，只使用地址中的信息: %v", err)
+		return concert
+	}
+	concert.Name = strings.TrimSpace(doc.Find(`meta[property="og:title"]`).AttrOr("content", ""))
+	if concert.Name == "" {
+		concert.Name = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	if html, err := doc.Html(); err == nil {
+		now := time.Now()
+		if ann, ok := saleopen.Pick(saleopen.FromHTML(html, now), false, now); ok {
+			concert.SaleOpenTime = ann.Time
+			log.Printf("页面公告的开售时间: %s (%s)", ann.Time.Format("2006-01-02 15:04"), ann.Label)
+		}
+	}
+	return concert
+}
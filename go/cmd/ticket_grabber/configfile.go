@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// replaceConfigKey 替换JSON对象中顶层key的值，文件其余部分(顺序、缩进、其他字段)保持不变；
+// key不存在时追加到对象末尾
+func replaceConfigKey(data []byte, key string, value []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("配置文件不是JSON对象")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		afterKey := dec.InputOffset()
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if tok != key {
+			continue
+		}
+
+		end := int(dec.InputOffset())
+		start := int(afterKey) + bytes.IndexByte(data[afterKey:end], ':') + 1
+		for start < end && isSpace(data[start]) {
+			start++
+		}
+		return append(append(append([]byte{}, data[:start]...), value...), data[end:]...), nil
+	}
+
+	// 追加到最后一个 '}' 之前
+	end := bytes.LastIndexByte(data, '}')
+	prefix := bytes.TrimRight(data[:end], " \t\r\n")
+	sep := ",\n"
+	if bytes.HasSuffix(prefix, []byte("{")) {
+		sep = "\n"
+	}
+	quoted, _ := json.Marshal(key)
+	added := fmt.Sprintf("%s  %s: %s\n", sep, quoted, value)
+	return append(append(append([]byte{}, prefix...), added...), data[end:]...), nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// pruneJSON 去掉对象中的空值字段(null、空字符串、0、false、空数组和对象、零时间)，保留字段顺序，
+// 用于把结构体写回配置时不输出大量默认值；返回值为空时第二个结果为true
+func pruneJSON(raw json.RawMessage) (json.RawMessage, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, true
+	}
+
+	switch raw[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.Token()
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for dec.More() {
+			key, _ := dec.Token()
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return raw, false
+			}
+			pruned, empty := pruneJSON(value)
+			if empty {
+				continue
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			quoted, _ := json.Marshal(key)
+			buf.Write(quoted)
+			buf.WriteByte(':')
+			buf.Write(pruned)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), buf.Len() == 2
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return raw, false
+		}
+		for i, item := range items {
+			items[i], _ = pruneJSON(item)
+		}
+		data, _ := json.Marshal(items)
+		return data, len(items) == 0
+	}
+
+	switch string(raw) {
+	case "null", `""`, "0", "false", `"0001-01-01T00:00:00Z"`:
+		return raw, true
+	}
+	return raw, false
+}
+
+// writeConfigFile 原子地写入配置文件：先写临时文件再重命名，写到一半时原文件不受影响
+func writeConfigFile(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/session"
+)
+
+// importCookiesCommand 导入真实浏览器导出的Cookie(Netscape cookies.txt或浏览器扩展的JSON)
+// 保存到 user.cookie_file，之后的抢票任务直接使用该会话，不需要自动登录
+func importCookiesCommand(config *models.Config, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("用法: import-cookies <cookies.txt或cookies.json>")
+	}
+	if config.User.CookieFile == "" {
+		return fmt.Errorf("需要在配置中设置 user.cookie_file")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	cookies, err := session.ParseCookies(data)
+	if err != nil {
+		return err
+	}
+
+	if err := grabber.WriteCookieFile(config.User.CookieFile, grabber.ArtifactKey(config), func(buf *bytes.Buffer) error {
+		return session.WriteCookies(buf, cookies, session.FormatJSON)
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("已导入 %d 个Cookie到 %s\n", len(cookies), config.User.CookieFile)
+	return nil
+}
+
+// exportCookiesCommand 把 user.cookie_file 中的会话导出为json或netscape格式
+func exportCookiesCommand(config *models.Config, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("用法: export-cookies <输出文件> [json|netscape]")
+	}
+	format := session.FormatJSON
+	if len(args) > 1 {
+		format = args[1]
+	}
+
+	data, err := grabber.ReadCookieFile(config.User.CookieFile, grabber.ArtifactKey(config))
+	if err != nil {
+		return fmt.Errorf("读取Cookie文件失败(需要先登录或导入): %w", err)
+	}
+	cookies, err := session.ParseCookies(data)
+	if err != nil {
+		return err
+	}
+
+	if err := grabber.WriteCookieFile(args[0], "", func(buf *bytes.Buffer) error {
+		return session.WriteCookies(buf, cookies, format)
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("已导出 %d 个Cookie到 %s\n", len(cookies), args[0])
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/flow"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// flowStages 可以用流程文件替换的阶段
+var flowStages = []string{grabber.StageLogin, grabber.StageSelectRound, grabber.StagePresale, grabber.StageSelectSeats, grabber.StageDelivery, grabber.StagePurchase}
+
+// doctorCheck doctor 在页面上检查的一个元素
+type doctorCheck struct {
+	key   string
+	chain selectors.Chain
+	// later 元素在后续页面(选座、领取方式、付款)或开售后才出现，当前页面没有匹配只提示不算失败
+	later string
+}
+
+// doctorCommand 开售前检查演唱会会用到的选择器和流程文件，输出通过/失败表: doctor [--concert ID]
+// 打开登录页检查登录字段，打开演出页面检查余票、场次和预售验证等元素；选座、领取方式和购买按钮
+// 在后续页面出现，演出页面上没有匹配时只提示。站点的流程文件逐个解析检查，由流程文件执行的阶段不检查内置选择器
+func doctorCommand(config *models.Config, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	id := fs.String("concert", *concertID, "检查的演唱会ID，默认为配置中的第一个")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var concert *models.Concert
+	switch {
+	case *id != "":
+		if concert = findConcertByID(config.Concerts, *id); concert == nil {
+			return fmt.Errorf("找不到ID为 %s 的演唱会", *id)
+		}
+	case len(config.Concerts) > 0:
+		concert = &config.Concerts[0]
+	default:
+		return fmt.Errorf("配置中没有演唱会信息")
+	}
+	site := grabber.DetectSite(config, concert)
+	cfg, ok := config.Ticketing.Sites[site]
+	if !ok {
+		return fmt.Errorf("配置中没有站点 %s", site)
+	}
+
+	fmt.Printf("%s (%s)\n", concert.Name, cfg.Name)
+	if cfg.Adapter.Command != "" {
+		fmt.Printf("  站点使用外部适配器 %s，适配器执行的阶段不使用下面的选择器\n", cfg.Adapter.Command)
+	}
+	failed := 0
+	flows := lintFlows(config.Ticketing.FlowDir, site, &failed)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	b, err := newDriver(ctx, config, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	registry := selectors.NewRegistry(config.Ticketing.SelectorDir)
+	check := func(key, later string) doctorCheck {
+		return doctorCheck{key: key, chain: registry.Get(site, key), later: later}
+	}
+
+	if !flows[grabber.StageLogin] && cfg.LoginURL != "" {
+		failed += checkPage(ctx, b, "登录页", cfg.LoginURL, []doctorCheck{
+			check(selectors.LoginUsername, ""),
+			check(selectors.LoginPassword, ""),
+			check(selectors.LoginSubmit, ""),
+		})
+	}
+
+	var checks []doctorCheck
+	available := check(selectors.TicketAvailable, "")
+	if concert.SaleOpenTime.After(time.Now()) {
+		available.later = "开售后"
+	}
+	checks = append(checks, available)
+	if len(concert.Rounds) > 0 && !flows[grabber.StageSelectRound] {
+		round := concert.Rounds[0]
+		if round.Label != "" {
+			checks = append(checks, doctorCheck{key: selectors.RoundLabel, chain: registry.Format(site, selectors.RoundLabel, round.Label)})
+		} else {
+			checks = append(checks, doctorCheck{key: selectors.RoundDate, chain: registry.Format(site, selectors.RoundDate, round.Date)})
+			if round.Time != "" {
+				checks = append(checks, doctorCheck{key: selectors.RoundTime, chain: registry.Format(site, selectors.RoundTime, round.Time), later: "选择日期后"})
+			}
+		}
+	}
+	presale := concert.Presale
+	if (presale.Code != "" || presale.Membership != "" || len(presale.Fields) > 0) && !flows[grabber.StagePresale] {
+		checks = append(checks, check(selectors.PresaleGate, "点击购买后"))
+	}
+	if !flows[grabber.StageSelectSeats] {
+		checks = append(checks, check(selectors.SeatAvailable, "选座页面"))
+	}
+	if !flows[grabber.StageDelivery] {
+		checks = append(checks, check(selectors.DeliverySection, "领取方式页面"))
+	}
+	if !flows[grabber.StagePurchase] {
+		checks = append(checks, check(selectors.PurchaseConfirm, "付款页面"))
+	}
+	failed += checkPage(ctx, b, "演出页面", concert.URL, checks)
+
+	if failed > 0 {
+		return fmt.Errorf("%d 项检查未通过，可在 %s 中调整选择器", failed, registry.Path(site))
+	}
+	fmt.Println("检查通过")
+	return nil
+}
+
+// checkPage 打开页面逐个检查元素并输出结果，返回未通过的数量
+func checkPage(ctx context.Context, b browser.Driver, name, url string, checks []doctorCheck) int {
+	if err := b.Navigate(ctx, url); err != nil {
+		fmt.Printf("✗ %-8s 无法打开 %s: %v\n", name, url, err)
+		return 1
+	}
+
+	failed := 0
+	for _, c := range checks {
+		matched := locateChain(ctx, b, c.chain)
+		switch {
+		case matched != "":
+			fmt.Printf("✓ %-8s %-22s %s\n", name, c.key, matched)
+		case c.later != "":
+			fmt.Printf("· %-8s %-22s 没有匹配(%s出现，无法在此检查)\n", name, c.key, c.later)
+		default:
+			fmt.Printf("✗ %-8s %-22s 没有匹配: %s\n", name, c.key, strings.Join(c.chain, " | "))
+			failed++
+		}
+	}
+	return failed
+}
+
+// lintFlows 解析检查站点的流程文件并输出结果，返回有流程文件的阶段
+func lintFlows(dir, site string, failed *int) map[string]bool {
+	stages := make(map[string]bool)
+	if dir == "" {
+		return stages
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, site))
+	if err != nil {
+		return stages
+	}
+
+	for _, e := range entries {
+		stage, ok := strings.CutSuffix(e.Name(), ".yaml")
+		if e.IsDir() || !ok {
+			continue
+		}
+		path := filepath.Join(dir, site, e.Name())
+		if !slices.Contains(flowStages, stage) {
+			fmt.Printf("· %-8s %-22s 不是可替换的阶段(%s)，不会被使用\n", "流程文件", e.Name(), strings.Join(flowStages, "、"))
+			continue
+		}
+		// 流程文件存在时即使有错误也不会回退到内置选择器
+		stages[stage] = true
+		f, err := flow.Load(path)
+		if err != nil {
+			fmt.Printf("✗ %-8s %-22s %v\n", "流程文件", e.Name(), err)
+			*failed++
+			continue
+		}
+		fmt.Printf("✓ %-8s %-22s %d 个步骤\n", "流程文件", e.Name(), len(f.Steps))
+	}
+	return stages
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"tickgrabber/pkg/flow"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+)
+
+// runFlowCommand 单独执行流程文件，用于调试: run-flow <文件> [站点] [演唱会ID]
+func runFlowCommand(config *models.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: run-flow <文件> [站点] [演唱会ID]")
+	}
+	f, err := flow.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	site := config.Ticketing.DefaultSite
+	if len(args) > 1 {
+		site = args[1]
+	}
+	var concert *models.Concert
+	if len(args) > 2 {
+		if concert = findConcertByID(config.Concerts, args[2]); concert == nil {
+			return fmt.Errorf("找不到演唱会: %s", args[2])
+		}
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	b, err := newDriver(ctx, config, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	if err := flow.Run(ctx, b, f, grabber.FlowVars(config, site, concert)); err != nil {
+		return err
+	}
+	log.Printf("流程 %s 执行完成", f.Name)
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/watchdog"
+)
+
+// runConcert 运行演唱会的抢票任务
+// 配置了 listings 时为每个售票网站各运行一个任务，最先发现有票的任务取得购买权，其余任务暂停；
+// 购买失败时交还购买权，暂停的任务重新开始监控
+// claim 不为nil时还需要由它确认购买权(如分布式模式下向控制器申请)，release 不为nil时购买失败后同样通知它
+func runConcert(ctx context.Context, config *models.Config, concert *models.Concert, wd *watchdog.Watchdog, events *notify.Broadcaster, orders *order.Store, claim func() bool, release func()) error {
+	if len(concert.Listings) == 0 {
+		return runTask(ctx, config, concert, wd, events, orders, claim, release)
+	}
+
+	targets := listingConcerts(config, concert)
+	r := newRace()
+	results := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target *models.Concert) {
+			defer wg.Done()
+
+			for {
+				taskCtx, cancel := r.start(ctx, target.ID)
+				err := runTask(taskCtx, config, target, wd, events, orders, func() bool {
+					return r.claim(target.ID) && (claim == nil || claim())
+				}, func() {
+					r.release(target.ID)
+					if release != nil {
+						release()
+					}
+				})
+				cancel()
+
+				if !r.resume(ctx, target.ID) {
+					mu.Lock()
+					results[target.ID] = err
+					mu.Unlock()
+					return
+				}
+				log.Printf("[%s] 其他网站购买失败，重新开始监控", target.ID)
+			}
+		}(target)
+	}
+	log.Printf("[%s] 同时监控 %d 个售票网站", concert.ID, len(targets))
+	wg.Wait()
+
+	// 有网站取得购买权时以它的结果为准，否则返回所有网站的错误
+	if winner := r.result(); winner != "" {
+		log.Printf("[%s] 购买在 %s 进行", concert.ID, winner)
+		return results[winner]
+	}
+	var all []error
+	for _, target := range targets {
+		if err := results[target.ID]; err != nil {
+			all = append(all, err)
+		}
+	}
+	return errors.Join(all...)
+}
+
+// listingConcerts 为每个售票网站生成一份演唱会配置，ID为 "<演唱会ID>@<站点>"
+func listingConcerts(config *models.Config, concert *models.Concert) []*models.Concert {
+	listings := concert.Listings
+	if concert.URL != "" {
+		listings = append([]models.Listing{{Site: concert.Site, URL: concert.URL}}, listings...)
+	}
+
+	var targets []*models.Concert
+	for _, l := range listings {
+		target := *concert
+		target.Listings = nil
+		target.URL = l.URL
+		target.Site = l.Site
+		if target.Site == "" {
+			target.Site = grabber.SiteForURL(config, l.URL)
+		}
+		target.ID = concert.ID + "@" + target.Site
+		// 转让页面只需由一个任务跟踪
+		if len(targets) > 0 {
+			target.Resale = nil
+		}
+		targets = append(targets, &target)
+	}
+	return targets
+}
+
+// race 多个网站之间的购买权
+type race struct {
+	mu     sync.Mutex
+	winner string
+	// finished 取得购买权的任务已经结束(购买成功或放弃)，暂停的任务不再恢复
+	finished bool
+	cancels  map[string]context.CancelFunc
+	// paused 因其他网站取得购买权而被取消的任务
+	paused map[string]bool
+	// changed 购买权变化时关闭并替换，用于唤醒等待中的任务
+	changed chan struct{}
+}
+
+// newRace 创建购买权
+func newRace() *race {
+	return &race{
+		cancels: make(map[string]context.CancelFunc),
+		paused:  make(map[string]bool),
+		changed: make(chan struct{}),
+	}
+}
+
+// start 为任务创建可被其他网站取消的context
+func (r *race) start(ctx context.Context, id string) (context.Context, context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	r.cancels[id] = cancel
+	return taskCtx, cancel
+}
+
+// claim 第一个调用者取得购买权并暂停其他网站的任务
+func (r *race) claim(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner != "" {
+		return r.winner == id
+	}
+	r.winner = id
+	for other, cancel := range r.cancels {
+		if other != id {
+			r.paused[other] = true
+			cancel()
+		}
+	}
+	r.notify()
+	log.Printf("[%s] 最先发现有票，暂停其他网站的任务", id)
+	return true
+}
+
+// release 购买失败时交还购买权，唤醒暂停的任务
+func (r *race) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner != id {
+		return
+	}
+	r.winner = ""
+	r.notify()
+	log.Printf("[%s] 购买失败，交还购买权", id)
+}
+
+// resume 任务结束后调用，返回是否应重新运行任务：
+// 被暂停的任务等待取得购买权的任务的结果，对方交还购买权时返回true，购买结束或ctx取消时返回false
+func (r *race) resume(ctx context.Context, id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.winner == id {
+		r.finished = true
+		r.notify()
+		return false
+	}
+	for r.paused[id] {
+		switch {
+		case r.finished || ctx.Err() != nil:
+			return false
+		case r.winner == "":
+			delete(r.paused, id)
+			return true
+		}
+
+		changed := r.changed
+		r.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+		r.mu.Lock()
+	}
+	return false
+}
+
+// result 返回最终取得购买权的网站，没有时返回空字符串
+func (r *race) result() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.winner
+}
+
+// notify 唤醒等待购买权变化的任务，调用方需持有锁
+func (r *race) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/navwatch"
+	"tickgrabber/pkg/session"
+)
+
+// loginCommand 只执行登录流程并报告结果，用于在开售前单独排查账号密码和二次验证问题
+// 用法: login [--site 站点] [--timeout 秒]，登录后截图，成功时把会话保存到Cookie文件和共享存储
+func loginCommand(config *models.Config, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	site := fs.String("site", config.Ticketing.DefaultSite, "登录的站点")
+	timeout := fs.Int("timeout", 120, "提交后等待离开登录页的最长秒数，需要手动完成二次验证时可加大")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, ok := config.Ticketing.Sites[*site]
+	if !ok {
+		return fmt.Errorf("配置中没有站点 %s", *site)
+	}
+	if config.User.Username == "" {
+		log.Println("没有配置 user.username，登录流程文件需要自行填写账号")
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	b, err := newDriver(ctx, config, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	apiClient := api.NewClient(api.WithConfig(config))
+	tg := grabber.New(b, apiClient, config, grabber.Options{})
+	defer tg.Close()
+
+	start := time.Now()
+	err = tg.Login(ctx, *site)
+	if err == nil {
+		err = waitLoggedIn(ctx, b, cfg, time.Duration(*timeout)*time.Second)
+	}
+	shot := fmt.Sprintf("screenshots/login_%s_%s.png", *site, time.Now().Format("20060102_150405"))
+	if serr := b.Screenshot(ctx, shot); serr != nil {
+		log.Printf("登录后截图失败: %v", serr)
+	}
+	if err != nil {
+		return fmt.Errorf("%s 登录失败: %w", cfg.Name, err)
+	}
+
+	// 用同步到API客户端的Cookie请求会话检查页面，确认抢票时的接口请求也能使用这个会话
+	if err := session.NewBridge(b, apiClient, 0).Sync(ctx); err != nil {
+		return fmt.Errorf("读取浏览器Cookie失败: %w", err)
+	}
+	if err := apiClient.CheckSession(ctx, *site); err != nil {
+		return fmt.Errorf("%s 登录后会话检查未通过: %w", cfg.Name, err)
+	}
+	tg.SaveSession(ctx)
+
+	fmt.Printf("✓ %s 登录成功，用时 %s\n", cfg.Name, time.Since(start).Round(100*time.Millisecond))
+	if path := config.User.CookieFile; path != "" {
+		fmt.Printf("  会话已保存到 %s\n", path)
+	}
+	return nil
+}
+
+// waitLoggedIn 提交登录表单后等待页面离开登录页，二次验证、设备确认等页面也视为登录页，
+// 有界面运行时可以在浏览器中手动完成，超时仍在登录页时返回错误
+func waitLoggedIn(ctx context.Context, b browser.Driver, site models.SiteConfig, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	start, prompted := time.Now(), false
+	for {
+		current, err := b.GetCurrentURL(ctx)
+		if err == nil && !navwatch.IsLogin(current, site) {
+			return nil
+		}
+		if err == nil && !prompted && time.Since(start) > 5*time.Second {
+			prompted = true
+			log.Printf("仍在登录页 %s，需要二次验证时请在浏览器中完成", current)
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%s 后仍停留在登录页 %s，请检查账号密码或二次验证", timeout, current)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,27 +3,55 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"tickgrabber/pkg/api"
 	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/browser/record"
+	"tickgrabber/pkg/captcha"
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/egress"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/httpd"
 	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/redact"
+	"tickgrabber/pkg/secrets"
+	"tickgrabber/pkg/tracing"
+	"tickgrabber/pkg/watchdog"
 )
 
 var (
 	configFile = flag.String("config", "config/config.json", "配置文件路径")
 	concertID  = flag.String("concert", "", "演唱会ID")
+	profile    = flag.String("profile", "", "叠加 profiles/<名称>.json 中的命名配置(账号、通知、代理等)")
 	headless   = flag.Bool("headless", false, "无头模式")
 	debug      = flag.Bool("debug", false, "调试模式")
+	workdir    = flag.String("workdir", "", "工作目录，配置中的相对路径以此为基准")
+	recordFile = flag.String("record", "", "将浏览器操作和页面录制到文件")
+	replayFile = flag.String("replay", "", "回放录制文件代替真实浏览器")
+	notifyOnly = flag.Bool("notify-only", false, "只监控余票并发送提醒，不启动浏览器购票")
+	plain      = flag.Bool("plain", false, "在终端中直接输出日志，不使用进度界面")
+	resultFile = flag.String("result-file", "", "前台抢票结束时把结果摘要写入JSON文件")
+	clockStart = flag.String("clock", "", "使用从该时间(RFC3339)开始的模拟时钟，等待开售和轮询间隔自动快进，用于演练")
+	strict     = flag.Bool("strict-config", false, "配置中有未知字段(如拼错的键名)时报错退出，默认只输出警告")
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run 运行程序并返回退出码，前台抢票的退出码见 grabber.Result
+func run() int {
 	flag.Parse()
 
 	// 设置日志
@@ -33,25 +61,44 @@ func main() {
 
 	log.Println("韩国演唱会抢票系统 - Go版本启动")
 
+	if *workdir != "" {
+		if err := os.Chdir(*workdir); err != nil {
+			log.Fatalf("切换工作目录失败: %v", err)
+		}
+	}
+
 	// 加载配置
 	config, err := loadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	if *profile != "" {
+		log.Printf("使用命名配置: %s", *profile)
+	}
+	setupMasking(config)
+	if err := setupEgress(config); err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer startTracing(config)()
+	if err := setupClock(); err != nil {
+		log.Fatalf("%v", err)
+	}
 
-	// 创建浏览器实例
-	browser, err := browser.NewBrowser(&browser.Options{
-		Headless: *headless,
-		Debug:    *debug,
-		Timeout:  30 * time.Second,
-	})
-	if err != nil {
-		log.Fatalf("创建浏览器失败: %v", err)
+	// 执行子命令
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Arg(0), flag.Args()[1:], config); err != nil {
+			log.Fatalf("%s 失败: %v", flag.Arg(0), err)
+		}
+		return grabber.ExitSuccess
 	}
-	defer browser.Close()
+	syncCalendar(config)
 
-	// 创建API客户端
-	apiClient := api.NewClient(config)
+	if *notifyOnly {
+		if err := runNotifyOnly(config); err != nil {
+			log.Fatalf("余票监控失败: %v", err)
+		}
+		return grabber.ExitSuccess
+	}
 
 	// 获取演唱会信息
 	var targetConcert *models.Concert
@@ -68,343 +115,310 @@ func main() {
 		targetConcert = &config.Concerts[0]
 	}
 
-	log.Printf("开始抢票: %s", targetConcert.Name)
+	// 多个售票网站时每个网站使用独立的浏览器
+	if len(targetConcert.Listings) > 0 {
+		ctx, cancel := signalContext()
+		defer cancel()
 
-	// 创建抢票任务
-	task := NewTicketGrabber(browser, apiClient, config)
+		wd := watchdog.New(
+			time.Duration(config.Health.CheckInterval)*time.Second,
+			config.Health.MaxMemoryMB,
+		)
+		startHealthServer(ctx, config, wd)
+		result := grabber.NewResult(targetConcert)
+		ctx, stopProgress := startProgress(grabber.WithResult(ctx, result), config, targetConcert.Name)
+		err := runConcert(ctx, config, targetConcert, wd, nil, nil, nil, nil)
+		stopProgress()
+		return finishRun(result, err)
+	}
 
-	// 设置信号处理
-	ctx, cancel := context.WithCancel(context.Background())
+	// 设置信号处理，收到退出信号时浏览器随之关闭
+	ctx, cancel := signalContext()
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
-		log.Println("收到退出信号，正在停止...")
-		cancel()
-	}()
-
-	// 开始抢票
-	err = task.Start(ctx, targetConcert)
+	// 创建浏览器实例
+	browser, err := newDriver(ctx, config, "")
 	if err != nil {
-		log.Fatalf("抢票失败: %v", err)
+		log.Fatalf("创建浏览器失败: %v", err)
 	}
-}
-
-// TicketGrabber 抢票器
-type TicketGrabber struct {
-	browser   *browser.Browser
-	apiClient *api.Client
-	config    *models.Config
-}
+	defer browser.Close()
 
-// NewTicketGrabber 创建新的抢票器
-func NewTicketGrabber(browser *browser.Browser, apiClient *api.Client, config *models.Config) *TicketGrabber {
-	return &TicketGrabber{
-		browser:   browser,
-		apiClient: apiClient,
-		config:    config,
+	// 创建API客户端
+	apiClient := api.NewClient(api.WithConfig(config))
+	if *debug {
+		apiClient.Use(api.LoggingMiddleware(apiClient.Logger()))
 	}
-}
 
-// Start 开始抢票
-func (tg *TicketGrabber) Start(ctx context.Context, concert *models.Concert) error {
-	log.Printf("开始为演唱会 %s 抢票", concert.Name)
+	log.Printf("开始抢票: %s", targetConcert.Name)
 
-	// 登录票务网站
-	err := tg.login(ctx)
-	if err != nil {
-		return fmt.Errorf("登录失败: %v", err)
-	}
+	// 创建抢票任务
+	task := grabber.New(browser, apiClient, config, grabber.Options{Clock: grabberClock(), OwnLog: true})
 
-	// 进入演唱会页面
-	err = tg.navigateToConcert(ctx, concert)
-	if err != nil {
-		return fmt.Errorf("进入演唱会页面失败: %v", err)
-	}
+	startHealthServer(ctx, config, task.Watchdog())
 
-	// 开始监控票务
-	return tg.monitorTickets(ctx, concert)
+	// 开始抢票
+	result := grabber.NewResult(targetConcert)
+	ctx, stopProgress := startProgress(grabber.WithResult(ctx, result), config, targetConcert.Name)
+	err = task.Start(ctx, targetConcert)
+	stopProgress()
+	task.Close()
+	return finishRun(result, err)
 }
 
-// login 登录票务网站
-func (tg *TicketGrabber) login(ctx context.Context) error {
-	log.Println("正在登录票务网站...")
-
-	// 根据配置选择登录方式
-	switch tg.config.Ticketing.DefaultSite {
-	case "interpark":
-		return tg.loginInterpark(ctx)
-	case "yes24":
-		return tg.loginYes24(ctx)
-	case "melon":
-		return tg.loginMelon(ctx)
-	default:
-		return fmt.Errorf("不支持的票务网站: %s", tg.config.Ticketing.DefaultSite)
+// finishRun 记录抢票结果，指定了--result-file时写出结果，返回退出码
+func finishRun(result *grabber.Result, err error) int {
+	result.Finish(err)
+	if err != nil {
+		log.Printf("抢票失败: %v", err)
+	}
+	if *resultFile != "" {
+		if err := result.Write(*resultFile); err != nil {
+			log.Printf("写入结果文件失败: %v", err)
+		}
 	}
+	log.Printf("运行结果: %s (退出码 %d)", result.Outcome, result.ExitCode)
+	return result.ExitCode
 }
 
-// loginInterpark 登录Interpark
-func (tg *TicketGrabber) loginInterpark(ctx context.Context) error {
-	loginURL := tg.config.Ticketing.Sites["interpark"].LoginURL
-
-	err := tg.browser.Navigate(ctx, loginURL)
-	if err != nil {
-		return err
+// newDriver 按命令行参数创建浏览器：回放录制文件，或启动Chrome并按需录制，启用追踪时为浏览器操作创建span
+// 守护模式下每个任务使用独立的录制文件，name为任务名
+func newDriver(ctx context.Context, config *models.Config, name string) (browser.Driver, error) {
+	if *replayFile != "" {
+		log.Printf("回放浏览器会话: %s", *replayFile)
+		return record.NewPlayer(*replayFile)
 	}
 
-	// 填写用户名和密码
-	err = tg.browser.FillForm(ctx, map[string]string{
-		"username": tg.config.User.Username,
-		"password": tg.config.User.Password,
-	})
+	b, err := newChrome(ctx, config, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// 提交登录表单
-	err = tg.browser.SubmitForm(ctx)
-	if err != nil {
-		return err
+	if config.Tracing.Endpoint != "" {
+		b = browser.WithTracing(b)
 	}
+	// 监控循环、Cookie同步和健康检查共用同一个浏览器，经过队列避免CDP命令交错
+	return browser.NewQueue(b), nil
+}
 
-	log.Println("Interpark登录成功")
-	return nil
+// chromeOnce 浏览器只查找和检查一次，守护模式下多个任务共用结果
+var chromeOnce struct {
+	sync.Once
+	path string
+	err  error
 }
 
-// loginYes24 登录Yes24
-func (tg *TicketGrabber) loginYes24(ctx context.Context) error {
-	loginURL := tg.config.Ticketing.Sites["yes24"].LoginURL
+// findChrome 查找浏览器并检查版本
+func findChrome(config *models.Config) (string, error) {
+	chromeOnce.Do(func() {
+		path, err := browser.FindChrome(config.Browser.ExecPath)
+		if err != nil {
+			chromeOnce.err = err
+			return
+		}
+		// 结果由多个任务共用，版本检查不随单个任务取消
+		version, err := browser.CheckVersion(context.Background(), path, config.Browser.Version)
+		if err != nil {
+			chromeOnce.err = err
+			return
+		}
+		if version != "" {
+			log.Printf("使用浏览器: %s (%s)", path, version)
+		} else {
+			log.Printf("使用浏览器: %s", path)
+		}
+		chromeOnce.path = path
+	})
+	return chromeOnce.path, chromeOnce.err
+}
 
-	err := tg.browser.Navigate(ctx, loginURL)
-	if err != nil {
-		return err
+// newChrome 启动Chrome，设置了--record时录制会话，ctx取消时关闭浏览器
+func newChrome(ctx context.Context, config *models.Config, name string) (browser.Driver, error) {
+	remoteURL := os.Getenv("CHROME_WS_URL")
+	if remoteURL == "" {
+		remoteURL = config.Browser.RemoteURL
+	}
+	var execPath string
+	if remoteURL == "" {
+		var err error
+		if execPath, err = findChrome(config); err != nil {
+			return nil, err
+		}
 	}
 
-	// 填写登录信息
-	err = tg.browser.FillForm(ctx, map[string]string{
-		"userId": tg.config.User.Username,
-		"userPw": tg.config.User.Password,
-	})
+	b, err := browser.NewBrowser(ctx,
+		browser.WithHeadless(*headless || config.Browser.Headless),
+		browser.WithDebug(*debug),
+		browser.WithTimeout(30*time.Second),
+		browser.WithExecPath(execPath),
+		browser.WithRemoteURL(remoteURL),
+		browser.WithEgress(egress.Default),
+	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if *recordFile == "" {
+		return b, nil
 	}
 
-	// 提交登录
-	err = tg.browser.SubmitForm(ctx)
+	path := *recordFile
+	if name != "" {
+		ext := filepath.Ext(path)
+		path = strings.TrimSuffix(path, ext) + "_" + name + ext
+	}
+	recorder, err := record.NewRecorder(b, path)
 	if err != nil {
-		return err
+		b.Close()
+		return nil, err
 	}
-
-	log.Println("Yes24登录成功")
-	return nil
+	return recorder, nil
 }
 
-// loginMelon 登录Melon
-func (tg *TicketGrabber) loginMelon(ctx context.Context) error {
-	loginURL := tg.config.Ticketing.Sites["melon"].LoginURL
-
-	err := tg.browser.Navigate(ctx, loginURL)
-	if err != nil {
-		return err
-	}
+// simulatedClock --clock 指定的模拟时钟，为nil时使用系统时钟
+var simulatedClock clock.Clock
 
-	// 填写登录信息
-	err = tg.browser.FillForm(ctx, map[string]string{
-		"id": tg.config.User.Username,
-		"pw": tg.config.User.Password,
-	})
-	if err != nil {
-		return err
+// setupClock 按 --clock 创建快进的模拟时钟
+func setupClock() error {
+	if *clockStart == "" {
+		return nil
 	}
-
-	// 提交登录
-	err = tg.browser.SubmitForm(ctx)
+	start, err := time.Parse(time.RFC3339, *clockStart)
 	if err != nil {
-		return err
+		return fmt.Errorf("无效的 --clock 时间 %q，格式如 2026-11-20T19:59:00+09:00", *clockStart)
 	}
-
-	log.Println("Melon登录成功")
+	simulatedClock = clock.NewFastForward(start)
+	log.Printf("使用模拟时钟，从 %s 开始快进", start.Format("2006-01-02 15:04:05 MST"))
 	return nil
 }
 
-// navigateToConcert 进入演唱会页面
-func (tg *TicketGrabber) navigateToConcert(ctx context.Context, concert *models.Concert) error {
-	log.Printf("正在进入演唱会页面: %s", concert.URL)
-
-	err := tg.browser.Navigate(ctx, concert.URL)
-	if err != nil {
-		return err
+// grabberClock 返回抢票器使用的时钟
+func grabberClock() clock.Clock {
+	if simulatedClock != nil {
+		return simulatedClock
 	}
-
-	// 等待页面加载
-	time.Sleep(2 * time.Second)
-
-	log.Println("已进入演唱会页面")
-	return nil
+	return clock.Real
 }
 
-// monitorTickets 监控票务
-func (tg *TicketGrabber) monitorTickets(ctx context.Context, concert *models.Concert) error {
-	log.Println("开始监控票务...")
-
-	refreshInterval := time.Duration(tg.config.Ticketing.RefreshInterval*1000) * time.Millisecond
-	ticker := time.NewTicker(refreshInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("抢票任务已停止")
-			return nil
-		case <-ticker.C:
-			// 检查是否有票
-			available, err := tg.checkTicketAvailability(ctx)
-			if err != nil {
-				log.Printf("检查票务状态失败: %v", err)
-				continue
-			}
-
-			if available {
-				log.Println("发现可用票务！")
-
-				// 尝试购买
-				err = tg.purchaseTicket(ctx, concert)
-				if err != nil {
-					log.Printf("购买失败: %v", err)
-					continue
-				}
-
-				log.Println("购票成功！")
-				return nil
-			}
-
-			log.Println("暂无可用票务，继续监控...")
+// startTracing 按配置启用追踪，返回的函数在退出前导出剩余的span
+func startTracing(config *models.Config) func() {
+	shutdown, err := tracing.Setup(context.Background(), config.Tracing)
+	if err != nil {
+		log.Printf("启用追踪失败: %v", err)
+		return func() {}
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("导出追踪数据失败: %v", err)
 		}
 	}
 }
 
-// checkTicketAvailability 检查票务可用性
-func (tg *TicketGrabber) checkTicketAvailability(ctx context.Context) (bool, error) {
-	// 检查页面上的票务状态
-	selectors := []string{
-		".ticket-available",
-		".btn-buy",
-		"[data-status='available']",
-		".seat-available",
+// setupEgress 按配置启用出站白名单，此后默认HTTP客户端、API客户端和浏览器的请求都经过检查
+func setupEgress(config *models.Config) error {
+	allow, err := egress.FromConfig(config)
+	if err != nil || allow == nil {
+		return err
 	}
+	egress.Default = allow
+	http.DefaultTransport = allow.Transport(http.DefaultTransport)
+	log.Printf("出站白名单(%s): %s", config.Egress.Mode, strings.Join(allow.Domains(), ", "))
+	return nil
+}
 
-	for _, selector := range selectors {
-		exists, err := tg.browser.ElementExists(ctx, selector)
-		if err != nil {
-			continue
-		}
-		if exists {
-			return true, nil
+// setupMasking 把配置中的密码、令牌和收件信息加入脱敏列表，此后的日志输出都经过脱敏
+func setupMasking(config *models.Config) {
+	redact.Default.AddSecrets(configSecrets(config)...)
+	for _, pattern := range config.Logging.Mask {
+		if err := redact.Default.AddPattern(pattern); err != nil {
+			log.Printf("脱敏规则 %q 无效: %v", pattern, err)
 		}
 	}
 
-	return false, nil
+	log.SetOutput(redact.Default.Writer(os.Stderr))
 }
 
-// purchaseTicket 购买票务
-func (tg *TicketGrabber) purchaseTicket(ctx context.Context, concert *models.Concert) error {
-	log.Println("开始购买票务...")
-
-	// 选择座位
-	err := tg.selectSeats(ctx, concert)
-	if err != nil {
-		return fmt.Errorf("选择座位失败: %v", err)
-	}
-
-	// 确认购买
-	err = tg.confirmPurchase(ctx)
-	if err != nil {
-		return fmt.Errorf("确认购买失败: %v", err)
+// configSecrets 返回配置中需要脱敏的值: 密码、令牌、Webhook地址(路径中带有令牌)、网关地址和请求头、收件信息等
+// 同时返回JSON转义后的形式(如 & 转义为 \u0026)，以JSON输出的配置和请求体中也能匹配
+func configSecrets(config *models.Config) []string {
+	secrets := []string{
+		config.User.Username,
+		config.User.Password,
+		config.Proxy.Password,
+		config.Notification.Email.Password,
+		config.Notification.Telegram.BotToken,
+		config.Captcha.APIKey,
+		config.RPC.Token,
+		config.Health.Token,
+		config.SharedState.Password,
+		config.Tickets.Delivery.Recipient,
+		config.Tickets.Delivery.Phone,
+		config.Tickets.Delivery.Address,
+		config.Tickets.Delivery.AddressDetail,
+	}
+	for _, t := range config.RPC.Tokens {
+		secrets = append(secrets, t.Token)
+	}
+	for _, target := range config.Notification.Discord {
+		secrets = append(secrets, target.WebhookURL)
+	}
+	for _, target := range config.Notification.Slack {
+		secrets = append(secrets, target.WebhookURL)
+	}
+	for _, gateway := range config.Notification.Gateways {
+		secrets = append(secrets, gateway.URL)
+		for _, value := range gateway.Headers {
+			secrets = append(secrets, value)
+		}
 	}
-
-	// 处理支付
-	err = tg.handlePayment(ctx)
-	if err != nil {
-		return fmt.Errorf("处理支付失败: %v", err)
+	for _, callback := range config.Notification.Callbacks {
+		secrets = append(secrets, callback.Secret)
 	}
-
-	log.Println("票务购买完成！")
-	return nil
-}
-
-// selectSeats 选择座位
-func (tg *TicketGrabber) selectSeats(ctx context.Context, concert *models.Concert) error {
-	log.Println("正在选择座位...")
-
-	// 根据偏好选择座位
-	for _, preference := range concert.PreferredSeats {
-		selector := fmt.Sprintf("[data-seat-type='%s']", preference)
-		clicked, err := tg.browser.ClickElement(ctx, selector)
-		if err == nil && clicked {
-			log.Printf("已选择座位类型: %s", preference)
-			return nil
+	for _, concert := range config.Concerts {
+		secrets = append(secrets, concert.Presale.Code)
+		for _, value := range concert.Presale.Fields {
+			secrets = append(secrets, value)
+		}
+		if d := concert.Delivery; d != nil {
+			secrets = append(secrets, d.Recipient, d.Phone, d.Address, d.AddressDetail)
 		}
 	}
 
-	// 如果没有找到偏好座位，选择第一个可用座位
-	clicked, err := tg.browser.ClickElement(ctx, ".seat-available")
-	if err != nil || !clicked {
-		return fmt.Errorf("无法选择座位")
+	for _, secret := range secrets {
+		if quoted, err := json.Marshal(secret); err == nil {
+			if escaped := string(quoted[1 : len(quoted)-1]); escaped != secret {
+				secrets = append(secrets, escaped)
+			}
+		}
 	}
-
-	log.Println("座位选择完成")
-	return nil
+	return secrets
 }
 
-// confirmPurchase 确认购买
-func (tg *TicketGrabber) confirmPurchase(ctx context.Context) error {
-	log.Println("确认购买...")
-
-	// 点击购买按钮
-	selectors := []string{
-		".btn-purchase",
-		".btn-buy",
-		"[data-action='purchase']",
+// startHealthServer 运行看门狗并在配置了监听地址时提供/healthz和/metrics服务，直到ctx取消
+func startHealthServer(ctx context.Context, config *models.Config, wd *watchdog.Watchdog) {
+	if !config.Health.Enabled {
+		return
 	}
+	go wd.Run(ctx)
 
-	for _, selector := range selectors {
-		clicked, err := tg.browser.ClickElement(ctx, selector)
-		if err == nil && clicked {
-			log.Println("购买按钮点击成功")
-			return nil
-		}
+	if config.Health.Listen == "" {
+		return
 	}
 
-	return fmt.Errorf("无法找到购买按钮")
-}
-
-// handlePayment 处理支付
-func (tg *TicketGrabber) handlePayment(ctx context.Context) error {
-	log.Println("处理支付...")
-
-	// 等待支付页面加载
-	time.Sleep(3 * time.Second)
-
-	// 这里可以添加自动支付逻辑
-	// 目前只是等待用户手动完成支付
-	log.Println("请在浏览器中手动完成支付...")
-
-	// 等待支付完成
-	for i := 0; i < 60; i++ { // 最多等待60秒
-		time.Sleep(1 * time.Second)
-
-		// 检查是否支付成功
-		success, err := tg.browser.ElementExists(ctx, ".payment-success")
-		if err == nil && success {
-			log.Println("支付成功！")
-			return nil
-		}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", wd.Handler())
+	mux.Handle("/metrics", captcha.MetricsHandler())
+	server, err := httpd.New(config.Health, mux)
+	if err != nil {
+		log.Printf("健康检查服务配置无效: %v", err)
+		return
 	}
 
-	return fmt.Errorf("支付超时")
+	go func() {
+		log.Printf("健康检查服务监听于 %s", config.Health.Listen)
+		if err := server.Serve(ctx); err != nil {
+			log.Printf("健康检查服务启动失败: %v", err)
+		}
+	}()
 }
 
 // loadConfig 加载配置
@@ -413,16 +427,53 @@ func loadConfig(configFile string) (*models.Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if data, err = upgradeConfigFile(configFile, data); errors.Is(err, errConfigNotSaved) {
+		// 配置目录不可写时使用内存中升级后的配置继续运行，可以之后用 migrate-config 升级文件
+		log.Printf("警告: 配置文件 %s 已按版本 %d 加载，但%v", configFile, currentConfigVersion, err)
+	} else if err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		if data, err = applyProfile(data, configFile, *profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkUnknownFields(data); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	data, err = secrets.Resolve(ctx, data)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
 
 	var config models.Config
 	err = json.Unmarshal(data, &config)
 	if err != nil {
 		return nil, err
 	}
+	if err := notify.ValidateConfig(config.Notification); err != nil {
+		return nil, fmt.Errorf("通知配置无效: %w", err)
+	}
 
 	return &config, nil
 }
 
+// checkUnknownFields 检查配置中拼错或已不存在的键，--strict-config 时返回错误，否则输出警告
+func checkUnknownFields(data []byte) error {
+	fields, err := models.FindUnknownFields(data)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	uerr := &models.UnknownFieldsError{Fields: fields}
+	if *strict {
+		return uerr
+	}
+	log.Printf("警告: %v", uerr)
+	return nil
+}
+
 // findConcertByID 根据ID查找演唱会
 func findConcertByID(concerts []models.Concert, id string) *models.Concert {
 	for _, concert := range concerts {
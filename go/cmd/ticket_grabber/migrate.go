@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// currentConfigVersion 当前的配置格式版本。修改配置结构、旧配置不能直接使用时加1，
+// 并在 configMigrations 中添加把旧配置升级到新版本的步骤
+const currentConfigVersion = 1
+
+// configMigration 把配置从 version-1 升级到 version，apply 修改配置文件的JSON，
+// 应使用 replaceConfigKey 等保留原有格式的方法，只改动需要升级的部分
+type configMigration struct {
+	version int
+	summary string
+	apply   func(data []byte) ([]byte, error)
+}
+
+// configMigrations 按版本排列的升级步骤
+var configMigrations = []configMigration{
+	// 没有 config_version 的配置文件与版本1结构相同，只需记录版本
+	{version: 1, summary: "记录配置版本 config_version", apply: func(data []byte) ([]byte, error) { return data, nil }},
+}
+
+// configVersion 读取配置中的 config_version，没有时为0
+func configVersion(data []byte) (int, error) {
+	var head struct {
+		Version json.RawMessage `json:"config_version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return 0, err
+	}
+	if len(head.Version) == 0 {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(bytes.TrimSpace(head.Version)))
+	if err != nil {
+		return 0, fmt.Errorf("config_version 应为整数: %s", head.Version)
+	}
+	return version, nil
+}
+
+// errConfigNotSaved 配置已在内存中升级，但无法写回配置文件
+var errConfigNotSaved = errors.New("升级后的配置未能写回文件")
+
+// migrateConfig 把配置升级到当前版本，返回升级后的内容、原版本和执行的步骤
+func migrateConfig(data []byte) ([]byte, int, []string, error) {
+	return applyMigrations(data, configMigrations, currentConfigVersion)
+}
+
+// applyMigrations 依次执行migrations中高于原版本的步骤，把配置升级到target版本
+func applyMigrations(data []byte, migrations []configMigration, target int) ([]byte, int, []string, error) {
+	from, err := configVersion(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if from > target {
+		return nil, from, nil, fmt.Errorf("配置版本 %d 比程序支持的版本 %d 新，请升级程序", from, target)
+	}
+	if from == target {
+		return data, from, nil, nil
+	}
+
+	var applied []string
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+		if data, err = m.apply(data); err != nil {
+			return nil, from, applied, fmt.Errorf("升级到配置版本 %d (%s) 失败: %w", m.version, m.summary, err)
+		}
+		applied = append(applied, fmt.Sprintf("v%d: %s", m.version, m.summary))
+	}
+	data, err = replaceConfigKey(data, "config_version", []byte(strconv.Itoa(target)))
+	if err != nil {
+		return nil, from, applied, err
+	}
+	return data, from, applied, nil
+}
+
+// upgradeConfigFile 配置文件版本较旧时原地升级，原文件备份为 <文件>.v<原版本>.bak，返回升级后的内容
+// 配置目录不可写(如/etc下的服务配置、只读挂载)时仍返回升级后的内容，错误为errConfigNotSaved
+func upgradeConfigFile(path string, data []byte) ([]byte, error) {
+	upgraded, from, applied, err := migrateConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if from == currentConfigVersion {
+		return data, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return upgraded, fmt.Errorf("%w: 备份配置文件失败: %v", errConfigNotSaved, err)
+	}
+	if err := writeConfigFile(path, upgraded); err != nil {
+		return upgraded, fmt.Errorf("%w: %v", errConfigNotSaved, err)
+	}
+	log.Printf("配置文件 %s 已从版本 %d 升级到 %d，原文件备份为 %s", path, from, currentConfigVersion, backup)
+	for _, step := range applied {
+		log.Printf("  %s", step)
+	}
+	return upgraded, nil
+}
+
+// migrateConfigCommand 升级指定的配置文件(如命名配置)到当前版本: migrate-config <文件>...
+func migrateConfigCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: migrate-config <文件>...")
+	}
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := upgradeConfigFile(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConfigVersion(t *testing.T) {
+	for data, want := range map[string]int{
+		`{}`:                              0,
+		`{"user": {}}`:                    0,
+		`{"config_version": 1}`:           1,
+		`{"config_version":  3 , "a": 1}`: 3,
+	} {
+		got, err := configVersion([]byte(data))
+		if err != nil || got != want {
+			t.Errorf("configVersion(%s) = %d, %v，期望 %d", data, got, err, want)
+		}
+	}
+
+	for _, data := range []string{`{"config_version": "1"}`, `{"config_version": 1.5}`, `[1]`, `{`} {
+		if _, err := configVersion([]byte(data)); err == nil {
+			t.Errorf("configVersion(%s) 应返回错误", data)
+		}
+	}
+}
+
+func TestApplyMigrations(t *testing.T) {
+	rename := func(data []byte) ([]byte, error) {
+		return bytes.ReplaceAll(data, []byte(`"old"`), []byte(`"new"`)), nil
+	}
+	double := func(data []byte) ([]byte, error) {
+		return replaceConfigKey(data, "retries", []byte("6"))
+	}
+	migrations := []configMigration{
+		{version: 1, summary: "记录版本", apply: func(data []byte) ([]byte, error) { return data, nil }},
+		{version: 2, summary: "重命名", apply: rename},
+		{version: 3, summary: "重试次数", apply: double},
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		from    int
+		applied []string
+		want    map[string]any
+	}{
+		{
+			name:    "没有版本",
+			data:    `{"old": 1, "retries": 3}`,
+			from:    0,
+			applied: []string{"v1: 记录版本", "v2: 重命名", "v3: 重试次数"},
+			want:    map[string]any{"new": 1.0, "retries": 6.0, "config_version": 3.0},
+		},
+		{
+			name:    "从中间版本升级",
+			data:    `{"config_version": 2, "old": 1, "retries": 3}`,
+			from:    2,
+			applied: []string{"v3: 重试次数"},
+			want:    map[string]any{"old": 1.0, "retries": 6.0, "config_version": 3.0},
+		},
+		{
+			name: "已是最新版本",
+			data: `{"config_version": 3, "old": 1}`,
+			from: 3,
+			want: map[string]any{"old": 1.0, "config_version": 3.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, from, applied, err := applyMigrations([]byte(tt.data), migrations, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from || !slices.Equal(applied, tt.applied) {
+				t.Errorf("原版本 %d 步骤 %q，期望 %d %q", from, applied, tt.from, tt.applied)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("升级后不是有效的JSON: %v\n%s", err, data)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("升级后 %v，期望 %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %v，期望 %v", k, got[k], v)
+				}
+			}
+		})
+	}
+
+	if _, _, _, err := applyMigrations([]byte(`{"config_version": 4}`), migrations, 3); err == nil {
+		t.Error("配置版本比程序新时应返回错误")
+	}
+
+	failing := append(slices.Clone(migrations[:1]), configMigration{
+		version: 2, summary: "失败", apply: func([]byte) ([]byte, error) { return nil, errors.New("boom") },
+	})
+	if _, _, applied, err := applyMigrations([]byte(`{}`), failing, 2); err == nil || len(applied) != 1 {
+		t.Errorf("步骤失败时应返回错误和已执行的步骤，得到 %q, %v", applied, err)
+	}
+}
+
+func TestMigrateConfigCurrent(t *testing.T) {
+	last := configMigrations[len(configMigrations)-1].version
+	if last != currentConfigVersion {
+		t.Fatalf("configMigrations 最后的版本 %d 与 currentConfigVersion %d 不一致", last, currentConfigVersion)
+	}
+
+	data, from, _, err := migrateConfig([]byte(`{"user": {"username": "a"}}`))
+	if err != nil || from != 0 {
+		t.Fatalf("migrateConfig = %d, %v", from, err)
+	}
+	if version, _ := configVersion(data); version != currentConfigVersion {
+		t.Errorf("升级后版本 %d，期望 %d", version, currentConfigVersion)
+	}
+}
+
+func TestUpgradeConfigFileNotWritable(t *testing.T) {
+	// 配置所在的目录不存在，备份和写回都会失败，与只读目录的情况相同
+	path := filepath.Join(t.TempDir(), "missing", "config.json")
+	data := []byte(`{"user": {}}`)
+
+	upgraded, err := upgradeConfigFile(path, data)
+	if !errors.Is(err, errConfigNotSaved) {
+		t.Fatalf("无法写入时应返回 errConfigNotSaved，得到 %v", err)
+	}
+	if version, _ := configVersion(upgraded); version != currentConfigVersion {
+		t.Errorf("应返回内存中升级后的配置，版本 %d", version)
+	}
+}
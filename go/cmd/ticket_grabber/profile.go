@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileDir 配置文件同目录下存放命名配置的目录
+const profileDir = "profiles"
+
+// profilePath 返回命名配置的文件：名称中含路径或以.json结尾时直接使用，否则为 <配置目录>/profiles/<名称>.json
+func profilePath(configFile, name string) string {
+	if strings.ContainsAny(name, `/\`) || strings.HasSuffix(name, ".json") {
+		return name
+	}
+	return filepath.Join(filepath.Dir(configFile), profileDir, name+".json")
+}
+
+// applyProfile 把命名配置叠加到基础配置上：对象按字段递归合并，数组和其他值整体替换
+// 命名配置通常只包含账号(user)、通知(notification)和代理(proxy)等与账号相关的部分；
+// 没有单独指定Cookie文件和订单记录时，按配置名称区分文件，避免不同账号共用登录会话和重复订单检查
+func applyProfile(base []byte, configFile, name string) ([]byte, error) {
+	path := profilePath(configFile, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取命名配置 %s 失败: %w", name, err)
+	}
+
+	var merged, overlay map[string]interface{}
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("命名配置 %s 格式错误: %w", path, err)
+	}
+	mergeJSON(merged, overlay)
+
+	suffix := profileSuffix(name)
+	if !hasPath(overlay, "user", "cookie_file") {
+		renamePath(merged, suffix, "user", "cookie_file")
+	}
+	if !hasPath(overlay, "ticketing", "orders_file") {
+		renamePath(merged, suffix, "ticketing", "orders_file")
+	}
+	return json.Marshal(merged)
+}
+
+// mergeJSON 把overlay递归合并到dst
+func mergeJSON(dst, overlay map[string]interface{}) {
+	for key, value := range overlay {
+		sub, ok := value.(map[string]interface{})
+		existing, isMap := dst[key].(map[string]interface{})
+		if ok && isMap {
+			mergeJSON(existing, sub)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// hasPath 判断对象中是否有嵌套字段
+func hasPath(m map[string]interface{}, keys ...string) bool {
+	for i, key := range keys {
+		value, ok := m[key]
+		if !ok {
+			return false
+		}
+		if i == len(keys)-1 {
+			return true
+		}
+		if m, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// renamePath 在嵌套字段的文件名中加入后缀，如 data/cookies.json -> data/cookies.fanclub.json
+func renamePath(m map[string]interface{}, suffix string, section, key string) {
+	sub, ok := m[section].(map[string]interface{})
+	if !ok {
+		return
+	}
+	path, ok := sub[key].(string)
+	if !ok || path == "" {
+		return
+	}
+	ext := filepath.Ext(path)
+	sub[key] = strings.TrimSuffix(path, ext) + "." + suffix + ext
+}
+
+// profileSuffix 用于文件名的配置名称
+func profileSuffix(name string) string {
+	return strings.TrimSuffix(filepath.Base(name), ".json")
+}
+
+// listProfiles 列出可用的命名配置: profiles
+func listProfiles() error {
+	dir := filepath.Join(filepath.Dir(*configFile), profileDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s 中没有命名配置", dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		marker := " "
+		if name == *profile {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/snapshot"
+)
+
+// rehearse 开售前的演练：在本地打开保存的演出页面快照(HTML或HAR)，运行检测余票、预售验证、选座和领取方式的逻辑，
+// 检查选择器和座位评分，最后只定位购买按钮而不点击: rehearse <快照文件>
+// 演唱会由 --concert 指定，默认为配置中的第一个；演练不发送通知、不访问真实网站
+func rehearse(config *models.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: rehearse <快照.html|快照.har> [--concert 演唱会ID]")
+	}
+
+	var concert models.Concert
+	switch {
+	case *concertID != "":
+		found := findConcertByID(config.Concerts, *concertID)
+		if found == nil {
+			return fmt.Errorf("找不到ID为 %s 的演唱会", *concertID)
+		}
+		concert = *found
+	case len(config.Concerts) > 0:
+		concert = config.Concerts[0]
+	default:
+		return fmt.Errorf("配置中没有演唱会信息")
+	}
+
+	server, err := snapshot.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	log.Printf("快照页面: %s", server.URL)
+
+	// 演练使用配置的副本：不发送通知、不共享会话、不记录余票状态，修复的选择器不写回文件
+	rc := *config
+	rc.Notification = models.NotificationConfig{}
+	rc.SharedState = models.SharedStateConfig{}
+	rc.Ticketing.HistoryFile = ""
+	if rc.Ticketing.SelectorHealing == "persist" {
+		rc.Ticketing.SelectorHealing = "log"
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	b, err := newDriver(ctx, &rc, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	tg := grabber.New(b, api.NewClient(api.WithConfig(&rc)), &rc, grabber.Options{Clock: grabberClock()})
+	defer tg.Close()
+	concert.URL = server.URL
+
+	if err := b.Navigate(ctx, server.URL); err != nil {
+		return err
+	}
+
+	report := &rehearsalReport{}
+	tg.Rehearse(ctx, &concert, report)
+
+	if report.failed > 0 {
+		return fmt.Errorf("%d 个步骤未通过，可用 verify-selectors 检查选择器", report.failed)
+	}
+	log.Println("演练通过")
+	return nil
+}
+
+// rehearsalReport 在终端输出演练各步骤的结果
+type rehearsalReport struct {
+	failed int
+}
+
+// Step 输出一个步骤的结果
+func (r *rehearsalReport) Step(name string, err error, detail string) {
+	mark := "✓"
+	if err != nil {
+		mark, detail = "✗", err.Error()
+		r.failed++
+	}
+	fmt.Printf("%s %-8s %s\n", mark, name, detail)
+}
+
+// Scores 按区域输出座位评分
+func (r *rehearsalReport) Scores(venue string, scores []grabber.SectionScore) {
+	fmt.Printf("  %s 的座位评分(共 %d 个可选座位):\n", venue, len(scores))
+	for _, s := range uniqueSections(scores) {
+		if s.Rated {
+			fmt.Printf("    %-24s %d分\n", s.Section, s.Score)
+		} else {
+			fmt.Printf("    %-24s 无评分\n", s.Section)
+		}
+	}
+}
+
+// uniqueSections 按区域去重，保留页面上的顺序
+func uniqueSections(scores []grabber.SectionScore) []grabber.SectionScore {
+	seen := make(map[string]bool)
+	var unique []grabber.SectionScore
+	for _, s := range scores {
+		if !seen[s.Section] {
+			seen[s.Section] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+)
+
+// startPaymentReminders 在后台按付款期限发送提醒，直到ctx取消
+func startPaymentReminders(ctx context.Context, config *models.Config, store *order.Store, manager *notify.Manager) {
+	cfg := config.Notification.PaymentReminder
+	if !cfg.Enabled || store == nil || len(cfg.Offsets) == 0 {
+		return
+	}
+
+	reminder := order.NewReminder(store, cfg.Offsets, func(ctx context.Context, o *order.Order, left time.Duration, final bool) error {
+		// 错过付款期限订单会被取消，按紧急事件发送，不受免打扰时段影响
+		// 同步发送，只有至少一个渠道送达才记录为已提醒，否则下次检查时重试
+		title := "付款期限提醒"
+		if final {
+			title = "最后付款提醒"
+		}
+
+		delivered, err := manager.Deliver(ctx, notify.Event{
+			Type:     notify.EventPaymentReminder,
+			Severity: notify.SeverityCritical,
+			Title:    title,
+			Message:  fmt.Sprintf("订单将在 %s 后因未付款被取消", left.Round(time.Minute)),
+			Concert:  o.ConcertName,
+			URL:      o.URL,
+			OrderID:  o.ID,
+			Details: map[string]string{
+				"付款期限": o.PaymentDeadline.Format("2006-01-02 15:04"),
+				"金额":   fmt.Sprintf("%d원", o.Total),
+			},
+		})
+		if delivered == 0 {
+			if err == nil {
+				err = fmt.Errorf("没有渠道接收付款提醒")
+			}
+			return err
+		}
+		return nil
+	})
+
+	log.Printf("付款提醒已启用: 截止前 %v 分钟", cfg.Offsets)
+	go reminder.Run(ctx, time.Minute)
+}
+
+// markPaid 标记订单已付款，停止后续提醒: mark-paid <订单号>
+func markPaid(config *models.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: mark-paid <订单号>")
+	}
+
+	store, err := order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config))
+	if err != nil {
+		return err
+	}
+	if err := store.Update(args[0], func(o *order.Order) { o.Paid = true }); err != nil {
+		return err
+	}
+
+	log.Printf("订单 %s 已标记为已付款", args[0])
+	return nil
+}
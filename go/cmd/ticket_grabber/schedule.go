@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/scheduler"
+)
+
+// runScheduled 守护模式下按演唱会的监控时段运行任务
+// 时段之外等待，时段开始时运行，时段结束时停止任务并等待下一个时段；
+// 取得购买权后时段结束不再中断，购买失败交还购买权时如果时段已结束则立即停止
+func runScheduled(ctx context.Context, concert *models.Concert, claim func() bool, run func(ctx context.Context, claim func() bool, release func()) error) error {
+	calendar, err := scheduler.NewCalendar(concert.Schedule)
+	if err != nil {
+		return fmt.Errorf("监控时段配置错误: %w", err)
+	}
+	if calendar == nil {
+		return run(ctx, claim, nil)
+	}
+
+	clk := grabberClock()
+	if open := concert.SaleOpenTime; !open.IsZero() && open.After(clk.Now()) {
+		if start, _ := calendar.Next(open); start.After(open) {
+			log.Printf("[%s] 警告: 开售时间 %s 不在监控时段内", concert.ID, open.Format("01-02 15:04"))
+		}
+	}
+
+	status := progress.FromContext(ctx)
+	for {
+		start, end := calendar.Next(clk.Now())
+		if start.IsZero() {
+			return fmt.Errorf("之后没有监控时段")
+		}
+
+		if wait := clock.Until(clk, start); wait > 0 {
+			log.Printf("[%s] 不在监控时段内，%s 开始监控", concert.ID, start.Format("01-02 15:04 MST"))
+			status.SetStage("等待监控时段", "")
+			status.SetCountdown("距开始", start)
+
+			if err := clock.Sleep(ctx, clk, wait); err != nil {
+				return err
+			}
+		}
+		log.Printf("[%s] 进入监控时段，%s 结束", concert.ID, end.Format("01-02 15:04 MST"))
+
+		windowCtx, cancel := context.WithCancel(ctx)
+		var mu sync.Mutex
+		claimed, ended := false, false
+		guarded := func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if ended || windowCtx.Err() != nil || claim != nil && !claim() {
+				return false
+			}
+			claimed = true
+			return true
+		}
+		// 购买失败交还购买权，时段已结束时停止任务
+		release := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			claimed = false
+			if ended {
+				cancel()
+			}
+		}
+		go func() {
+			timer := clk.NewTimer(clock.Until(clk, end))
+			defer timer.Stop()
+			select {
+			case <-windowCtx.Done():
+			case <-timer.C():
+				mu.Lock()
+				defer mu.Unlock()
+				ended = true
+				if !claimed {
+					cancel()
+				}
+			}
+		}()
+
+		err := run(windowCtx, guarded, release)
+		// 时段结束时停止的任务进入下一个时段，取得购买权后完成的任务直接返回结果
+		mu.Lock()
+		closed := ended && windowCtx.Err() != nil && ctx.Err() == nil
+		mu.Unlock()
+		cancel()
+		if !closed {
+			return err
+		}
+		log.Printf("[%s] 监控时段结束，停止监控", concert.ID)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/mocksite"
+	"tickgrabber/pkg/models"
+)
+
+// selftest 自检账号，只对内置的模拟网站有效
+const (
+	selftestUser     = "selftest"
+	selftestPassword = "selftest"
+)
+
+// selftestCommand 在内置的模拟网站上完整运行一次抢票(登录 → 排队 → 选座 → 下单 → 通知)并报告用时，
+// 用于确认安装、Chrome和通知渠道都能正常工作: selftest [--queue 时长] [--timeout 时长]
+// 使用配置的浏览器和通知渠道，站点、账号和选择器换成模拟网站的，订单等记录写到临时目录，不访问真实网站
+func selftestCommand(config *models.Config, args []string) error {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	queue := fs.Duration("queue", 3*time.Second, "模拟网站的排队时间")
+	timeout := fs.Duration("timeout", 3*time.Minute, "整个自检的最长时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	site := mocksite.New(mocksite.Options{
+		Username:   selftestUser,
+		Password:   selftestPassword,
+		QueueDelay: *queue,
+	})
+	server := site.Start()
+	defer server.Close()
+	log.Printf("模拟票务网站: %s", server.URL)
+
+	tmp, err := os.MkdirTemp("", "tickgrabber-selftest-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	rc := selftestConfig(config, server.URL, tmp)
+	concert := rc.Concerts[0]
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	ctx, stop := context.WithTimeout(ctx, *timeout)
+	defer stop()
+
+	start := time.Now()
+	b, err := newDriver(ctx, rc, "")
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+	launched := time.Since(start)
+
+	tg := grabber.New(b, api.NewClient(api.WithConfig(rc)), rc, grabber.Options{})
+	err = tg.Start(ctx, &concert)
+	// 等待通知发送完毕再检查结果
+	tg.Close()
+	if err != nil {
+		return fmt.Errorf("自检失败: %w", err)
+	}
+	orders := site.Orders()
+	if len(orders) == 0 {
+		return fmt.Errorf("自检失败: %s 内没有在模拟网站上下单", *timeout)
+	}
+
+	fmt.Printf("✓ 自检通过，用时 %s (启动浏览器 %s)\n", time.Since(start).Round(100*time.Millisecond), launched.Round(100*time.Millisecond))
+	fmt.Printf("  模拟订单 %s: %v\n", orders[0].ID, orders[0].Seats)
+	if n := len(tg.Notifier().Notifiers()); n > 0 {
+		fmt.Printf("  已向 %d 个通知渠道发送本次自检的通知，请确认已收到\n", n)
+	} else {
+		fmt.Println("  没有启用通知渠道")
+	}
+	return nil
+}
+
+// selftestConfig 返回指向模拟网站的配置副本: 保留浏览器和通知设置，站点、账号、演唱会换成模拟网站的，
+// 不共享会话、不使用代理和购票限额，订单、收据和任务产物写到临时目录
+func selftestConfig(config *models.Config, baseURL, dir string) *models.Config {
+	rc := *config
+	// 模拟网站的页面使用默认选择器和 interpark 的登录字段
+	rc.Ticketing.Sites = map[string]models.SiteConfig{"interpark": mocksite.SiteConfig(baseURL)}
+	rc.Ticketing.DefaultSite = "interpark"
+	rc.Ticketing.SelectorDir = ""
+	rc.Ticketing.FlowDir = ""
+	rc.Ticketing.CacheFile = ""
+	rc.Ticketing.HistoryFile = ""
+	rc.Ticketing.CalendarFeed = ""
+	rc.Ticketing.TimelineDir = ""
+	rc.Ticketing.Warmup = models.WarmupConfig{}
+	rc.Ticketing.Payment = models.PaymentConfig{}
+	rc.Ticketing.OrdersFile = filepath.Join(dir, "orders.json")
+	rc.Ticketing.ReceiptDir = filepath.Join(dir, "receipts")
+	rc.Ticketing.ArtifactDir = filepath.Join(dir, "artifacts")
+	rc.Ticketing.EncryptArtifacts = false
+	rc.User = models.UserConfig{Username: selftestUser, Password: selftestPassword}
+	rc.Proxy = models.ProxyConfig{}
+	rc.Tickets.Guardrails = models.Guardrails{}
+	rc.Captcha.Relay = ""
+	rc.SharedState = models.SharedStateConfig{}
+	rc.Leader = models.LeaderConfig{}
+	rc.Health.Enabled = false
+	rc.Concerts = []models.Concert{{
+		ID:   "selftest",
+		Name: "自检模拟演出",
+		Site: "interpark",
+		URL:  mocksite.ConcertURL(baseURL, "selftest"),
+	}}
+	return &rc
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/redact"
+	"tickgrabber/pkg/rpc"
+	"tickgrabber/pkg/task"
+	"tickgrabber/pkg/watchdog"
+)
+
+// inactiveStatuses 守护模式下跳过的演唱会状态
+var inactiveStatuses = map[string]bool{
+	"done":     true,
+	"disabled": true,
+	"paused":   true,
+}
+
+// browsers 守护模式下的浏览器资源池，requests 为所有任务共用的请求限速器，单任务模式下均为nil(不限制)
+var (
+	browsers *task.Pool
+	requests *api.RateLimiter
+)
+
+// serve 守护模式：为所有启用的演唱会并发运行抢票任务
+// 启用gRPC接口时持续运行直到ctx取消，否则在所有任务结束后返回
+func serve(ctx context.Context, config *models.Config) error {
+	if err := setupLogFile(config.Logging.File); err != nil {
+		log.Printf("日志文件不可用，仅输出到控制台: %v", err)
+	}
+	syncCalendar(config)
+
+	var concerts []models.Concert
+	for _, concert := range config.Concerts {
+		if !inactiveStatuses[concert.Status] {
+			concerts = append(concerts, concert)
+		}
+	}
+	if len(concerts) == 0 && !config.RPC.Enabled {
+		return fmt.Errorf("配置中没有需要运行的演唱会")
+	}
+
+	wd := watchdog.New(
+		time.Duration(config.Health.CheckInterval)*time.Second,
+		config.Health.MaxMemoryMB,
+	)
+	startHealthServer(ctx, config, wd)
+
+	events := notify.NewBroadcaster()
+	browsers = task.NewPool(
+		browserSlots(config.Resources),
+		time.Duration(config.Resources.TimeSlice*float64(time.Second)),
+	)
+	requests = api.NewRateLimiter(config.Resources.MaxRequestsPerSecond)
+
+	// 所有任务共用订单记录，付款提醒在守护进程中持续运行
+	var orders *order.Store
+	if config.Ticketing.OrdersFile != "" {
+		var err error
+		if orders, err = order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config)); err != nil {
+			log.Printf("打开订单记录失败: %v", err)
+		}
+	}
+	reminders := notify.NewManager(config.Notification)
+	reminders.Tap(events)
+	defer closeNotifier(reminders)
+	startPaymentReminders(ctx, config, orders, reminders)
+	// 控制器不在本机运行任务，而是分配给工作节点
+	var controller *rpc.Controller
+	if config.Distributed.Role == "controller" {
+		if !config.RPC.Enabled {
+			return fmt.Errorf("分布式控制器需要启用rpc接口供工作节点连接")
+		}
+		controller = rpc.NewController(events, config.Distributed.Replicas)
+	}
+
+	tasks := task.NewManager(ctx, func(ctx context.Context, concert *models.Concert) error {
+		var err error
+		if controller != nil {
+			err = controller.Run(ctx, concert)
+		} else {
+			err = runScheduled(ctx, concert, nil, func(ctx context.Context, claim func() bool, release func()) error {
+				return runConcert(ctx, config, concert, wd, events, orders, claim, release)
+			})
+		}
+		if err != nil {
+			log.Printf("[%s] 任务结束: %v", concert.ID, err)
+		} else {
+			log.Printf("[%s] 任务完成", concert.ID)
+		}
+		return err
+	})
+
+	log.Printf("守护模式启动，共 %d 个任务", len(concerts))
+	for _, concert := range concerts {
+		if _, err := tasks.Submit(concert); err != nil {
+			log.Printf("[%s] 启动任务失败: %v", concert.ID, err)
+		}
+	}
+
+	if config.RPC.Enabled {
+		srv := rpc.NewServer(tasks, config.Concerts, events)
+		if controller != nil {
+			srv.SetController(controller)
+			log.Println("分布式模式: 任务将分配给连接的工作节点")
+		}
+		log.Printf("gRPC接口监听: %s", config.RPC.Listen)
+		if err := rpc.Serve(ctx, config.RPC, srv); err != nil {
+			return fmt.Errorf("gRPC服务失败: %w", err)
+		}
+	}
+
+	tasks.Wait()
+	log.Println("守护模式已停止")
+	return nil
+}
+
+// runTask 为单个演唱会创建浏览器和API客户端并运行抢票
+// events、orders 为nil时不转发事件、使用抢票器自己打开的订单记录；release 在购买失败时交还claim取得的购买权，可以为nil
+// 浏览器被更高优先级的任务抢占时关闭浏览器，重新排队后从头开始
+func runTask(ctx context.Context, config *models.Config, concert *models.Concert, wd *watchdog.Watchdog, events *notify.Broadcaster, orders *order.Store, claim func() bool, release func()) error {
+	status := progress.FromContext(ctx)
+	for {
+		if browsers != nil {
+			status.SetStage("排队等待浏览器", "")
+			status.SetQueue(func() int { return browsers.Position(concert.ID) })
+		}
+		lease, err := browsers.Acquire(ctx, concert.ID, concert.Priority)
+		if err != nil {
+			return err
+		}
+
+		// 发现有票后不再允许抢占
+		hold := func() bool {
+			if claim != nil && !claim() {
+				return false
+			}
+			lease.Hold()
+			return true
+		}
+		err = runLeased(lease.Context(), config, concert, wd, events, orders, hold, release)
+		lease.Release()
+
+		if ctx.Err() != nil || !lease.Preempted() {
+			return err
+		}
+		wd.Unregister("monitor:" + concert.ID)
+		wd.Unregister("browser:" + concert.ID)
+		log.Printf("[%s] 浏览器让给其他任务，排队等待重新分配", concert.ID)
+	}
+}
+
+// runLeased 在占用的浏览器资源内运行一次抢票
+func runLeased(ctx context.Context, config *models.Config, concert *models.Concert, wd *watchdog.Watchdog, events *notify.Broadcaster, orders *order.Store, claim func() bool, release func()) error {
+	b, err := newDriver(ctx, config, concert.ID)
+	if err != nil {
+		return fmt.Errorf("创建浏览器失败: %w", err)
+	}
+	defer b.Close()
+
+	apiClient := api.NewClient(api.WithConfig(config))
+	if *debug {
+		apiClient.Use(api.LoggingMiddleware(apiClient.Logger()))
+	}
+	if requests != nil {
+		apiClient.Use(api.LimiterMiddleware(requests))
+	}
+
+	tg := grabber.New(b, apiClient, config, grabber.Options{
+		Clock:    grabberClock(),
+		Watchdog: wd,
+		Orders:   orders,
+		Limiter:  requests,
+		Claim:    claim,
+		Release:  release,
+		Events:   events,
+	})
+	defer tg.Close()
+
+	return tg.Start(ctx, concert)
+}
+
+// browserSlots 按浏览器数量上限和内存预算计算可同时运行的浏览器数量，0为不限制
+func browserSlots(resources models.ResourceConfig) int {
+	slots := resources.MaxBrowsers
+	if resources.MaxMemoryMB > 0 {
+		perBrowser := resources.BrowserMemoryMB
+		if perBrowser <= 0 {
+			perBrowser = 300
+		}
+		byMemory := max(resources.MaxMemoryMB/perBrowser, 1)
+		if slots == 0 || byMemory < slots {
+			slots = byMemory
+		}
+	}
+	if slots > 0 {
+		log.Printf("最多同时运行 %d 个浏览器，其余任务排队", slots)
+	}
+	return slots
+}
+
+// closeNotifier 等待未发送的通知发送完毕
+func closeNotifier(manager *notify.Manager) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := manager.Close(ctx); err != nil {
+		log.Printf("关闭通知管理器: %v", err)
+	}
+}
+
+// signalContext 返回收到SIGINT/SIGTERM时取消的上下文
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigChan:
+			log.Println("收到退出信号，正在停止...")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+
+	return ctx, cancel
+}
+
+// setupLogFile 将日志同时写入文件，以服务方式运行时没有控制台可看
+func setupLogFile(path string) error {
+	f, err := openLogFile(path)
+	if err != nil || f == nil {
+		return err
+	}
+
+	log.SetOutput(redact.Default.Writer(io.MultiWriter(os.Stderr, f)))
+	return nil
+}
+
+// openLogFile 以追加方式打开日志文件，path为空时返回nil
+func openLogFile(path string) (*os.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+}
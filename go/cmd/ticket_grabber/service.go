@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// serviceName 注册到系统的服务名
+const serviceName = "tickgrabber"
+
+// serviceDescription 服务描述
+const serviceDescription = "韩国演唱会抢票系统"
+
+// serviceArgs 服务启动参数：使用绝对路径的配置文件和工作目录，以守护模式运行
+func serviceArgs() (exe string, args []string, err error) {
+	exe, err = os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("获取程序路径失败: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", nil, err
+	}
+
+	configPath, err := filepath.Abs(*configFile)
+	if err != nil {
+		return "", nil, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+
+	args = []string{"-config", configPath, "-workdir", wd}
+	if *profile != "" {
+		args = append(args, "-profile", *profile)
+	}
+	if *headless {
+		args = append(args, "-headless")
+	}
+	args = append(args, "serve")
+	return exe, args, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"tickgrabber/pkg/models"
+)
+
+// unitPath systemd单元文件路径
+const unitPath = "/etc/systemd/system/" + serviceName + ".service"
+
+// runService 守护模式入口，systemd通过SIGTERM停止服务
+func runService(config *models.Config) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+	return serve(ctx, config)
+}
+
+// installService 写入systemd单元文件并启用服务，异常退出后自动重启
+func installService(config *models.Config) error {
+	exe, args, err := serviceArgs()
+	if err != nil {
+		return err
+	}
+
+	quoted := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{exe}, args...) {
+		quoted = append(quoted, strconv.Quote(arg))
+	}
+	wd, _ := os.Getwd()
+
+	var unit strings.Builder
+	fmt.Fprintf(&unit, "[Unit]\nDescription=%s\nAfter=network-online.target\nWants=network-online.target\n\n", serviceDescription)
+	fmt.Fprintf(&unit, "[Service]\nType=simple\nWorkingDirectory=%s\nExecStart=%s\n", wd, strings.Join(quoted, " "))
+	unit.WriteString("Restart=on-failure\nRestartSec=5\nKillSignal=SIGTERM\nTimeoutStopSec=30\n")
+	unit.WriteString("StandardOutput=journal\nStandardError=journal\n\n")
+	unit.WriteString("[Install]\nWantedBy=multi-user.target\n")
+
+	if err := os.WriteFile(unitPath, []byte(unit.String()), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败（需要root权限）: %w", unitPath, err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", "--now", serviceName); err != nil {
+		return err
+	}
+
+	log.Printf("服务已安装: %s，日志: journalctl -u %s -f", unitPath, serviceName)
+	if config.Logging.File != "" {
+		log.Printf("日志同时写入: %s", config.Logging.File)
+	}
+	return nil
+}
+
+// uninstallService 停止并删除systemd服务
+func uninstallService() error {
+	if err := systemctl("disable", "--now", serviceName); err != nil {
+		log.Printf("停止服务失败: %v", err)
+	}
+
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 %s 失败: %w", unitPath, err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	log.Println("服务已卸载")
+	return nil
+}
+
+// systemctl 执行systemctl命令
+func systemctl(args ...string) error {
+	out, err := exec.CommandContext(context.Background(), "systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s 失败: %w, %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
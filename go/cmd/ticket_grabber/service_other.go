@@ -0,0 +1,27 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"tickgrabber/pkg/models"
+)
+
+// runService 守护模式入口
+func runService(config *models.Config) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+	return serve(ctx, config)
+}
+
+// installService 当前平台不支持注册系统服务
+func installService(config *models.Config) error {
+	return fmt.Errorf("%s 不支持安装服务，请直接运行 serve 命令", runtime.GOOS)
+}
+
+// uninstallService 当前平台不支持注册系统服务
+func uninstallService() error {
+	return fmt.Errorf("%s 不支持卸载服务", runtime.GOOS)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"tickgrabber/pkg/models"
+)
+
+// runService 守护模式入口，由服务管理器启动时通过svc.Run响应停止请求
+func runService(config *models.Config) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		ctx, cancel := signalContext()
+		defer cancel()
+		return serve(ctx, config)
+	}
+
+	return svc.Run(serviceName, &serviceHandler{config: config})
+}
+
+// serviceHandler Windows服务处理器
+type serviceHandler struct {
+	config *models.Config
+}
+
+// Execute 运行守护模式直到收到停止或关机请求
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, h.config)
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Printf("守护模式异常退出: %v", err)
+				// 非零退出码触发服务的恢复策略
+				return false, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+				select {
+				case <-done:
+				case <-time.After(30 * time.Second):
+					log.Println("等待任务停止超时")
+				}
+				return false, 0
+			}
+		}
+	}
+}
+
+// installService 注册Windows服务，自动启动，异常退出后5秒重启
+func installService(config *models.Config) error {
+	exe, args, err := serviceArgs()
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需要管理员权限）: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("服务 %s 已存在", serviceName)
+	}
+
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDescription,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %w", err)
+	}
+	defer s.Close()
+
+	recovery := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+	}
+	if err := s.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		log.Printf("设置恢复策略失败: %v", err)
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("启动服务失败: %w", err)
+	}
+
+	log.Printf("服务已安装: %s", serviceName)
+	if config.Logging.File != "" {
+		log.Printf("日志写入: %s", config.Logging.File)
+	}
+	return nil
+}
+
+// uninstallService 停止并删除Windows服务
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需要管理员权限）: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("服务 %s 不存在", serviceName)
+	}
+	defer s.Close()
+
+	if _, err := s.Control(svc.Stop); err != nil {
+		log.Printf("停止服务失败: %v", err)
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %w", err)
+	}
+
+	log.Println("服务已卸载")
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/redact"
+	"tickgrabber/pkg/rpc"
+	"tickgrabber/pkg/task"
+)
+
+// stateNames 任务状态的显示名称
+var stateNames = map[task.State]string{
+	task.StatePending:   "等待中",
+	task.StateRunning:   "运行中",
+	task.StateSucceeded: "成功",
+	task.StateFailed:    "失败",
+	task.StateCanceled:  "已取消",
+}
+
+// startProgress 前台运行时在交互式终端上用进度界面代替滚动的日志，返回带有进度的ctx和关闭界面的函数
+// 界面下方只保留最近几行日志，完整日志写入 logging.file；--plain、--debug 或输出不是终端时照常输出日志
+func startProgress(ctx context.Context, config *models.Config, title string) (context.Context, func()) {
+	status := progress.New()
+	ctx = progress.NewContext(ctx, status)
+	if *plain || *debug || !progress.IsTerminal(os.Stderr) {
+		return ctx, func() {}
+	}
+
+	display := progress.NewDisplay(os.Stderr, title, status)
+	var out io.Writer = display
+	f, err := openLogFile(config.Logging.File)
+	if err != nil {
+		log.Printf("日志文件不可用: %v", err)
+	} else if f != nil {
+		out = io.MultiWriter(display, f)
+	}
+	log.SetOutput(redact.Default.Writer(out))
+
+	displayCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		display.Run(displayCtx)
+	}()
+
+	return ctx, func() {
+		cancel()
+		<-done
+		log.SetOutput(redact.Default.Writer(os.Stderr))
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// statusCommand 查询守护进程中的任务和实时进度，--watch 时每秒刷新
+func statusCommand(config *models.Config, args []string) error {
+	watch := len(args) > 0 && (args[0] == "--watch" || args[0] == "-w")
+	if !config.RPC.Enabled {
+		return fmt.Errorf("配置中没有启用rpc接口，无法查询守护进程")
+	}
+
+	client, err := rpc.Dial(dialAddress(config.RPC.Listen), config.RPC)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	for {
+		queryCtx, queryCancel := context.WithTimeout(ctx, 5*time.Second)
+		tasks, err := client.ListTasks(queryCtx)
+		queryCancel()
+		if err != nil {
+			return fmt.Errorf("查询守护进程失败: %w", err)
+		}
+
+		if watch {
+			// 清屏后重新输出
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+		printTasks(tasks, time.Now())
+		if !watch {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// printTasks 输出任务列表，运行中的任务附带进度
+func printTasks(tasks []task.Task, now time.Time) {
+	if len(tasks) == 0 {
+		fmt.Println("守护进程中没有任务")
+		return
+	}
+
+	for _, t := range tasks {
+		name := t.Concert.Name
+		if name == "" {
+			name = t.Concert.ID
+		}
+		fmt.Printf("%-8s %-6s %s\n", t.ID, stateNames[t.State], name)
+
+		switch {
+		case t.Progress != nil:
+			fmt.Printf("         %s\n", t.Progress.Summary(now))
+		case t.Error != "":
+			fmt.Printf("         %s\n", t.Error)
+		}
+		if !t.StartedAt.IsZero() && t.FinishedAt.IsZero() {
+			fmt.Printf("         已运行 %s\n", now.Sub(t.StartedAt).Round(time.Second))
+		}
+	}
+}
+
+// dialAddress 把监听地址转换为连接地址，只有端口时连接本机
+func dialAddress(listen string) string {
+	if strings.HasPrefix(listen, ":") {
+		return "localhost" + listen
+	}
+	return strings.Replace(listen, "0.0.0.0", "localhost", 1)
+}
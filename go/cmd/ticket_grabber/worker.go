@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/grabber"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/rpc"
+	"tickgrabber/pkg/task"
+	"tickgrabber/pkg/watchdog"
+)
+
+// runWorker 分布式模式的工作节点：连接控制器，运行分配给本节点的任务
+func runWorker(config *models.Config) error {
+	if config.Distributed.Controller == "" {
+		return fmt.Errorf("需要在 distributed.controller 中配置控制器地址")
+	}
+	if config.Distributed.Name == "" {
+		config.Distributed.Name, _ = os.Hostname()
+	}
+	if err := setupLogFile(config.Logging.File); err != nil {
+		log.Printf("日志文件不可用，仅输出到控制台: %v", err)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	wd := watchdog.New(
+		time.Duration(config.Health.CheckInterval)*time.Second,
+		config.Health.MaxMemoryMB,
+	)
+	startHealthServer(ctx, config, wd)
+
+	var orders *order.Store
+	if config.Ticketing.OrdersFile != "" {
+		var err error
+		if orders, err = order.OpenStore(config.Ticketing.OrdersFile, grabber.ArtifactKey(config)); err != nil {
+			log.Printf("打开订单记录失败: %v", err)
+		}
+	}
+
+	browsers = task.NewPool(
+		browserSlots(config.Resources),
+		time.Duration(config.Resources.TimeSlice*float64(time.Second)),
+	)
+	requests = api.NewRateLimiter(config.Resources.MaxRequestsPerSecond)
+
+	log.Printf("工作节点 %s 启动，控制器: %s", config.Distributed.Name, config.Distributed.Controller)
+	return rpc.RunWorker(ctx, config.Distributed, config.RPC,
+		func(ctx context.Context, concert *models.Concert, claim func() bool, events *notify.Broadcaster) error {
+			err := runScheduled(ctx, concert, claim, func(ctx context.Context, claim func() bool, release func()) error {
+				return runConcert(ctx, config, concert, wd, events, orders, claim, release)
+			})
+			if err != nil {
+				log.Printf("[%s] 任务结束: %v", concert.ID, err)
+			}
+			return err
+		})
+}
@@ -0,0 +1,247 @@
+package adapter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// infoTimeout 等待适配器返回adapter.info的时间
+const infoTimeout = 10 * time.Second
+
+// ErrClosed 适配器进程已退出
+var ErrClosed = errors.New("适配器进程已退出")
+
+// Process 运行中的适配器进程
+type Process struct {
+	name string
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	out  chan message
+	info Info
+
+	// mu 同一时间只执行一个请求，适配器发来的浏览器请求在等待响应期间处理
+	mu     sync.Mutex
+	nextID int64
+}
+
+// Start 启动适配器进程并获取其信息
+func Start(ctx context.Context, name string, config models.AdapterConfig) (*Process, error) {
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动适配器 %s 失败: %w", name, err)
+	}
+
+	p := &Process{name: name, cmd: cmd, in: in, out: make(chan message, 16)}
+	go p.readLoop(stdout)
+	go p.logLoop(stderr)
+
+	infoCtx, cancel := context.WithTimeout(ctx, infoTimeout)
+	defer cancel()
+	if err := p.call(infoCtx, nil, "adapter.info", nil, &p.info); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("适配器 %s 握手失败: %w", name, err)
+	}
+	if p.info.Name == "" {
+		p.info.Name = name
+	}
+	log.Printf("已加载适配器 %s，支持阶段: %v", p.info.Name, p.info.Stages)
+	return p, nil
+}
+
+// Info 返回适配器信息
+func (p *Process) Info() Info {
+	return p.info
+}
+
+// Run 执行一个抢票阶段，期间适配器通过browser.*请求操作浏览器b
+func (p *Process) Run(ctx context.Context, b browser.Driver, stage string, vars map[string]string) error {
+	return p.call(ctx, b, "stage.run", runParams{Stage: stage, Vars: vars}, nil)
+}
+
+// Close 关闭适配器进程
+func (p *Process) Close() error {
+	p.in.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(3 * time.Second):
+		p.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// call 发送请求并等待响应，等待期间处理适配器发来的请求
+func (p *Process) call(ctx context.Context, b browser.Driver, method string, params, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	id := p.nextID
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := p.send(message{ID: id, Method: method, Params: raw}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case msg, ok := <-p.out:
+			if !ok {
+				return ErrClosed
+			}
+			if msg.Method != "" {
+				p.handle(ctx, b, msg)
+				continue
+			}
+			if msg.ID != id {
+				log.Printf("适配器 %s 返回了未知请求的响应: %d", p.name, msg.ID)
+				continue
+			}
+			if msg.Error != "" {
+				return fmt.Errorf("适配器 %s: %s", p.name, msg.Error)
+			}
+			if result != nil && len(msg.Result) > 0 {
+				return json.Unmarshal(msg.Result, result)
+			}
+			return nil
+		case <-ctx.Done():
+			// 适配器仍在执行，状态已无法确定，结束进程
+			p.cmd.Process.Kill()
+			return ctx.Err()
+		}
+	}
+}
+
+// handle 执行适配器发来的请求
+func (p *Process) handle(ctx context.Context, b browser.Driver, msg message) {
+	if msg.Method == "log" {
+		var params logParams
+		json.Unmarshal(msg.Params, &params)
+		log.Printf("[%s] %s", p.name, params.Message)
+		return
+	}
+
+	reply := message{ID: msg.ID}
+	result, err := p.dispatch(ctx, b, msg)
+	if err != nil {
+		reply.Error = err.Error()
+	} else if reply.Result, err = json.Marshal(result); err != nil {
+		reply.Error = err.Error()
+	}
+	if msg.ID != 0 {
+		if err := p.send(reply); err != nil {
+			log.Printf("适配器 %s 响应发送失败: %v", p.name, err)
+		}
+	}
+}
+
+// dispatch 执行浏览器操作
+func (p *Process) dispatch(ctx context.Context, b browser.Driver, msg message) (interface{}, error) {
+	if b == nil {
+		return nil, fmt.Errorf("当前没有可用的浏览器")
+	}
+
+	var params browserParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, fmt.Errorf("参数格式错误: %w", err)
+		}
+	}
+
+	switch msg.Method {
+	case "browser.navigate":
+		return nil, b.Navigate(ctx, params.URL)
+	case "browser.fill":
+		return nil, b.FillForm(ctx, params.Fields)
+	case "browser.click":
+		return selectors.Click(ctx, b, params.Selector)
+	case "browser.exists":
+		_, ok := selectors.Locate(ctx, b, params.Selector)
+		return ok, nil
+	case "browser.text":
+		selector, ok := selectors.Locate(ctx, b, params.Selector)
+		if !ok {
+			return "", nil
+		}
+		return b.GetText(ctx, selector)
+	case "browser.script":
+		return b.ExecuteScript(ctx, params.Script)
+	case "browser.url":
+		return b.GetCurrentURL(ctx)
+	case "browser.source":
+		return b.PageSource(ctx)
+	case "browser.reload":
+		return nil, b.Reload(ctx)
+	default:
+		return nil, fmt.Errorf("未知方法: %s", msg.Method)
+	}
+}
+
+// send 写入一条消息
+func (p *Process) send(msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = p.in.Write(append(data, '\n'))
+	return err
+}
+
+// readLoop 读取适配器的输出，进程退出时关闭out
+func (p *Process) readLoop(r io.Reader) {
+	defer close(p.out)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			log.Printf("适配器 %s 输出无法解析: %s", p.name, scanner.Text())
+			continue
+		}
+		p.out <- msg
+	}
+}
+
+// logLoop 把适配器的stderr写入日志
+func (p *Process) logLoop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		log.Printf("[%s] %s", p.name, scanner.Text())
+	}
+}
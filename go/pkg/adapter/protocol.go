@@ -0,0 +1,72 @@
+// Package adapter 外部站点适配器
+//
+// 适配器是独立的进程，可以用任何语言编写，不需要修改或重新编译本项目即可支持小众站点。
+// 双方通过适配器的标准输入输出交换JSON消息，每行一条:
+//
+//	请求: {"id": 1, "method": "stage.run", "params": {...}}
+//	响应: {"id": 1, "result": {...}} 或 {"id": 1, "error": "..."}
+//
+// 抢票程序启动适配器后先调用 adapter.info，适配器返回名称和支持的阶段
+// (select_round、login、presale、select_seats、delivery、purchase):
+//
+//	{"name": "ticketlink", "stages": ["login", "select_seats", "purchase"]}
+//
+// 执行阶段时调用 stage.run，参数为 {"stage": "login", "vars": {...}}。
+// 在响应之前适配器可以反过来发送 browser.* 请求操作浏览器，抢票程序执行后返回结果，
+// 选择器同样支持 "text=" 和 "text~=" 按文字定位:
+//
+//	browser.navigate {"url"}              browser.fill   {"fields": {选择器: 值}}
+//	browser.click    {"selector"}         browser.exists {"selector"} -> bool
+//	browser.text     {"selector"} -> 文本  browser.script {"script"} -> 脚本结果
+//	browser.url      -> 当前地址          browser.source -> 页面源码
+//	browser.reload
+//
+// 适配器可以随时发送没有id的 log 消息 {"method": "log", "params": {"message": "..."}}，
+// 写入stderr的内容同样会记录到日志
+package adapter
+
+import "encoding/json"
+
+// message 协议中的一条消息，有method为请求，否则为响应
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Info adapter.info 的返回值
+type Info struct {
+	Name   string   `json:"name"`
+	Stages []string `json:"stages"`
+}
+
+// Supports 判断适配器是否支持某个阶段
+func (i Info) Supports(stage string) bool {
+	for _, s := range i.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// runParams stage.run 的参数
+type runParams struct {
+	Stage string            `json:"stage"`
+	Vars  map[string]string `json:"vars"`
+}
+
+// browserParams browser.* 请求的参数
+type browserParams struct {
+	URL      string            `json:"url"`
+	Selector string            `json:"selector"`
+	Script   string            `json:"script"`
+	Fields   map[string]string `json:"fields"`
+}
+
+// logParams log 消息的参数
+type logParams struct {
+	Message string `json:"message"`
+}
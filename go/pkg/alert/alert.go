@@ -0,0 +1,176 @@
+// Package alert 只提醒不购票的余票监控
+//
+// 用HTTP直接获取演出页面，按站点的 ticket.available 选择器判断是否有票，不启动浏览器，
+// 可以同时低成本地监控很多场演出。发现有票时发送带演出链接的通知，由用户手动购买
+package alert
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/history"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/selectors"
+)
+
+// 默认的检查间隔和重复提醒间隔
+const (
+	defaultInterval = 30 * time.Second
+	defaultCooldown = 10 * time.Minute
+)
+
+// Target 监控的演出
+type Target struct {
+	Concert models.Concert
+	Site    string
+}
+
+// Monitor 余票监控
+type Monitor struct {
+	client    *api.Client
+	selectors *selectors.Registry
+	notifier  *notify.Manager
+
+	// Interval 每场演出的检查间隔
+	Interval time.Duration
+	// Cooldown 持续有票时重复提醒的间隔
+	Cooldown time.Duration
+	// History 记录每次检查的余票状态，为nil时不记录
+	History *history.Log
+
+	// state 每场演出上一次的结果和提醒时间
+	state map[string]*targetState
+}
+
+// targetState 演出的监控状态
+type targetState struct {
+	available bool
+	alerted   time.Time
+	// appOnly 演出只在APP中开放预约，不再检查
+	appOnly bool
+}
+
+// NewMonitor 创建余票监控
+func NewMonitor(client *api.Client, registry *selectors.Registry, notifier *notify.Manager) *Monitor {
+	return &Monitor{
+		client:    client,
+		selectors: registry,
+		notifier:  notifier,
+		Interval:  defaultInterval,
+		Cooldown:  defaultCooldown,
+		state:     make(map[string]*targetState),
+	}
+}
+
+// Run 轮流检查所有演出直到ctx取消，请求均匀分布在检查间隔内，避免同时请求
+func (m *Monitor) Run(ctx context.Context, targets []Target) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	gap := m.Interval / time.Duration(len(targets))
+	ticker := time.NewTicker(gap)
+	defer ticker.Stop()
+
+	for i := 0; ; i = (i + 1) % len(targets) {
+		m.Check(ctx, targets[i])
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 检查一场演出，状态从无票变为有票或超过重复提醒间隔时发送通知
+func (m *Monitor) Check(ctx context.Context, t Target) {
+	s := m.state[t.Concert.ID]
+	if s == nil {
+		s = &targetState{}
+		m.state[t.Concert.ID] = s
+	}
+	if s.appOnly {
+		return
+	}
+
+	doc, err := m.client.GetDocument(ctx, t.Concert.URL)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[%s] 获取演出页面失败: %v", t.Concert.ID, err)
+		}
+		return
+	}
+
+	// 只在APP中开放预约的演出网页上不会出现购买按钮，提醒一次后不再检查
+	if selectors.MatchDocumentChain(doc, m.selectors.Get(t.Site, selectors.SaleAppOnly)) {
+		log.Printf("[%s] 演出只能在APP中预约，停止检查", t.Concert.ID)
+		s.appOnly = true
+		m.notifier.Notify(ctx, notify.Event{
+			Type:    notify.EventActionRequired,
+			Title:   "只能在APP中购票",
+			Message: "该演出只在APP中开放预约，请在手机APP中购买",
+			Concert: t.Concert.Name,
+			URL:     t.Concert.URL,
+		})
+		return
+	}
+
+	available := selectors.MatchDocumentChain(doc, m.selectors.Get(t.Site, selectors.TicketAvailable))
+	observation := history.Observation{ConcertID: t.Concert.ID, Site: t.Site, Available: available}
+	if available {
+		observation.Grades = documentGrades(doc, m.selectors.Get(t.Site, selectors.SeatAvailable))
+	}
+	if err := m.History.Record(observation); err != nil {
+		log.Printf("[%s] 记录余票状态失败: %v", t.Concert.ID, err)
+	}
+
+	changed := available != s.available
+	s.available = available
+
+	if !available {
+		if changed {
+			log.Printf("[%s] 已无票", t.Concert.ID)
+		}
+		return
+	}
+	if !changed && time.Since(s.alerted) < m.Cooldown {
+		return
+	}
+
+	log.Printf("[%s] 发现可用票务: %s", t.Concert.ID, t.Concert.URL)
+	s.alerted = time.Now()
+	m.notifier.Notify(ctx, notify.Event{
+		Type:    notify.EventTicketFound,
+		Title:   "发现可用票务",
+		Message: "请尽快打开链接手动购买",
+		Concert: t.Concert.Name,
+		URL:     t.Concert.URL,
+	})
+}
+
+// documentGrades 统计页面上各座位等级(data-seat-type 或 data-grade 属性)的可选座位数
+func documentGrades(doc *goquery.Document, chain selectors.Chain) map[string]int {
+	grades := make(map[string]int)
+	for _, selector := range chain {
+		// 按文字定位的选择器不对应具体座位
+		if _, _, ok := selectors.Text(selector); ok {
+			continue
+		}
+		doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+			grade := s.AttrOr("data-seat-type", s.AttrOr("data-grade", ""))
+			if grade != "" {
+				grades[grade]++
+			}
+		})
+		if len(grades) > 0 {
+			break
+		}
+	}
+	return grades
+}
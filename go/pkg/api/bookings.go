@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"tickgrabber/pkg/models"
+)
+
+// Booking 账号中已有的订单
+type Booking struct {
+	OrderID string `json:"order_id"`
+	Title   string `json:"title"`
+	Date    string `json:"date"`
+	Status  string `json:"status"`
+}
+
+// Canceled 订单是否已取消
+func (b Booking) Canceled() bool {
+	for _, word := range []string{"취소", "取消", "cancel"} {
+		if strings.Contains(strings.ToLower(b.Status), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paid 订单是否已付款
+func (b Booking) Paid() bool {
+	status := strings.ToLower(b.Status)
+	for _, word := range []string{"결제완료", "입금완료", "예매완료", "已付款", "支付完成", "paid"} {
+		if strings.Contains(status, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultBookingsPage 通用的"我的预订"页面选择器
+var defaultBookingsPage = models.BookingsPage{
+	Rows:    ".reservation-list tr, .booking-list li",
+	OrderID: ".reserve-no, .order-number",
+	Title:   ".title, .subject",
+	Date:    ".play-date, .date",
+	Status:  ".status, .state",
+}
+
+// ErrNoBookingsURL 站点没有配置bookings_url，无法查询订单列表
+var ErrNoBookingsURL = errors.New("站点未配置 bookings_url")
+
+// GetBookings 抓取站点"我的预订"页面中的订单，站点未配置bookings_url时返回ErrNoBookingsURL
+func (c *Client) GetBookings(ctx context.Context, site string) ([]Booking, error) {
+	cfg := c.config.Ticketing.Sites[site]
+	if cfg.BookingsURL == "" {
+		return nil, fmt.Errorf("%s: %w", site, ErrNoBookingsURL)
+	}
+
+	doc, err := c.GetDocument(ctx, cfg.BookingsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	page := cfg.BookingsPage
+	pick := func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}
+	rows := pick(page.Rows, defaultBookingsPage.Rows)
+	text := func(row *goquery.Selection, selector, def string) string {
+		return strings.Join(strings.Fields(row.Find(pick(selector, def)).First().Text()), " ")
+	}
+
+	var bookings []Booking
+	doc.Find(rows).Each(func(_ int, row *goquery.Selection) {
+		b := Booking{
+			OrderID: text(row, page.OrderID, defaultBookingsPage.OrderID),
+			Title:   text(row, page.Title, defaultBookingsPage.Title),
+			Date:    text(row, page.Date, defaultBookingsPage.Date),
+			Status:  text(row, page.Status, defaultBookingsPage.Status),
+		}
+		if b.OrderID != "" || b.Title != "" {
+			bookings = append(bookings, b)
+		}
+	})
+
+	return bookings, nil
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/shared"
+)
+
+// sharedTimeout 读写共享缓存的超时时间，超时按未命中处理
+const sharedTimeout = 2 * time.Second
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// Cache 带TTL的元数据缓存，用于票务、场馆、场次等不常变化的数据
+// path非空时每次写入都会持久化到文件，重启后继续使用未过期的条目
+// 设置了共享存储时本地未命中的键从共享存储读取，写入和删除同时作用于共享存储
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	ttl     time.Duration
+	path    string
+	shared  shared.Store
+	logger  *log.Logger
+}
+
+// NewCache 创建缓存，path为空时仅保存在内存中
+func NewCache(ttl time.Duration, path string) *Cache {
+	return newCache(ttl, path, log.Default())
+}
+
+// newCache 创建日志写到logger的缓存
+func newCache(ttl time.Duration, path string, logger *log.Logger) *Cache {
+	c := &Cache{
+		entries: make(map[string]cacheEntry),
+		ttl:     ttl,
+		path:    path,
+		logger:  logger,
+	}
+
+	if path != "" {
+		if err := c.load(); err != nil && !os.IsNotExist(err) {
+			logger.Printf("加载缓存文件失败: %v", err)
+		}
+	}
+
+	return c
+}
+
+// Get 读取未过期的缓存值到v，命中返回true
+func (c *Cache) Get(key string, v interface{}) bool {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.ExpiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		if entry, ok = c.loadShared(key); !ok {
+			return false
+		}
+	}
+	return json.Unmarshal(entry.Value, v) == nil
+}
+
+// Share 让多个实例共用缓存，InvalidatePrefix 和 Clear 只作用于本地
+func (c *Cache) Share(store shared.Store) {
+	c.shared = store
+}
+
+// loadShared 从共享存储读取条目并保存到本地
+func (c *Cache) loadShared(key string) (cacheEntry, bool) {
+	if c.shared == nil {
+		return cacheEntry{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+	data, ok, err := c.shared.Get(ctx, "cache:"+key)
+	if err != nil {
+		c.logger.Printf("读取共享缓存失败: %v", err)
+	}
+	var entry cacheEntry
+	if !ok || json.Unmarshal(data, &entry) != nil || time.Now().After(entry.ExpiresAt) {
+		return cacheEntry{}, false
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry, true
+}
+
+// storeShared 写入共享存储，entry为nil时删除
+func (c *Cache) storeShared(key string, entry *cacheEntry) {
+	if c.shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+	var err error
+	if entry == nil {
+		err = c.shared.Delete(ctx, "cache:"+key)
+	} else {
+		data, _ := json.Marshal(entry)
+		err = c.shared.Set(ctx, "cache:"+key, data, time.Until(entry.ExpiresAt))
+	}
+	if err != nil {
+		c.logger.Printf("写入共享缓存失败: %v", err)
+	}
+}
+
+// Set 写入缓存值
+func (c *Cache) Set(key string, v interface{}) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	entry := cacheEntry{Value: data, ExpiresAt: time.Now().Add(c.ttl)}
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	c.persist()
+	c.storeShared(key, &entry)
+}
+
+// Invalidate 删除指定键
+func (c *Cache) Invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+
+	c.persist()
+	c.storeShared(key, nil)
+}
+
+// InvalidatePrefix 删除所有以prefix开头的键
+func (c *Cache) InvalidatePrefix(prefix string) {
+	c.mu.Lock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+
+	c.persist()
+}
+
+// Clear 清空缓存
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]cacheEntry)
+	c.mu.Unlock()
+
+	c.persist()
+}
+
+// load 从文件加载缓存，丢弃已过期的条目
+func (c *Cache) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for key, entry := range entries {
+		if now.Before(entry.ExpiresAt) {
+			c.entries[key] = entry
+		}
+	}
+	return nil
+}
+
+// persist 将缓存写入文件
+func (c *Cache) persist() {
+	if c.path == "" {
+		return
+	}
+
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		c.logger.Printf("写入缓存文件失败: %v", err)
+		return
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		c.logger.Printf("写入缓存文件失败: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+)
+
+// maxStagedBlocks 预先构造占座请求的区域数量上限
+const maxStagedBlocks = 3
+
+// StagedRequest 预先构造好的占座请求，Block 为请求对应的区域
+type StagedRequest struct {
+	Block  string
+	Method string
+	URL    string
+	Body   []byte
+	Header http.Header
+}
+
+// StagedCheckout 开售前准备好的直接占座请求，按优先顺序排列
+type StagedCheckout struct {
+	Requests []StagedRequest
+	success  string
+	next     string
+}
+
+// StageCheckout 读取座位图，为匹配grades的区域(按grades的顺序)渲染占座请求，
+// 开售后只需发送一次POST。grades为空时使用座位图中的前几个区域
+// CSRF令牌在发送时由中间件附加，令牌在开售前刷新也不影响已构造的请求
+func (c *Client) StageCheckout(ctx context.Context, cfg models.DirectCheckout, vars map[string]string, grades []string) (*StagedCheckout, error) {
+	if cfg.HoldURL == "" {
+		return nil, fmt.Errorf("没有配置占座接口")
+	}
+
+	seatMapURL, err := renderRequest(cfg.SeatMapURL, vars, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.get(ctx, seatMapURL)
+	if err != nil {
+		return nil, fmt.Errorf("读取座位图失败: %w", err)
+	}
+	var seatMap interface{}
+	if err := DecodeJSON(body, &seatMap); err != nil {
+		return nil, fmt.Errorf("解析座位图失败: %w", err)
+	}
+	blocks, ok := lookupJSON(seatMap, cfg.Blocks).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("座位图中没有区域列表 %q", cfg.Blocks)
+	}
+
+	method := strings.ToUpper(cfg.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := cfg.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	staged := &StagedCheckout{success: cfg.Success, next: cfg.NextURL}
+	for _, block := range pickBlocks(blocks, cfg.Grade, grades) {
+		holdURL, err := renderRequest(cfg.HoldURL, vars, block)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := renderRequest(cfg.Body, vars, block)
+		if err != nil {
+			return nil, err
+		}
+
+		header := make(http.Header)
+		header.Set("Content-Type", contentType)
+		for name, value := range cfg.Headers {
+			header.Set(name, value)
+		}
+		staged.Requests = append(staged.Requests, StagedRequest{
+			Block:  fmt.Sprint(block[cfg.Grade]),
+			Method: method,
+			URL:    holdURL,
+			Body:   []byte(payload),
+			Header: header,
+		})
+	}
+	if len(staged.Requests) == 0 {
+		return nil, fmt.Errorf("座位图中没有匹配 %v 的区域", grades)
+	}
+	return staged, nil
+}
+
+// SubmitCheckout 依次发送预先构造的占座请求，返回第一个成功的请求和响应中的下一步页面地址
+// 会话过期(errs.ErrSessionExpired)或需要验证码(errs.ErrCaptchaRequired)时不再尝试其他区域
+func (c *Client) SubmitCheckout(ctx context.Context, staged *StagedCheckout) (*StagedRequest, string, error) {
+	var lastErr error
+	for i := range staged.Requests {
+		r := &staged.Requests[i]
+		body, err := c.send(ctx, r)
+		if errors.Is(err, errs.ErrSessionExpired) {
+			return nil, "", err
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("区域 %s: %w", r.Block, err)
+			continue
+		}
+
+		var result interface{}
+		if staged.success != "" || staged.next != "" {
+			if err := DecodeJSON(body, &result); err != nil {
+				lastErr = fmt.Errorf("区域 %s: 解析占座结果失败: %w", r.Block, err)
+				continue
+			}
+		}
+		if staged.success != "" && !truthy(lookupJSON(result, staged.success)) {
+			lastErr = fmt.Errorf("区域 %s: 占座未成功: %s", r.Block, truncate(body, 200))
+			if captchaResponse(body) {
+				// 需要验证码时其他区域的请求也会被拒绝
+				return nil, "", errs.Wrap(errs.ErrCaptchaRequired, "api.checkout", lastErr)
+			}
+			continue
+		}
+		next := ""
+		if staged.next != "" {
+			if v, ok := lookupJSON(result, staged.next).(string); ok {
+				next = v
+			}
+		}
+		return r, next, nil
+	}
+	return nil, "", lastErr
+}
+
+// send 发送预先构造的请求，每次发送使用新的请求体
+func (c *Client) send(ctx context.Context, r *StagedRequest) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	c.applyHeaderProfile(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	body, err = DecodeBody(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newHTTPError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// pickBlocks 按等级的优先顺序选出区域，同一等级中保持座位图中的顺序
+func pickBlocks(blocks []interface{}, gradeField string, grades []string) []map[string]interface{} {
+	var all []map[string]interface{}
+	for _, b := range blocks {
+		if m, ok := b.(map[string]interface{}); ok {
+			all = append(all, m)
+		}
+	}
+	if len(grades) == 0 {
+		if len(all) > maxStagedBlocks {
+			all = all[:maxStagedBlocks]
+		}
+		return all
+	}
+
+	var picked []map[string]interface{}
+	for _, grade := range grades {
+		for _, m := range all {
+			if len(picked) == maxStagedBlocks {
+				return picked
+			}
+			if strings.Contains(strings.ToUpper(fmt.Sprint(m[gradeField])), strings.ToUpper(grade)) {
+				picked = append(picked, m)
+			}
+		}
+	}
+	return picked
+}
+
+// renderRequest 渲染请求地址和请求体模板，block 中的字段通过 .block 引用
+func renderRequest(text string, vars map[string]string, block map[string]interface{}) (string, error) {
+	data := make(map[string]interface{}, len(vars)+1)
+	for k, v := range vars {
+		data[k] = v
+	}
+	if block != nil {
+		data["block"] = block
+	}
+
+	tmpl, err := template.New("checkout").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("模板错误 %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染 %q 失败: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// lookupJSON 按点分隔的路径取出JSON中的值，路径为空时返回整个值
+func lookupJSON(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// truthy 判断JSON值是否表示成功
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		switch strings.ToLower(t) {
+		case "", "0", "false", "n", "fail", "error":
+			return false
+		}
+		return true
+	default:
+		return v != nil
+	}
+}
+
+// captchaMarkers 占座响应中表示需要验证码的内容
+var captchaMarkers = []string{"captcha", "보안문자", "자동입력방지"}
+
+// captchaResponse 判断占座被拒绝是否因为需要验证码
+func captchaResponse(body []byte) bool {
+	text := strings.ToLower(string(body))
+	for _, marker := range captchaMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate 截断过长的响应用于错误信息
+func truncate(body []byte, n int) string {
+	if len(body) > n {
+		return string(body[:n]) + "..."
+	}
+	return string(body)
+}
@@ -4,29 +4,157 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
 	"time"
 
+	"tickgrabber/pkg/egress"
+	"tickgrabber/pkg/errs"
 	"tickgrabber/pkg/models"
 )
 
 // Client API客户端
 type Client struct {
-	config *models.Config
-	client *http.Client
+	config      *models.Config
+	client      *http.Client
+	transport   http.RoundTripper
+	middlewares []Middleware
+	metrics     *Metrics
+	csrf        *CSRFTokens
+	profile     models.HeaderProfile
+	userAgent   string
+	cache       *Cache
+	hints       *Hints
+	logger      *log.Logger
+	// opts 创建时的可选设置，登录时创建的站点客户端沿用
+	opts []Option
 }
 
-// NewClient 创建新的API客户端
-func NewClient(config *models.Config) *Client {
-	return &Client{
+// NewClient 创建新的API客户端，没有 WithConfig 时使用空配置，只能请求完整的地址
+func NewClient(opts ...Option) *Client {
+	o := newOptions(opts)
+	config := o.config
+	jar, _ := cookiejar.New(nil)
+
+	transport := NewTransport(config)
+	if o.proxy != nil {
+		transport.Proxy = http.ProxyURL(o.proxy)
+	}
+	userAgent := config.Browser.UserAgent
+	if o.userAgent != "" {
+		userAgent = o.userAgent
+	}
+
+	c := &Client{
 		config: config,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: o.timeout,
+			Jar:     jar,
 		},
+		transport: transport,
+		metrics:   &Metrics{},
+		csrf:      &CSRFTokens{},
+		hints:     NewHints(),
+		profile:   ResolveHeaderProfile(config, ""),
+		userAgent: userAgent,
+		cache: newCache(
+			time.Duration(config.Ticketing.CacheTTL*1000)*time.Millisecond,
+			config.Ticketing.CacheFile,
+			o.logger,
+		),
+		logger: o.logger,
+		opts:   opts,
+	}
+
+	retryDelay := time.Duration(config.Ticketing.RetryDelay*1000) * time.Millisecond
+	c.Use(
+		egress.Default.Transport,
+		TracingMiddleware(),
+		MetricsMiddleware(c.metrics),
+		RetryMiddleware(config.Ticketing.MaxRetries, retryDelay),
+		HintsMiddleware(c.hints),
+	)
+
+	return c
+}
+
+// Use 追加中间件，后追加的中间件更靠近底层传输
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.client.Transport = Chain(c.transport, c.middlewares...)
+}
+
+// Metrics 返回请求统计
+func (c *Client) Metrics() MetricsSnapshot {
+	return c.metrics.Snapshot()
+}
+
+// Logger 返回客户端使用的logger
+func (c *Client) Logger() *log.Logger {
+	return c.logger
+}
+
+// Hints 返回各主机最近响应中的请求频率提示
+func (c *Client) Hints() *Hints {
+	return c.hints
+}
+
+// ImportCookies 导入浏览器Cookie，使API请求与浏览器共享同一会话
+func (c *Client) ImportCookies(cookies []*http.Cookie) {
+	byURL := make(map[string][]*http.Cookie)
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		if host == "" {
+			continue
+		}
+
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+
+		// 以点开头的是域Cookie，否则只对该主机有效
+		imported := *cookie
+		if strings.HasPrefix(cookie.Domain, ".") {
+			imported.Domain = host
+		} else {
+			imported.Domain = ""
+		}
+
+		key := "https://" + host + path
+		byURL[key] = append(byURL[key], &imported)
+	}
+
+	for rawURL, group := range byURL {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		c.client.Jar.SetCookies(u, group)
+	}
+}
+
+// Cookies 返回指定URL当前携带的Cookie
+func (c *Client) Cookies(rawURL string) []*http.Cookie {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
 	}
+	return c.client.Jar.Cookies(u)
+}
+
+// newSiteClient 创建使用站点请求头和CSRF设置的客户端
+func newSiteClient(site string, opts []Option) *Client {
+	client := NewClient(opts...)
+	config := client.config
+	client.SetHeaderProfile(ResolveHeaderProfile(config, site))
+	client.Use(siteHeaders(config, site))
+	client.EnableCSRF(config.Ticketing.Sites[site].CSRF)
+	return client
 }
 
 // InterParkClient Interpark客户端
@@ -35,9 +163,9 @@ type InterParkClient struct {
 }
 
 // NewInterParkClient 创建Interpark客户端
-func NewInterParkClient(config *models.Config) *InterParkClient {
+func NewInterParkClient(opts ...Option) *InterParkClient {
 	return &InterParkClient{
-		Client: NewClient(config),
+		Client: newSiteClient("interpark", opts),
 	}
 }
 
@@ -47,9 +175,9 @@ type Yes24Client struct {
 }
 
 // NewYes24Client 创建Yes24客户端
-func NewYes24Client(config *models.Config) *Yes24Client {
+func NewYes24Client(opts ...Option) *Yes24Client {
 	return &Yes24Client{
-		Client: NewClient(config),
+		Client: newSiteClient("yes24", opts),
 	}
 }
 
@@ -59,10 +187,20 @@ type MelonClient struct {
 }
 
 // NewMelonClient 创建Melon客户端
-func NewMelonClient(config *models.Config) *MelonClient {
+func NewMelonClient(opts ...Option) *MelonClient {
 	return &MelonClient{
-		Client: NewClient(config),
+		Client: newSiteClient("melon", opts),
+	}
+}
+
+// siteHeaders 返回注入站点Referer和Origin的中间件
+func siteHeaders(config *models.Config, site string) Middleware {
+	headers := make(map[string]string)
+	if siteURL := config.Ticketing.Sites[site].URL; siteURL != "" {
+		headers["Referer"] = siteURL + "/"
+		headers["Origin"] = siteURL
 	}
+	return HeaderMiddleware(headers)
 }
 
 // LoginRequest 登录请求
@@ -107,7 +245,7 @@ type PurchaseResponse struct {
 
 // Login 登录
 func (c *Client) Login(ctx context.Context, site string) error {
-	log.Printf("登录到 %s", site)
+	c.logger.Printf("登录到 %s", site)
 
 	switch site {
 	case "interpark":
@@ -117,7 +255,7 @@ func (c *Client) Login(ctx context.Context, site string) error {
 	case "melon":
 		return c.loginMelon(ctx)
 	default:
-		return fmt.Errorf("不支持的网站: %s", site)
+		return errs.New(errs.ErrUnsupportedSite, "api.login", site)
 	}
 }
 
@@ -136,16 +274,16 @@ func (c *InterParkClient) Login(ctx context.Context) error {
 	}
 
 	var loginResp LoginResponse
-	err = json.Unmarshal(resp, &loginResp)
+	err = DecodeJSON(resp, &loginResp)
 	if err != nil {
 		return err
 	}
 
 	if !loginResp.Success {
-		return fmt.Errorf("登录失败: %s", loginResp.Message)
+		return errs.New(errs.ErrLoginFailed, "interpark.login", loginResp.Message)
 	}
 
-	log.Println("Interpark登录成功")
+	c.logger.Println("Interpark登录成功")
 	return nil
 }
 
@@ -164,16 +302,16 @@ func (c *Yes24Client) Login(ctx context.Context) error {
 	}
 
 	var loginResp LoginResponse
-	err = json.Unmarshal(resp, &loginResp)
+	err = DecodeJSON(resp, &loginResp)
 	if err != nil {
 		return err
 	}
 
 	if !loginResp.Success {
-		return fmt.Errorf("登录失败: %s", loginResp.Message)
+		return errs.New(errs.ErrLoginFailed, "yes24.login", loginResp.Message)
 	}
 
-	log.Println("Yes24登录成功")
+	c.logger.Println("Yes24登录成功")
 	return nil
 }
 
@@ -192,22 +330,29 @@ func (c *MelonClient) Login(ctx context.Context) error {
 	}
 
 	var loginResp LoginResponse
-	err = json.Unmarshal(resp, &loginResp)
+	err = DecodeJSON(resp, &loginResp)
 	if err != nil {
 		return err
 	}
 
 	if !loginResp.Success {
-		return fmt.Errorf("登录失败: %s", loginResp.Message)
+		return errs.New(errs.ErrLoginFailed, "melon.login", loginResp.Message)
 	}
 
-	log.Println("Melon登录成功")
+	c.logger.Println("Melon登录成功")
 	return nil
 }
 
-// GetTicketInfo 获取票务信息
+// GetTicketInfo 获取票务信息，命中缓存时不再请求网站
 func (c *Client) GetTicketInfo(ctx context.Context, concertID string) ([]TicketInfo, error) {
-	log.Printf("获取演唱会 %s 的票务信息", concertID)
+	cacheKey := ticketInfoCacheKey(concertID)
+
+	var cached []TicketInfo
+	if c.cache.Get(cacheKey, &cached) {
+		return cached, nil
+	}
+
+	c.logger.Printf("获取演唱会 %s 的票务信息", concertID)
 
 	// 这里应该调用实际的API
 	// 为了演示，返回模拟数据
@@ -236,12 +381,28 @@ func (c *Client) GetTicketInfo(ctx context.Context, concertID string) ([]TicketI
 		},
 	}
 
+	c.cache.Set(cacheKey, tickets)
 	return tickets, nil
 }
 
+// InvalidateTicketInfo 使演唱会的票务信息缓存失效
+func (c *Client) InvalidateTicketInfo(concertID string) {
+	c.cache.Invalidate(ticketInfoCacheKey(concertID))
+}
+
+// Cache 返回客户端的元数据缓存，供适配器缓存场馆、场次等数据
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
+// ticketInfoCacheKey 票务信息的缓存键
+func ticketInfoCacheKey(concertID string) string {
+	return "tickets:" + concertID
+}
+
 // PurchaseTicket 购买票务
 func (c *Client) PurchaseTicket(ctx context.Context, req PurchaseRequest) (*PurchaseResponse, error) {
-	log.Printf("购买票务: %s, 数量: %d", req.TicketID, req.Quantity)
+	c.logger.Printf("购买票务: %s, 数量: %d", req.TicketID, req.Quantity)
 
 	// 这里应该调用实际的购买API
 	// 为了演示，返回模拟响应
@@ -251,13 +412,13 @@ func (c *Client) PurchaseTicket(ctx context.Context, req PurchaseRequest) (*Purc
 		OrderID: "order_12345",
 	}
 
-	log.Printf("购票成功，订单号: %s", resp.OrderID)
+	c.logger.Printf("购票成功，订单号: %s", resp.OrderID)
 	return resp, nil
 }
 
 // CheckAvailability 检查票务可用性
 func (c *Client) CheckAvailability(ctx context.Context, concertID string) (bool, error) {
-	log.Printf("检查演唱会 %s 的票务可用性", concertID)
+	c.logger.Printf("检查演唱会 %s 的票务可用性", concertID)
 
 	// 这里应该调用实际的API检查
 	// 为了演示，返回随机结果
@@ -278,7 +439,7 @@ func (c *Client) post(ctx context.Context, url string, data interface{}) ([]byte
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", c.config.Browser.UserAgent)
+	c.applyHeaderProfile(req)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -291,8 +452,13 @@ func (c *Client) post(ctx context.Context, url string, data interface{}) ([]byte
 		return nil, err
 	}
 
+	body, err = DecodeBody(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(body))
+		return nil, newHTTPError(resp.StatusCode, body)
 	}
 
 	return body, nil
@@ -305,7 +471,7 @@ func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", c.config.Browser.UserAgent)
+	c.applyHeaderProfile(req)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -318,8 +484,13 @@ func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
 		return nil, err
 	}
 
+	body, err = DecodeBody(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(body))
+		return nil, newHTTPError(resp.StatusCode, body)
 	}
 
 	return body, nil
@@ -327,16 +498,16 @@ func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
 
 // 为了兼容性，添加这些方法到基础Client
 func (c *Client) loginInterpark(ctx context.Context) error {
-	client := NewInterParkClient(c.config)
+	client := NewInterParkClient(c.opts...)
 	return client.Login(ctx)
 }
 
 func (c *Client) loginYes24(ctx context.Context) error {
-	client := NewYes24Client(c.config)
+	client := NewYes24Client(c.opts...)
 	return client.Login(ctx)
 }
 
 func (c *Client) loginMelon(ctx context.Context) error {
-	client := NewMelonClient(c.config)
+	client := NewMelonClient(c.opts...)
 	return client.Login(ctx)
 }
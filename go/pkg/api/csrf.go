@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"tickgrabber/pkg/models"
+)
+
+// CSRF令牌来源
+const (
+	CSRFSourceForm   = "form"
+	CSRFSourceMeta   = "meta"
+	CSRFSourceCookie = "cookie"
+	CSRFSourceJSON   = "json"
+)
+
+// defaultCSRFHeader 未配置时附加令牌使用的请求头
+const defaultCSRFHeader = "X-CSRF-Token"
+
+// maxCSRFScanSize 扫描令牌时最多读取的响应体大小
+const maxCSRFScanSize = 2 << 20
+
+// CSRFTokens 按主机保存的CSRF令牌
+type CSRFTokens struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+}
+
+// Get 获取主机对应的令牌
+func (t *CSRFTokens) Get(host string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tokens[host]
+}
+
+// Set 保存主机对应的令牌
+func (t *CSRFTokens) Set(host, token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tokens == nil {
+		t.tokens = make(map[string]string)
+	}
+	t.tokens[host] = token
+}
+
+// ExtractCSRFToken 按来源类型从响应体中提取令牌
+func ExtractCSRFToken(source, name string, body []byte) (string, bool) {
+	var patterns []string
+	quoted := regexp.QuoteMeta(name)
+
+	switch source {
+	case CSRFSourceForm:
+		patterns = []string{
+			`<input[^>]*name=["']` + quoted + `["'][^>]*value=["']([^"']*)["']`,
+			`<input[^>]*value=["']([^"']*)["'][^>]*name=["']` + quoted + `["']`,
+		}
+	case CSRFSourceMeta:
+		patterns = []string{
+			`<meta[^>]*name=["']` + quoted + `["'][^>]*content=["']([^"']*)["']`,
+			`<meta[^>]*content=["']([^"']*)["'][^>]*name=["']` + quoted + `["']`,
+		}
+	case CSRFSourceJSON:
+		patterns = []string{
+			`["']?` + quoted + `["']?\s*:\s*["']([^"']+)["']`,
+		}
+	default:
+		return "", false
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			continue
+		}
+		if m := re.FindSubmatch(body); m != nil {
+			return html.UnescapeString(string(m[1])), true
+		}
+	}
+
+	return "", false
+}
+
+// EnableCSRF 按站点配置启用CSRF令牌的自动提取和附加
+func (c *Client) EnableCSRF(cfg models.CSRFConfig) {
+	if cfg.Source == "" || cfg.Name == "" {
+		return
+	}
+	c.Use(c.csrfMiddleware(cfg))
+}
+
+// CSRFToken 返回为主机保存的CSRF令牌
+func (c *Client) CSRFToken(host string) string {
+	return c.csrf.Get(host)
+}
+
+// csrfMiddleware 从响应中提取令牌，并附加到后续的写请求上
+func (c *Client) csrfMiddleware(cfg models.CSRFConfig) Middleware {
+	header := cfg.Header
+	if header == "" {
+		header = defaultCSRFHeader
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if isUnsafeMethod(req.Method) && req.Header.Get(header) == "" {
+				if token := c.lookupCSRFToken(cfg, req); token != "" {
+					req = req.Clone(req.Context())
+					req.Header.Set(header, token)
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil || cfg.Source == CSRFSourceCookie {
+				return resp, err
+			}
+
+			c.captureCSRFToken(cfg, req.URL.Host, resp)
+			return resp, nil
+		})
+	}
+}
+
+// lookupCSRFToken 获取请求应附加的令牌
+func (c *Client) lookupCSRFToken(cfg models.CSRFConfig, req *http.Request) string {
+	if cfg.Source == CSRFSourceCookie {
+		for _, cookie := range c.client.Jar.Cookies(req.URL) {
+			if cookie.Name == cfg.Name {
+				return cookie.Value
+			}
+		}
+		return ""
+	}
+	return c.csrf.Get(req.URL.Host)
+}
+
+// captureCSRFToken 扫描响应体中的令牌，扫描后恢复响应体供调用方读取
+func (c *Client) captureCSRFToken(cfg models.CSRFConfig, host string, resp *http.Response) {
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "html") && !strings.Contains(contentType, "json") &&
+		!strings.Contains(contentType, "javascript") {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCSRFScanSize))
+	rest := resp.Body
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rest), rest}
+	if err != nil {
+		return
+	}
+
+	if token, ok := ExtractCSRFToken(cfg.Source, cfg.Name, body); ok {
+		c.csrf.Set(host, token)
+	}
+}
+
+// isUnsafeMethod 判断请求是否会修改服务端状态
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
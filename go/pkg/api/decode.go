@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/korean"
+)
+
+// DecodeBody 根据Content-Type和页面内的meta声明将响应体转换为UTF-8
+// Content-Type声明了编码时严格按声明解码；都没有声明且内容不是有效的UTF-8时，
+// 按韩国票务网站常见的EUC-KR(CP949)解码
+func DecodeBody(body []byte, contentType string) ([]byte, error) {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		enc, _ := charset.Lookup(params["charset"])
+		if enc == nil {
+			return nil, fmt.Errorf("无法识别响应编码: %s", params["charset"])
+		}
+		return enc.NewDecoder().Bytes(body)
+	}
+
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if !certain && name == "windows-1252" && !utf8.Valid(body) {
+		// 没有BOM和meta声明时charset包默认使用windows-1252，不适合韩文页面
+		enc = korean.EUCKR
+	}
+	return enc.NewDecoder().Bytes(body)
+}
+
+// ParseHTML 将UTF-8的HTML响应解析为goquery文档
+func ParseHTML(body []byte) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(bytes.NewReader(body))
+}
+
+// ExtractJSON 从响应中取出JSON内容
+// 部分接口会把JSON包在HTML的<pre>、<textarea>或<body>中返回
+func ExtractJSON(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '[' {
+		return trimmed
+	}
+
+	text := string(trimmed)
+	if doc, err := ParseHTML(trimmed); err == nil {
+		for _, selector := range []string{"pre", "textarea", "body"} {
+			if t := strings.TrimSpace(doc.Find(selector).First().Text()); t != "" {
+				text = t
+				break
+			}
+		}
+	}
+
+	// 截取第一个完整的JSON对象或数组
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return nil
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return nil
+	}
+
+	return []byte(text[start : end+1])
+}
+
+// DecodeJSON 从可能被HTML包裹的响应中解析JSON
+func DecodeJSON(body []byte, v interface{}) error {
+	data := ExtractJSON(body)
+	if data == nil {
+		return fmt.Errorf("响应中没有JSON内容")
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// GetDocument 获取页面并解析为goquery文档，便于适配器抓取页面内容
+func (c *Client) GetDocument(ctx context.Context, url string) (*goquery.Document, error) {
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseHTML(body)
+}
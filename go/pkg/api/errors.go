@@ -0,0 +1,34 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"tickgrabber/pkg/errs"
+)
+
+// HTTPError 非200响应
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error 实现error接口
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP错误: %d, %s", e.StatusCode, e.Body)
+}
+
+// newHTTPError 根据状态码返回带失败原因的错误
+// 401/403视为会话过期，429/503视为排队已满，其余只返回*HTTPError
+func newHTTPError(statusCode int, body []byte) error {
+	httpErr := &HTTPError{StatusCode: statusCode, Body: string(body)}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errs.Wrap(errs.ErrSessionExpired, "api.request", httpErr)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return errs.Wrap(errs.ErrQueueFull, "api.request", httpErr)
+	default:
+		return httpErr
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"tickgrabber/pkg/models"
+)
+
+// defaultAcceptLanguage 未配置时使用的Accept-Language
+const defaultAcceptLanguage = "ko-KR,ko;q=0.9,en-US;q=0.8,en;q=0.7"
+
+// ResolveHeaderProfile 解析站点使用的请求头配置
+// 依次叠加: "default"配置 -> 站点指定的配置(未指定时使用与站点同名的配置)
+func ResolveHeaderProfile(config *models.Config, site string) models.HeaderProfile {
+	profile := models.HeaderProfile{AcceptLanguage: defaultAcceptLanguage}
+
+	profile = mergeHeaderProfile(profile, config.Ticketing.HeaderProfiles["default"])
+
+	if site == "" {
+		return profile
+	}
+
+	name := config.Ticketing.Sites[site].HeaderProfile
+	if name == "" {
+		name = site
+	}
+
+	return mergeHeaderProfile(profile, config.Ticketing.HeaderProfiles[name])
+}
+
+// mergeHeaderProfile 用override中非空的字段覆盖base
+func mergeHeaderProfile(base, override models.HeaderProfile) models.HeaderProfile {
+	if override.AcceptLanguage != "" {
+		base.AcceptLanguage = override.AcceptLanguage
+	}
+
+	base.Headers = mergeHeaders(base.Headers, override.Headers)
+	return base
+}
+
+// mergeHeaders 合并两组请求头，返回新的map
+func mergeHeaders(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+// SetHeaderProfile 设置客户端发出请求时使用的请求头配置
+func (c *Client) SetHeaderProfile(profile models.HeaderProfile) {
+	c.profile = profile
+}
+
+// HeaderProfile 返回客户端当前使用的请求头配置
+func (c *Client) HeaderProfile() models.HeaderProfile {
+	return c.profile
+}
+
+// applyHeaderProfile 将请求头配置写入请求，调用方已设置的请求头优先
+func (c *Client) applyHeaderProfile(req *http.Request) {
+	setIfAbsent := func(key, value string) {
+		if value != "" && req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	setIfAbsent("User-Agent", c.userAgent)
+	setIfAbsent("Accept-Language", c.profile.AcceptLanguage)
+	for key, value := range c.profile.Headers {
+		setIfAbsent(key, value)
+	}
+}
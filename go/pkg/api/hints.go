@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hint 服务器响应中关于请求频率的提示
+// RetryAfter 为Retry-After要求的最早再次请求时间，MaxAge 为Cache-Control声明的缓存有效期，
+// 有效期内页面内容不会变化，更频繁地刷新没有意义
+type Hint struct {
+	RetryAfter time.Time
+	MaxAge     time.Duration
+	Updated    time.Time
+}
+
+// Hints 按主机记录最近一次响应中的提示
+type Hints struct {
+	mu    sync.Mutex
+	hosts map[string]Hint
+}
+
+// NewHints 创建提示记录
+func NewHints() *Hints {
+	return &Hints{hosts: make(map[string]Hint)}
+}
+
+// Get 返回host最近一次响应的提示
+func (h *Hints) Get(host string) (Hint, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hint, ok := h.hosts[host]
+	return hint, ok
+}
+
+// record 记录一次响应，Retry-After在到期前不会被没有该响应头的响应覆盖
+func (h *Hints) record(host string, header http.Header, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hint := Hint{
+		RetryAfter: parseRetryAfter(header.Get("Retry-After"), now),
+		MaxAge:     parseMaxAge(header.Get("Cache-Control")),
+		Updated:    now,
+	}
+	if prev, ok := h.hosts[host]; ok && hint.RetryAfter.IsZero() && prev.RetryAfter.After(now) {
+		hint.RetryAfter = prev.RetryAfter
+	}
+	h.hosts[host] = hint
+}
+
+// HintsMiddleware 把每个响应(含重试)中的Retry-After和Cache-Control记录到h
+func HintsMiddleware(h *Hints) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err == nil {
+				h.record(req.URL.Hostname(), resp.Header, time.Now())
+			}
+			return resp, err
+		})
+	}
+}
+
+// parseRetryAfter 解析秒数或HTTP日期格式的Retry-After，无法解析时返回零值
+func parseRetryAfter(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// parseMaxAge 返回Cache-Control中的s-maxage或max-age，no-cache、no-store时返回0
+func parseMaxAge(value string) time.Duration {
+	var maxAge, sharedMaxAge time.Duration
+	for _, directive := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(arg, `"`)); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		case "s-maxage":
+			if seconds, err := strconv.Atoi(strings.Trim(arg, `"`)); err == nil && seconds > 0 {
+				sharedMaxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if sharedMaxAge > 0 {
+		return sharedMaxAge
+	}
+	return maxAge
+}
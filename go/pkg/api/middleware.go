@@ -0,0 +1,216 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"tickgrabber/pkg/tracing"
+)
+
+// Middleware 包装RoundTripper的中间件
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc 函数形式的RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 实现http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain 按顺序组合中间件，第一个中间件位于最外层
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rt := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// LoggingMiddleware 把每个请求的方法、URL、状态码和耗时记录到logger，logger为nil时使用标准库的默认logger
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				logger.Printf("%s %s 失败: %v (%v)", req.Method, req.URL, err, time.Since(start))
+				return nil, err
+			}
+
+			logger.Printf("%s %s -> %d (%v)", req.Method, req.URL, resp.StatusCode, time.Since(start))
+			return resp, nil
+		})
+	}
+}
+
+// HeaderMiddleware 为请求注入固定的请求头，已设置的请求头不会被覆盖
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return HeaderFuncMiddleware(func(req *http.Request) {
+		for key, value := range headers {
+			if req.Header.Get(key) == "" {
+				req.Header.Set(key, value)
+			}
+		}
+	})
+}
+
+// HeaderFuncMiddleware 在请求发出前调用fn修改请求头，适用于动态值（如CSRF令牌）
+func HeaderFuncMiddleware(fn func(req *http.Request)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// RoundTripper不应修改原请求
+			req = req.Clone(req.Context())
+			fn(req)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RetryMiddleware 在网络错误、429和5xx时按固定间隔重试
+// 只重试GET、HEAD等幂等请求，占座、结账等POST请求重发可能重复下单
+func RetryMiddleware(maxRetries int, delay time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !idempotent(req.Method) {
+				return next.RoundTrip(req)
+			}
+
+			var resp *http.Response
+			var err error
+
+			for attempt := 0; ; attempt++ {
+				if attempt > 0 {
+					// 请求体只能读取一次，重试前需要重新获取
+					if req.Body != nil && req.GetBody != nil {
+						body, bodyErr := req.GetBody()
+						if bodyErr != nil {
+							return nil, bodyErr
+						}
+						req = req.Clone(req.Context())
+						req.Body = body
+					}
+				}
+
+				resp, err = next.RoundTrip(req)
+				if !shouldRetry(resp, err) || attempt >= maxRetries {
+					return resp, err
+				}
+				if req.Body != nil && req.GetBody == nil {
+					return resp, err
+				}
+
+				if resp != nil {
+					resp.Body.Close()
+				}
+				log.Printf("请求 %s 第 %d 次重试", req.URL, attempt+1)
+
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-time.After(delay):
+				}
+			}
+		})
+	}
+}
+
+// idempotent 判断请求方法是否可以安全地重复发送
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// shouldRetry 判断响应是否值得重试
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// RateLimitMiddleware 限制每秒最多发出rps个请求
+func RateLimitMiddleware(rps float64) Middleware {
+	return LimiterMiddleware(NewRateLimiter(rps))
+}
+
+// Metrics 请求统计
+type Metrics struct {
+	requests     atomic.Int64
+	failures     atomic.Int64
+	totalLatency atomic.Int64
+}
+
+// MetricsSnapshot 请求统计快照
+type MetricsSnapshot struct {
+	Requests       int64         `json:"requests"`
+	Failures       int64         `json:"failures"`
+	AverageLatency time.Duration `json:"average_latency"`
+}
+
+// Snapshot 返回当前统计
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{
+		Requests: m.requests.Load(),
+		Failures: m.failures.Load(),
+	}
+	if snapshot.Requests > 0 {
+		snapshot.AverageLatency = time.Duration(m.totalLatency.Load() / snapshot.Requests)
+	}
+	return snapshot
+}
+
+// TracingMiddleware 为每个请求(含重试)创建追踪span，作为请求ctx中span的子span
+// 追踪上下文只在本进程内传递，不向票务网站发送traceparent请求头
+func TracingMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracing.Start(req.Context(), "HTTP "+req.Method,
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.String()),
+			)
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			spanErr := err
+			if err == nil {
+				span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+				if resp.StatusCode >= 400 {
+					spanErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+				}
+			}
+			tracing.End(span, spanErr)
+			return resp, err
+		})
+	}
+}
+
+// MetricsMiddleware 将请求数、失败数和耗时记录到m
+func MetricsMiddleware(m *Metrics) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			m.requests.Add(1)
+			m.totalLatency.Add(int64(time.Since(start)))
+			if err != nil || resp.StatusCode >= 400 {
+				m.failures.Add(1)
+			}
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"tickgrabber/pkg/models"
+)
+
+// defaultMobileUserAgent 没有配置时请求移动网页版接口使用的User-Agent
+const defaultMobileUserAgent = "Mozilla/5.0 (Linux; Android 14; SM-S918N) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+
+// goodsCodePattern 演出地址中的Interpark商品编号
+var goodsCodePattern = regexp.MustCompile(`(?i)(?:goods/|GoodsCode=)(\d+)`)
+
+// GoodsCode 从Interpark演出地址中取出商品编号，取不到时返回空字符串
+func GoodsCode(concertURL string) string {
+	if m := goodsCodePattern.FindStringSubmatch(concertURL); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// MobileAvailability 通过移动网页版(m.ticket.interpark)的余票接口查询各座位等级的余票数
+// 移动版接口比桌面页面轻，开售和放票时有时更早更新；vars 中的 goods_code 为商品编号
+func (c *InterParkClient) MobileAvailability(ctx context.Context, cfg models.MobileAPI, vars map[string]string) (map[string]int, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("没有配置移动版余票接口")
+	}
+	target, err := renderRequest(cfg.URL, vars, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = defaultMobileUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Sec-CH-UA-Mobile", "?1")
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	if u, err := url.Parse(target); err == nil {
+		req.Header.Set("Referer", u.Scheme+"://"+u.Host+"/")
+	}
+	c.applyHeaderProfile(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp.StatusCode, body)
+	}
+
+	var data interface{}
+	if err := DecodeJSON(body, &data); err != nil {
+		return nil, fmt.Errorf("解析移动版余票失败: %w", err)
+	}
+	blocks, ok := lookupJSON(data, cfg.Blocks).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("移动版余票中没有等级列表 %q", cfg.Blocks)
+	}
+
+	remain := make(map[string]int)
+	for _, b := range blocks {
+		m, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		grade := fmt.Sprint(m[cfg.Grade])
+		remain[grade] += jsonInt(m[cfg.Remain])
+	}
+	return remain, nil
+}
+
+// jsonInt 把JSON中的数字或数字字符串转换为整数
+func jsonInt(v interface{}) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case string:
+		n, _ := strconv.Atoi(t)
+		return n
+	default:
+		return 0
+	}
+}
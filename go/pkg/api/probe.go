@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ProbeResult 延迟与服务器时钟偏移的测量结果
+type ProbeResult struct {
+	URL     string        `json:"url"`
+	RTT     time.Duration `json:"rtt"`
+	Offset  time.Duration `json:"offset"`
+	Error   time.Duration `json:"error"`
+	Samples int           `json:"samples"`
+}
+
+// ServerTime 将本地时间换算为服务器时间
+func (r *ProbeResult) ServerTime(local time.Time) time.Time {
+	return local.Add(r.Offset)
+}
+
+// LocalTime 将服务器时间换算为本地时间
+func (r *ProbeResult) LocalTime(server time.Time) time.Time {
+	return server.Add(-r.Offset)
+}
+
+// ProbeClock 测量到url的往返延迟并估算服务器时钟偏移(服务器时间-本地时间)
+//
+// Date响应头只精确到秒，单次采样误差可达1秒。这里把每次采样得到的
+// 偏移区间 [Date-mid, Date+1s-mid) 取交集，并把采样时刻错开到秒内的
+// 不同位置，使交集逐步收窄到几十毫秒以内
+func (c *Client) ProbeClock(ctx context.Context, url string, samples int) (*ProbeResult, error) {
+	if samples <= 0 {
+		samples = 5
+	}
+
+	var (
+		lower, upper time.Duration
+		totalRTT     time.Duration
+		valid        int
+	)
+
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			// 错开采样在秒内的位置
+			step := time.Second/time.Duration(samples) + 7*time.Millisecond
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(step):
+			}
+		}
+
+		sent, received, serverDate, err := c.sampleDate(ctx, url)
+		if err != nil {
+			c.logger.Printf("时钟探测采样失败: %v", err)
+			continue
+		}
+
+		rtt := received.Sub(sent)
+		mid := sent.Add(rtt / 2)
+		lo := serverDate.Sub(mid) - rtt/2
+		hi := serverDate.Add(time.Second).Sub(mid) + rtt/2
+
+		if valid == 0 {
+			lower, upper = lo, hi
+		} else {
+			lower = max(lower, lo)
+			upper = min(upper, hi)
+		}
+		totalRTT += rtt
+		valid++
+	}
+
+	if valid == 0 {
+		return nil, fmt.Errorf("无法从 %s 获取服务器时间", url)
+	}
+
+	// 网络抖动可能导致区间为空，此时退化为上下界的中点
+	result := &ProbeResult{
+		URL:     url,
+		RTT:     totalRTT / time.Duration(valid),
+		Offset:  (lower + upper) / 2,
+		Error:   (upper - lower) / 2,
+		Samples: valid,
+	}
+	if result.Error < 0 {
+		result.Error = -result.Error
+	}
+
+	c.logger.Printf("时钟探测 %s: RTT=%v 偏移=%v (±%v)", url, result.RTT, result.Offset, result.Error)
+	return result, nil
+}
+
+// sampleDate 发送一次HEAD请求，返回发送时刻、接收时刻和服务器Date
+func (c *Client) sampleDate(ctx context.Context, url string) (time.Time, time.Time, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	c.applyHeaderProfile(req)
+
+	sent := time.Now()
+	resp, err := c.client.Do(req)
+	received := time.Now()
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("无效的Date响应头: %v", err)
+	}
+
+	return sent, received, date, nil
+}
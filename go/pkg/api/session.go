@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"tickgrabber/pkg/errs"
+)
+
+// loginPathHints 会话失效后网站跳转到的登录页路径特征
+var loginPathHints = []string{"login", "signin", "sign-in"}
+
+// CheckSession 请求站点的会话检查页面(session_check_url，默认为我的预订页)确认登录是否有效
+// 返回401/403或被跳转到登录页时返回ErrSessionExpired；站点没有可用的检查页面时返回nil
+func (c *Client) CheckSession(ctx context.Context, site string) error {
+	cfg := c.config.Ticketing.Sites[site]
+	target := cfg.SessionCheckURL
+	if target == "" {
+		target = cfg.BookingsURL
+	}
+	if target == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return err
+	}
+	c.applyHeaderProfile(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return newHTTPError(resp.StatusCode, nil)
+	}
+	if final := resp.Request.URL; final.String() != target && isLoginPage(final, cfg.LoginURL) {
+		return errs.New(errs.ErrSessionExpired, "api.checkSession", "跳转到登录页: "+final.String())
+	}
+	return nil
+}
+
+// isLoginPage 判断跳转后的地址是否为登录页：与站点的登录地址相同，或路径中带有登录字样
+func isLoginPage(u *url.URL, loginURL string) bool {
+	if login, err := url.Parse(loginURL); err == nil && login.Host != "" {
+		if strings.EqualFold(u.Host, login.Host) && u.Path == login.Path {
+			return true
+		}
+	}
+
+	path := strings.ToLower(u.Path)
+	for _, hint := range loginPathHints {
+		if strings.Contains(path, hint) {
+			return true
+		}
+	}
+	return false
+}
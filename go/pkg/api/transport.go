@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// defaultMaxIdleConnsPerHost 每个主机默认保留的空闲连接数
+const defaultMaxIdleConnsPerHost = 8
+
+// NewTransport 创建针对抢票场景调优的Transport
+// 启用HTTP/2并为每个主机保留更多空闲连接，避免开售瞬间重新握手
+func NewTransport(config *models.Config) *http.Transport {
+	perHost := config.Ticketing.MaxIdleConnsPerHost
+	if perHost <= 0 {
+		perHost = defaultMaxIdleConnsPerHost
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   perHost,
+		IdleConnTimeout:       5 * time.Minute,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// Prewarm 预先建立到各URL的TLS连接并放入连接池
+// 每个URL并发发出conns个HEAD请求，HTTP/2下多个请求会复用同一连接
+func (c *Client) Prewarm(ctx context.Context, urls []string, conns int) error {
+	if conns <= 0 {
+		conns = 1
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(urls)*conns)
+
+	for _, u := range urls {
+		for i := 0; i < conns; i++ {
+			wg.Add(1)
+			go func(u string) {
+				defer wg.Done()
+				if err := c.warm(ctx, u); err != nil {
+					errs <- err
+				}
+			}(u)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// 只要有一个连接失败就返回第一个错误，其余连接仍然有效
+	for err := range errs {
+		return err
+	}
+
+	c.logger.Printf("已预热 %d 个地址的连接", len(urls))
+	return nil
+}
+
+// KeepWarm 定期重新预热，防止空闲连接被服务器关闭，直到ctx取消
+func (c *Client) KeepWarm(ctx context.Context, urls []string, conns int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Prewarm(ctx, urls, conns); err != nil {
+				c.logger.Printf("连接保活失败: %v", err)
+			}
+		}
+	}
+}
+
+// warm 发出一个HEAD请求并读完响应体，使连接回到连接池
+func (c *Client) warm(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	c.applyHeaderProfile(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
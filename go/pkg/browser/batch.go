@@ -0,0 +1,101 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// BatchAction 批量操作中一步的动作
+type BatchAction string
+
+const (
+	BatchNavigate BatchAction = "navigate"
+	BatchWait     BatchAction = "wait"
+	BatchClick    BatchAction = "click"
+	BatchFill     BatchAction = "fill"
+	BatchSleep    BatchAction = "sleep"
+)
+
+// BatchStep 批量操作中的一步
+// Selector 为CSS选择器或XPath(navigate时为地址)，Value 为fill填写的值，
+// Timeout 为这一步最多等待的时间(sleep时为等待时长)，Optional 为true时失败也继续执行
+type BatchStep struct {
+	Action   BatchAction
+	Selector string
+	Value    string
+	Timeout  time.Duration
+	Optional bool
+}
+
+// String 返回用于日志和录制的描述，不含填写的值
+func (s BatchStep) String() string {
+	if s.Action == BatchSleep {
+		return fmt.Sprintf("sleep %v", s.Timeout)
+	}
+	return string(s.Action) + " " + s.Selector
+}
+
+// RunBatch 在一次chromedp.Run中依次执行steps，中间不经过Go侧的日志、截图和选择器解析
+func (b *Browser) RunBatch(ctx context.Context, steps []BatchStep) error {
+	var total time.Duration
+	tasks := make(chromedp.Tasks, 0, len(steps))
+	for i, step := range steps {
+		total += step.Timeout
+		tasks = append(tasks, batchAction(i, step))
+	}
+	if total <= 0 {
+		total = b.opts.timeout
+	}
+
+	runCtx, cancel := scoped(b.ctx, ctx, total)
+	defer cancel()
+	return chromedp.Run(runCtx, tasks)
+}
+
+// queryBy 返回选择器的查找方式：XPath(按文字定位的选择器转换而来)用全文搜索，其余按CSS选择器查找，
+// 避免全文搜索先匹配到页面脚本中出现的同名文字
+func queryBy(selector string) chromedp.QueryOption {
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") {
+		return chromedp.BySearch
+	}
+	return chromedp.ByQuery
+}
+
+// batchAction 把一步转换为chromedp动作，每步有自己的超时
+func batchAction(i int, step BatchStep) chromedp.Action {
+	var action chromedp.Action
+	switch step.Action {
+	case BatchNavigate:
+		action = chromedp.Navigate(step.Selector)
+	case BatchWait:
+		action = chromedp.WaitVisible(step.Selector, queryBy(step.Selector))
+	case BatchClick:
+		action = chromedp.Click(step.Selector, queryBy(step.Selector))
+	case BatchFill:
+		action = chromedp.SetValue(step.Selector, step.Value, queryBy(step.Selector))
+	case BatchSleep:
+		return chromedp.Sleep(step.Timeout)
+	default:
+		return chromedp.ActionFunc(func(context.Context) error {
+			return fmt.Errorf("第 %d 步: 未知动作 %q", i+1, step.Action)
+		})
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		stepCtx := ctx
+		if step.Timeout > 0 {
+			var cancel context.CancelFunc
+			stepCtx, cancel = context.WithTimeout(ctx, step.Timeout)
+			defer cancel()
+		}
+		err := action.Do(stepCtx)
+		if err == nil || (step.Optional && ctx.Err() == nil) {
+			return nil
+		}
+		return fmt.Errorf("第 %d 步 (%s): %w", i+1, step, err)
+	})
+}
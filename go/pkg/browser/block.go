@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// 各类资源的地址模式，用于 BlockPatterns，结尾的*匹配查询参数
+var (
+	imagePatterns = []string{"*.png*", "*.jpg*", "*.jpeg*", "*.gif*", "*.webp*", "*.svg*", "*.bmp*", "*.avif*"}
+	mediaPatterns = []string{"*.mp4*", "*.webm*", "*.m3u8*", "*.mp3*", "*.ogg*", "*.wav*"}
+	fontPatterns  = []string{"*.woff*", "*.ttf*", "*.otf*", "*.eot*"}
+	// 售票网站常见的统计、广告和客服脚本，不影响购票流程
+	thirdPartyPatterns = []string{
+		"*googletagmanager.com*",
+		"*google-analytics.com*",
+		"*doubleclick.net*",
+		"*googlesyndication.com*",
+		"*facebook.net*",
+		"*connect.facebook.com*",
+		"*criteo.com*",
+		"*criteo.net*",
+		"*wcs.naver.net*",
+		"*adservice.google.com*",
+		"*hotjar.com*",
+		"*clarity.ms*",
+		"*channel.io*",
+		"*kakao.ad*",
+		"*mobon.net*",
+		"*dable.io*",
+	}
+)
+
+// BlockPatterns 把资源类别转换为地址模式，extra为额外的模式
+func BlockPatterns(images, media, fonts, thirdParty bool, extra []string) []string {
+	var patterns []string
+	if images {
+		patterns = append(patterns, imagePatterns...)
+	}
+	if media {
+		patterns = append(patterns, mediaPatterns...)
+	}
+	if fonts {
+		patterns = append(patterns, fontPatterns...)
+	}
+	if thirdParty {
+		patterns = append(patterns, thirdPartyPatterns...)
+	}
+	return append(patterns, extra...)
+}
+
+// BlockURLs 阻止加载匹配patterns的请求(支持*通配符)，patterns为空时解除阻止
+func (b *Browser) BlockURLs(ctx context.Context, patterns []string) error {
+	runCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	if patterns == nil {
+		patterns = []string{}
+	}
+	return chromedp.Run(runCtx, network.SetBlockedURLs(patterns))
+}
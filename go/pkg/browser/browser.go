@@ -2,74 +2,215 @@ package browser
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
-)
 
-// Options 浏览器选项
-type Options struct {
-	Headless bool
-	Debug    bool
-	Timeout  time.Duration
-}
+	"tickgrabber/pkg/errs"
+)
 
 // Browser 浏览器实例
 type Browser struct {
 	ctx    context.Context
 	cancel context.CancelFunc
-	opts   *Options
+	opts   *options
+
+	mu       sync.Mutex
+	popups   map[target.ID]context.CancelFunc
+	popupCtx map[target.ID]context.Context
+	egress   sync.Once
+	// closed 保证 Close 只执行一次，调用方和ctx取消可能同时关闭浏览器
+	closed sync.Once
+	// stop 取消ctx取消时关闭浏览器的回调
+	stop func() bool
+}
+
+// NewBrowser 创建新的浏览器实例并启动浏览器，浏览器的生命周期随ctx：ctx取消时关闭浏览器，
+// 正在执行的命令随之结束；各方法的ctx和超时只作用于单个命令
+// 浏览器在第一次执行命令时启动并绑定到该次命令的context，所以在这里用浏览器自己的context先启动
+func NewBrowser(ctx context.Context, options ...Option) (*Browser, error) {
+	opts := newOptions(options)
+	if opts.proxy != nil && opts.proxy.User != nil {
+		return nil, fmt.Errorf("浏览器代理不支持账号密码: %s", opts.proxy.Redacted())
+	}
+
+	var b *Browser
+	if opts.remoteURL != "" {
+		if opts.profileDir != "" {
+			return nil, fmt.Errorf("远程浏览器不能指定用户数据目录")
+		}
+		b = newRemoteBrowser(ctx, opts)
+	} else {
+		var err error
+		if b, err = newLocalBrowser(ctx, opts); err != nil {
+			return nil, err
+		}
+	}
+	b.mu.Lock()
+	b.stop = context.AfterFunc(ctx, b.Close)
+	b.mu.Unlock()
+	if err := chromedp.Run(b.ctx); err != nil {
+		b.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("启动浏览器失败: %w", err)
+	}
+	if opts.remoteURL != "" && opts.userAgent != "" {
+		// 远程浏览器的启动参数由对方决定，只能在页面上替换
+		if err := chromedp.Run(b.ctx, emulation.SetUserAgentOverride(opts.userAgent)); err != nil {
+			b.Close()
+			return nil, fmt.Errorf("设置User-Agent失败: %w", err)
+		}
+	}
+	return b, nil
 }
 
-// NewBrowser 创建新的浏览器实例
-func NewBrowser(opts *Options) (*Browser, error) {
+// newLocalBrowser 在本机启动Chrome
+func newLocalBrowser(ctx context.Context, opts *options) (*Browser, error) {
 	// 创建Chrome选项
 	chromeOpts := []chromedp.ExecAllocatorOption{
 		chromedp.NoFirstRun,
 		chromedp.NoDefaultBrowserCheck,
 		chromedp.DisableGPU,
 		chromedp.NoSandbox,
-		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("disable-dev-shm-usage", !shmLargeEnough(opts.logger)),
 		chromedp.Flag("disable-infobars", true),
 		chromedp.Flag("disable-extensions", true),
 		chromedp.Flag("start-maximized", true),
 	}
 
-	if opts.Headless {
+	if InContainer() {
+		// 容器中通常以root运行且没有沙箱所需的权限，zygote进程也无法正常工作
+		chromeOpts = append(chromeOpts, chromedp.Flag("no-zygote", true))
+		if !opts.headless && !hasDisplay() {
+			opts.logger.Println("容器中没有显示服务，使用无头模式")
+			opts.headless = true
+		}
+	}
+
+	if opts.execPath != "" {
+		chromeOpts = append(chromeOpts, chromedp.ExecPath(opts.execPath))
+	}
+	if opts.proxy != nil {
+		chromeOpts = append(chromeOpts, chromedp.ProxyServer(opts.proxy.String()))
+	}
+	if opts.userAgent != "" {
+		chromeOpts = append(chromeOpts, chromedp.UserAgent(opts.userAgent))
+	}
+	if opts.profileDir != "" {
+		chromeOpts = append(chromeOpts, chromedp.UserDataDir(opts.profileDir))
+	}
+
+	if opts.headless {
 		chromeOpts = append(chromeOpts, chromedp.Headless)
 	}
 
-	if opts.Debug {
+	if opts.debug {
 		chromeOpts = append(chromeOpts, chromedp.Flag("enable-logging", true))
 	}
 
-	// 创建上下文
-	ctx, cancel := chromedp.NewExecAllocator(context.Background(), chromeOpts...)
+	// 创建上下文，Chrome进程随ctx取消退出
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, chromeOpts...)
 
 	// 创建Chrome实例
-	ctx, cancel = chromedp.NewContext(ctx)
+	browserCtx, cancel := chromedp.NewContext(allocCtx)
 
 	return &Browser{
-		ctx:    ctx,
-		cancel: cancel,
-		opts:   opts,
+		ctx: browserCtx,
+		cancel: func() {
+			cancel()
+			allocCancel()
+		},
+		opts: opts,
 	}, nil
 }
 
-// Close 关闭浏览器
+// newRemoteBrowser 连接远程浏览器，在其中创建独立的浏览器上下文(相当于无痕窗口)，
+// 多个任务共用一个远程浏览器时Cookie和登录会话互不影响，关闭时只释放该上下文
+func newRemoteBrowser(ctx context.Context, opts *options) *Browser {
+	opts.logger.Printf("连接远程浏览器: %s", opts.remoteURL)
+	allocCtx, allocCancel := chromedp.NewRemoteAllocator(ctx, opts.remoteURL)
+	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithNewBrowserContext(
+		func(p *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+			if opts.proxy != nil {
+				p = p.WithProxyServer(opts.proxy.String())
+			}
+			return p
+		},
+	))
+	return &Browser{
+		ctx: ctx,
+		cancel: func() {
+			cancel()
+			allocCancel()
+		},
+		opts: opts,
+	}
+}
+
+// shmWarning 守护模式下每个任务都会创建浏览器，/dev/shm不足的提示只输出一次
+var shmWarning sync.Once
+
+// shmLargeEnough 判断/dev/shm是否足够Chrome使用，不足或无法判断时让Chrome改用临时目录
+func shmLargeEnough(logger *log.Logger) bool {
+	size, ok := shmSize()
+	if !ok {
+		return false
+	}
+	if size < minShmSize && InContainer() {
+		shmWarning.Do(func() {
+			logger.Printf("/dev/shm 只有 %dMB，Chrome改用临时目录；可用 docker run --shm-size=1g 提高性能", size>>20)
+		})
+	}
+	return size >= minShmSize
+}
+
+// Close 关闭浏览器，正在执行的命令立即以context.Canceled结束，可以重复调用
 func (b *Browser) Close() {
-	if b.cancel != nil {
-		b.cancel()
+	b.closed.Do(func() {
+		b.mu.Lock()
+		if b.stop != nil {
+			b.stop()
+		}
+		b.closePopups()
+		b.mu.Unlock()
+		if b.cancel != nil {
+			b.cancel()
+		}
+	})
+}
+
+// scoped 返回在浏览器上下文中执行、随ctx取消的带超时上下文
+func scoped(browserCtx, ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithTimeout(browserCtx, timeout)
+	stop := context.AfterFunc(ctx, cancel)
+	return runCtx, func() {
+		stop()
+		cancel()
 	}
 }
 
 // Navigate 导航到指定URL
 func (b *Browser) Navigate(ctx context.Context, url string) error {
-	log.Printf("导航到: %s", url)
+	b.opts.logger.Printf("导航到: %s", url)
+	b.egress.Do(b.watchEgress)
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
 	defer cancel()
 
 	return chromedp.Run(timeoutCtx, chromedp.Navigate(url))
@@ -77,14 +218,14 @@ func (b *Browser) Navigate(ctx context.Context, url string) error {
 
 // FillForm 填写表单
 func (b *Browser) FillForm(ctx context.Context, fields map[string]string) error {
-	log.Println("填写表单...")
+	b.opts.logger.Println("填写表单...")
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
 	defer cancel()
 
 	var tasks []chromedp.Action
 	for selector, value := range fields {
-		tasks = append(tasks, chromedp.SetValue(selector, value))
+		tasks = append(tasks, chromedp.SetValue(selector, value, chromedp.ByQuery))
 	}
 
 	return chromedp.Run(timeoutCtx, tasks...)
@@ -92,39 +233,45 @@ func (b *Browser) FillForm(ctx context.Context, fields map[string]string) error
 
 // SubmitForm 提交表单
 func (b *Browser) SubmitForm(ctx context.Context) error {
-	log.Println("提交表单...")
+	b.opts.logger.Println("提交表单...")
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
 	defer cancel()
 
-	return chromedp.Run(timeoutCtx, chromedp.Click("input[type='submit'], button[type='submit']"))
+	return chromedp.Run(timeoutCtx, chromedp.Click("input[type='submit'], button[type='submit']", chromedp.ByQuery))
 }
 
 // ElementExists 检查元素是否存在
 func (b *Browser) ElementExists(ctx context.Context, selector string) (bool, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
 	defer cancel()
 
+	// 选择器中常有单引号(如 [data-seat-type='R'])，按JSON字符串转义后再放入脚本
+	query, _ := json.Marshal(selector)
 	var exists bool
 	err := chromedp.Run(timeoutCtx, chromedp.Evaluate(fmt.Sprintf(`
-		document.querySelector('%s') !== null
-	`, selector), &exists))
+		document.querySelector(%s) !== null
+	`, query), &exists))
 
 	return exists, err
 }
 
 // ClickElement 点击元素
 func (b *Browser) ClickElement(ctx context.Context, selector string) (bool, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
 	defer cancel()
 
 	// 先检查元素是否存在
 	exists, err := b.ElementExists(ctx, selector)
 	if err != nil || !exists {
-		return false, fmt.Errorf("元素不存在: %s", selector)
+		if err != nil {
+			return false, errs.Wrap(errs.ErrElementNotFound, "browser.click", err)
+		}
+		return false, errs.New(errs.ErrElementNotFound, "browser.click", selector)
 	}
 
-	err = chromedp.Run(timeoutCtx, chromedp.Click(selector))
+	// 按CSS选择器查找，与上面的存在检查一致；默认的全文搜索会先匹配到脚本中出现的同名文字，一直等不到它可见
+	err = chromedp.Run(timeoutCtx, chromedp.Click(selector, chromedp.ByQuery))
 	if err != nil {
 		return false, err
 	}
@@ -134,25 +281,29 @@ func (b *Browser) ClickElement(ctx context.Context, selector string) (bool, erro
 
 // WaitForElement 等待元素出现
 func (b *Browser) WaitForElement(ctx context.Context, selector string) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
 	defer cancel()
 
-	return chromedp.Run(timeoutCtx, chromedp.WaitVisible(selector))
+	err := chromedp.Run(timeoutCtx, chromedp.WaitVisible(selector, chromedp.ByQuery))
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errs.Wrap(errs.ErrElementNotFound, "browser.wait", fmt.Errorf("%s: %w", selector, err))
+	}
+	return err
 }
 
 // GetText 获取元素文本
 func (b *Browser) GetText(ctx context.Context, selector string) (string, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
 	defer cancel()
 
 	var text string
-	err := chromedp.Run(timeoutCtx, chromedp.Text(selector, &text))
+	err := chromedp.Run(timeoutCtx, chromedp.Text(selector, &text, chromedp.ByQuery))
 	return text, err
 }
 
 // ExecuteScript 执行JavaScript
 func (b *Browser) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 10*time.Second)
 	defer cancel()
 
 	var result interface{}
@@ -162,7 +313,7 @@ func (b *Browser) ExecuteScript(ctx context.Context, script string) (interface{}
 
 // Screenshot 截图
 func (b *Browser) Screenshot(ctx context.Context, filename string) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 10*time.Second)
 	defer cancel()
 
 	var buf []byte
@@ -171,22 +322,72 @@ func (b *Browser) Screenshot(ctx context.Context, filename string) error {
 		return err
 	}
 
-	// 这里应该将buf保存到文件
-	// 为了简化，我们只记录日志
-	log.Printf("截图已保存到: %s", filename)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, buf, 0644); err != nil {
+		return err
+	}
+
+	b.opts.logger.Printf("截图已保存到: %s", filename)
 	return nil
 }
 
-// WaitForPageLoad 等待页面加载完成
+// ElementScreenshot 截取单个元素的图片，用于验证码等
+func (b *Browser) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 10*time.Second)
+	defer cancel()
+
+	var buf []byte
+	err := chromedp.Run(timeoutCtx, chromedp.Screenshot(selector, &buf, chromedp.NodeVisible, chromedp.ByQuery))
+	return buf, err
+}
+
+// PageSource 获取当前页面的HTML
+func (b *Browser) PageSource(ctx context.Context) (string, error) {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 10*time.Second)
+	defer cancel()
+
+	var html string
+	err := chromedp.Run(timeoutCtx, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+	return html, err
+}
+
+// PrintPDF 将当前页面打印为PDF，用于保存订单确认页
+func (b *Browser) PrintPDF(ctx context.Context) ([]byte, error) {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 30*time.Second)
+	defer cancel()
+
+	var buf []byte
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		data, _, err := page.PrintToPDF().WithPrintBackground(true).Do(ctx)
+		buf = data
+		return err
+	}))
+	return buf, err
+}
+
+// WaitForPageLoad 等待页面加载完成(document.readyState为complete)，已经完成时立即返回
 func (b *Browser) WaitForPageLoad(ctx context.Context) error {
-	// 简单等待页面加载
-	time.Sleep(2 * time.Second)
-	return nil
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
+	defer cancel()
+
+	return chromedp.Run(timeoutCtx, chromedp.Poll(`document.readyState === "complete"`, nil,
+		chromedp.WithPollingInterval(100*time.Millisecond)))
+}
+
+// Ping 检查浏览器是否仍能响应CDP命令
+func (b *Browser) Ping(ctx context.Context) error {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	var result int
+	return chromedp.Run(timeoutCtx, chromedp.Evaluate(`1`, &result))
 }
 
 // GetCurrentURL 获取当前URL
 func (b *Browser) GetCurrentURL(ctx context.Context) (string, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
 	defer cancel()
 
 	var url string
@@ -196,34 +397,105 @@ func (b *Browser) GetCurrentURL(ctx context.Context) (string, error) {
 
 // Reload 刷新页面
 func (b *Browser) Reload(ctx context.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, b.opts.timeout)
 	defer cancel()
 
 	return chromedp.Run(timeoutCtx, chromedp.Reload())
 }
 
-// WaitForNetworkIdle 等待网络空闲
+// networkIdleScript 已加载的资源数量在500毫秒内没有变化时视为网络空闲
+const networkIdleScript = `(() => {
+	const count = performance.getEntriesByType("resource").length;
+	const now = performance.now();
+	if (window.__tgResources !== count) {
+		window.__tgResources = count;
+		window.__tgResourcesAt = now;
+	}
+	return now - window.__tgResourcesAt >= 500;
+})()`
+
+// WaitForNetworkIdle 等待网络空闲，最多等待timeout
 func (b *Browser) WaitForNetworkIdle(ctx context.Context, timeout time.Duration) error {
-	// 等待一段时间确保网络请求完成
-	time.Sleep(2 * time.Second)
-	return nil
+	timeoutCtx, cancel := scoped(b.ctx, ctx, timeout)
+	defer cancel()
+
+	return chromedp.Run(timeoutCtx, chromedp.Poll(networkIdleScript, nil,
+		chromedp.WithPollingInterval(100*time.Millisecond)))
 }
 
 // HandleAlert 处理弹窗
 func (b *Browser) HandleAlert(ctx context.Context, accept bool) error {
 	// 简化处理，直接返回成功
-	log.Printf("处理弹窗: accept=%v", accept)
+	b.opts.logger.Printf("处理弹窗: accept=%v", accept)
 	return nil
 }
 
 // ScrollToElement 滚动到元素
 func (b *Browser) ScrollToElement(ctx context.Context, selector string) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
 	defer cancel()
 
+	query, _ := json.Marshal(selector)
 	script := fmt.Sprintf(`
-		document.querySelector('%s').scrollIntoView({behavior: 'smooth'});
-	`, selector)
+		document.querySelector(%s).scrollIntoView({behavior: 'smooth'});
+	`, query)
 
 	return chromedp.Run(timeoutCtx, chromedp.Evaluate(script, nil))
 }
+
+// Cookies 导出浏览器中的全部Cookie
+func (b *Browser) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	var cookies []*http.Cookie
+	err := chromedp.Run(timeoutCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		raw, err := storage.GetCookies().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range raw {
+			cookie := &http.Cookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HttpOnly: c.HTTPOnly,
+			}
+			if !c.Session && c.Expires > 0 {
+				cookie.Expires = time.Unix(int64(c.Expires), 0)
+			}
+			cookies = append(cookies, cookie)
+		}
+		return nil
+	}))
+
+	return cookies, err
+}
+
+// ImportCookies 把Cookie写入浏览器，用于导入真实浏览器导出的登录会话
+func (b *Browser) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		param := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		if !c.Expires.IsZero() {
+			expires := cdp.TimeSinceEpoch(c.Expires)
+			param.Expires = &expires
+		}
+		params = append(params, param)
+	}
+
+	return chromedp.Run(timeoutCtx, storage.SetCookies(params))
+}
@@ -0,0 +1,99 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testPage 选择器中带引号的元素和只能按文字定位的按钮
+const testPage = `<!DOCTYPE html>
+<html lang="ko"><head><meta charset="utf-8"></head><body>
+<div class="seat-map">
+  <button type="button" data-seat-type='R' onclick="this.textContent = 'R석 선택됨'">R석</button>
+  <div data-name="it's">quote</div>
+</div>
+<button type="button" onclick="document.getElementById('result').textContent = 'ok'">예매하기</button>
+<div id="result"></div>
+</body></html>`
+
+// newTestBrowser 启动无头浏览器并打开测试页面，没有Chrome时跳过
+func newTestBrowser(t *testing.T) *Browser {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("short模式下不启动浏览器")
+	}
+	path, err := FindChrome("")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	b, err := NewBrowser(ctx, WithHeadless(true), WithExecPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+
+	if err := b.Navigate(ctx, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestQuotedSelectors(t *testing.T) {
+	b := newTestBrowser(t)
+	ctx := context.Background()
+
+	for selector, want := range map[string]bool{
+		"[data-seat-type='R']":   true,
+		`[data-seat-type="R"]`:   true,
+		`[data-name="it's"]`:     true,
+		"[data-seat-type='VIP']": false,
+	} {
+		exists, err := b.ElementExists(ctx, selector)
+		if err != nil || exists != want {
+			t.Errorf("ElementExists(%s) = %v, %v，期望 %v", selector, exists, err, want)
+		}
+	}
+
+	if err := b.ScrollToElement(ctx, "[data-seat-type='R']"); err != nil {
+		t.Fatalf("ScrollToElement: %v", err)
+	}
+	if clicked, err := b.ClickElement(ctx, "[data-seat-type='R']"); !clicked || err != nil {
+		t.Fatalf("ClickElement = %v, %v", clicked, err)
+	}
+	if text, err := b.GetText(ctx, "[data-seat-type='R']"); err != nil || text != "R석 선택됨" {
+		t.Fatalf("点击后文字 = %q, %v", text, err)
+	}
+}
+
+func TestClickByText(t *testing.T) {
+	b := newTestBrowser(t)
+	ctx := context.Background()
+
+	selector, err := b.FindByText(ctx, "예매 하기", MatchFuzzy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := b.ElementExists(ctx, selector); !exists || err != nil {
+		t.Fatalf("FindByText返回的选择器 %s 不可用: %v, %v", selector, exists, err)
+	}
+
+	if clicked, err := b.ClickByText(ctx, "예매하기", MatchExact); !clicked || err != nil {
+		t.Fatalf("ClickByText = %v, %v", clicked, err)
+	}
+	if text, err := b.GetText(ctx, "#result"); err != nil || text != "ok" {
+		t.Fatalf("点击后结果 = %q, %v", text, err)
+	}
+}
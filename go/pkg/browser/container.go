@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// minShmSize Chrome直接使用/dev/shm所需的最小空间，Docker默认只有64MB，不足时渲染进程会崩溃
+const minShmSize = 1 << 30
+
+// InContainer 判断是否运行在Docker、Podman或Kubernetes容器中
+func InContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, word := range []string{"docker", "kubepods", "containerd", "libpod"} {
+		if strings.Contains(string(data), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDisplay 判断当前环境是否有可用的显示服务
+func hasDisplay() bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
@@ -0,0 +1,160 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinVersion 支持的最低Chrome主版本，更早的版本缺少chromedp使用的CDP命令
+const MinVersion = 109
+
+// chromeNames Linux和其他类Unix系统中按名称在PATH里查找的浏览器
+var chromeNames = []string{
+	"google-chrome", "google-chrome-stable", "chromium", "chromium-browser",
+	"microsoft-edge", "microsoft-edge-stable", "brave-browser",
+}
+
+// chromeCandidates 返回当前平台常见的安装位置
+func chromeCandidates() []string {
+	switch runtime.GOOS {
+	case "windows":
+		var paths []string
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+			dir := os.Getenv(env)
+			if dir == "" {
+				continue
+			}
+			paths = append(paths,
+				filepath.Join(dir, `Google\Chrome\Application\chrome.exe`),
+				filepath.Join(dir, `Chromium\Application\chrome.exe`),
+				filepath.Join(dir, `Microsoft\Edge\Application\msedge.exe`),
+				filepath.Join(dir, `BraveSoftware\Brave-Browser\Application\brave.exe`),
+			)
+		}
+		return paths
+	case "darwin":
+		apps := []string{
+			"Google Chrome.app/Contents/MacOS/Google Chrome",
+			"Chromium.app/Contents/MacOS/Chromium",
+			"Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+			"Brave Browser.app/Contents/MacOS/Brave Browser",
+		}
+		var paths []string
+		home, _ := os.UserHomeDir()
+		for _, app := range apps {
+			paths = append(paths, filepath.Join("/Applications", app))
+			if home != "" {
+				paths = append(paths, filepath.Join(home, "Applications", app))
+			}
+		}
+		return paths
+	default:
+		return []string{"/snap/bin/chromium", "/usr/bin/chromium", "/opt/google/chrome/chrome"}
+	}
+}
+
+// FindChrome 查找Chrome/Chromium可执行文件：优先使用explicit(配置的 browser.exec_path)，
+// 其次为环境变量CHROME_PATH、PATH中的浏览器和各平台的默认安装位置
+func FindChrome(explicit string) (string, error) {
+	if explicit != "" {
+		if path, err := exec.LookPath(explicit); err == nil {
+			return path, nil
+		}
+		return "", fmt.Errorf("browser.exec_path 指定的浏览器不存在: %s", explicit)
+	}
+	if env := os.Getenv("CHROME_PATH"); env != "" {
+		if path, err := exec.LookPath(env); err == nil {
+			return path, nil
+		}
+		return "", fmt.Errorf("环境变量CHROME_PATH指定的浏览器不存在: %s", env)
+	}
+
+	searched := []string{}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		for _, name := range chromeNames {
+			if path, err := exec.LookPath(name); err == nil {
+				return path, nil
+			}
+		}
+		searched = append(searched, "PATH中的 "+strings.Join(chromeNames, ", "))
+	}
+	for _, path := range chromeCandidates() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		searched = append(searched, path)
+	}
+
+	return "", fmt.Errorf("没有找到Chrome或Chromium浏览器，请安装 Google Chrome (https://www.google.com/chrome/) "+
+		"或在配置的 browser.exec_path 中指定浏览器路径。已查找:\n  %s", strings.Join(searched, "\n  "))
+}
+
+// versionPattern 版本号，如 120.0.6099.109
+var versionPattern = regexp.MustCompile(`\b(\d+)\.\d+\.\d+\.\d+\b`)
+
+// ChromeVersion 返回浏览器的完整版本号和主版本
+// Windows上的chrome.exe --version 不输出版本，改为读取安装目录中以版本号命名的子目录
+func ChromeVersion(ctx context.Context, path string) (string, int, error) {
+	var text string
+	if runtime.GOOS == "windows" {
+		entries, err := os.ReadDir(filepath.Dir(path))
+		if err != nil {
+			return "", 0, err
+		}
+		for _, e := range entries {
+			if e.IsDir() && versionPattern.MatchString(e.Name()) && e.Name() > text {
+				text = e.Name()
+			}
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, path, "--version").Output()
+		if err != nil {
+			return "", 0, fmt.Errorf("读取浏览器版本失败: %w", err)
+		}
+		text = string(out)
+	}
+
+	match := versionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return "", 0, fmt.Errorf("无法识别浏览器版本: %q", strings.TrimSpace(text))
+	}
+	major, _ := strconv.Atoi(match[1])
+	return match[0], major, nil
+}
+
+// CheckVersion 检查浏览器版本：低于MinVersion时报错；pin不为空时要求主版本与之相同(如 "120")，
+// 用于固定经过验证的浏览器版本，避免浏览器自动更新后行为变化
+func CheckVersion(ctx context.Context, path, pin string) (string, error) {
+	version, major, err := ChromeVersion(ctx, path)
+	if err != nil {
+		if pin != "" {
+			return "", err
+		}
+		// 没有固定版本时无法读取版本不阻止启动
+		return "", nil
+	}
+
+	if major < MinVersion {
+		return version, fmt.Errorf("浏览器版本 %s 过旧，需要 %d 或更高版本: %s", version, MinVersion, path)
+	}
+	if pin != "" {
+		want, err := strconv.Atoi(strings.SplitN(pin, ".", 2)[0])
+		if err != nil {
+			return version, fmt.Errorf("browser.version 格式错误: %s", pin)
+		}
+		if major != want {
+			return version, fmt.Errorf("浏览器版本为 %s，与配置固定的版本 %s 不一致: %s", version, pin, path)
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+)
+
+// Driver 抢票流程使用的浏览器操作
+// *Browser 为Chrome实现，单元测试时可以换成 fake.Browser
+type Driver interface {
+	Navigate(ctx context.Context, url string) error
+	FillForm(ctx context.Context, fields map[string]string) error
+	SubmitForm(ctx context.Context) error
+	ElementExists(ctx context.Context, selector string) (bool, error)
+	ClickElement(ctx context.Context, selector string) (bool, error)
+	FindByText(ctx context.Context, text string, match TextMatch) (string, error)
+	ClickByText(ctx context.Context, text string, match TextMatch) (bool, error)
+	WaitForElement(ctx context.Context, selector string) error
+	GetText(ctx context.Context, selector string) (string, error)
+	ExecuteScript(ctx context.Context, script string) (interface{}, error)
+	Screenshot(ctx context.Context, filename string) error
+	ElementScreenshot(ctx context.Context, selector string) ([]byte, error)
+	PageSource(ctx context.Context) (string, error)
+	PrintPDF(ctx context.Context) ([]byte, error)
+	GetCurrentURL(ctx context.Context) (string, error)
+	Reload(ctx context.Context) error
+	Ping(ctx context.Context) error
+	Cookies(ctx context.Context) ([]*http.Cookie, error)
+	ImportCookies(ctx context.Context, cookies []*http.Cookie) error
+	Popups(ctx context.Context) ([]Popup, error)
+	PopupScreenshot(ctx context.Context, id string) ([]byte, error)
+	PopupScript(ctx context.Context, id string, script string) (interface{}, error)
+	BlockURLs(ctx context.Context, patterns []string) error
+	RunBatch(ctx context.Context, steps []BatchStep) error
+	Close()
+}
+
+var _ Driver = (*Browser)(nil)
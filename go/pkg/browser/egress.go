@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// watchEgress 按出站白名单检查页面发出的请求，第一次导航前启用
+// 阻止模式下拦截所有请求，不在白名单中的请求以 BlockedByClient 失败；记录模式下只监听不拦截
+// 启用拦截只随浏览器关闭而取消，不随第一次导航的ctx取消，否则之后的导航不再检查
+func (b *Browser) watchEgress() {
+	allow := b.opts.egress
+	if allow == nil {
+		return
+	}
+
+	if !allow.Enforce() {
+		chromedp.ListenTarget(b.ctx, func(ev interface{}) {
+			if e, ok := ev.(*network.EventRequestWillBeSent); ok {
+				if u, err := url.Parse(e.Request.URL); err == nil {
+					allow.Check(u)
+				}
+			}
+		})
+		runCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+		defer cancel()
+		if err := chromedp.Run(runCtx, network.Enable()); err != nil {
+			b.opts.logger.Printf("启用出站地址记录失败: %v", err)
+		}
+		return
+	}
+
+	chromedp.ListenTarget(b.ctx, func(ev interface{}) {
+		e, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		// 回调中不能直接执行CDP命令，否则会阻塞事件处理
+		go func() {
+			c := chromedp.FromContext(b.ctx)
+			if c == nil || c.Target == nil {
+				return
+			}
+			exec := cdp.WithExecutor(b.ctx, c.Target)
+			u, err := url.Parse(e.Request.URL)
+			if err == nil && allow.Check(u) != nil {
+				fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+				return
+			}
+			fetch.ContinueRequest(e.RequestID).Do(exec)
+		}()
+	})
+	runCtx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+	if err := chromedp.Run(runCtx, fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}})); err != nil {
+		b.opts.logger.Printf("启用出站白名单拦截失败: %v", err)
+	}
+}
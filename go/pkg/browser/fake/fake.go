@@ -0,0 +1,562 @@
+// Package fake 内存中的浏览器实现，记录所有操作并返回预设的结果，
+// 用于在没有Chrome的环境下测试抢票流程(选座、重试、状态切换)
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/errs"
+)
+
+// Action 一次浏览器操作
+type Action struct {
+	Method string
+	Args   []string
+}
+
+// Browser 假浏览器，页面状态由元素集合、文本和脚本结果描述
+// 可以通过OnClick、OnNavigate在操作发生时修改页面状态，模拟页面跳转
+type Browser struct {
+	mu sync.Mutex
+
+	url      string
+	html     string
+	elements map[string]bool
+	texts    map[string]string
+	scripts  map[string]interface{}
+	fields   map[string]string
+	cookies  []*http.Cookie
+	image    []byte
+	pdf      []byte
+	popups   []browser.Popup
+	blocked  []string
+
+	onClick    map[string]func(b *Browser)
+	onNavigate map[string]func(b *Browser)
+	onSubmit   func(b *Browser)
+	failures   map[string]error
+	actions    []Action
+}
+
+var _ browser.Driver = (*Browser)(nil)
+
+// New 创建空白页面的假浏览器
+func New() *Browser {
+	return &Browser{
+		elements:   make(map[string]bool),
+		texts:      make(map[string]string),
+		scripts:    make(map[string]interface{}),
+		fields:     make(map[string]string),
+		onClick:    make(map[string]func(b *Browser)),
+		onNavigate: make(map[string]func(b *Browser)),
+		failures:   make(map[string]error),
+	}
+}
+
+// SetElement 设置元素是否存在
+func (b *Browser) SetElement(selector string, exists bool) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.elements[selector] = exists
+	return b
+}
+
+// SetText 设置元素文本，同时使元素存在
+func (b *Browser) SetText(selector, text string) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.elements[selector] = true
+	b.texts[selector] = text
+	return b
+}
+
+// SetScriptResult 设置脚本的返回值
+func (b *Browser) SetScriptResult(script string, result interface{}) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.scripts[script] = result
+	return b
+}
+
+// SetHTML 设置页面源码
+func (b *Browser) SetHTML(html string) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.html = html
+	return b
+}
+
+// SetCookies 设置浏览器Cookie
+func (b *Browser) SetCookies(cookies []*http.Cookie) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cookies = cookies
+	return b
+}
+
+// SetImage 设置截图和元素截图返回的图片
+func (b *Browser) SetImage(data []byte) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.image = data
+	return b
+}
+
+// SetPDF 设置PrintPDF返回的内容
+func (b *Browser) SetPDF(data []byte) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pdf = data
+	return b
+}
+
+// SetPopups 设置打开的弹出窗口，不传参数时表示所有窗口已关闭
+func (b *Browser) SetPopups(popups ...browser.Popup) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.popups = popups
+	return b
+}
+
+// OnClick 点击selector时调用fn
+func (b *Browser) OnClick(selector string, fn func(b *Browser)) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onClick[selector] = fn
+	return b
+}
+
+// OnNavigate 导航到url时调用fn
+func (b *Browser) OnNavigate(url string, fn func(b *Browser)) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onNavigate[url] = fn
+	return b
+}
+
+// OnSubmit 提交表单时调用fn
+func (b *Browser) OnSubmit(fn func(b *Browser)) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onSubmit = fn
+	return b
+}
+
+// Fail 使方法返回err，err为nil时恢复正常
+func (b *Browser) Fail(method string, err error) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.failures, method)
+	} else {
+		b.failures[method] = err
+	}
+	return b
+}
+
+// Actions 返回已记录的全部操作
+func (b *Browser) Actions() []Action {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Action(nil), b.actions...)
+}
+
+// Calls 返回某个方法的调用记录
+func (b *Browser) Calls(method string) []Action {
+	var calls []Action
+	for _, a := range b.Actions() {
+		if a.Method == method {
+			calls = append(calls, a)
+		}
+	}
+	return calls
+}
+
+// Field 返回表单字段填写的值
+func (b *Browser) Field(selector string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.fields[selector]
+}
+
+// record 记录操作并返回预设的失败，调用方需持有锁
+func (b *Browser) record(method string, args ...string) error {
+	b.actions = append(b.actions, Action{Method: method, Args: args})
+	return b.failures[method]
+}
+
+// run 在锁外执行回调，回调中可以继续修改页面状态
+func (b *Browser) run(fn func(b *Browser)) {
+	if fn != nil {
+		fn(b)
+	}
+}
+
+// Navigate 导航
+func (b *Browser) Navigate(ctx context.Context, url string) error {
+	b.mu.Lock()
+	if err := b.record("Navigate", url); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	b.url = url
+	fn := b.onNavigate[url]
+	b.mu.Unlock()
+
+	b.run(fn)
+	return ctx.Err()
+}
+
+// FillForm 填写表单，按选择器排序记录字段，与录制文件的格式一致
+func (b *Browser) FillForm(ctx context.Context, fields map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var args []string
+	for selector, value := range fields {
+		args = append(args, selector)
+		b.fields[selector] = value
+	}
+	sort.Strings(args)
+	return b.record("FillForm", args...)
+}
+
+// SubmitForm 提交表单
+func (b *Browser) SubmitForm(ctx context.Context) error {
+	b.mu.Lock()
+	if err := b.record("SubmitForm"); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	fn := b.onSubmit
+	b.mu.Unlock()
+
+	b.run(fn)
+	return nil
+}
+
+// ElementExists 检查元素是否存在
+func (b *Browser) ElementExists(ctx context.Context, selector string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("ElementExists", selector); err != nil {
+		return false, err
+	}
+	return b.elements[selector], nil
+}
+
+// ClickElement 点击元素，元素不存在时返回ErrElementNotFound
+func (b *Browser) ClickElement(ctx context.Context, selector string) (bool, error) {
+	b.mu.Lock()
+	if err := b.record("ClickElement", selector); err != nil {
+		b.mu.Unlock()
+		return false, err
+	}
+	if !b.elements[selector] {
+		b.mu.Unlock()
+		return false, errs.New(errs.ErrElementNotFound, "browser.click", selector)
+	}
+	fn := b.onClick[selector]
+	b.mu.Unlock()
+
+	b.run(fn)
+	return true, nil
+}
+
+// FindByText 在SetText设置的元素中查找文字匹配的元素，多个匹配时返回选择器排序最前的
+func (b *Browser) FindByText(ctx context.Context, text string, match browser.TextMatch) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("FindByText", text, match.String()); err != nil {
+		return "", err
+	}
+	return b.findByText(text, match)
+}
+
+// ClickByText 点击文字匹配的元素
+func (b *Browser) ClickByText(ctx context.Context, text string, match browser.TextMatch) (bool, error) {
+	b.mu.Lock()
+	if err := b.record("ClickByText", text, match.String()); err != nil {
+		b.mu.Unlock()
+		return false, err
+	}
+	selector, err := b.findByText(text, match)
+	if err != nil {
+		b.mu.Unlock()
+		return false, err
+	}
+	fn := b.onClick[selector]
+	b.mu.Unlock()
+
+	b.run(fn)
+	return true, nil
+}
+
+// findByText 调用方需持有锁
+func (b *Browser) findByText(text string, match browser.TextMatch) (string, error) {
+	var found []string
+	for selector, t := range b.texts {
+		if b.elements[selector] && browser.MatchText(t, text, match) {
+			found = append(found, selector)
+		}
+	}
+	if len(found) == 0 {
+		return "", errs.New(errs.ErrElementNotFound, "browser.findByText", text)
+	}
+	sort.Strings(found)
+	return found[0], nil
+}
+
+// WaitForElement 元素不存在时立即返回ErrElementNotFound
+func (b *Browser) WaitForElement(ctx context.Context, selector string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("WaitForElement", selector); err != nil {
+		return err
+	}
+	if !b.elements[selector] {
+		return errs.New(errs.ErrElementNotFound, "browser.wait", selector)
+	}
+	return nil
+}
+
+// GetText 获取元素文本
+func (b *Browser) GetText(ctx context.Context, selector string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("GetText", selector); err != nil {
+		return "", err
+	}
+	if !b.elements[selector] {
+		return "", errs.New(errs.ErrElementNotFound, "browser.text", selector)
+	}
+	return b.texts[selector], nil
+}
+
+// ExecuteScript 返回预设的脚本结果，未设置时返回nil
+func (b *Browser) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("ExecuteScript", script); err != nil {
+		return nil, err
+	}
+	return b.scripts[script], nil
+}
+
+// Screenshot 将预设图片写入文件
+func (b *Browser) Screenshot(ctx context.Context, filename string) error {
+	b.mu.Lock()
+	err := b.record("Screenshot", filename)
+	image := b.image
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, image, 0644)
+}
+
+// ElementScreenshot 返回预设图片
+func (b *Browser) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("ElementScreenshot", selector); err != nil {
+		return nil, err
+	}
+	return b.image, nil
+}
+
+// PageSource 返回预设的页面源码
+func (b *Browser) PageSource(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("PageSource"); err != nil {
+		return "", err
+	}
+	return b.html, nil
+}
+
+// PrintPDF 返回预设的PDF
+func (b *Browser) PrintPDF(ctx context.Context) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("PrintPDF"); err != nil {
+		return nil, err
+	}
+	return b.pdf, nil
+}
+
+// GetCurrentURL 返回最后一次导航的地址
+func (b *Browser) GetCurrentURL(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("GetCurrentURL"); err != nil {
+		return "", err
+	}
+	return b.url, nil
+}
+
+// Reload 刷新页面，触发当前地址的OnNavigate回调
+func (b *Browser) Reload(ctx context.Context) error {
+	b.mu.Lock()
+	if err := b.record("Reload"); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	fn := b.onNavigate[b.url]
+	b.mu.Unlock()
+
+	b.run(fn)
+	return nil
+}
+
+// Ping 检查浏览器是否响应
+func (b *Browser) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.record("Ping")
+}
+
+// Cookies 返回预设的Cookie
+func (b *Browser) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("Cookies"); err != nil {
+		return nil, err
+	}
+	return b.cookies, nil
+}
+
+// ImportCookies 追加Cookie，同名同域的Cookie被替换
+func (b *Browser) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("ImportCookies"); err != nil {
+		return err
+	}
+	for _, c := range cookies {
+		replaced := false
+		for i, existing := range b.cookies {
+			if existing.Name == c.Name && existing.Domain == c.Domain {
+				b.cookies[i] = c
+				replaced = true
+			}
+		}
+		if !replaced {
+			b.cookies = append(b.cookies, c)
+		}
+	}
+	return nil
+}
+
+// Popups 返回预设的弹出窗口
+func (b *Browser) Popups(ctx context.Context) ([]browser.Popup, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("Popups"); err != nil {
+		return nil, err
+	}
+	return append([]browser.Popup(nil), b.popups...), nil
+}
+
+// PopupScreenshot 返回预设图片
+func (b *Browser) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("PopupScreenshot", id); err != nil {
+		return nil, err
+	}
+	return b.image, nil
+}
+
+// PopupScript 返回预设的脚本结果，与页面脚本共用SetScriptResult的设置
+func (b *Browser) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("PopupScript", id, script); err != nil {
+		return nil, err
+	}
+	return b.scripts[script], nil
+}
+
+// BlockURLs 记录阻止的地址模式，可通过Blocked查看
+func (b *Browser) BlockURLs(ctx context.Context, patterns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.record("BlockURLs", patterns...); err != nil {
+		return err
+	}
+	b.blocked = append([]string(nil), patterns...)
+	return nil
+}
+
+// Blocked 返回当前阻止的地址模式
+func (b *Browser) Blocked() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.blocked...)
+}
+
+// RunBatch 依次执行批量操作：wait检查元素、click触发OnClick、fill记录填写的值，失败的可选步骤跳过
+func (b *Browser) RunBatch(ctx context.Context, steps []browser.BatchStep) error {
+	b.mu.Lock()
+	args := make([]string, len(steps))
+	for i, step := range steps {
+		args[i] = step.String()
+	}
+	err := b.record("RunBatch", args...)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		var err error
+		switch step.Action {
+		case browser.BatchNavigate:
+			err = b.Navigate(ctx, step.Selector)
+		case browser.BatchWait:
+			err = b.WaitForElement(ctx, step.Selector)
+		case browser.BatchClick:
+			_, err = b.ClickElement(ctx, step.Selector)
+		case browser.BatchFill:
+			err = b.FillForm(ctx, map[string]string{step.Selector: step.Value})
+		}
+		if err != nil && !step.Optional {
+			return fmt.Errorf("第 %d 步 (%s): %w", i+1, step, err)
+		}
+	}
+	return nil
+}
+
+// Close 关闭浏览器
+func (b *Browser) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record("Close")
+}
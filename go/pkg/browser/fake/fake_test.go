@@ -0,0 +1,102 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/errs"
+)
+
+func TestClickChangesPage(t *testing.T) {
+	ctx := context.Background()
+	b := New().SetElement(".btn-next", true)
+	b.OnClick(".btn-next", func(b *Browser) {
+		b.SetElement(".btn-next", false).SetText(".seat-map", "좌석")
+	})
+
+	if clicked, err := b.ClickElement(ctx, ".btn-next"); !clicked || err != nil {
+		t.Fatalf("ClickElement = %v, %v", clicked, err)
+	}
+	if text, err := b.GetText(ctx, ".seat-map"); err != nil || text != "좌석" {
+		t.Fatalf("GetText = %q, %v", text, err)
+	}
+	if _, err := b.ClickElement(ctx, ".btn-next"); !errors.Is(err, errs.ErrElementNotFound) {
+		t.Fatalf("点击不存在的元素应返回ErrElementNotFound，实际 %v", err)
+	}
+}
+
+func TestFillFormRecordsSortedFields(t *testing.T) {
+	b := New()
+	fields := map[string]string{"#username": "test", "#password": "secret", "#captcha": "1234"}
+	for i := 0; i < 5; i++ {
+		if err := b.FillForm(context.Background(), fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"#captcha", "#password", "#username"}
+	for _, call := range b.Calls("FillForm") {
+		if !reflect.DeepEqual(call.Args, want) {
+			t.Fatalf("FillForm 记录 = %v，期望 %v", call.Args, want)
+		}
+	}
+	if got := b.Field("#password"); got != "secret" {
+		t.Fatalf("Field = %q", got)
+	}
+}
+
+func TestFail(t *testing.T) {
+	ctx := context.Background()
+	b := New().Fail("Navigate", errs.ErrSessionExpired)
+
+	if err := b.Navigate(ctx, "https://example.com"); !errors.Is(err, errs.ErrSessionExpired) {
+		t.Fatalf("Navigate = %v", err)
+	}
+	b.Fail("Navigate", nil)
+	if err := b.Navigate(ctx, "https://example.com"); err != nil {
+		t.Fatalf("恢复后 Navigate = %v", err)
+	}
+	if n := len(b.Calls("Navigate")); n != 2 {
+		t.Fatalf("记录了 %d 次导航", n)
+	}
+}
+
+func TestFindByText(t *testing.T) {
+	ctx := context.Background()
+	b := New().SetText("#b", "좌석 선택하기").SetText("#a", "좌석선택").SetText("#c", "결제")
+
+	if selector, err := b.FindByText(ctx, "좌석선택", browser.MatchFuzzy); err != nil || selector != "#a" {
+		t.Fatalf("模糊匹配 = %q, %v", selector, err)
+	}
+	if selector, err := b.FindByText(ctx, "좌석 선택하기", browser.MatchExact); err != nil || selector != "#b" {
+		t.Fatalf("完全匹配 = %q, %v", selector, err)
+	}
+	if _, err := b.FindByText(ctx, "취소", browser.MatchFuzzy); !errors.Is(err, errs.ErrElementNotFound) {
+		t.Fatalf("没有匹配时应返回ErrElementNotFound，实际 %v", err)
+	}
+}
+
+func TestRunBatchSkipsOptionalSteps(t *testing.T) {
+	ctx := context.Background()
+	b := New().SetElement(".btn-purchase", true)
+
+	err := b.RunBatch(ctx, []browser.BatchStep{
+		{Action: browser.BatchClick, Selector: ".popup-close", Optional: true},
+		{Action: browser.BatchFill, Selector: "#captcha-input", Value: "1234"},
+		{Action: browser.BatchClick, Selector: ".btn-purchase"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Field("#captcha-input"); got != "1234" {
+		t.Fatalf("Field = %q", got)
+	}
+
+	err = b.RunBatch(ctx, []browser.BatchStep{{Action: browser.BatchWait, Selector: ".missing"}})
+	if !errors.Is(err, errs.ErrElementNotFound) {
+		t.Fatalf("必需步骤失败时应返回错误，实际 %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"log"
+	"net/url"
+	"time"
+
+	"tickgrabber/pkg/egress"
+)
+
+// defaultTimeout 单个命令默认的超时时间
+const defaultTimeout = 30 * time.Second
+
+// Option 创建浏览器的可选设置，不需要完整的配置文件即可组合出浏览器
+type Option func(*options)
+
+// options 创建浏览器的设置，零值为在本机启动有界面的Chrome
+type options struct {
+	headless bool
+	debug    bool
+	timeout  time.Duration
+	// execPath 浏览器可执行文件，为空时由chromedp按默认位置查找
+	execPath string
+	// remoteURL 连接已运行的浏览器(如单独容器中的chrome-headless-shell)，ws://或http://地址，
+	// 设置时不启动本地浏览器，每个实例在远程浏览器中使用独立的浏览器上下文
+	remoteURL string
+	// egress 出站地址白名单，为nil时不检查页面请求
+	egress *egress.Allowlist
+	// proxy 浏览器使用的代理，为nil时直接连接
+	proxy *url.URL
+	// userAgent 替换浏览器的User-Agent，为空时使用浏览器自己的
+	userAgent string
+	// profileDir Chrome的用户数据目录，为空时每次使用新的临时目录
+	profileDir string
+	logger     *log.Logger
+}
+
+// WithHeadless 无头模式运行
+func WithHeadless(headless bool) Option {
+	return func(o *options) { o.headless = headless }
+}
+
+// WithDebug 开启Chrome自身的日志
+func WithDebug(debug bool) Option {
+	return func(o *options) { o.debug = debug }
+}
+
+// WithTimeout 单个命令的默认超时时间，默认30秒
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithExecPath 指定浏览器可执行文件
+func WithExecPath(path string) Option {
+	return func(o *options) { o.execPath = path }
+}
+
+// WithRemoteURL 连接已运行的远程浏览器而不是在本机启动
+func WithRemoteURL(remoteURL string) Option {
+	return func(o *options) { o.remoteURL = remoteURL }
+}
+
+// WithEgress 按出站白名单检查页面发出的请求
+func WithEgress(allow *egress.Allowlist) Option {
+	return func(o *options) { o.egress = allow }
+}
+
+// WithProxy 通过代理访问网站，如 http://127.0.0.1:8080、socks5://127.0.0.1:1080
+// Chrome的代理参数不能携带账号密码，代理地址中有账号密码时创建浏览器失败
+func WithProxy(proxy *url.URL) Option {
+	return func(o *options) { o.proxy = proxy }
+}
+
+// WithUserAgent 替换浏览器的User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) { o.userAgent = userAgent }
+}
+
+// WithProfileDir 使用指定的Chrome用户数据目录，登录状态和缓存在多次运行之间保留；只对本地浏览器有效
+func WithProfileDir(dir string) Option {
+	return func(o *options) { o.profileDir = dir }
+}
+
+// WithLogger 浏览器的日志写到logger，默认使用标准库的默认logger
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// newOptions 应用可选设置并补全默认值
+func newOptions(opts []Option) *options {
+	o := &options{timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.timeout <= 0 {
+		o.timeout = defaultTimeout
+	}
+	if o.logger == nil {
+		o.logger = log.Default()
+	}
+	return o
+}
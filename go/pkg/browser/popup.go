@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// Popup 页面通过window.open打开的窗口，如结账时跳出的支付网关(KG이니시스、토스페이먼츠、카카오페이)窗口
+type Popup struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// Popups 列出当前打开的弹出窗口，已关闭窗口的连接同时释放
+func (b *Browser) Popups(ctx context.Context) ([]Popup, error) {
+	runCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	infos, err := chromedp.Targets(runCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var main target.ID
+	if c := chromedp.FromContext(b.ctx); c != nil && c.Target != nil {
+		main = c.Target.TargetID
+	}
+	var popups []Popup
+	open := make(map[target.ID]bool)
+	for _, info := range infos {
+		if info.Type != "page" || info.OpenerID == "" || info.TargetID == main {
+			continue
+		}
+		open[info.TargetID] = true
+		popups = append(popups, Popup{ID: string(info.TargetID), URL: info.URL, Title: info.Title})
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, cancel := range b.popups {
+		if !open[id] {
+			cancel()
+			delete(b.popups, id)
+		}
+	}
+	return popups, nil
+}
+
+// popupContext 返回连接到弹出窗口的上下文，连接在窗口关闭或浏览器关闭前保持
+// 取消chromedp上下文会关闭窗口，所以不能每次操作后取消
+func (b *Browser) popupContext(id string) context.Context {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.popupCtx == nil {
+		b.popupCtx = make(map[target.ID]context.Context)
+		b.popups = make(map[target.ID]context.CancelFunc)
+	}
+	tid := target.ID(id)
+	if ctx, ok := b.popupCtx[tid]; ok && ctx.Err() == nil {
+		return ctx
+	}
+	ctx, cancel := chromedp.NewContext(b.ctx, chromedp.WithTargetID(tid))
+	b.popupCtx[tid] = ctx
+	b.popups[tid] = func() {
+		cancel()
+		delete(b.popupCtx, tid)
+	}
+	return ctx
+}
+
+// PopupScreenshot 截取弹出窗口的可见区域
+func (b *Browser) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	runCtx, cancel := scoped(b.popupContext(id), ctx, 10*time.Second)
+	defer cancel()
+
+	var buf []byte
+	err := chromedp.Run(runCtx, chromedp.CaptureScreenshot(&buf))
+	return buf, err
+}
+
+// PopupScript 在弹出窗口中执行JavaScript
+func (b *Browser) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	runCtx, cancel := scoped(b.popupContext(id), ctx, 10*time.Second)
+	defer cancel()
+
+	var result interface{}
+	err := chromedp.Run(runCtx, chromedp.Evaluate(script, &result))
+	return result, err
+}
+
+// closePopups 断开所有弹出窗口的连接，调用方持有锁
+func (b *Browser) closePopups() {
+	for id, cancel := range b.popups {
+		cancel()
+		delete(b.popups, id)
+	}
+}
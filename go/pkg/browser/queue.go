@@ -0,0 +1,436 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Priority 浏览器操作的优先级，数值大的先执行
+type Priority int
+
+const (
+	// PriorityBackground 状态截图、Cookie同步、健康检查等后台操作
+	PriorityBackground Priority = iota
+	// PriorityMonitor 监控循环的检查，ctx中没有指定优先级时的默认值
+	PriorityMonitor
+	// PriorityPurchase 购买流程，到达时中断正在执行和等待中的监控操作
+	PriorityPurchase
+)
+
+// ErrPreempted 操作被购买流程中断
+var ErrPreempted = errors.New("浏览器操作被购买流程中断")
+
+// ErrClosed 浏览器已关闭
+var ErrClosed = errors.New("浏览器已关闭")
+
+type priorityKey struct{}
+
+type holdKey struct{}
+
+// WithPriority 返回带优先级的ctx，经过 Queue 的浏览器操作按此优先级排队
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityOf 返回ctx中的优先级，没有指定时为 PriorityMonitor
+func PriorityOf(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityMonitor
+}
+
+// slot 一次排队，独占期间(Exclusive)一个slot覆盖多个操作
+type slot struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+	cancel   context.CancelCauseFunc
+}
+
+// Queue 串行执行浏览器操作的Driver包装
+// 监控循环、状态截图、Cookie同步等调用方并发使用同一个浏览器时，CDP命令不会在一个流程中间交错；
+// 等待中的操作按优先级和到达顺序执行，购买流程的操作到达时取消正在执行和等待中的监控操作
+type Queue struct {
+	inner Driver
+
+	mu      sync.Mutex
+	seq     uint64
+	running *slot
+	waiting []*slot
+	closed  bool
+}
+
+var _ Driver = (*Queue)(nil)
+
+// NewQueue 包装浏览器，使所有操作经过队列
+func NewQueue(inner Driver) *Queue {
+	return &Queue{inner: inner}
+}
+
+// Exclusive 让ctx的调用方独占浏览器直到调用release，期间其他调用方的操作排队等待，
+// 用于选座到确认购买这种不能被打断的多步流程；d不是 *Queue 时直接返回
+func Exclusive(ctx context.Context, d Driver) (context.Context, func(), error) {
+	q, ok := d.(*Queue)
+	if !ok {
+		return ctx, func() {}, nil
+	}
+	if held, ok := ctx.Value(holdKey{}).(*slot); ok && held == q.current() {
+		return ctx, func() {}, nil
+	}
+	s, _, err := q.enter(ctx)
+	if err != nil {
+		return ctx, func() {}, err
+	}
+	// 返回调用方的ctx而不是排队用的ctx，释放后调用方仍可以继续使用(如等待支付)
+	return context.WithValue(ctx, holdKey{}, s), func() { q.release(s) }, nil
+}
+
+// current 返回正在执行的slot
+func (q *Queue) current() *slot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running
+}
+
+// acquire 排队等待执行，返回在执行期间有效的ctx和释放函数
+// ctx处于独占期间时直接执行
+func (q *Queue) acquire(ctx context.Context) (context.Context, func(), error) {
+	if held, ok := ctx.Value(holdKey{}).(*slot); ok && held == q.current() {
+		return ctx, func() {}, nil
+	}
+	s, runCtx, err := q.enter(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return runCtx, func() { q.release(s) }, nil
+}
+
+// enter 排队直到轮到本次操作，返回的ctx在被抢占或释放时取消
+func (q *Queue) enter(ctx context.Context) (*slot, context.Context, error) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		cancel(ErrClosed)
+		return nil, nil, ErrClosed
+	}
+	q.seq++
+	s := &slot{priority: PriorityOf(ctx), seq: q.seq, ready: make(chan struct{}), cancel: cancel}
+	if s.priority == PriorityPurchase {
+		q.preemptLocked()
+	}
+	if q.running == nil {
+		q.running = s
+		q.mu.Unlock()
+		return s, runCtx, nil
+	}
+	q.waiting = append(q.waiting, s)
+	q.mu.Unlock()
+
+	select {
+	case <-s.ready:
+		return s, runCtx, nil
+	case <-runCtx.Done():
+		q.mu.Lock()
+		for i, w := range q.waiting {
+			if w == s {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				q.mu.Unlock()
+				cancel(nil)
+				return nil, nil, context.Cause(runCtx)
+			}
+		}
+		q.mu.Unlock()
+		// 取消的同时轮到了本次操作，交给下一个
+		q.release(s)
+		return nil, nil, context.Cause(runCtx)
+	}
+}
+
+// preemptLocked 取消正在执行和等待中的监控操作，调用方持有锁
+func (q *Queue) preemptLocked() {
+	if q.running != nil && q.running.priority == PriorityMonitor {
+		q.running.cancel(ErrPreempted)
+	}
+	for _, w := range q.waiting {
+		if w.priority == PriorityMonitor {
+			w.cancel(ErrPreempted)
+		}
+	}
+}
+
+// release 结束s，把浏览器交给等待中优先级最高、到达最早的操作
+func (q *Queue) release(s *slot) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s.cancel(nil)
+	if q.running != s {
+		return
+	}
+
+	q.running = nil
+	if len(q.waiting) == 0 {
+		return
+	}
+	next := 0
+	for i, w := range q.waiting {
+		if w.priority > q.waiting[next].priority {
+			next = i
+		}
+	}
+	q.running = q.waiting[next]
+	q.waiting = append(q.waiting[:next], q.waiting[next+1:]...)
+	close(q.running.ready)
+}
+
+// Navigate 导航
+func (q *Queue) Navigate(ctx context.Context, url string) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.Navigate(ctx, url)
+}
+
+// FillForm 填写表单
+func (q *Queue) FillForm(ctx context.Context, fields map[string]string) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.FillForm(ctx, fields)
+}
+
+// SubmitForm 提交表单
+func (q *Queue) SubmitForm(ctx context.Context) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.SubmitForm(ctx)
+}
+
+// ElementExists 检查元素是否存在
+func (q *Queue) ElementExists(ctx context.Context, selector string) (bool, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+	return q.inner.ElementExists(ctx, selector)
+}
+
+// ClickElement 点击元素
+func (q *Queue) ClickElement(ctx context.Context, selector string) (bool, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+	return q.inner.ClickElement(ctx, selector)
+}
+
+// FindByText 按文字查找元素
+func (q *Queue) FindByText(ctx context.Context, text string, match TextMatch) (string, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	return q.inner.FindByText(ctx, text, match)
+}
+
+// ClickByText 按文字点击元素
+func (q *Queue) ClickByText(ctx context.Context, text string, match TextMatch) (bool, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+	return q.inner.ClickByText(ctx, text, match)
+}
+
+// WaitForElement 等待元素
+func (q *Queue) WaitForElement(ctx context.Context, selector string) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.WaitForElement(ctx, selector)
+}
+
+// GetText 获取元素文本
+func (q *Queue) GetText(ctx context.Context, selector string) (string, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	return q.inner.GetText(ctx, selector)
+}
+
+// ExecuteScript 执行脚本
+func (q *Queue) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.ExecuteScript(ctx, script)
+}
+
+// Screenshot 截图
+func (q *Queue) Screenshot(ctx context.Context, filename string) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.Screenshot(ctx, filename)
+}
+
+// ElementScreenshot 截取元素图片
+func (q *Queue) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.ElementScreenshot(ctx, selector)
+}
+
+// PageSource 获取页面源码
+func (q *Queue) PageSource(ctx context.Context) (string, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	return q.inner.PageSource(ctx)
+}
+
+// PrintPDF 打印PDF
+func (q *Queue) PrintPDF(ctx context.Context) ([]byte, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.PrintPDF(ctx)
+}
+
+// GetCurrentURL 获取当前地址
+func (q *Queue) GetCurrentURL(ctx context.Context) (string, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	return q.inner.GetCurrentURL(ctx)
+}
+
+// Reload 刷新页面
+func (q *Queue) Reload(ctx context.Context) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.Reload(ctx)
+}
+
+// Ping 检查浏览器是否响应，不经过队列：购买流程独占浏览器时排队等待会被看门狗误判为无响应
+func (q *Queue) Ping(ctx context.Context) error {
+	return q.inner.Ping(ctx)
+}
+
+// Cookies 导出Cookie
+func (q *Queue) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.Cookies(ctx)
+}
+
+// ImportCookies 导入Cookie
+func (q *Queue) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.ImportCookies(ctx, cookies)
+}
+
+// Popups 列出弹出窗口
+func (q *Queue) Popups(ctx context.Context) ([]Popup, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.Popups(ctx)
+}
+
+// PopupScreenshot 弹出窗口截图
+func (q *Queue) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.PopupScreenshot(ctx, id)
+}
+
+// PopupScript 在弹出窗口中执行脚本
+func (q *Queue) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return q.inner.PopupScript(ctx, id, script)
+}
+
+// BlockURLs 阻止加载匹配的请求
+func (q *Queue) BlockURLs(ctx context.Context, patterns []string) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.BlockURLs(ctx, patterns)
+}
+
+// RunBatch 批量执行操作，整批只排队一次
+func (q *Queue) RunBatch(ctx context.Context, steps []BatchStep) error {
+	ctx, done, err := q.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return q.inner.RunBatch(ctx, steps)
+}
+
+// Close 关闭浏览器，不经过队列；正在执行和等待中的操作立即以 ErrClosed 结束
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	if q.running != nil {
+		q.running.cancel(ErrClosed)
+	}
+	for _, w := range q.waiting {
+		w.cancel(ErrClosed)
+	}
+	q.mu.Unlock()
+	q.inner.Close()
+}
@@ -0,0 +1,297 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/browser"
+)
+
+// Player 回放录制的浏览器会话，自身实现browser.Driver
+//
+// 每次调用按顺序取下一条方法和参数都相同的记录；参数不同时(如选择器已修改)
+// 退回到下一条同名方法的记录，录制中没有的调用返回错误
+type Player struct {
+	// Realtime 为true时按录制时的耗时等待，用于复现与时间相关的问题
+	Realtime bool
+
+	mu      sync.Mutex
+	entries []Entry
+	used    []bool
+	url     string
+	page    string
+}
+
+var _ browser.Driver = (*Player)(nil)
+
+// NewPlayer 读取录制文件
+func NewPlayer(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &Player{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("录制文件第 %d 行格式错误: %w", len(p.entries)+1, err)
+		}
+		p.entries = append(p.entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p.used = make([]bool, len(p.entries))
+	return p, nil
+}
+
+// Unplayed 返回尚未回放的记录，用于检查流程是否走完了录制时的路径
+func (p *Player) Unplayed() []Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var entries []Entry
+	for i, e := range p.entries {
+		if !p.used[i] {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// next 取出下一条匹配的记录并还原结果
+func (p *Player) next(ctx context.Context, method string, args []string, result interface{}) error {
+	p.mu.Lock()
+	index := p.find(method, args, true)
+	if index < 0 {
+		index = p.find(method, args, false)
+	}
+	if index < 0 {
+		p.mu.Unlock()
+		return fmt.Errorf("回放记录中没有更多的 %s 调用", method)
+	}
+
+	e := p.entries[index]
+	p.used[index] = true
+	if e.URL != "" {
+		p.url = e.URL
+	}
+	if e.Page != "" {
+		p.page = e.Page
+	}
+	p.mu.Unlock()
+
+	if p.Realtime && e.Elapsed > 0 {
+		select {
+		case <-time.After(e.Elapsed):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if result != nil && len(e.Result) > 0 {
+		if err := json.Unmarshal(e.Result, result); err != nil {
+			return fmt.Errorf("还原 %s 结果失败: %w", method, err)
+		}
+	}
+	return replayError(e)
+}
+
+// find 查找第一条未使用的同名记录，exact为true时要求参数相同
+func (p *Player) find(method string, args []string, exact bool) int {
+	for i, e := range p.entries {
+		if p.used[i] || e.Method != method {
+			continue
+		}
+		if !exact || equal(e.Args, args) {
+			return i
+		}
+	}
+	return -1
+}
+
+// equal 比较参数
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Navigate 导航
+func (p *Player) Navigate(ctx context.Context, url string) error {
+	return p.next(ctx, "Navigate", []string{url}, nil)
+}
+
+// FillForm 填写表单
+func (p *Player) FillForm(ctx context.Context, fields map[string]string) error {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return p.next(ctx, "FillForm", names, nil)
+}
+
+// SubmitForm 提交表单
+func (p *Player) SubmitForm(ctx context.Context) error {
+	return p.next(ctx, "SubmitForm", nil, nil)
+}
+
+// ElementExists 检查元素是否存在
+func (p *Player) ElementExists(ctx context.Context, selector string) (bool, error) {
+	var exists bool
+	err := p.next(ctx, "ElementExists", []string{selector}, &exists)
+	return exists, err
+}
+
+// ClickElement 点击元素
+func (p *Player) ClickElement(ctx context.Context, selector string) (bool, error) {
+	var clicked bool
+	err := p.next(ctx, "ClickElement", []string{selector}, &clicked)
+	return clicked, err
+}
+
+// FindByText 按文字查找元素
+func (p *Player) FindByText(ctx context.Context, text string, match browser.TextMatch) (string, error) {
+	var selector string
+	err := p.next(ctx, "FindByText", []string{text, match.String()}, &selector)
+	return selector, err
+}
+
+// ClickByText 按文字点击元素
+func (p *Player) ClickByText(ctx context.Context, text string, match browser.TextMatch) (bool, error) {
+	var clicked bool
+	err := p.next(ctx, "ClickByText", []string{text, match.String()}, &clicked)
+	return clicked, err
+}
+
+// WaitForElement 等待元素
+func (p *Player) WaitForElement(ctx context.Context, selector string) error {
+	return p.next(ctx, "WaitForElement", []string{selector}, nil)
+}
+
+// GetText 获取元素文本
+func (p *Player) GetText(ctx context.Context, selector string) (string, error) {
+	var text string
+	err := p.next(ctx, "GetText", []string{selector}, &text)
+	return text, err
+}
+
+// ExecuteScript 执行脚本
+func (p *Player) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	var result interface{}
+	err := p.next(ctx, "ExecuteScript", []string{script}, &result)
+	return result, err
+}
+
+// Screenshot 回放时不生成截图文件
+func (p *Player) Screenshot(ctx context.Context, filename string) error {
+	return p.next(ctx, "Screenshot", []string{filename}, nil)
+}
+
+// ElementScreenshot 返回录制的元素图片
+func (p *Player) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	var data []byte
+	err := p.next(ctx, "ElementScreenshot", []string{selector}, &data)
+	return data, err
+}
+
+// PageSource 返回录制的页面源码，没有对应记录时返回最近一次页面快照
+func (p *Player) PageSource(ctx context.Context) (string, error) {
+	var html string
+	if err := p.next(ctx, "PageSource", nil, &html); err != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.page == "" {
+			return "", err
+		}
+		return p.page, nil
+	}
+	return html, nil
+}
+
+// PrintPDF 回放时返回空PDF
+func (p *Player) PrintPDF(ctx context.Context) ([]byte, error) {
+	return nil, p.next(ctx, "PrintPDF", nil, nil)
+}
+
+// GetCurrentURL 返回录制的地址，没有对应记录时返回最近一次页面快照的地址
+func (p *Player) GetCurrentURL(ctx context.Context) (string, error) {
+	var url string
+	if err := p.next(ctx, "GetCurrentURL", nil, &url); err != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.url, nil
+	}
+	return url, nil
+}
+
+// Reload 刷新页面
+func (p *Player) Reload(ctx context.Context) error {
+	return p.next(ctx, "Reload", nil, nil)
+}
+
+// Ping 回放时浏览器总是可用
+func (p *Player) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Cookies 录制中不保存Cookie内容，回放时返回空
+func (p *Player) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	return nil, p.next(ctx, "Cookies", nil, nil)
+}
+
+// ImportCookies 导入Cookie
+func (p *Player) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	return p.next(ctx, "ImportCookies", nil, nil)
+}
+
+// Popups 返回录制的弹出窗口
+func (p *Player) Popups(ctx context.Context) ([]browser.Popup, error) {
+	var popups []browser.Popup
+	err := p.next(ctx, "Popups", nil, &popups)
+	return popups, err
+}
+
+// PopupScreenshot 回放时返回空图片
+func (p *Player) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	return nil, p.next(ctx, "PopupScreenshot", []string{id}, nil)
+}
+
+// PopupScript 返回录制的脚本结果
+func (p *Player) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	var result interface{}
+	err := p.next(ctx, "PopupScript", []string{id, script}, &result)
+	return result, err
+}
+
+// BlockURLs 回放阻止请求的调用
+func (p *Player) BlockURLs(ctx context.Context, patterns []string) error {
+	return p.next(ctx, "BlockURLs", patterns, nil)
+}
+
+// RunBatch 回放批量操作的调用
+func (p *Player) RunBatch(ctx context.Context, steps []browser.BatchStep) error {
+	return p.next(ctx, "RunBatch", batchArgs(steps), nil)
+}
+
+// Close 关闭
+func (p *Player) Close() {}
@@ -0,0 +1,68 @@
+// Package record 录制和回放浏览器会话
+//
+// Recorder 包装真实浏览器，把每次操作的参数、结果、错误和操作后的页面源码写入JSONL录制文件；
+// Player 读取录制文件，按顺序返回录制时的结果，用于离线复现实际运行中出现的问题
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"tickgrabber/pkg/errs"
+)
+
+// Entry 录制文件中的一次浏览器操作
+type Entry struct {
+	Seq     int             `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Elapsed time.Duration   `json:"elapsed"`
+	Method  string          `json:"method"`
+	Args    []string        `json:"args,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	// Kind 错误的失败原因，回放时还原为对应的errs哨兵错误
+	Kind string `json:"kind,omitempty"`
+	// URL 和 Page 为操作完成后的页面地址和源码，只在导航、点击等会改变页面的操作后记录
+	URL  string `json:"url,omitempty"`
+	Page string `json:"page,omitempty"`
+}
+
+// kinds 可以在回放时还原的失败原因
+var kinds = []error{
+	errs.ErrLoginFailed,
+	errs.ErrSoldOut,
+	errs.ErrQueueFull,
+	errs.ErrCaptchaRequired,
+	errs.ErrSessionExpired,
+	errs.ErrElementNotFound,
+	errs.ErrPaymentTimeout,
+	errs.ErrUnsupportedSite,
+	errs.ErrPurchaseRejected,
+	errs.ErrDuplicateOrder,
+	errs.ErrPresaleRejected,
+	errs.ErrBudgetExceeded,
+}
+
+// kindOf 返回错误的失败原因名称
+func kindOf(err error) string {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind.Error()
+		}
+	}
+	return ""
+}
+
+// replayError 还原录制的错误
+func replayError(e Entry) error {
+	if e.Error == "" {
+		return nil
+	}
+	for _, kind := range kinds {
+		if kind.Error() == e.Kind {
+			return errs.New(kind, "replay."+e.Method, e.Error)
+		}
+	}
+	return errors.New(e.Error)
+}
@@ -0,0 +1,92 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"tickgrabber/pkg/browser/fake"
+	"tickgrabber/pkg/errs"
+)
+
+// recordSession 用假浏览器录制一次登录和选座
+func recordSession(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+
+	b := fake.New().SetHTML("<html>login</html>").SetElement(".btn-login", true)
+	b.OnClick(".btn-login", func(b *fake.Browser) {
+		b.SetHTML("<html>seats</html>").SetText(".seat-grade", "R석")
+	})
+
+	r, err := NewRecorder(b, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Navigate(ctx, "https://ticket.example.com/login")
+	r.FillForm(ctx, map[string]string{"#username": "test", "#password": "secret"})
+	r.ClickElement(ctx, ".btn-login")
+	r.GetText(ctx, ".seat-grade")
+	r.ClickElement(ctx, ".btn-purchase")
+	r.Close()
+	return path
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPlayer(recordSession(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Navigate(ctx, "https://ticket.example.com/login"); err != nil {
+		t.Fatal(err)
+	}
+	if html, _ := p.PageSource(ctx); html != "<html>login</html>" {
+		t.Fatalf("导航后的页面快照 = %q", html)
+	}
+	if err := p.FillForm(ctx, map[string]string{"#password": "x", "#username": "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if clicked, err := p.ClickElement(ctx, ".btn-login"); !clicked || err != nil {
+		t.Fatalf("ClickElement = %v, %v", clicked, err)
+	}
+	if html, _ := p.PageSource(ctx); html != "<html>seats</html>" {
+		t.Fatalf("点击后的页面快照 = %q", html)
+	}
+	if text, err := p.GetText(ctx, ".seat-grade"); err != nil || text != "R석" {
+		t.Fatalf("GetText = %q, %v", text, err)
+	}
+
+	// 录制时的失败原因在回放时还原为对应的哨兵错误
+	if _, err := p.ClickElement(ctx, ".btn-purchase"); !errors.Is(err, errs.ErrElementNotFound) {
+		t.Fatalf("回放的错误 = %v，期望ErrElementNotFound", err)
+	}
+	if unplayed := p.Unplayed(); len(unplayed) != 0 {
+		t.Fatalf("还有 %d 条记录未回放", len(unplayed))
+	}
+	if err := p.Navigate(ctx, "https://ticket.example.com/"); err == nil {
+		t.Fatal("录制中没有的调用应返回错误")
+	}
+}
+
+func TestReplayFallsBackToChangedArgs(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPlayer(recordSession(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 选择器修改后仍按顺序回放同名方法的记录
+	if text, err := p.GetText(ctx, ".grade-name"); err != nil || text != "R석" {
+		t.Fatalf("GetText = %q, %v", text, err)
+	}
+	if clicked, err := p.ClickElement(ctx, ".btn-purchase"); clicked || !errors.Is(err, errs.ErrElementNotFound) {
+		t.Fatalf("参数相同的记录应优先回放: %v, %v", clicked, err)
+	}
+	if clicked, err := p.ClickElement(ctx, ".btn-submit"); !clicked || err != nil {
+		t.Fatalf("ClickElement = %v, %v", clicked, err)
+	}
+}
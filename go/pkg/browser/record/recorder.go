@@ -0,0 +1,290 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/redact"
+)
+
+// Recorder 录制浏览器会话，自身实现browser.Driver
+type Recorder struct {
+	inner browser.Driver
+
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	seq  int
+}
+
+var _ browser.Driver = (*Recorder)(nil)
+
+// NewRecorder 包装浏览器并把操作写入path
+func NewRecorder(inner browser.Driver, path string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("录制浏览器会话到: %s", path)
+	return &Recorder{inner: inner, file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// write 写入一条记录，每条记录立即落盘，进程崩溃时也能保留崩溃前的操作
+func (r *Recorder) write(ctx context.Context, start time.Time, method string, args []string, result interface{}, err error, snapshot bool) {
+	e := Entry{
+		Time:    start,
+		Elapsed: time.Since(start),
+		Method:  method,
+		Args:    args,
+	}
+	if result != nil {
+		e.Result, _ = json.Marshal(result)
+	}
+	if err != nil {
+		e.Error = err.Error()
+		e.Kind = kindOf(err)
+	}
+	if snapshot {
+		e.URL, _ = r.inner.GetCurrentURL(ctx)
+		e.Page, _ = r.inner.PageSource(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	e.Seq = r.seq
+	data, _ := json.Marshal(e)
+	// 页面源码和脚本结果中可能有账号、电话等个人信息，写入前脱敏
+	r.w.WriteString(redact.String(string(data)) + "\n")
+	r.w.Flush()
+}
+
+// Navigate 导航
+func (r *Recorder) Navigate(ctx context.Context, url string) error {
+	start := time.Now()
+	err := r.inner.Navigate(ctx, url)
+	r.write(ctx, start, "Navigate", []string{url}, nil, err, true)
+	return err
+}
+
+// FillForm 填写表单，只记录字段名，不记录填写的值(可能是密码)
+func (r *Recorder) FillForm(ctx context.Context, fields map[string]string) error {
+	start := time.Now()
+	err := r.inner.FillForm(ctx, fields)
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	r.write(ctx, start, "FillForm", names, nil, err, false)
+	return err
+}
+
+// SubmitForm 提交表单
+func (r *Recorder) SubmitForm(ctx context.Context) error {
+	start := time.Now()
+	err := r.inner.SubmitForm(ctx)
+	r.write(ctx, start, "SubmitForm", nil, nil, err, true)
+	return err
+}
+
+// ElementExists 检查元素是否存在
+func (r *Recorder) ElementExists(ctx context.Context, selector string) (bool, error) {
+	start := time.Now()
+	exists, err := r.inner.ElementExists(ctx, selector)
+	r.write(ctx, start, "ElementExists", []string{selector}, exists, err, false)
+	return exists, err
+}
+
+// ClickElement 点击元素
+func (r *Recorder) ClickElement(ctx context.Context, selector string) (bool, error) {
+	start := time.Now()
+	clicked, err := r.inner.ClickElement(ctx, selector)
+	r.write(ctx, start, "ClickElement", []string{selector}, clicked, err, clicked)
+	return clicked, err
+}
+
+// FindByText 按文字查找元素
+func (r *Recorder) FindByText(ctx context.Context, text string, match browser.TextMatch) (string, error) {
+	start := time.Now()
+	selector, err := r.inner.FindByText(ctx, text, match)
+	r.write(ctx, start, "FindByText", []string{text, match.String()}, selector, err, false)
+	return selector, err
+}
+
+// ClickByText 按文字点击元素
+func (r *Recorder) ClickByText(ctx context.Context, text string, match browser.TextMatch) (bool, error) {
+	start := time.Now()
+	clicked, err := r.inner.ClickByText(ctx, text, match)
+	r.write(ctx, start, "ClickByText", []string{text, match.String()}, clicked, err, clicked)
+	return clicked, err
+}
+
+// WaitForElement 等待元素
+func (r *Recorder) WaitForElement(ctx context.Context, selector string) error {
+	start := time.Now()
+	err := r.inner.WaitForElement(ctx, selector)
+	r.write(ctx, start, "WaitForElement", []string{selector}, nil, err, false)
+	return err
+}
+
+// GetText 获取元素文本
+func (r *Recorder) GetText(ctx context.Context, selector string) (string, error) {
+	start := time.Now()
+	text, err := r.inner.GetText(ctx, selector)
+	r.write(ctx, start, "GetText", []string{selector}, text, err, false)
+	return text, err
+}
+
+// ExecuteScript 执行脚本
+func (r *Recorder) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	start := time.Now()
+	result, err := r.inner.ExecuteScript(ctx, script)
+	r.write(ctx, start, "ExecuteScript", []string{script}, result, err, false)
+	return result, err
+}
+
+// Screenshot 截图，只记录文件名
+func (r *Recorder) Screenshot(ctx context.Context, filename string) error {
+	start := time.Now()
+	err := r.inner.Screenshot(ctx, filename)
+	r.write(ctx, start, "Screenshot", []string{filename}, nil, err, false)
+	return err
+}
+
+// ElementScreenshot 截取元素图片
+func (r *Recorder) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	start := time.Now()
+	data, err := r.inner.ElementScreenshot(ctx, selector)
+	r.write(ctx, start, "ElementScreenshot", []string{selector}, data, err, false)
+	return data, err
+}
+
+// PageSource 获取页面源码
+func (r *Recorder) PageSource(ctx context.Context) (string, error) {
+	start := time.Now()
+	html, err := r.inner.PageSource(ctx)
+	r.write(ctx, start, "PageSource", nil, html, err, false)
+	return html, err
+}
+
+// PrintPDF 打印PDF，不记录PDF内容
+func (r *Recorder) PrintPDF(ctx context.Context) ([]byte, error) {
+	start := time.Now()
+	data, err := r.inner.PrintPDF(ctx)
+	r.write(ctx, start, "PrintPDF", nil, nil, err, false)
+	return data, err
+}
+
+// GetCurrentURL 获取当前地址
+func (r *Recorder) GetCurrentURL(ctx context.Context) (string, error) {
+	start := time.Now()
+	url, err := r.inner.GetCurrentURL(ctx)
+	r.write(ctx, start, "GetCurrentURL", nil, url, err, false)
+	return url, err
+}
+
+// Reload 刷新页面
+func (r *Recorder) Reload(ctx context.Context) error {
+	start := time.Now()
+	err := r.inner.Reload(ctx)
+	r.write(ctx, start, "Reload", nil, nil, err, true)
+	return err
+}
+
+// Ping 检查浏览器是否响应
+func (r *Recorder) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.inner.Ping(ctx)
+	r.write(ctx, start, "Ping", nil, nil, err, false)
+	return err
+}
+
+// Cookies 导出Cookie，只记录数量，不记录会话内容
+func (r *Recorder) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	start := time.Now()
+	cookies, err := r.inner.Cookies(ctx)
+	r.write(ctx, start, "Cookies", nil, len(cookies), err, false)
+	return cookies, err
+}
+
+// ImportCookies 导入Cookie，只记录数量
+func (r *Recorder) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	start := time.Now()
+	err := r.inner.ImportCookies(ctx, cookies)
+	r.write(ctx, start, "ImportCookies", nil, len(cookies), err, false)
+	return err
+}
+
+// Popups 列出弹出窗口
+func (r *Recorder) Popups(ctx context.Context) ([]browser.Popup, error) {
+	start := time.Now()
+	popups, err := r.inner.Popups(ctx)
+	r.write(ctx, start, "Popups", nil, popups, err, false)
+	return popups, err
+}
+
+// PopupScreenshot 弹出窗口截图，不记录图片内容
+func (r *Recorder) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	start := time.Now()
+	data, err := r.inner.PopupScreenshot(ctx, id)
+	r.write(ctx, start, "PopupScreenshot", []string{id}, nil, err, false)
+	return data, err
+}
+
+// PopupScript 在弹出窗口中执行脚本
+func (r *Recorder) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	start := time.Now()
+	result, err := r.inner.PopupScript(ctx, id, script)
+	r.write(ctx, start, "PopupScript", []string{id, script}, result, err, false)
+	return result, err
+}
+
+// BlockURLs 阻止加载匹配的请求
+func (r *Recorder) BlockURLs(ctx context.Context, patterns []string) error {
+	start := time.Now()
+	err := r.inner.BlockURLs(ctx, patterns)
+	r.write(ctx, start, "BlockURLs", patterns, nil, err, false)
+	return err
+}
+
+// RunBatch 批量执行操作，只记录每步的动作和选择器，不记录填写的值
+func (r *Recorder) RunBatch(ctx context.Context, steps []browser.BatchStep) error {
+	start := time.Now()
+	err := r.inner.RunBatch(ctx, steps)
+	r.write(ctx, start, "RunBatch", batchArgs(steps), nil, err, true)
+	return err
+}
+
+// batchArgs 批量操作的录制参数
+func batchArgs(steps []browser.BatchStep) []string {
+	args := make([]string, len(steps))
+	for i, step := range steps {
+		args[i] = step.String()
+	}
+	return args
+}
+
+// Close 关闭浏览器和录制文件
+func (r *Recorder) Close() {
+	r.inner.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.w.Flush()
+	r.file.Close()
+}
@@ -0,0 +1,12 @@
+package browser
+
+import "syscall"
+
+// shmSize 返回/dev/shm的大小，无法读取时第二个结果为false
+func shmSize() (int64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs("/dev/shm", &st); err != nil {
+		return 0, false
+	}
+	return int64(st.Blocks) * int64(st.Bsize), true
+}
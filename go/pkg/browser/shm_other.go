@@ -0,0 +1,8 @@
+//go:build !linux
+
+package browser
+
+// shmSize 只有Linux上的Chrome使用/dev/shm
+func shmSize() (int64, bool) {
+	return 0, false
+}
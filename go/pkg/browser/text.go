@@ -0,0 +1,118 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chromedp/chromedp"
+	"golang.org/x/text/unicode/norm"
+
+	"tickgrabber/pkg/errs"
+)
+
+// TextMatch 按文字查找元素时的匹配方式
+type TextMatch int
+
+// 文字匹配方式
+const (
+	// MatchExact 忽略首尾和连续空白后完全相同
+	MatchExact TextMatch = iota
+	// MatchFuzzy 忽略空白、标点和大小写后包含关键字，"좌석선택"可以匹配"좌석 선택하기"
+	MatchFuzzy
+)
+
+// String 返回匹配方式名称
+func (m TextMatch) String() string {
+	if m == MatchFuzzy {
+		return "fuzzy"
+	}
+	return "exact"
+}
+
+// NormalizeText 按匹配方式规范化文字，韩文统一为NFC组合形式
+func NormalizeText(s string, match TextMatch) string {
+	s = norm.NFC.String(s)
+	if match == MatchExact {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// MatchText 判断元素文字是否匹配关键字
+func MatchText(text, keyword string, match TextMatch) bool {
+	text, keyword = NormalizeText(text, match), NormalizeText(keyword, match)
+	if keyword == "" {
+		return false
+	}
+	if match == MatchExact {
+		return text == keyword
+	}
+	return strings.Contains(text, keyword)
+}
+
+// findByTextScript 查找文字匹配的可见且未禁用的元素并打上标记，返回标记选择器
+// 可点击元素优先，其次是文字最短(最内层)的元素，与 NormalizeText 的规则一致
+const findByTextScript = `(() => {
+	const keyword = %s, fuzzy = %t;
+	const normalize = s => {
+		s = (s || "").normalize("NFC");
+		if (!fuzzy) return s.trim().replace(/\s+/g, " ");
+		return s.replace(/[\s\p{P}\p{S}]/gu, "").toLowerCase();
+	};
+	const want = normalize(keyword);
+	if (!want) return "";
+	const visible = e => {
+		const r = e.getBoundingClientRect();
+		return r.width > 0 && r.height > 0 && !e.disabled && e.getAttribute("aria-disabled") !== "true";
+	};
+	const text = e => e.tagName === "INPUT" ? e.value : e.innerText;
+	const clickable = "button, a, input[type='submit'], input[type='button'], [role='button'], [onclick]";
+
+	const matches = Array.from(document.body.querySelectorAll("*")).filter(e => {
+		const t = normalize(text(e));
+		return visible(e) && (fuzzy ? t.includes(want) : t === want);
+	});
+	if (matches.length === 0) return "";
+
+	matches.sort((a, b) => (b.matches(clickable) - a.matches(clickable)) || (text(a).length - text(b).length));
+	const el = matches[0].closest(clickable) || matches[0];
+	const id = String(Date.now()) + Math.floor(Math.random() * 1000);
+	el.setAttribute("data-tg-text", id);
+	return "[data-tg-text='" + id + "']";
+})()`
+
+// FindByText 查找文字匹配的可见且未禁用的元素，返回可用于其他方法的选择器
+// 很多购票按钮没有稳定的class或id，只能按"예매"、"결제"等文字定位
+func (b *Browser) FindByText(ctx context.Context, text string, match TextMatch) (string, error) {
+	timeoutCtx, cancel := scoped(b.ctx, ctx, 5*time.Second)
+	defer cancel()
+
+	keyword, _ := json.Marshal(text)
+	var selector string
+	err := chromedp.Run(timeoutCtx, chromedp.Evaluate(fmt.Sprintf(findByTextScript, keyword, match == MatchFuzzy), &selector))
+	if err != nil {
+		return "", errs.Wrap(errs.ErrElementNotFound, "browser.findByText", err)
+	}
+	if selector == "" {
+		return "", errs.New(errs.ErrElementNotFound, "browser.findByText", text)
+	}
+	return selector, nil
+}
+
+// ClickByText 点击文字匹配的元素
+func (b *Browser) ClickByText(ctx context.Context, text string, match TextMatch) (bool, error) {
+	selector, err := b.FindByText(ctx, text, match)
+	if err != nil {
+		return false, err
+	}
+	return b.ClickElement(ctx, selector)
+}
@@ -0,0 +1,222 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"tickgrabber/pkg/tracing"
+)
+
+// tracedDriver 为每个浏览器操作创建追踪span
+// 表单只记录字段的选择器，不记录填写的值；脚本只记录长度
+type tracedDriver struct {
+	inner Driver
+}
+
+// WithTracing 包装浏览器，使浏览器操作成为调用方ctx中span的子span
+func WithTracing(inner Driver) Driver {
+	return &tracedDriver{inner: inner}
+}
+
+// Navigate 导航
+func (t *tracedDriver) Navigate(ctx context.Context, url string) error {
+	ctx, span := tracing.Start(ctx, "browser.Navigate", attribute.String("url", url))
+	err := t.inner.Navigate(ctx, url)
+	tracing.End(span, err)
+	return err
+}
+
+// FillForm 填写表单
+func (t *tracedDriver) FillForm(ctx context.Context, fields map[string]string) error {
+	selectors := make([]string, 0, len(fields))
+	for selector := range fields {
+		selectors = append(selectors, selector)
+	}
+	ctx, span := tracing.Start(ctx, "browser.FillForm", attribute.StringSlice("fields", selectors))
+	err := t.inner.FillForm(ctx, fields)
+	tracing.End(span, err)
+	return err
+}
+
+// SubmitForm 提交表单
+func (t *tracedDriver) SubmitForm(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "browser.SubmitForm")
+	err := t.inner.SubmitForm(ctx)
+	tracing.End(span, err)
+	return err
+}
+
+// ElementExists 检查元素是否存在
+func (t *tracedDriver) ElementExists(ctx context.Context, selector string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "browser.ElementExists", attribute.String("selector", selector))
+	found, err := t.inner.ElementExists(ctx, selector)
+	span.SetAttributes(attribute.Bool("found", found))
+	tracing.End(span, err)
+	return found, err
+}
+
+// ClickElement 点击元素
+func (t *tracedDriver) ClickElement(ctx context.Context, selector string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "browser.ClickElement", attribute.String("selector", selector))
+	clicked, err := t.inner.ClickElement(ctx, selector)
+	span.SetAttributes(attribute.Bool("clicked", clicked))
+	tracing.End(span, err)
+	return clicked, err
+}
+
+// FindByText 按文字查找元素
+func (t *tracedDriver) FindByText(ctx context.Context, text string, match TextMatch) (string, error) {
+	ctx, span := tracing.Start(ctx, "browser.FindByText", attribute.String("text", text))
+	selector, err := t.inner.FindByText(ctx, text, match)
+	tracing.End(span, err)
+	return selector, err
+}
+
+// ClickByText 按文字点击元素
+func (t *tracedDriver) ClickByText(ctx context.Context, text string, match TextMatch) (bool, error) {
+	ctx, span := tracing.Start(ctx, "browser.ClickByText", attribute.String("text", text))
+	clicked, err := t.inner.ClickByText(ctx, text, match)
+	span.SetAttributes(attribute.Bool("clicked", clicked))
+	tracing.End(span, err)
+	return clicked, err
+}
+
+// WaitForElement 等待元素出现
+func (t *tracedDriver) WaitForElement(ctx context.Context, selector string) error {
+	ctx, span := tracing.Start(ctx, "browser.WaitForElement", attribute.String("selector", selector))
+	err := t.inner.WaitForElement(ctx, selector)
+	tracing.End(span, err)
+	return err
+}
+
+// GetText 获取元素文字
+func (t *tracedDriver) GetText(ctx context.Context, selector string) (string, error) {
+	ctx, span := tracing.Start(ctx, "browser.GetText", attribute.String("selector", selector))
+	text, err := t.inner.GetText(ctx, selector)
+	tracing.End(span, err)
+	return text, err
+}
+
+// ExecuteScript 执行脚本
+func (t *tracedDriver) ExecuteScript(ctx context.Context, script string) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "browser.ExecuteScript", attribute.Int("script_length", len(script)))
+	result, err := t.inner.ExecuteScript(ctx, script)
+	tracing.End(span, err)
+	return result, err
+}
+
+// Screenshot 截图
+func (t *tracedDriver) Screenshot(ctx context.Context, filename string) error {
+	ctx, span := tracing.Start(ctx, "browser.Screenshot", attribute.String("file", filename))
+	err := t.inner.Screenshot(ctx, filename)
+	tracing.End(span, err)
+	return err
+}
+
+// ElementScreenshot 元素截图
+func (t *tracedDriver) ElementScreenshot(ctx context.Context, selector string) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "browser.ElementScreenshot", attribute.String("selector", selector))
+	data, err := t.inner.ElementScreenshot(ctx, selector)
+	tracing.End(span, err)
+	return data, err
+}
+
+// PageSource 获取页面源码
+func (t *tracedDriver) PageSource(ctx context.Context) (string, error) {
+	ctx, span := tracing.Start(ctx, "browser.PageSource")
+	source, err := t.inner.PageSource(ctx)
+	tracing.End(span, err)
+	return source, err
+}
+
+// PrintPDF 打印页面为PDF
+func (t *tracedDriver) PrintPDF(ctx context.Context) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "browser.PrintPDF")
+	data, err := t.inner.PrintPDF(ctx)
+	tracing.End(span, err)
+	return data, err
+}
+
+// GetCurrentURL 获取当前地址
+func (t *tracedDriver) GetCurrentURL(ctx context.Context) (string, error) {
+	ctx, span := tracing.Start(ctx, "browser.GetCurrentURL")
+	url, err := t.inner.GetCurrentURL(ctx)
+	tracing.End(span, err)
+	return url, err
+}
+
+// Reload 刷新页面
+func (t *tracedDriver) Reload(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "browser.Reload")
+	err := t.inner.Reload(ctx)
+	tracing.End(span, err)
+	return err
+}
+
+// Ping 检查浏览器是否响应，看门狗定期调用，不创建span
+func (t *tracedDriver) Ping(ctx context.Context) error {
+	return t.inner.Ping(ctx)
+}
+
+// Cookies 获取Cookie
+func (t *tracedDriver) Cookies(ctx context.Context) ([]*http.Cookie, error) {
+	ctx, span := tracing.Start(ctx, "browser.Cookies")
+	cookies, err := t.inner.Cookies(ctx)
+	tracing.End(span, err)
+	return cookies, err
+}
+
+// ImportCookies 导入Cookie
+func (t *tracedDriver) ImportCookies(ctx context.Context, cookies []*http.Cookie) error {
+	ctx, span := tracing.Start(ctx, "browser.ImportCookies", attribute.Int("count", len(cookies)))
+	err := t.inner.ImportCookies(ctx, cookies)
+	tracing.End(span, err)
+	return err
+}
+
+// Popups 列出弹出窗口
+func (t *tracedDriver) Popups(ctx context.Context) ([]Popup, error) {
+	ctx, span := tracing.Start(ctx, "browser.Popups")
+	popups, err := t.inner.Popups(ctx)
+	tracing.End(span, err)
+	return popups, err
+}
+
+// PopupScreenshot 弹出窗口截图
+func (t *tracedDriver) PopupScreenshot(ctx context.Context, id string) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "browser.PopupScreenshot", attribute.String("popup", id))
+	data, err := t.inner.PopupScreenshot(ctx, id)
+	tracing.End(span, err)
+	return data, err
+}
+
+// PopupScript 在弹出窗口中执行脚本
+func (t *tracedDriver) PopupScript(ctx context.Context, id string, script string) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "browser.PopupScript", attribute.String("popup", id), attribute.Int("script_length", len(script)))
+	result, err := t.inner.PopupScript(ctx, id, script)
+	tracing.End(span, err)
+	return result, err
+}
+
+// BlockURLs 阻止加载匹配的请求
+func (t *tracedDriver) BlockURLs(ctx context.Context, patterns []string) error {
+	ctx, span := tracing.Start(ctx, "browser.BlockURLs", attribute.Int("patterns", len(patterns)))
+	err := t.inner.BlockURLs(ctx, patterns)
+	tracing.End(span, err)
+	return err
+}
+
+// RunBatch 批量执行操作
+func (t *tracedDriver) RunBatch(ctx context.Context, steps []BatchStep) error {
+	ctx, span := tracing.Start(ctx, "browser.RunBatch", attribute.Int("steps", len(steps)))
+	err := t.inner.RunBatch(ctx, steps)
+	tracing.End(span, err)
+	return err
+}
+
+// Close 关闭浏览器
+func (t *tracedDriver) Close() {
+	t.inner.Close()
+}
@@ -0,0 +1,33 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+
+	"tickgrabber/pkg/models"
+)
+
+// Solver 验证码求解器，输入验证码图片，返回识别出的文本
+type Solver interface {
+	Name() string
+	Solve(ctx context.Context, image []byte) (string, error)
+}
+
+// NewSolver 根据配置创建带统计的验证码求解器，未配置时返回nil
+func NewSolver(config *models.Config) (*TrackedSolver, error) {
+	var solver Solver
+
+	switch config.Captcha.Relay {
+	case "":
+		return nil, nil
+	case "telegram":
+		if config.Notification.Telegram.BotToken == "" || config.Notification.Telegram.ChatID == "" {
+			return nil, fmt.Errorf("Telegram验证码中继需要配置bot_token和chat_id")
+		}
+		solver = NewTelegramRelay(config.Notification.Telegram)
+	default:
+		return nil, fmt.Errorf("不支持的验证码中继: %s", config.Captcha.Relay)
+	}
+
+	return Track(solver, config.Captcha.CostPerSolve, config.Captcha.MaxSpendPerRun), nil
+}
@@ -0,0 +1,149 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded 本次运行的验证码花费已达上限
+var ErrBudgetExceeded = errors.New("验证码花费已达上限")
+
+// Stats 验证码求解统计
+type Stats struct {
+	mu           sync.Mutex
+	attempts     int
+	successes    int
+	failures     int
+	totalLatency time.Duration
+	spent        float64
+}
+
+// Process 进程内所有求解器的累计统计，守护模式下包含全部任务，由/metrics输出
+var Process = &Stats{}
+
+// record 记录一次求解的结果
+func (s *Stats) record(elapsed time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.totalLatency += elapsed
+	if err != nil {
+		s.failures++
+	} else {
+		s.successes++
+	}
+}
+
+// StatsSnapshot 验证码统计快照
+type StatsSnapshot struct {
+	Attempts       int           `json:"attempts"`
+	Successes      int           `json:"successes"`
+	Failures       int           `json:"failures"`
+	AverageLatency time.Duration `json:"average_latency"`
+	Spent          float64       `json:"spent"`
+}
+
+// Snapshot 返回当前统计
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := StatsSnapshot{
+		Attempts:  s.attempts,
+		Successes: s.successes,
+		Failures:  s.failures,
+		Spent:     s.spent,
+	}
+	if s.attempts > 0 {
+		snapshot.AverageLatency = s.totalLatency / time.Duration(s.attempts)
+	}
+	return snapshot
+}
+
+// String 返回运行结束时打印的摘要
+func (s StatsSnapshot) String() string {
+	rate := 0.0
+	if s.Attempts > 0 {
+		rate = float64(s.Successes) / float64(s.Attempts) * 100
+	}
+	return fmt.Sprintf("验证码: 尝试 %d 次, 成功 %d 次 (%.0f%%), 平均耗时 %v, 花费 %.4f",
+		s.Attempts, s.Successes, rate, s.AverageLatency.Round(time.Millisecond), s.Spent)
+}
+
+// TrackedSolver 记录统计并执行花费上限的求解器包装
+type TrackedSolver struct {
+	Solver
+	stats        *Stats
+	costPerSolve float64
+	maxSpend     float64
+}
+
+// Track 包装求解器，costPerSolve为每次求解的花费，maxSpend为0时不限制
+func Track(solver Solver, costPerSolve, maxSpend float64) *TrackedSolver {
+	return &TrackedSolver{
+		Solver:       solver,
+		stats:        &Stats{},
+		costPerSolve: costPerSolve,
+		maxSpend:     maxSpend,
+	}
+}
+
+// Solve 在预算内求解并记录结果
+func (t *TrackedSolver) Solve(ctx context.Context, image []byte) (string, error) {
+	t.stats.mu.Lock()
+	if t.maxSpend > 0 && t.stats.spent+t.costPerSolve > t.maxSpend {
+		t.stats.mu.Unlock()
+		return "", ErrBudgetExceeded
+	}
+	t.stats.attempts++
+	t.stats.spent += t.costPerSolve
+	t.stats.mu.Unlock()
+
+	Process.mu.Lock()
+	Process.attempts++
+	Process.spent += t.costPerSolve
+	Process.mu.Unlock()
+
+	start := time.Now()
+	answer, err := t.Solver.Solve(ctx, image)
+	elapsed := time.Since(start)
+
+	t.stats.record(elapsed, err)
+	Process.record(elapsed, err)
+
+	return answer, err
+}
+
+// Stats 返回统计快照
+func (t *TrackedSolver) Stats() StatsSnapshot {
+	return t.stats.Snapshot()
+}
+
+// MetricsHandler 以Prometheus文本格式输出进程内的验证码统计
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Process.mu.Lock()
+		attempts, successes, failures := Process.attempts, Process.successes, Process.failures
+		latency, spent := Process.totalLatency, Process.spent
+		Process.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		metrics := []struct {
+			name, help string
+			value      float64
+		}{
+			{"tickgrabber_captcha_attempts_total", "验证码求解次数", float64(attempts)},
+			{"tickgrabber_captcha_successes_total", "验证码求解成功次数", float64(successes)},
+			{"tickgrabber_captcha_failures_total", "验证码求解失败次数", float64(failures)},
+			{"tickgrabber_captcha_solve_seconds_total", "验证码求解累计耗时(秒)", latency.Seconds()},
+			{"tickgrabber_captcha_spent_total", "验证码求解累计花费", spent},
+		}
+		for _, m := range metrics {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %g\n", m.name, m.help, m.name, m.name, m.value)
+		}
+	})
+}
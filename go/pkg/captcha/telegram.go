@@ -0,0 +1,252 @@
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// telegramAPI Telegram Bot API地址
+const telegramAPI = "https://api.telegram.org/bot"
+
+// TelegramRelay 将验证码图片发到Telegram，由用户回复验证码文本
+// 用于无头服务器上的远程操作
+type TelegramRelay struct {
+	config models.TelegramConfig
+	client *http.Client
+}
+
+// NewTelegramRelay 创建Telegram验证码中继
+func NewTelegramRelay(config models.TelegramConfig) *TelegramRelay {
+	return &TelegramRelay{
+		config: config,
+		// 长轮询最多等待30秒，超时时间需要更长
+		client: &http.Client{Timeout: 40 * time.Second},
+	}
+}
+
+// Name 求解器名称
+func (r *TelegramRelay) Name() string {
+	return "telegram"
+}
+
+// telegramUpdate getUpdates返回的更新
+type telegramUpdate struct {
+	UpdateID int `json:"update_id"`
+	Message  *struct {
+		Date int64  `json:"date"`
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		ReplyTo *struct {
+			MessageID int `json:"message_id"`
+		} `json:"reply_to_message"`
+	} `json:"message"`
+}
+
+// Solve 发送验证码图片并等待用户回复，直到ctx取消
+func (r *TelegramRelay) Solve(ctx context.Context, image []byte) (string, error) {
+	// 跳过发送前已有的消息，避免把旧回复当作答案
+	offset, err := r.latestOffset(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	messageID, err := r.sendPhoto(ctx, image, "需要验证码，请直接回复图片中的字符")
+	if err != nil {
+		return "", fmt.Errorf("发送验证码图片失败: %w", err)
+	}
+	log.Println("验证码已发送到Telegram，等待回复...")
+	return r.waitReply(ctx, offset, messageID)
+}
+
+// Ask 发送文字问题并等待用户回复，直到ctx取消，用于购买前的确认
+func (r *TelegramRelay) Ask(ctx context.Context, question string) (string, error) {
+	offset, err := r.latestOffset(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	messageID, err := r.sendMessage(ctx, question)
+	if err != nil {
+		return "", fmt.Errorf("发送消息失败: %w", err)
+	}
+	return r.waitReply(ctx, offset, messageID)
+}
+
+// waitReply 从offset开始等待对messageID的回复或同一会话中的下一条消息
+func (r *TelegramRelay) waitReply(ctx context.Context, offset, messageID int) (string, error) {
+	for {
+		updates, err := r.getUpdates(ctx, offset, 30)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			log.Printf("获取Telegram回复失败: %v", err)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if answer, ok := r.answer(u, messageID); ok {
+				return answer, nil
+			}
+		}
+	}
+}
+
+// answer 判断更新是否为对所发消息的回复
+func (r *TelegramRelay) answer(u telegramUpdate, messageID int) (string, bool) {
+	if u.Message == nil || fmt.Sprint(u.Message.Chat.ID) != r.config.ChatID {
+		return "", false
+	}
+	if u.Message.ReplyTo != nil && u.Message.ReplyTo.MessageID != messageID {
+		return "", false
+	}
+
+	text := strings.TrimSpace(u.Message.Text)
+	if text == "" || strings.HasPrefix(text, "/") {
+		return "", false
+	}
+	return text, true
+}
+
+// latestOffset 返回下一条新消息的offset
+func (r *TelegramRelay) latestOffset(ctx context.Context) (int, error) {
+	updates, err := r.getUpdates(ctx, -1, 0)
+	if err != nil {
+		return 0, err
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	return updates[len(updates)-1].UpdateID + 1, nil
+}
+
+// getUpdates 长轮询获取更新
+func (r *TelegramRelay) getUpdates(ctx context.Context, offset, timeout int) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", fmt.Sprint(offset))
+	params.Set("timeout", fmt.Sprint(timeout))
+	params.Set("allowed_updates", `["message"]`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint("getUpdates")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := r.do(req, &result); err != nil {
+		return nil, err
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("Telegram API错误: %s", result.Description)
+	}
+	return result.Result, nil
+}
+
+// sendPhoto 发送图片，返回消息ID
+func (r *TelegramRelay) sendPhoto(ctx context.Context, image []byte, caption string) (int, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("chat_id", r.config.ChatID)
+	writer.WriteField("caption", caption)
+
+	part, err := writer.CreateFormFile("photo", "captcha.png")
+	if err != nil {
+		return 0, err
+	}
+	part.Write(image)
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint("sendPhoto"), &buf)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			MessageID int `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := r.do(req, &result); err != nil {
+		return 0, err
+	}
+	if !result.OK {
+		return 0, fmt.Errorf("Telegram API错误: %s", result.Description)
+	}
+	return result.Result.MessageID, nil
+}
+
+// sendMessage 发送文字消息，返回消息ID
+func (r *TelegramRelay) sendMessage(ctx context.Context, text string) (int, error) {
+	body, err := json.Marshal(map[string]string{"chat_id": r.config.ChatID, "text": text})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint("sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			MessageID int `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := r.do(req, &result); err != nil {
+		return 0, err
+	}
+	if !result.OK {
+		return 0, fmt.Errorf("Telegram API错误: %s", result.Description)
+	}
+	return result.Result.MessageID, nil
+}
+
+// do 发送请求并解析JSON响应
+func (r *TelegramRelay) do(req *http.Request, v interface{}) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// endpoint 返回Bot API方法地址
+func (r *TelegramRelay) endpoint(method string) string {
+	return telegramAPI + r.config.BotToken + "/" + method
+}
@@ -0,0 +1,75 @@
+// Package clock 抢票器和调度器使用的时间来源
+// 正常运行使用系统时钟；演练和测试使用模拟时钟，"等待到20:00开售" 之类的等待可以确定性地快进
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock 时间来源
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer 一次性定时器
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker 周期定时器
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real 系统时钟
+var Real Clock = realClock{}
+
+// IsReal 判断c是否为系统时钟
+func IsReal(c Clock) bool {
+	_, ok := c.(realClock)
+	return ok
+}
+
+// Sleep 等待d，ctx取消时提前返回ctx的错误
+func Sleep(ctx context.Context, c Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// Until 返回距t的时长
+func Until(c Clock, t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+
+func (t realTicker) Stop() { t.t.Stop() }
@@ -0,0 +1,183 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Simulated 模拟时钟
+// NewSimulated 创建的时钟只在调用Advance时前进，到期的定时器按时间顺序触发，用于测试；
+// NewFastForward 创建的时钟自动快进：定时器立即触发并把时钟拨到到期时刻，周期定时器每被读取一次前进一个周期，
+// 用于演练时跳过等待开售和轮询间隔
+type Simulated struct {
+	mu      sync.Mutex
+	now     time.Time
+	auto    bool
+	waiters []*waiter
+}
+
+// waiter 模拟时钟上的定时器，period大于0时为周期定时器
+type waiter struct {
+	s      *Simulated
+	when   time.Time
+	period time.Duration
+	c      chan time.Time
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// NewSimulated 创建从start开始、手动前进的模拟时钟
+func NewSimulated(start time.Time) *Simulated {
+	return &Simulated{now: start}
+}
+
+// NewFastForward 创建从start开始、自动快进的模拟时钟
+func NewFastForward(start time.Time) *Simulated {
+	return &Simulated{now: start, auto: true}
+}
+
+// Now 返回模拟的当前时间
+func (s *Simulated) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance 把时钟拨快d，期间到期的定时器按到期时间依次触发
+func (s *Simulated) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.now.Add(d)
+	for {
+		next := -1
+		for i, w := range s.waiters {
+			if !w.when.After(target) && (next < 0 || w.when.Before(s.waiters[next].when)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		w := s.waiters[next]
+		if w.when.After(s.now) {
+			s.now = w.when
+		}
+		w.fire(s.now)
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			s.remove(w)
+		}
+	}
+	if target.After(s.now) {
+		s.now = target
+	}
+}
+
+// NewTimer 创建一次性定时器
+func (s *Simulated) NewTimer(d time.Duration) Timer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := &waiter{s: s, when: s.now.Add(d), c: make(chan time.Time, 1)}
+	switch {
+	case s.auto:
+		if w.when.After(s.now) {
+			s.now = w.when
+		}
+		w.fire(s.now)
+	case d <= 0:
+		w.fire(s.now)
+	default:
+		s.waiters = append(s.waiters, w)
+	}
+	return simTimer{w}
+}
+
+// NewTicker 创建周期定时器，d必须大于0
+func (s *Simulated) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: 周期定时器的间隔必须大于0")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := &waiter{s: s, when: s.now.Add(d), period: d}
+	if !s.auto {
+		w.c = make(chan time.Time, 1)
+		s.waiters = append(s.waiters, w)
+		return simTicker{w}
+	}
+
+	// 自动快进时每次被读取前才把时钟拨到下一个周期
+	w.c = make(chan time.Time)
+	w.stop = make(chan struct{})
+	go func() {
+		next := w.when
+		for {
+			s.mu.Lock()
+			if next.After(s.now) {
+				s.now = next
+			}
+			now := s.now
+			s.mu.Unlock()
+
+			select {
+			case <-w.stop:
+				return
+			case w.c <- now:
+				next = now.Add(d)
+			}
+		}
+	}()
+	return simTicker{w}
+}
+
+// simTimer 模拟时钟的一次性定时器
+type simTimer struct{ w *waiter }
+
+func (t simTimer) C() <-chan time.Time { return t.w.c }
+
+// Stop 停止定时器，返回定时器是否尚未触发
+func (t simTimer) Stop() bool {
+	t.w.s.mu.Lock()
+	defer t.w.s.mu.Unlock()
+	return t.w.s.remove(t.w)
+}
+
+// simTicker 模拟时钟的周期定时器
+type simTicker struct{ w *waiter }
+
+func (t simTicker) C() <-chan time.Time { return t.w.c }
+
+// Stop 停止定时器
+func (t simTicker) Stop() {
+	if t.w.stop != nil {
+		t.w.once.Do(func() { close(t.w.stop) })
+		return
+	}
+	t.w.s.mu.Lock()
+	defer t.w.s.mu.Unlock()
+	t.w.s.remove(t.w)
+}
+
+// fire 非阻塞地发送触发时间，接收方还没读取上一次时丢弃(与time.Ticker一致)
+func (w *waiter) fire(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+}
+
+// remove 移除定时器，调用方持有锁
+func (s *Simulated) remove(w *waiter) bool {
+	for i, other := range s.waiters {
+		if other == w {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
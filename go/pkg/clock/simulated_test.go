@@ -0,0 +1,88 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 3, 1, 19, 59, 0, 0, time.UTC)
+
+// fired 非阻塞地读取定时器
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestAdvanceFiresTimersInOrder(t *testing.T) {
+	c := NewSimulated(start)
+	late := c.NewTimer(2 * time.Minute)
+	early := c.NewTimer(time.Minute)
+
+	c.Advance(30 * time.Second)
+	if _, ok := fired(early.C()); ok {
+		t.Fatal("定时器提前触发")
+	}
+
+	c.Advance(time.Minute)
+	if at, ok := fired(early.C()); !ok || !at.Equal(start.Add(time.Minute)) {
+		t.Fatalf("定时器触发时间 = %v, %v", at, ok)
+	}
+	if _, ok := fired(late.C()); ok {
+		t.Fatal("后到期的定时器提前触发")
+	}
+	if now := c.Now(); !now.Equal(start.Add(90 * time.Second)) {
+		t.Fatalf("Now = %v", now)
+	}
+
+	if !late.Stop() {
+		t.Fatal("未触发的定时器Stop应返回true")
+	}
+	c.Advance(time.Hour)
+	if _, ok := fired(late.C()); ok {
+		t.Fatal("已停止的定时器仍然触发")
+	}
+}
+
+func TestTickerDropsMissedTicks(t *testing.T) {
+	c := NewSimulated(start)
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	c.Advance(5 * time.Second)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(start.Add(time.Second)) {
+		t.Fatalf("第一次触发 = %v, %v", at, ok)
+	}
+	if _, ok := fired(ticker.C()); ok {
+		t.Fatal("没有读取期间的触发应被丢弃")
+	}
+
+	c.Advance(time.Second)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(start.Add(6*time.Second)) {
+		t.Fatalf("读取后的下一次触发 = %v, %v", at, ok)
+	}
+}
+
+func TestFastForwardSkipsWaits(t *testing.T) {
+	c := NewFastForward(start)
+	open := start.Add(time.Minute)
+
+	if err := Sleep(context.Background(), c, Until(c, open)); err != nil {
+		t.Fatal(err)
+	}
+	if now := c.Now(); !now.Equal(open) {
+		t.Fatalf("等待后 Now = %v，期望 %v", now, open)
+	}
+
+	ticker := c.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for i := 1; i <= 3; i++ {
+		if at := <-ticker.C(); !at.Equal(open.Add(time.Duration(i) * 10 * time.Second)) {
+			t.Fatalf("第 %d 次触发 = %v", i, at)
+		}
+	}
+}
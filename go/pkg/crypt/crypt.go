@@ -0,0 +1,147 @@
+// Package crypt 用口令加密配置中的敏感信息和保存的文件
+// 密文格式为 "enc:v1:" 加base64编码的 盐(16字节)|随机数(12字节)|AES-256-GCM密文，
+// 密钥由口令经PBKDF2-SHA256派生
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Prefix 密文前缀
+const Prefix = "enc:v1:"
+
+const (
+	saltSize   = 16
+	iterations = 600000
+)
+
+// ErrDecrypt 口令错误或密文被修改
+var ErrDecrypt = errors.New("解密失败，口令错误或密文已损坏")
+
+// IsSealed 判断s是否为Seal生成的密文
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Seal 用口令加密数据
+func Seal(passphrase string, plaintext []byte) (string, error) {
+	if passphrase == "" {
+		return "", errors.New("口令不能为空")
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, plaintext, nil)
+	return Prefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Open 用口令解密Seal生成的密文
+func Open(passphrase, sealed string) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("不是加密的内容")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, Prefix))
+	if err != nil || len(data) < saltSize {
+		return nil, ErrDecrypt
+	}
+	aead, err := newAEAD(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// newAEAD 由口令和盐派生密钥
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// FileExt 加密文件的扩展名
+const FileExt = ".enc"
+
+// SealFile 用口令加密文件，写入 path+FileExt 后删除原文件
+func SealFile(passphrase, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sealed, err := Seal(passphrase, data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+FileExt, []byte(sealed), 0600); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// ReadFile 读取文件，扩展名为 FileExt 时用口令解密
+func ReadFile(passphrase, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasSuffix(path, FileExt) {
+		return data, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("文件已加密，没有提供解密口令")
+	}
+	return Open(passphrase, strings.TrimSpace(string(data)))
+}
+
+// WriteFile 写入文件，口令非空时写入加密后的内容，文件名不变
+func WriteFile(passphrase, path string, data []byte, perm os.FileMode) error {
+	if passphrase == "" {
+		return os.WriteFile(path, data, perm)
+	}
+	sealed, err := Seal(passphrase, data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(sealed), perm)
+}
+
+// Decode 解密WriteFile写入的内容，不是密文时原样返回，读取之前可能没有加密的文件时使用
+func Decode(passphrase string, data []byte) ([]byte, error) {
+	sealed := strings.TrimSpace(string(data))
+	if !IsSealed(sealed) {
+		return data, nil
+	}
+	if passphrase == "" {
+		return nil, errors.New("文件已加密，没有提供解密口令")
+	}
+	return Open(passphrase, sealed)
+}
@@ -0,0 +1,166 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	for _, plaintext := range [][]byte{nil, []byte("a"), []byte("4111-1111-1111-1111|12/28"), bytes.Repeat([]byte{0xff}, 4096)} {
+		sealed, err := Seal("口令", plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsSealed(sealed) {
+			t.Errorf("密文 %q 没有前缀 %s", sealed, Prefix)
+		}
+		got, err := Open("口令", sealed)
+		if err != nil {
+			t.Fatalf("Open = %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("解密得到 %q，期望 %q", got, plaintext)
+		}
+	}
+
+	// 每次加密使用新的盐和随机数
+	a, _ := Seal("口令", []byte("x"))
+	b, _ := Seal("口令", []byte("x"))
+	if a == b {
+		t.Error("相同内容两次加密的密文相同")
+	}
+
+	if _, err := Seal("", []byte("x")); err == nil {
+		t.Error("空口令应返回错误")
+	}
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	sealed, err := Seal("正确的口令", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"错误的口令", "", "正确的口令 "} {
+		if _, err := Open(key, sealed); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Open(%q) = %v，期望 ErrDecrypt", key, err)
+		}
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	sealed, err := Seal("口令", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, Prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(data []byte) string {
+		return Prefix + base64.StdEncoding.EncodeToString(data)
+	}
+
+	tests := []struct {
+		name   string
+		sealed string
+	}{
+		{"修改盐", encode(flip(data, 0))},
+		{"修改随机数", encode(flip(data, saltSize))},
+		{"修改密文", encode(flip(data, len(data)-20))},
+		{"修改认证标签", encode(flip(data, len(data)-1))},
+		{"截断", encode(data[:len(data)-1])},
+		{"只有盐", encode(data[:saltSize])},
+		{"不是base64", Prefix + "!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open("口令", tt.sealed); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Open = %v，期望 ErrDecrypt", err)
+			}
+		})
+	}
+
+	if _, err := Open("口令", "secret"); err == nil {
+		t.Error("没有前缀的内容应返回错误")
+	}
+}
+
+// flip 返回翻转第i个字节后的副本
+func flip(data []byte, i int) []byte {
+	cp := bytes.Clone(data)
+	cp[i] ^= 0x01
+	return cp
+}
+
+func TestWriteFileDecode(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.json")
+	sealed := filepath.Join(dir, "sealed.json")
+	if err := WriteFile("", plain, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile("口令", sealed, []byte(`{"a":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		key     string
+		wantErr bool
+	}{
+		{"明文没有口令", plain, "", false},
+		{"明文有口令", plain, "口令", false},
+		{"密文", sealed, "口令", false},
+		{"密文没有口令", sealed, "", true},
+		{"密文口令错误", sealed, "错误", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Decode(tt.key, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode = %v，期望错误 %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != `{"a":1}` {
+				t.Errorf("Decode = %q", got)
+			}
+		})
+	}
+
+	if data, _ := os.ReadFile(sealed); bytes.Contains(data, []byte(`"a"`)) {
+		t.Errorf("加密写入的文件中有明文: %s", data)
+	}
+}
+
+func TestSealFileReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(path, []byte("<html>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SealFile("口令", path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("加密后原文件应删除")
+	}
+
+	got, err := ReadFile("口令", path+FileExt)
+	if err != nil || string(got) != "<html>" {
+		t.Errorf("ReadFile = %q, %v", got, err)
+	}
+	if _, err := ReadFile("错误", path+FileExt); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("口令错误时 ReadFile = %v，期望 ErrDecrypt", err)
+	}
+	if _, err := ReadFile("", path+FileExt); err == nil {
+		t.Error("没有口令时读取加密文件应返回错误")
+	}
+}
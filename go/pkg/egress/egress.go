@@ -0,0 +1,259 @@
+// Package egress 出站地址白名单: 只允许访问配置的售票网站、通知渠道等域名，
+// 用于确认程序只和用户配置的网站、渠道通信。HTTP客户端和浏览器请求都经过检查
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+
+	"tickgrabber/pkg/models"
+)
+
+// 白名单模式
+const (
+	// ModeAudit 只记录不在白名单中的地址
+	ModeAudit = "audit"
+	// ModeEnforce 阻止不在白名单中的请求
+	ModeEnforce = "enforce"
+)
+
+// ErrBlocked 请求的地址不在白名单中
+var ErrBlocked = errors.New("地址不在出站白名单中")
+
+// paymentDomains 售票网站跳转的常见支付网关和验证码服务，购票时必须能访问
+var paymentDomains = []string{
+	"inicis.com",
+	"kcp.co.kr",
+	"tosspayments.com",
+	"nicepay.co.kr",
+	"kakaopay.com",
+	"payco.com",
+	"naver.com",
+	"recaptcha.net",
+	"gstatic.com",
+	"hcaptcha.com",
+}
+
+// Default 程序使用的白名单，为nil时不检查
+var Default *Allowlist
+
+// Allowlist 允许访问的域名，域名本身和其子域名都允许
+type Allowlist struct {
+	domains map[string]bool
+	enforce bool
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// New 创建白名单，enforce为false时只记录不阻止
+func New(domains []string, enforce bool) *Allowlist {
+	a := &Allowlist{domains: make(map[string]bool), enforce: enforce, seen: make(map[string]bool)}
+	for _, d := range domains {
+		if d = strings.ToLower(strings.Trim(strings.TrimSpace(d), ".")); d != "" {
+			a.domains[d] = true
+		}
+	}
+	return a
+}
+
+// FromConfig 按配置生成白名单，egress.mode 为空或off时返回nil
+// 包含各站点的地址和 egress 域名、演唱会页面、通知渠道、追踪导出地址和 egress.allow 中的域名，
+// 站点地址按可注册域名放行(tickets.interpark.com 放行 interpark.com 的所有子域名)
+func FromConfig(config *models.Config) (*Allowlist, error) {
+	cfg := config.Egress
+	switch cfg.Mode {
+	case "", "off":
+		return nil, nil
+	case ModeAudit, ModeEnforce:
+	default:
+		return nil, fmt.Errorf("egress.mode %q 无效，应为 off、audit 或 enforce", cfg.Mode)
+	}
+
+	var domains []string
+	site := func(raw string) {
+		if host := hostOf(raw); host != "" {
+			if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+				host = d
+			}
+			domains = append(domains, host)
+		}
+	}
+	exact := func(raw string) {
+		if host := hostOf(raw); host != "" {
+			domains = append(domains, host)
+		}
+	}
+
+	for _, s := range config.Ticketing.Sites {
+		for _, u := range []string{s.URL, s.LoginURL, s.SearchURL, s.BookingsURL, s.SessionCheckURL,
+			s.DirectCheckout.SeatMapURL, s.DirectCheckout.HoldURL, s.MobileAPI.URL} {
+			site(u)
+		}
+		for _, u := range s.PrewarmURLs {
+			site(u)
+		}
+		for _, h := range s.Hosts {
+			site(h)
+		}
+		domains = append(domains, s.Egress...)
+	}
+	for _, c := range config.Concerts {
+		site(c.URL)
+		for _, l := range c.Listings {
+			site(l.URL)
+		}
+		if c.Resale != nil {
+			site(c.Resale.URL)
+		}
+	}
+	domains = append(domains, paymentDomains...)
+
+	n := config.Notification
+	if n.Telegram.Enabled || config.Captcha.Relay == "telegram" {
+		domains = append(domains, "api.telegram.org")
+	}
+	for _, w := range append(append([]models.WebhookTarget{}, n.Discord...), n.Slack...) {
+		if w.Enabled {
+			exact(w.WebhookURL)
+		}
+	}
+	for _, g := range n.Gateways {
+		if g.Enabled {
+			exact(g.URL)
+		}
+	}
+	for _, c := range n.Callbacks {
+		if c.Enabled {
+			exact(c.URL)
+		}
+	}
+	exact(config.Ticketing.CalendarFeed)
+	exact(config.Tracing.Endpoint)
+	domains = append(domains, cfg.Allow...)
+
+	return New(domains, cfg.Mode == ModeEnforce), nil
+}
+
+// Domains 返回白名单中的域名
+func (a *Allowlist) Domains() []string {
+	list := make([]string, 0, len(a.domains))
+	for d := range a.domains {
+		list = append(list, d)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Allowed 判断主机是否在白名单中，本机地址总是允许
+func (a *Allowlist) Allowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	// 不带端口的IPv6地址，如URL中的 [::1]
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || a.domains[host]
+	}
+	for d := host; d != ""; {
+		if a.domains[d] {
+			return true
+		}
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	return false
+}
+
+// Check 检查请求地址，每个不在白名单中的主机只记录一次日志，阻止模式下返回ErrBlocked
+// 非http(s)/ws(s)地址(data:、blob:等)不检查
+func (a *Allowlist) Check(u *url.URL) error {
+	if a == nil || u == nil {
+		return nil
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return nil
+	}
+	if a.Allowed(u.Host) {
+		return nil
+	}
+
+	a.mu.Lock()
+	first := !a.seen[u.Hostname()]
+	a.seen[u.Hostname()] = true
+	a.mu.Unlock()
+	if a.enforce {
+		if first {
+			log.Printf("已阻止访问不在出站白名单中的地址: %s", u.Hostname())
+		}
+		return fmt.Errorf("%w: %s", ErrBlocked, u.Hostname())
+	}
+	if first {
+		log.Printf("出站请求的地址不在白名单中: %s (%s)", u.Hostname(), u.Redacted())
+	}
+	return nil
+}
+
+// Enforce 是否阻止不在白名单中的请求
+func (a *Allowlist) Enforce() bool {
+	return a != nil && a.enforce
+}
+
+// Transport 包装next，请求前检查地址，a为nil时直接返回next
+func (a *Allowlist) Transport(next http.RoundTripper) http.RoundTripper {
+	if a == nil {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return transport{a: a, next: next}
+}
+
+// transport 检查出站地址的RoundTripper
+type transport struct {
+	a    *Allowlist
+	next http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.a.Check(req.URL); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// hostOf 取地址或主机名中的主机，模板等无法解析的地址返回空
+func hostOf(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.Contains(raw, "{{") && !strings.Contains(raw, "://") {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || strings.Contains(u.Host, "{{") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,203 @@
+package egress
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"tickgrabber/pkg/models"
+)
+
+func TestAllowed(t *testing.T) {
+	a := New([]string{"interpark.com", " .Melon.com. ", "hooks.slack.com", "203.0.113.5", ""}, true)
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"interpark.com", true},
+		{"tickets.interpark.com", true},
+		{"a.b.interpark.com", true},
+		{"TICKETS.Interpark.COM.", true},
+		{"tickets.interpark.com:443", true},
+		{"melon.com", true},
+		{"ticket.melon.com", true},
+		{"hooks.slack.com", true},
+		// 只放行配置的子域名，不放行它的上级域名
+		{"slack.com", false},
+		{"api.slack.com", false},
+		// 后缀相同但不是子域名
+		{"evilinterpark.com", false},
+		{"interpark.com.evil.net", false},
+		{"com", false},
+		{"", false},
+		// 本机地址总是允许
+		{"localhost", true},
+		{"localhost:9222", true},
+		{"127.0.0.1", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{"[::1]", true},
+		// 其他IP只有完整列出时才允许
+		{"203.0.113.5", true},
+		{"203.0.113.5:443", true},
+		{"203.0.113.6", false},
+		{"10.0.0.1", false},
+		{"[2001:db8::1]:443", false},
+	}
+	for _, tt := range tests {
+		if got := a.Allowed(tt.host); got != tt.want {
+			t.Errorf("Allowed(%q) = %v，期望 %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	parse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	enforce := New([]string{"interpark.com"}, true)
+	audit := New([]string{"interpark.com"}, false)
+	var none *Allowlist
+
+	for _, raw := range []string{"https://tickets.interpark.com/x", "data:image/png;base64,AA", "blob:https://evil.net/1", "chrome://version"} {
+		if err := enforce.Check(parse(raw)); err != nil {
+			t.Errorf("Check(%s) = %v，期望允许", raw, err)
+		}
+	}
+	for _, raw := range []string{"https://evil.net/", "wss://evil.net/socket", "http://evil.net:8080/"} {
+		if err := enforce.Check(parse(raw)); !errors.Is(err, ErrBlocked) {
+			t.Errorf("阻止模式下 Check(%s) = %v，期望 ErrBlocked", raw, err)
+		}
+		if err := audit.Check(parse(raw)); err != nil {
+			t.Errorf("记录模式下 Check(%s) = %v，期望只记录", raw, err)
+		}
+		if err := none.Check(parse(raw)); err != nil {
+			t.Errorf("没有白名单时 Check(%s) = %v", raw, err)
+		}
+	}
+	if !enforce.Enforce() || audit.Enforce() || none.Enforce() {
+		t.Error("Enforce 与创建时的模式不一致")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	called := 0
+	next := roundTripper(func(req *http.Request) (*http.Response, error) {
+		called++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	rt := New([]string{"interpark.com"}, true).Transport(next)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://evil.net/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrBlocked) || called != 0 {
+		t.Errorf("不在白名单中的请求应在发出前阻止，err=%v called=%d", err, called)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "https://tickets.interpark.com/", nil)
+	if _, err := rt.RoundTrip(req); err != nil || called != 1 {
+		t.Errorf("白名单中的请求应正常发出，err=%v called=%d", err, called)
+	}
+
+	var none *Allowlist
+	if none.Transport(next) == nil {
+		t.Error("没有白名单时应返回原RoundTripper")
+	}
+}
+
+// roundTripper 函数形式的RoundTripper
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFromConfig(t *testing.T) {
+	for _, mode := range []string{"", "off"} {
+		a, err := FromConfig(&models.Config{Egress: models.EgressConfig{Mode: mode}})
+		if err != nil || a != nil {
+			t.Errorf("mode %q 应不启用白名单，得到 %v, %v", mode, a, err)
+		}
+	}
+	if _, err := FromConfig(&models.Config{Egress: models.EgressConfig{Mode: "block"}}); err == nil {
+		t.Error("无效的 mode 应返回错误")
+	}
+
+	config := &models.Config{
+		Egress: models.EgressConfig{Mode: ModeEnforce, Allow: []string{"cdn.example.org"}},
+		Ticketing: models.TicketingConfig{
+			Sites: map[string]models.SiteConfig{
+				"interpark": {
+					URL:       "https://tickets.interpark.com",
+					SearchURL: "https://tickets.interpark.com/search?q={{.Query}}",
+					Hosts:     []string{"ticket.yes24.co.kr"},
+					Egress:    []string{"img.pstatic.net"},
+				},
+			},
+			CalendarFeed: "https://calendar.example.net/feed.ics",
+		},
+		Concerts: []models.Concert{{URL: "https://www.melon.com/concert/1"}},
+		Notification: models.NotificationConfig{
+			Discord: []models.WebhookTarget{
+				{Enabled: true, WebhookURL: "https://discord.com/api/webhooks/1"},
+				{Enabled: false, WebhookURL: "https://disabled.example.com/hook"},
+			},
+		},
+	}
+	a, err := FromConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Enforce() {
+		t.Error("mode enforce 应阻止请求")
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		// 站点地址按可注册域名放行
+		{"tickets.interpark.com", true},
+		{"www.interpark.com", true},
+		{"api.yes24.co.kr", true},
+		{"melon.com", true},
+		// 站点额外的域名、egress.allow 和通知渠道只放行配置的主机
+		{"img.pstatic.net", true},
+		{"pstatic.net", false},
+		{"cdn.example.org", true},
+		{"example.org", false},
+		{"discord.com", true},
+		{"calendar.example.net", true},
+		{"example.net", false},
+		// 未启用的渠道不放行
+		{"disabled.example.com", false},
+		// 支付网关总是放行
+		{"stdpay.inicis.com", true},
+		{"evil.net", false},
+	}
+	for _, tt := range tests {
+		if got := a.Allowed(tt.host); got != tt.want {
+			t.Errorf("Allowed(%q) = %v，期望 %v，白名单 %v", tt.host, got, tt.want, a.Domains())
+		}
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://Tickets.Interpark.com:443/path": "tickets.interpark.com",
+		"tickets.interpark.com":                  "tickets.interpark.com",
+		" ws://127.0.0.1:9222 ":                  "127.0.0.1",
+		"{{.Host}}/path":                         "",
+		"https://{{.Host}}/path":                 "",
+		"":                                       "",
+	} {
+		if got := hostOf(raw); got != want {
+			t.Errorf("hostOf(%q) = %q，期望 %q", raw, got, want)
+		}
+	}
+}
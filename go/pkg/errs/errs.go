@@ -0,0 +1,100 @@
+// Package errs 定义api和browser包共用的错误类型
+//
+// 调用方通过errors.Is判断失败原因(需要重新登录、重试还是放弃)，
+// 通过errors.As取出*Error获取出错的操作和详细信息
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 失败原因
+var (
+	ErrLoginFailed      = errors.New("登录失败")
+	ErrSoldOut          = errors.New("票已售罄")
+	ErrQueueFull        = errors.New("排队人数已满")
+	ErrCaptchaRequired  = errors.New("需要验证码")
+	ErrSessionExpired   = errors.New("会话已过期")
+	ErrElementNotFound  = errors.New("元素不存在")
+	ErrPaymentTimeout   = errors.New("支付超时")
+	ErrUnsupportedSite  = errors.New("不支持的票务网站")
+	ErrPurchaseRejected = errors.New("购买被拒绝")
+	ErrDuplicateOrder   = errors.New("已有相同演出的订单")
+	ErrPresaleRejected  = errors.New("预售会员验证未通过")
+	ErrBudgetExceeded   = errors.New("超出购票限额")
+	ErrAppOnly          = errors.New("只能在APP中购票")
+)
+
+// Error 带失败原因的错误
+type Error struct {
+	// Kind 失败原因，为上面定义的哨兵错误之一
+	Kind error
+	// Op 出错的操作，如 "interpark.login"、"browser.click"
+	Op string
+	// Detail 附加信息，如选择器或网站返回的消息
+	Detail string
+	// Err 底层错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.Op != "" {
+		msg = e.Op + ": " + msg
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is 使errors.Is(err, ErrXxx)按失败原因匹配
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Unwrap 返回底层错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New 创建错误
+func New(kind error, op, detail string) error {
+	return &Error{Kind: kind, Op: op, Detail: detail}
+}
+
+// Newf 创建带格式化详细信息的错误
+func Newf(kind error, op, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Op: op, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Wrap 用失败原因包装底层错误，err为nil时返回nil
+func Wrap(kind error, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+// Retryable 判断错误是否可以直接重试
+func Retryable(err error) bool {
+	return errors.Is(err, ErrQueueFull) || errors.Is(err, ErrElementNotFound)
+}
+
+// NeedsRelogin 判断错误是否需要重新登录
+func NeedsRelogin(err error) bool {
+	return errors.Is(err, ErrSessionExpired)
+}
+
+// Fatal 判断错误是否应放弃当前任务
+func Fatal(err error) bool {
+	return errors.Is(err, ErrSoldOut) || errors.Is(err, ErrLoginFailed) ||
+		errors.Is(err, ErrUnsupportedSite) || errors.Is(err, ErrDuplicateOrder) ||
+		errors.Is(err, ErrPresaleRejected) || errors.Is(err, ErrBudgetExceeded) ||
+		errors.Is(err, ErrAppOnly)
+}
@@ -0,0 +1,82 @@
+package flow
+
+import (
+	"fmt"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/selectors"
+)
+
+// 编译后click、fill和navigate每步最多等待的时间
+const (
+	batchClickTimeout    = 5 * time.Second
+	batchNavigateTimeout = 30 * time.Second
+)
+
+// Compile 把线性流程预先编译为批量操作，执行时不再渲染模板、解析选择器或逐步记录日志
+// 模板在编译时用vars渲染，按文字定位的选择器转换为XPath；批量操作不能根据页面分支，
+// 流程中有 if 或 fail 步骤时返回错误
+func Compile(f *Flow, vars map[string]string) ([]browser.BatchStep, error) {
+	e := &executor{vars: vars}
+	var steps []browser.BatchStep
+	for i, step := range f.Steps {
+		compiled, err := e.compile(step)
+		if err != nil {
+			return nil, fmt.Errorf("流程 %s 步骤 %d (%s): %w", f.Name, i+1, step.label(), err)
+		}
+		steps = append(steps, compiled...)
+	}
+	return steps, nil
+}
+
+// compile 编译单个步骤，fill的每个字段为一步
+func (e *executor) compile(step Step) ([]browser.BatchStep, error) {
+	switch {
+	case step.Navigate != "":
+		url, err := e.render(step.Navigate)
+		if err != nil {
+			return nil, err
+		}
+		return []browser.BatchStep{{Action: browser.BatchNavigate, Selector: url, Timeout: batchNavigateTimeout, Optional: step.Optional}}, nil
+
+	case step.Wait != "":
+		selector, err := e.render(step.Wait)
+		if err != nil {
+			return nil, err
+		}
+		timeout := defaultWait
+		if step.Timeout > 0 {
+			timeout = seconds(step.Timeout)
+		}
+		return []browser.BatchStep{{Action: browser.BatchWait, Selector: selectors.XPath(selector), Timeout: timeout, Optional: step.Optional}}, nil
+
+	case len(step.Fill) > 0:
+		var steps []browser.BatchStep
+		for selector, value := range step.Fill {
+			s, err := e.render(selector)
+			if err != nil {
+				return nil, err
+			}
+			v, err := e.render(value)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, browser.BatchStep{Action: browser.BatchFill, Selector: selectors.XPath(s), Value: v, Timeout: batchClickTimeout, Optional: step.Optional})
+		}
+		return steps, nil
+
+	case step.Click != "":
+		selector, err := e.render(step.Click)
+		if err != nil {
+			return nil, err
+		}
+		return []browser.BatchStep{{Action: browser.BatchClick, Selector: selectors.XPath(selector), Timeout: batchClickTimeout, Optional: step.Optional}}, nil
+
+	case step.Sleep > 0:
+		return []browser.BatchStep{{Action: browser.BatchSleep, Timeout: seconds(step.Sleep)}}, nil
+
+	default:
+		return nil, fmt.Errorf("快速流程只支持 navigate、wait、fill、click 和 sleep")
+	}
+}
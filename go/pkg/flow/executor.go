@@ -0,0 +1,173 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"text/template"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/selectors"
+)
+
+// defaultWait wait步骤默认的等待时间
+const defaultWait = 10 * time.Second
+
+// pollInterval 等待元素时的检查间隔
+const pollInterval = 200 * time.Millisecond
+
+// Run 在浏览器中按顺序执行流程，vars为模板变量
+func Run(ctx context.Context, b browser.Driver, f *Flow, vars map[string]string) error {
+	e := &executor{browser: b, vars: vars}
+	if err := e.run(ctx, f.Steps, ""); err != nil {
+		return fmt.Errorf("流程 %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// executor 流程执行器
+type executor struct {
+	browser browser.Driver
+	vars    map[string]string
+}
+
+// run 执行一组步骤
+func (e *executor) run(ctx context.Context, steps []Step, prefix string) error {
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		label := fmt.Sprintf("%s%d", prefix, i+1)
+		err := e.step(ctx, step, label)
+		if err == nil {
+			continue
+		}
+		if step.Optional {
+			log.Printf("可选步骤 %s (%s) 失败，继续执行: %v", label, step.label(), err)
+			continue
+		}
+		return fmt.Errorf("步骤 %s (%s): %w", label, step.label(), err)
+	}
+	return nil
+}
+
+// step 执行单个步骤
+func (e *executor) step(ctx context.Context, step Step, label string) error {
+	switch {
+	case step.Navigate != "":
+		url, err := e.render(step.Navigate)
+		if err != nil {
+			return err
+		}
+		return e.browser.Navigate(ctx, url)
+
+	case step.Wait != "":
+		selector, err := e.render(step.Wait)
+		if err != nil {
+			return err
+		}
+		timeout := defaultWait
+		if step.Timeout > 0 {
+			timeout = seconds(step.Timeout)
+		}
+		if !e.waitFor(ctx, selector, timeout) {
+			return errs.New(errs.ErrElementNotFound, "flow.wait", selector)
+		}
+		return nil
+
+	case len(step.Fill) > 0:
+		fields := make(map[string]string, len(step.Fill))
+		for selector, value := range step.Fill {
+			s, err := e.render(selector)
+			if err != nil {
+				return err
+			}
+			v, err := e.render(value)
+			if err != nil {
+				return err
+			}
+			fields[s] = v
+		}
+		return e.browser.FillForm(ctx, fields)
+
+	case step.Click != "":
+		selector, err := e.render(step.Click)
+		if err != nil {
+			return err
+		}
+		clicked, err := selectors.Click(ctx, e.browser, selector)
+		if err != nil {
+			return err
+		}
+		if !clicked {
+			return errs.New(errs.ErrElementNotFound, "flow.click", selector)
+		}
+		return nil
+
+	case step.Sleep > 0:
+		select {
+		case <-time.After(seconds(step.Sleep)):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	case step.Fail != "":
+		message, err := e.render(step.Fail)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("流程终止: %s", message)
+
+	case step.If != "":
+		selector, err := e.render(step.If)
+		if err != nil {
+			return err
+		}
+		if e.waitFor(ctx, selector, seconds(step.Timeout)) {
+			return e.run(ctx, step.Then, label+".then.")
+		}
+		return e.run(ctx, step.Else, label+".else.")
+	}
+	return nil
+}
+
+// waitFor 等待元素出现，timeout为0时只检查一次
+func (e *executor) waitFor(ctx context.Context, selector string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, ok := selectors.Locate(ctx, e.browser, selector); ok {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// render 用变量渲染参数，引用未定义的变量时报错
+func (e *executor) render(text string) (string, error) {
+	tmpl, err := template.New("step").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("模板错误 %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e.vars); err != nil {
+		return "", fmt.Errorf("渲染 %q 失败: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// seconds 将秒数转换为时长
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,131 @@
+// Package flow 声明式的抢票流程
+//
+// 流程文件为YAML(也可以是JSON)，由一组按顺序执行的步骤组成，例如:
+//
+//	name: interpark-login
+//	steps:
+//	  - navigate: "{{.login_url}}"
+//	  - wait: "#userId"
+//	    timeout: 10
+//	  - fill:
+//	      userId: "{{.username}}"
+//	      userPw: "{{.password}}"
+//	  - click: "text=로그인"
+//	  - if: ".captcha-box"
+//	    then:
+//	      - fail: 需要验证码
+//
+// 网站改版或接入新网站时修改流程文件即可，不需要重新编译。
+// 选择器支持 "text=" 和 "text~=" 按文字定位，参数值可以使用 text/template 引用变量
+package flow
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flow 流程定义
+type Flow struct {
+	Name  string `yaml:"name"`
+	Steps []Step `yaml:"steps"`
+}
+
+// Step 流程中的一个步骤，每个步骤只能有一个动作
+type Step struct {
+	// Name 步骤名称，只用于日志和错误信息
+	Name string `yaml:"name"`
+
+	Navigate string            `yaml:"navigate"`
+	Wait     string            `yaml:"wait"`
+	Fill     map[string]string `yaml:"fill"`
+	Click    string            `yaml:"click"`
+	Sleep    float64           `yaml:"sleep"`
+	Fail     string            `yaml:"fail"`
+
+	// If 元素存在时执行Then，否则执行Else；设置Timeout时等待元素出现
+	If   string `yaml:"if"`
+	Then []Step `yaml:"then"`
+	Else []Step `yaml:"else"`
+
+	// Timeout wait和if等待元素的秒数，wait默认10秒，if默认不等待
+	Timeout float64 `yaml:"timeout"`
+	// Optional 为true时步骤失败只记录日志，继续执行后续步骤
+	Optional bool `yaml:"optional"`
+}
+
+// Load 读取并检查流程文件
+func Load(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f Flow
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("流程文件 %s 格式错误: %w", path, err)
+	}
+	if f.Name == "" {
+		f.Name = path
+	}
+	if err := validate(f.Steps, ""); err != nil {
+		return nil, fmt.Errorf("流程 %s: %w", f.Name, err)
+	}
+	return &f, nil
+}
+
+// validate 检查每个步骤有且只有一个动作
+func validate(steps []Step, prefix string) error {
+	for i, step := range steps {
+		label := fmt.Sprintf("%s%d", prefix, i+1)
+		if n := len(step.actions()); n != 1 {
+			return fmt.Errorf("步骤 %s 应有一个动作，实际有 %d 个", label, n)
+		}
+		if err := validate(step.Then, label+".then."); err != nil {
+			return err
+		}
+		if err := validate(step.Else, label+".else."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// actions 返回步骤中设置的动作名称
+func (s Step) actions() []string {
+	var names []string
+	if s.Navigate != "" {
+		names = append(names, "navigate")
+	}
+	if s.Wait != "" {
+		names = append(names, "wait")
+	}
+	if len(s.Fill) > 0 {
+		names = append(names, "fill")
+	}
+	if s.Click != "" {
+		names = append(names, "click")
+	}
+	if s.Sleep > 0 {
+		names = append(names, "sleep")
+	}
+	if s.Fail != "" {
+		names = append(names, "fail")
+	}
+	if s.If != "" {
+		names = append(names, "if")
+	}
+	return names
+}
+
+// label 步骤在日志中的名称
+func (s Step) label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if actions := s.actions(); len(actions) > 0 {
+		return actions[0]
+	}
+	return "?"
+}
@@ -0,0 +1,147 @@
+// Package gate 识别选座前后插入的条款同意、年龄确认和公告页面并自动通过
+//
+// 每条规则由检测、勾选和提交三组选择器组成：检测到的规则先勾选页面上存在的复选框，再点击第一个存在的提交按钮，
+// 直到检测选择器消失。一个页面通过后可能接着出现另一个(如公告后是条款)，所以反复检查直到没有规则匹配。
+// 需要本人认证等无法自动完成的页面标记为Manual，检测到时返回ErrManual由调用方通知用户
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// ErrManual 页面需要手动完成
+var ErrManual = errors.New("需要手动完成的页面")
+
+// maxRounds 一次最多连续通过的页面数量，防止提交后页面没有变化时无限循环
+const maxRounds = 5
+
+// dismissTimeout 提交后等待页面消失的时间
+const dismissTimeout = 3 * time.Second
+
+// Defaults 所有站点共用的规则，站点配置中同名的规则替换默认规则
+var Defaults = []models.GateRule{
+	{
+		Name:   "terms",
+		Detect: []string{"[data-gate='terms']", ".agree-all", "#agreeAllLayer"},
+		Check: []string{
+			"input[name='agreeAll']:not(:checked)",
+			".agree-all input[type='checkbox']:not(:checked)",
+			"[data-gate='terms'] input[type='checkbox']:not(:checked)",
+		},
+		Submit: []string{"[data-gate='terms'] button[type='submit']", "text=동의하고 계속", "text=다음"},
+	},
+	{
+		Name:   "age",
+		Detect: []string{"[data-gate='age']", ".age-confirm", "#ageCheckLayer"},
+		Check:  []string{"input[name='ageConfirm']:not(:checked)", "[data-gate='age'] input[type='checkbox']:not(:checked)"},
+		Submit: []string{"[data-gate='age'] button", ".age-confirm button", "text=확인"},
+	},
+	{
+		Name:   "notice",
+		Detect: []string{"[data-gate='notice']", ".notice-layer", "#noticePopup"},
+		Check:  []string{"[data-gate='notice'] input[type='checkbox']:not(:checked)"},
+		Submit: []string{"[data-gate='notice'] button", "text=확인했습니다", ".notice-layer .btn-close", "#noticePopup .close"},
+	},
+	{
+		Name:   "identity",
+		Detect: []string{"[data-gate='identity']", "#certLayer", ".cert-layer"},
+		Manual: true,
+	},
+}
+
+// Rules 返回站点使用的规则，站点规则在前，同名的默认规则被替换
+func Rules(site []models.GateRule) []models.GateRule {
+	rules := append([]models.GateRule(nil), site...)
+	for _, def := range Defaults {
+		replaced := false
+		for _, r := range site {
+			if r.Name == def.Name {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, def)
+		}
+	}
+	return rules
+}
+
+// Pass 依次通过页面上出现的页面，返回通过的规则名
+// 提交后页面仍未消失时返回错误，遇到Manual规则时返回ErrManual
+func Pass(ctx context.Context, b browser.Driver, rules []models.GateRule) ([]string, error) {
+	var passed []string
+	for round := 0; round < maxRounds; round++ {
+		rule, ok := detect(ctx, b, rules)
+		if !ok {
+			return passed, nil
+		}
+		if rule.Manual {
+			return passed, fmt.Errorf("%w: %s", ErrManual, rule.Name)
+		}
+
+		for _, selector := range rule.Check {
+			if found, ok := selectors.Locate(ctx, b, selector); ok {
+				selectors.Click(ctx, b, found)
+			}
+		}
+		submitted := false
+		for _, selector := range rule.Submit {
+			if clicked, err := selectors.Click(ctx, b, selector); err == nil && clicked {
+				submitted = true
+				break
+			}
+		}
+		if !submitted {
+			return passed, fmt.Errorf("%s: 找不到提交按钮", rule.Name)
+		}
+		if err := waitDismissed(ctx, b, rule); err != nil {
+			return passed, err
+		}
+		passed = append(passed, rule.Name)
+	}
+	return passed, fmt.Errorf("连续出现 %d 个页面，停止自动通过", maxRounds)
+}
+
+// detect 返回第一条在页面上出现的规则
+func detect(ctx context.Context, b browser.Driver, rules []models.GateRule) (models.GateRule, bool) {
+	for _, rule := range rules {
+		if present(ctx, b, rule) {
+			return rule, true
+		}
+	}
+	return models.GateRule{}, false
+}
+
+// present 判断规则的页面是否出现
+func present(ctx context.Context, b browser.Driver, rule models.GateRule) bool {
+	for _, selector := range rule.Detect {
+		if _, ok := selectors.Locate(ctx, b, selector); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// waitDismissed 等待提交后页面消失
+func waitDismissed(ctx context.Context, b browser.Driver, rule models.GateRule) error {
+	deadline := time.Now().Add(dismissTimeout)
+	for present(ctx, b, rule) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s: 提交后页面仍未关闭", rule.Name)
+		}
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Card 银行卡信息，只在内存中以明文存在
+type Card struct {
+	Number string `json:"number"`
+	// ExpiryMonth 两位月份，ExpiryYear 四位年份
+	ExpiryMonth string `json:"expiry_month"`
+	ExpiryYear  string `json:"expiry_year"`
+}
+
+// ParseCard 解析卡号和 "MM/YY" 或 "MM/YYYY" 格式的有效期，卡号中的空格和连字符被忽略
+func ParseCard(number, expiry string) (*Card, error) {
+	number = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+	if len(number) < 13 || len(number) > 19 || strings.Trim(number, "0123456789") != "" {
+		return nil, fmt.Errorf("卡号格式不正确")
+	}
+
+	month, year, ok := strings.Cut(strings.TrimSpace(expiry), "/")
+	if !ok || len(month) == 0 || len(month) > 2 || (len(year) != 2 && len(year) != 4) ||
+		strings.Trim(month+year, "0123456789") != "" {
+		return nil, fmt.Errorf("有效期格式应为 MM/YY")
+	}
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if month < "01" || month > "12" {
+		return nil, fmt.Errorf("有效期月份不正确")
+	}
+	if len(year) == 2 {
+		year = "20" + year
+	}
+	return &Card{Number: number, ExpiryMonth: month, ExpiryYear: year}, nil
+}
+
+// cardScript 按字段名、autocomplete和占位文字找到卡号、有效期和分期字段并填写，不点击付款按钮
+// 卡号分成多个输入框时平均拆分；页面上没有卡号字段(网关使用自己的应用或安全键盘)时返回false
+const cardScript = `(() => {
+	const card = %s;
+	const installment = %d;
+	const visible = e => { const r = e.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+	const fields = Array.from(document.querySelectorAll("input, select")).filter(visible);
+	const key = e => [e.name, e.id, e.getAttribute("autocomplete"), e.getAttribute("placeholder"), e.getAttribute("aria-label")].join(" ").toLowerCase();
+	const find = patterns => fields.filter(e => patterns.some(p => p.test(key(e))));
+	const set = (e, v) => {
+		const proto = e.tagName === "SELECT" ? HTMLSelectElement.prototype : HTMLInputElement.prototype;
+		Object.getOwnPropertyDescriptor(proto, "value").set.call(e, v);
+		e.dispatchEvent(new Event("input", { bubbles: true }));
+		e.dispatchEvent(new Event("change", { bubbles: true }));
+	};
+	const choose = (e, values) => {
+		if (e.tagName !== "SELECT") { set(e, values[0]); return; }
+		const option = Array.from(e.options).find(o => values.includes(o.value) || values.includes(o.text.trim()));
+		if (option) set(e, option.value);
+	};
+
+	const numbers = find([/cc-number/, /card_?no|cardnum|card_?number|카드번호/]);
+	if (numbers.length === 0) return false;
+	const size = Math.ceil(card.number.length / numbers.length);
+	numbers.forEach((e, i) => set(e, card.number.substr(i * size, size)));
+
+	const mm = card.expiry_month, yyyy = card.expiry_year, yy = yyyy.slice(2);
+	const month = find([/cc-exp-month/, /exp.?mon|month|\bmm\b|유효기간\s*\(?월/])[0];
+	const year = find([/cc-exp-year/, /exp.?year|year|\byy(yy)?\b|유효기간\s*\(?년/])[0];
+	if (month) choose(month, [mm, String(Number(mm))]);
+	if (year) choose(year, year.maxLength === 4 ? [yyyy, yy] : [yy, yyyy]);
+	if (!month || !year) {
+		const both = find([/cc-exp\b/, /expiry|exp_?date|유효기간/]).find(e => e !== month && e !== year);
+		if (both) set(both, both.maxLength === 4 ? mm + yy : mm + "/" + yy);
+	}
+
+	const quota = find([/quota|installment|할부/])[0];
+	if (quota) {
+		const n = String(installment), padded = n.padStart(2, "0");
+		choose(quota, installment === 0 ? ["0", "00", "일시불"] : [n, padded, n + "개월"]);
+	}
+	return true;
+})()`
+
+// CardScript 返回填写银行卡的脚本，installment为分期月数，0为一次付清
+func CardScript(card *Card, installment int) string {
+	data, _ := json.Marshal(card)
+	return fmt.Sprintf(cardScript, data, installment)
+}
@@ -0,0 +1,100 @@
+// Package gateway 识别结账时跳转的外部支付网关(PG)页面
+// 韩国票务网站付款时通常打开KG이니시스、토스페이먼츠、카카오페이等支付网关的弹出窗口或内嵌框架，
+// 抢票器在窗口中只做发放虚拟账户这类不涉及卡号和密码的操作，其余由用户完成
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Gateway 支付网关
+type Gateway struct {
+	Name  string
+	Hosts []string
+}
+
+// known 已知支付网关的域名，子域名同样匹配
+var known = []Gateway{
+	{Name: "KG이니시스", Hosts: []string{"inicis.com"}},
+	{Name: "토스페이먼츠", Hosts: []string{"tosspayments.com", "toss.im"}},
+	{Name: "카카오페이", Hosts: []string{"kakaopay.com"}},
+	{Name: "네이버페이", Hosts: []string{"pay.naver.com"}},
+	{Name: "NHN KCP", Hosts: []string{"kcp.co.kr"}},
+	{Name: "나이스페이", Hosts: []string{"nicepay.co.kr"}},
+	{Name: "페이코", Hosts: []string{"payco.com"}},
+}
+
+// Detect 判断地址是否为支付网关的页面，返回网关名称
+func Detect(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, g := range known {
+		for _, h := range g.Hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return g.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// FramesScript 返回页面中所有内嵌框架的地址，用于识别以页内弹层方式打开的支付网关
+const FramesScript = `Array.from(document.querySelectorAll("iframe")).map(f => f.src).filter(Boolean)`
+
+// 虚拟账户发放的步骤，VirtualAccountScript 每次执行完成一步并返回步骤名称，没有可做的操作时返回空字符串
+const (
+	StepMethod = "method"
+	StepBank   = "bank"
+	StepAgree  = "agree"
+	StepSubmit = "submit"
+)
+
+// virtualAccountScript 在支付窗口中选择虚拟账户、银行，勾选条款并申请发放
+// 已点击的元素打上标记，窗口跳转到下一页前重复执行不会重复点击
+const virtualAccountScript = `(() => {
+	const bank = %s;
+	const visible = e => { const r = e.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+	const norm = s => (s || "").replace(/\s+/g, "");
+	const clickable = "button, a, label, li, input[type='button'], input[type='submit'], [role='button'], [role='tab'], [role='radio']";
+	const byText = words => Array.from(document.querySelectorAll(clickable)).filter(visible)
+		.find(e => !e.dataset.tgClicked && words.some(w => norm(e.innerText || e.value).includes(w)));
+	const click = e => { e.dataset.tgClicked = "1"; e.click(); };
+
+	const method = byText(["가상계좌", "무통장입금"]);
+	if (method) { click(method); return "method"; }
+
+	if (bank) {
+		for (const select of document.querySelectorAll("select")) {
+			const option = Array.from(select.options).find(o => norm(o.text).includes(norm(bank)));
+			if (option && select.value !== option.value) {
+				select.value = option.value;
+				select.dispatchEvent(new Event("change", { bubbles: true }));
+				return "bank";
+			}
+		}
+		const button = byText([norm(bank)]);
+		if (button) { click(button); return "bank"; }
+	}
+
+	let agreed = false;
+	for (const box of document.querySelectorAll("input[type='checkbox']")) {
+		if (!box.checked && !box.disabled && visible(box.closest("label") || box)) { box.click(); agreed = true; }
+	}
+	if (agreed) return "agree";
+
+	const submit = byText(["발급", "다음", "결제하기", "확인"]);
+	if (submit) { click(submit); return "submit"; }
+	return "";
+})()`
+
+// VirtualAccountScript 返回发放虚拟账户的脚本，bank为空时使用窗口默认选择的银行
+func VirtualAccountScript(bank string) string {
+	quoted, _ := json.Marshal(bank)
+	return fmt.Sprintf(virtualAccountScript, quoted)
+}
@@ -0,0 +1,76 @@
+package grabber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"tickgrabber/pkg/history"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// gradesScript 统计页面上各座位等级(data-seat-type 或 data-grade 属性)的可选座位数
+const gradesScript = `(() => {
+	const counts = {};
+	document.querySelectorAll(%s).forEach(e => {
+		const grade = e.getAttribute("data-seat-type") || e.getAttribute("data-grade");
+		if (grade) counts[grade] = (counts[grade] || 0) + 1;
+	});
+	return counts;
+})()`
+
+// recordAvailability 记录本次检查的余票状态，有票时同时记录各座位等级的数量
+func (tg *TicketGrabber) recordAvailability(ctx context.Context, concert *models.Concert, available bool) {
+	if tg.history == nil {
+		return
+	}
+
+	o := history.Observation{
+		Time:      tg.clock.Now(),
+		ConcertID: concert.ID,
+		Site:      tg.site,
+		Available: available,
+	}
+	if tg.round != nil {
+		o.Round = tg.round.String()
+	}
+	if available {
+		o.Grades = tg.pageGrades(ctx)
+	}
+	if err := tg.history.Record(o); err != nil {
+		log.Printf("记录余票状态失败: %v", err)
+	}
+}
+
+// pageGrades 读取页面上各座位等级的可选座位数，页面上没有座位图时返回nil
+func (tg *TicketGrabber) pageGrades(ctx context.Context) map[string]int {
+	var css []string
+	for _, selector := range tg.selectors.Get(tg.site, selectors.SeatAvailable) {
+		if _, _, ok := selectors.Text(selector); !ok {
+			css = append(css, selector)
+		}
+	}
+	if len(css) == 0 {
+		return nil
+	}
+
+	query, _ := json.Marshal(strings.Join(css, ", "))
+	result, err := tg.browser.ExecuteScript(ctx, fmt.Sprintf(gradesScript, query))
+	if err != nil {
+		return nil
+	}
+	counts, _ := result.(map[string]interface{})
+	grades := make(map[string]int)
+	for grade, n := range counts {
+		if f, ok := n.(float64); ok && f > 0 {
+			grades[grade] = int(f)
+		}
+	}
+	if len(grades) == 0 {
+		return nil
+	}
+	return grades
+}
@@ -0,0 +1,29 @@
+package grabber
+
+import (
+	"context"
+	"log"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/selectors"
+)
+
+// checkAppOnly 演出只在APP中开放预约时通知并返回ErrAppOnly，网页上永远不会出现购买按钮，继续监控没有意义
+func (tg *TicketGrabber) checkAppOnly(ctx context.Context, concert *models.Concert) error {
+	selector, found := tg.find(ctx, tg.selectors.Get(tg.site, selectors.SaleAppOnly))
+	if !found {
+		return nil
+	}
+
+	log.Printf("演出只能在APP中预约(%s)，停止抢票", selector)
+	tg.notify(ctx, notify.Event{
+		Type:    notify.EventActionRequired,
+		Title:   "只能在APP中购票",
+		Message: "该演出只在APP中开放预约，请在手机APP中购买",
+		Concert: concert.Name,
+		URL:     concert.URL,
+	})
+	return errs.New(errs.ErrAppOnly, tg.site+".app_only", selector)
+}
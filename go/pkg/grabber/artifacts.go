@@ -0,0 +1,187 @@
+package grabber
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/crypt"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/redact"
+)
+
+// ArtifactKeyEnv 加密任务产物的口令所在的环境变量，口令不写入配置文件
+const ArtifactKeyEnv = "TICKGRABBER_ARTIFACT_KEY"
+
+// ArtifactKey 配置了 encrypt_artifacts 时返回加密任务产物、订单记录、收据和Cookie文件的口令，否则返回空
+func ArtifactKey(config *models.Config) string {
+	if !config.Ticketing.EncryptArtifacts {
+		return ""
+	}
+	return os.Getenv(ArtifactKeyEnv)
+}
+
+// artifacts 一次抢票任务的产物目录: 任务日志、截图、失败时的页面源码、时间线和结果，
+// bundle 命令把它打包成一个zip文件，方便排查问题
+type artifacts struct {
+	dir string
+	// key 加密产物的口令，不加密时为空
+	key string
+	// result 本任务的结果，和前台运行的 --result-file 分开记录
+	result *Result
+	// log 任务日志文件，没有单独记录日志时为nil
+	log *os.File
+	// logBuf 加密时任务日志先保存在内存中，结束时加密写入 task.log.enc，磁盘上不出现明文
+	logBuf *bytes.Buffer
+	// restore 恢复任务开始前的日志输出
+	restore func()
+}
+
+// openArtifacts 创建 <artifact_dir>/<演唱会ID>_<时间>/ 目录，没有配置artifact_dir时返回nil
+// ownLog 为true时(前台只有一个任务)同时把日志写入目录中的 task.log
+func (tg *TicketGrabber) openArtifacts(concert *models.Concert) *artifacts {
+	root := tg.config.Ticketing.ArtifactDir
+	if root == "" {
+		return nil
+	}
+	// 产物中有Cookie和个人信息，拿不到口令时不保存
+	key := ArtifactKey(tg.config)
+	if tg.config.Ticketing.EncryptArtifacts && key == "" {
+		log.Printf("配置了加密任务产物但环境变量 %s 中没有口令，不保存任务产物", ArtifactKeyEnv)
+		return nil
+	}
+	dir := filepath.Join(root, fmt.Sprintf("%s_%s", concert.ID, time.Now().Format("20060102_150405")))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("创建任务产物目录失败: %v", err)
+		return nil
+	}
+
+	a := &artifacts{dir: dir, key: key, result: NewResult(concert)}
+	if tg.ownLog && key != "" {
+		prev := log.Writer()
+		a.logBuf = &bytes.Buffer{}
+		log.SetOutput(io.MultiWriter(prev, redact.Default.Writer(a.logBuf)))
+		a.restore = func() { log.SetOutput(prev) }
+	} else if tg.ownLog {
+		f, err := os.OpenFile(filepath.Join(dir, "task.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("创建任务日志失败: %v", err)
+		} else {
+			prev := log.Writer()
+			log.SetOutput(io.MultiWriter(prev, redact.Default.Writer(f)))
+			a.log = f
+			a.restore = func() { log.SetOutput(prev) }
+		}
+	}
+	log.Printf("任务产物保存到 %s", dir)
+	return a
+}
+
+// path 返回产物目录中的文件路径，a为nil时使用fallback目录
+func (a *artifacts) path(fallback, name string) string {
+	if a == nil {
+		return filepath.Join(fallback, name)
+	}
+	return filepath.Join(a.dir, name)
+}
+
+// sealFile 配置了加密时立即加密刚写入的产物文件，a为nil或不加密时不做处理
+func (a *artifacts) sealFile(path string) {
+	if a == nil || a.key == "" {
+		return
+	}
+	if err := crypt.SealFile(a.key, path); err != nil {
+		log.Printf("加密 %s 失败: %v", filepath.Base(path), err)
+	}
+}
+
+// recordPurchase 记录购票成功
+func (a *artifacts) recordPurchase(site string, o *order.Order) {
+	if a == nil {
+		return
+	}
+	a.result.recordPurchase(site, o)
+}
+
+// closeArtifacts 任务结束时保存时间线和结果，失败时保存页面截图和源码，然后关闭任务日志，
+// 配置了加密时每个文件写入后立即加密，最后再加密目录中其余的文件
+func (tg *TicketGrabber) closeArtifacts(ctx context.Context, err error) {
+	a := tg.artifacts
+	if a == nil {
+		return
+	}
+	// 任务被取消时ctx已失效，截图使用单独的超时
+	if err != nil && tg.browser != nil {
+		capture, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		if serr := tg.browser.Screenshot(capture, a.path("", "failure.png")); serr != nil {
+			log.Printf("保存失败截图失败: %v", serr)
+		} else {
+			a.sealFile(a.path("", "failure.png"))
+		}
+		if source, serr := tg.browser.PageSource(capture); serr == nil {
+			if werr := os.WriteFile(a.path("", "page.html"), []byte(redact.String(source)), 0644); werr != nil {
+				log.Printf("保存页面源码失败: %v", werr)
+			} else {
+				a.sealFile(a.path("", "page.html"))
+			}
+		}
+		cancel()
+	}
+	if _, werr := tg.timeline.Write(a.dir); werr != nil {
+		log.Printf("保存时间线报告失败: %v", werr)
+	}
+	if a.result.Site == "" {
+		a.result.Site = tg.site
+	}
+	a.result.Finish(err)
+	if werr := a.result.Write(a.path("", "result.json")); werr != nil {
+		log.Printf("保存任务结果失败: %v", werr)
+	}
+
+	if a.log != nil {
+		a.restore()
+		a.log.Close()
+	}
+	if a.logBuf != nil {
+		a.restore()
+		a.writeSealedLog()
+	}
+	if a.key != "" {
+		a.seal()
+	}
+}
+
+// seal 加密产物目录中还没有加密的文件
+func (a *artifacts) seal() {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		log.Printf("加密任务产物失败: %v", err)
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), crypt.FileExt) {
+			continue
+		}
+		if err := crypt.SealFile(a.key, filepath.Join(a.dir, e.Name())); err != nil {
+			log.Printf("加密 %s 失败: %v", e.Name(), err)
+		}
+	}
+}
+
+// writeSealedLog 加密内存中的任务日志并写入 task.log.enc
+func (a *artifacts) writeSealedLog() {
+	sealed, err := crypt.Seal(a.key, a.logBuf.Bytes())
+	if err == nil {
+		err = os.WriteFile(a.path("", "task.log"+crypt.FileExt), []byte(sealed), 0600)
+	}
+	if err != nil {
+		log.Printf("保存加密的任务日志失败: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package grabber
+
+import (
+	"context"
+	"log"
+
+	"tickgrabber/pkg/browser"
+)
+
+// blockRequests 监控阶段按站点配置阻止图片、音视频和第三方脚本等资源，on为false时解除，
+// 选座和支付页面需要完整加载，购买前必须解除
+func (tg *TicketGrabber) blockRequests(ctx context.Context, on bool) {
+	b := tg.config.Ticketing.Sites[tg.site].Blocking
+	patterns := browser.BlockPatterns(b.Images, b.Media, b.Fonts, b.ThirdParty, b.Patterns)
+	if len(patterns) == 0 || on == tg.blocking {
+		return
+	}
+	if !on {
+		patterns = nil
+	}
+	if err := tg.browser.BlockURLs(ctx, patterns); err != nil {
+		log.Printf("设置请求阻止失败: %v", err)
+		return
+	}
+	tg.blocking = on
+	if on {
+		log.Printf("监控期间阻止 %d 类地址的请求", len(patterns))
+	} else {
+		log.Println("已解除请求阻止")
+	}
+}
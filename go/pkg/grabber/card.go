@@ -0,0 +1,43 @@
+package grabber
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"tickgrabber/pkg/crypt"
+	"tickgrabber/pkg/gateway"
+	"tickgrabber/pkg/redact"
+)
+
+// CardKeyEnv 银行卡密文的解密口令所在的环境变量，口令不写入配置文件
+const CardKeyEnv = "TICKGRABBER_CARD_KEY"
+
+// loadCard 按配置解密银行卡，未配置或未接受风险时不加载
+func (tg *TicketGrabber) loadCard() error {
+	cfg := tg.config.Ticketing.Payment.Card
+	if tg.card != nil || cfg.Encrypted == "" {
+		return nil
+	}
+	if !cfg.IAcceptRisk {
+		return fmt.Errorf("配置了银行卡但没有设置 i_accept_risk")
+	}
+	passphrase := os.Getenv(CardKeyEnv)
+	if passphrase == "" {
+		return fmt.Errorf("环境变量 %s 中没有解密口令", CardKeyEnv)
+	}
+
+	data, err := crypt.Open(passphrase, strings.TrimSpace(cfg.Encrypted))
+	if err != nil {
+		return err
+	}
+	var card gateway.Card
+	if err := json.Unmarshal(data, &card); err != nil {
+		return fmt.Errorf("银行卡密文内容无效")
+	}
+	// 填写脚本会出现在录制文件中，卡号先加入脱敏列表
+	redact.Default.AddSecrets(card.Number)
+	tg.card = &card
+	return nil
+}
@@ -0,0 +1,78 @@
+package grabber
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+
+	"tickgrabber/pkg/crypt"
+	"tickgrabber/pkg/session"
+)
+
+// WriteCookieFile 写入Cookie文件，文件包含登录会话，只允许本人读写；key 非空时加密内容
+func WriteCookieFile(path, key string, encode func(buf *bytes.Buffer) error) error {
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return crypt.WriteFile(key, path, buf.Bytes(), 0600)
+}
+
+// ReadCookieFile 读取Cookie文件，加密的文件用key解密
+func ReadCookieFile(path, key string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return crypt.Decode(key, data)
+}
+
+// loadCookieFile 把Cookie文件中的会话导入浏览器和API客户端，成功时第一次登录直接使用该会话
+func (tg *TicketGrabber) loadCookieFile(ctx context.Context) {
+	path := tg.config.User.CookieFile
+	if path == "" {
+		return
+	}
+	data, err := ReadCookieFile(path, ArtifactKey(tg.config))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("读取Cookie文件失败: %v", err)
+		}
+		return
+	}
+	cookies, err := session.ParseCookies(data)
+	if err != nil {
+		log.Printf("解析Cookie文件失败: %v", err)
+		return
+	}
+
+	if err := tg.browser.ImportCookies(ctx, cookies); err != nil {
+		log.Printf("导入Cookie到浏览器失败: %v", err)
+		return
+	}
+	tg.apiClient.ImportCookies(cookies)
+	tg.cookieSession = true
+	log.Printf("已从 %s 导入 %d 个Cookie", path, len(cookies))
+}
+
+// saveCookieFile 登录后把浏览器的会话写回Cookie文件，供下次启动和export-cookies使用
+func (tg *TicketGrabber) saveCookieFile(ctx context.Context) {
+	path := tg.config.User.CookieFile
+	if path == "" {
+		return
+	}
+	cookies, err := tg.browser.Cookies(ctx)
+	if err != nil || len(cookies) == 0 {
+		return
+	}
+	if err := WriteCookieFile(path, ArtifactKey(tg.config), func(buf *bytes.Buffer) error {
+		return session.WriteCookies(buf, cookies, session.FormatJSON)
+	}); err != nil {
+		log.Printf("保存Cookie文件失败: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// deliveryLabels 领取方式在页面上的默认文字
+var deliveryLabels = map[string]string{
+	"mobile": "모바일티켓",
+	"pickup": "현장수령",
+	"mail":   "배송",
+}
+
+// chooseDelivery 结算页有领取方式选项时按配置选择，配送时填写收件信息
+// 演唱会单独配置的领取方式优先于全局配置
+func (tg *TicketGrabber) chooseDelivery(ctx context.Context, concert *models.Concert) error {
+	if handled, err := tg.runStage(ctx, tg.site, StageDelivery); handled {
+		return err
+	}
+	if _, ok := tg.find(ctx, tg.selectors.Get(tg.site, selectors.DeliverySection)); !ok {
+		return nil
+	}
+
+	cfg := tg.config.Tickets.Delivery
+	if concert.Delivery != nil {
+		cfg = *concert.Delivery
+	}
+	if cfg.Method == "" {
+		cfg.Method = "mobile"
+	}
+	label := cfg.Label
+	if label == "" {
+		label = deliveryLabels[cfg.Method]
+	}
+	if label == "" {
+		return fmt.Errorf("未知的领取方式: %s", cfg.Method)
+	}
+
+	chain := tg.selectors.Format(tg.site, selectors.DeliveryMethod, label)
+	if _, err := tg.clickChain(ctx, selectors.DeliveryMethod, chain); err != nil {
+		return errs.New(errs.ErrElementNotFound, "chooseDelivery", "找不到领取方式: "+label)
+	}
+	log.Printf("已选择领取方式: %s", label)
+
+	if cfg.Method != "mail" {
+		return nil
+	}
+	return tg.fillAddress(ctx, cfg)
+}
+
+// fillAddress 填写配送地址，优先使用网站保存的地址
+func (tg *TicketGrabber) fillAddress(ctx context.Context, cfg models.Delivery) error {
+	if cfg.UseSavedAddress {
+		if _, err := tg.click(ctx, tg.site, selectors.DeliverySavedAddress); err == nil {
+			log.Println("已选择保存的配送地址")
+			return nil
+		}
+		log.Println("找不到保存的配送地址，改为填写配置中的地址")
+	}
+	if cfg.Address == "" || cfg.Recipient == "" {
+		return fmt.Errorf("配送需要填写收件人和地址，请在delivery中配置recipient和address或启用use_saved_address")
+	}
+
+	values := map[string]string{
+		selectors.DeliveryRecipient:     cfg.Recipient,
+		selectors.DeliveryPhone:         cfg.Phone,
+		selectors.DeliveryPostcode:      cfg.Postcode,
+		selectors.DeliveryAddress:       cfg.Address,
+		selectors.DeliveryAddressDetail: cfg.AddressDetail,
+	}
+	fields := make(map[string]string)
+	for key, value := range values {
+		if value == "" {
+			continue
+		}
+		selector, ok := tg.find(ctx, tg.selectors.Get(tg.site, key))
+		if !ok {
+			// 收件人和地址是配送必需的，找不到时不能带着空地址继续下单
+			if key == selectors.DeliveryRecipient || key == selectors.DeliveryAddress {
+				return errs.New(errs.ErrElementNotFound, "fillAddress", "找不到配送信息输入框: "+key)
+			}
+			log.Printf("页面上没有 %s 输入框，跳过", key)
+			continue
+		}
+		fields[selector] = value
+	}
+	return tg.browser.FillForm(ctx, fields)
+}
@@ -0,0 +1,123 @@
+package grabber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+)
+
+// 占座方式，见 models.SiteConfig.Strategy
+const (
+	strategyAPI     = "api"
+	strategyBrowser = "browser"
+	strategyHybrid  = "hybrid"
+)
+
+// checkoutStrategy 当前站点的占座方式，没有配置占座接口时只能在页面中选座
+func (tg *TicketGrabber) checkoutStrategy() string {
+	site := tg.config.Ticketing.Sites[tg.site]
+	if site.DirectCheckout.HoldURL == "" {
+		return strategyBrowser
+	}
+	switch site.Strategy {
+	case strategyAPI, strategyBrowser, strategyHybrid:
+		return site.Strategy
+	default:
+		return strategyHybrid
+	}
+}
+
+// holdSeats 按站点的占座方式选座
+// hybrid 在接口返回会话过期或需要验证码时，本次任务之后都改为在浏览器中选座，浏览器和接口共用同一会话
+func (tg *TicketGrabber) holdSeats(ctx context.Context, concert *models.Concert) error {
+	strategy := tg.checkoutStrategy()
+	if strategy == strategyBrowser || tg.apiFallback {
+		return tg.selectSeats(ctx, concert)
+	}
+
+	err := tg.directCheckout(ctx, concert)
+	if err == nil || strategy == strategyAPI {
+		return err
+	}
+	if errors.Is(err, errs.ErrSessionExpired) || errors.Is(err, errs.ErrCaptchaRequired) {
+		log.Printf("接口占座被拒绝，改为在浏览器中完成购买: %v", err)
+		tg.apiFallback = true
+	} else {
+		log.Printf("直接占座失败，改为在页面中选座: %v", err)
+	}
+	return tg.selectSeats(ctx, concert)
+}
+
+// prepareDirectCheckout 开售前读取座位图并构造占座请求，发现有票时只需一次POST，跳过逐页选座
+// 站点只在页面中选座时不构造；座位图尚未公开时在发现有票时再构造
+func (tg *TicketGrabber) prepareDirectCheckout(ctx context.Context, concert *models.Concert) {
+	tg.staged = nil
+	site := tg.config.Ticketing.Sites[tg.site]
+	if site.Strategy != "" && site.Strategy != tg.checkoutStrategy() {
+		log.Printf("站点 %s 的占座方式 %q 无效或没有配置direct_checkout，使用 %s", tg.site, site.Strategy, tg.checkoutStrategy())
+	}
+	if tg.checkoutStrategy() == strategyBrowser {
+		return
+	}
+
+	staged, err := tg.apiClient.StageCheckout(ctx, site.DirectCheckout, tg.stageVars(tg.site), concert.PreferredSeats)
+	if err != nil {
+		log.Printf("预先构造占座请求失败，发现有票时重试: %v", err)
+		return
+	}
+	tg.staged = staged
+	for _, r := range staged.Requests {
+		log.Printf("已构造占座请求: %s %s (区域 %s)", r.Method, r.URL, r.Block)
+	}
+}
+
+// directCheckout 发送预先构造的占座请求，成功后浏览器打开下一步页面
+// 失败时浏览器仍在演出页面，可以继续在页面中选座
+func (tg *TicketGrabber) directCheckout(ctx context.Context, concert *models.Concert) error {
+	if tg.staged == nil {
+		tg.prepareDirectCheckout(ctx, concert)
+		if tg.staged == nil {
+			return fmt.Errorf("没有可用的占座请求")
+		}
+	}
+
+	// 占座请求需要浏览器的最新会话
+	if tg.bridge != nil {
+		if err := tg.bridge.Sync(ctx); err != nil {
+			log.Printf("同步浏览器会话失败: %v", err)
+		}
+	}
+
+	start := time.Now()
+	hold, next, err := tg.apiClient.SubmitCheckout(ctx, tg.staged)
+	if err != nil {
+		return err
+	}
+	log.Printf("已直接占座: 区域 %s，耗时 %v", hold.Block, time.Since(start).Round(time.Millisecond))
+
+	if next == "" {
+		return nil
+	}
+	if base, err := url.Parse(hold.URL); err == nil {
+		if ref, err := base.Parse(next); err == nil {
+			next = ref.String()
+		}
+	}
+	// 占座响应可能设置了新的Cookie，带到浏览器中再打开下一步页面
+	if err := tg.browser.ImportCookies(ctx, tg.apiClient.Cookies(next)); err != nil {
+		log.Printf("导入占座会话失败: %v", err)
+	}
+	if err := tg.browser.Navigate(ctx, next); err != nil {
+		return fmt.Errorf("打开占座后的页面失败: %w", err)
+	}
+	budget := waitBudget(tg.config.Ticketing.Waits.PageLoad, 10*time.Second)
+	tg.waitUntil(ctx, budget, pollInterval, tg.pageReady)
+	tg.recordSelectedSeats(ctx)
+	return nil
+}
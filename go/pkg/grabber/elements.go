@@ -0,0 +1,125 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/selectors"
+)
+
+// find 返回选择器链中第一个在页面上存在的选择器
+func (tg *TicketGrabber) find(ctx context.Context, chain selectors.Chain) (string, bool) {
+	for _, selector := range chain {
+		if found, ok := selectors.Locate(ctx, tg.browser, selector); ok {
+			return found, true
+		}
+	}
+	return "", false
+}
+
+// resolve 返回用于填写表单的选择器，链中只有一项时直接使用，否则取第一个存在的
+func (tg *TicketGrabber) resolve(ctx context.Context, chain selectors.Chain) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	if len(chain) > 1 {
+		if selector, ok := tg.find(ctx, chain); ok {
+			return selector
+		}
+	}
+	return chain[0]
+}
+
+// click 依次尝试点击站点某个元素的选择器链，返回点击成功的选择器
+// 整条链都失效时尝试按文字和属性重新定位元素
+func (tg *TicketGrabber) click(ctx context.Context, site, key string) (string, error) {
+	selector, err := tg.clickChain(ctx, key, tg.selectors.Get(site, key))
+	if err == nil {
+		return selector, nil
+	}
+
+	healed, ok := tg.heal(ctx, site, key)
+	if !ok {
+		return "", err
+	}
+	if clicked, clickErr := tg.browser.ClickElement(ctx, healed); clickErr != nil || !clicked {
+		return "", err
+	}
+	return healed, nil
+}
+
+// clickChain 依次尝试点击选择器链
+func (tg *TicketGrabber) clickChain(ctx context.Context, key string, chain selectors.Chain) (string, error) {
+	for _, selector := range chain {
+		clicked, err := selectors.Click(ctx, tg.browser, selector)
+		if err == nil && clicked {
+			return selector, nil
+		}
+	}
+	return "", errs.New(errs.ErrElementNotFound, "click", key)
+}
+
+// heal 选择器失效时按关键字在页面上查找元素，记录找到的新选择器
+// selector_healing 为off时不修复，persist时写回站点选择器文件
+func (tg *TicketGrabber) heal(ctx context.Context, site, key string) (string, bool) {
+	mode := tg.config.Ticketing.SelectorHealing
+	if mode == "off" {
+		return "", false
+	}
+	script, ok := selectors.HealScript(key)
+	if !ok {
+		return "", false
+	}
+
+	result, err := tg.browser.ExecuteScript(ctx, script)
+	if err != nil {
+		return "", false
+	}
+	selector, _ := result.(string)
+	if selector == "" {
+		return "", false
+	}
+
+	log.Printf("选择器 %s 已失效，自动修复为: %s", key, selector)
+	if err := tg.selectors.Remember(site, key, selector, mode == "persist"); err != nil {
+		log.Printf("保存修复后的选择器失败: %v", err)
+	}
+	return selector, true
+}
+
+// pollInterval 条件等待的检查间隔
+const pollInterval = 100 * time.Millisecond
+
+// readyScript 页面是否加载完成
+const readyScript = `document.readyState === "complete"`
+
+// waitUntil 每隔interval检查一次cond，满足时立即返回true，超过budget或ctx取消时返回false
+func (tg *TicketGrabber) waitUntil(ctx context.Context, budget, interval time.Duration, cond func(ctx context.Context) bool) bool {
+	deadline := tg.clock.Now().Add(budget)
+	for {
+		if cond(ctx) {
+			return true
+		}
+		if !tg.clock.Now().Before(deadline) || clock.Sleep(ctx, tg.clock, interval) != nil {
+			return false
+		}
+	}
+}
+
+// pageReady 页面是否加载完成
+func (tg *TicketGrabber) pageReady(ctx context.Context) bool {
+	result, err := tg.browser.ExecuteScript(ctx, readyScript)
+	ready, _ := result.(bool)
+	return err == nil && ready
+}
+
+// waitBudget 把配置的等待时间(秒)转换为时长，未配置时使用fallback
+func waitBudget(seconds float64, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
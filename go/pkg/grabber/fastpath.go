@@ -0,0 +1,73 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tickgrabber/pkg/flow"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/tracing"
+)
+
+// stageFastPath 快速购买流程，从选座到确认购买
+const stageFastPath = "fast_path"
+
+// defaultFastPathWindow 没有配置fast_path_window时开售后使用快速流程的时长
+const defaultFastPathWindow = 2 * time.Minute
+
+// prepareFastPath 开售前把站点的快速购买流程编译好，开售后的最初几秒内不再解析模板和选择器
+// 没有流程文件、演唱会没有开售时间或配置了购票限额时不使用快速流程
+func (tg *TicketGrabber) prepareFastPath(concert *models.Concert) {
+	tg.fastPath = nil
+	if tg.config.Ticketing.FlowDir == "" || concert.SaleOpenTime.IsZero() {
+		return
+	}
+	path := filepath.Join(tg.config.Ticketing.FlowDir, tg.site, stageFastPath+".yaml")
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	// 快速流程一次执行到确认购买，中间无法检查金额和张数
+	if g := tg.config.Tickets.Guardrails; g.MaxSpendPerRun > 0 || g.MaxSpendPerDay > 0 || g.MaxTicketsPerConcert > 0 || g.ConfirmAbove > 0 {
+		log.Printf("配置了购票限额，不使用快速购买流程 %s", path)
+		return
+	}
+
+	f, err := flow.Load(path)
+	if err != nil {
+		log.Printf("读取快速购买流程失败: %v", err)
+		return
+	}
+	steps, err := flow.Compile(f, tg.stageVars(tg.site))
+	if err != nil {
+		log.Printf("编译快速购买流程失败: %v", err)
+		return
+	}
+	tg.fastPath = steps
+	log.Printf("已编译快速购买流程 %s (%d 步)", path, len(steps))
+}
+
+// runFastPath 在开售后的时间窗口内执行快速购买流程，返回是否完成
+// 失败时由常规流程从当前页面继续
+func (tg *TicketGrabber) runFastPath(ctx context.Context, concert *models.Concert) bool {
+	if len(tg.fastPath) == 0 {
+		return false
+	}
+	window := waitBudget(tg.config.Ticketing.FastPathWindow, defaultFastPathWindow)
+	if since := tg.clock.Now().Sub(concert.SaleOpenTime); since < 0 || since > window {
+		return false
+	}
+
+	err := tracing.Run(ctx, stageFastPath, func(ctx context.Context) error {
+		return tg.browser.RunBatch(ctx, tg.fastPath)
+	})
+	if err != nil {
+		log.Printf("快速购买流程失败，改用常规流程: %v", err)
+		return false
+	}
+	log.Println("快速购买流程完成")
+	tg.recordSelectedSeats(ctx)
+	return true
+}
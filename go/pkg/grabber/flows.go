@@ -0,0 +1,113 @@
+package grabber
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tickgrabber/pkg/adapter"
+	"tickgrabber/pkg/flow"
+	"tickgrabber/pkg/models"
+)
+
+// 可以用流程文件替换的抢票阶段，文件为 <flow_dir>/<站点>/<阶段>.yaml
+const (
+	StageLogin       = "login"
+	StageSelectRound = "select_round"
+	StagePresale     = "presale"
+	StageSelectSeats = "select_seats"
+	StageDelivery    = "delivery"
+	StagePurchase    = "purchase"
+)
+
+// runStage 站点配置了外部适配器或该阶段的流程文件时由它们执行，返回是否已处理
+// 流程文件每次执行都重新读取，修改后下一次重试即生效
+func (tg *TicketGrabber) runStage(ctx context.Context, site, stage string) (bool, error) {
+	if p := tg.adapterFor(ctx, site); p != nil && p.Info().Supports(stage) {
+		log.Printf("使用适配器 %s 执行 %s", p.Info().Name, stage)
+		err := p.Run(ctx, tg.browser, stage, tg.stageVars(site))
+		if errors.Is(err, adapter.ErrClosed) || ctx.Err() != nil {
+			tg.closeAdapter(site)
+		}
+		return true, err
+	}
+
+	if tg.config.Ticketing.FlowDir == "" {
+		return false, nil
+	}
+	path := filepath.Join(tg.config.Ticketing.FlowDir, site, stage+".yaml")
+	if _, err := os.Stat(path); err != nil {
+		return false, nil
+	}
+
+	f, err := flow.Load(path)
+	if err != nil {
+		return true, err
+	}
+	log.Printf("使用流程文件: %s", path)
+	return true, flow.Run(ctx, tg.browser, f, tg.stageVars(site))
+}
+
+// stageVars 执行阶段时的变量，包括当前尝试的场次
+func (tg *TicketGrabber) stageVars(site string) map[string]string {
+	vars := FlowVars(tg.config, site, tg.concert)
+	if tg.round != nil {
+		vars["round_date"] = tg.round.Date
+		vars["round_time"] = tg.round.Time
+		vars["round_label"] = tg.round.Label
+	}
+	return vars
+}
+
+// FlowVars 流程模板可以使用的变量
+func FlowVars(config *models.Config, site string, concert *models.Concert) map[string]string {
+	vars := map[string]string{
+		"username":  config.User.Username,
+		"password":  config.User.Password,
+		"site":      site,
+		"login_url": config.Ticketing.Sites[site].LoginURL,
+	}
+	if concert != nil {
+		vars["concert_id"] = concert.ID
+		vars["concert_name"] = concert.Name
+		vars["concert_url"] = concert.URL
+		vars["preferred_seats"] = strings.Join(concert.PreferredSeats, ",")
+		if len(concert.PreferredSeats) > 0 {
+			vars["preferred_seat"] = concert.PreferredSeats[0]
+		}
+	}
+	return vars
+}
+
+// adapterFor 返回站点的适配器进程，第一次使用或进程退出后重新启动
+func (tg *TicketGrabber) adapterFor(ctx context.Context, site string) *adapter.Process {
+	cfg := tg.config.Ticketing.Sites[site].Adapter
+	if cfg.Command == "" {
+		return nil
+	}
+	if p := tg.adapters[site]; p != nil {
+		return p
+	}
+
+	p, err := adapter.Start(ctx, site, cfg)
+	if err != nil {
+		log.Printf("适配器不可用，使用内置流程: %v", err)
+		return nil
+	}
+	if tg.adapters == nil {
+		tg.adapters = make(map[string]*adapter.Process)
+	}
+	tg.adapters[site] = p
+	return p
+}
+
+// closeAdapter 关闭站点的适配器进程
+func (tg *TicketGrabber) closeAdapter(site string) {
+	if p := tg.adapters[site]; p != nil {
+		p.Close()
+		delete(tg.adapters, site)
+	}
+}
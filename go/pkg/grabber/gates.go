@@ -0,0 +1,32 @@
+package grabber
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"tickgrabber/pkg/gate"
+	"tickgrabber/pkg/notify"
+)
+
+// passGates 页面出现条款同意、年龄确认或公告时按站点规则自动通过，需要手动完成(如本人认证)时通知用户
+func (tg *TicketGrabber) passGates(ctx context.Context) error {
+	rules := gate.Rules(tg.config.Ticketing.Sites[tg.site].Gates)
+	passed, err := gate.Pass(ctx, tg.browser, rules)
+	for _, name := range passed {
+		log.Printf("已自动通过页面: %s", name)
+	}
+	if errors.Is(err, gate.ErrManual) {
+		event := notify.Event{
+			Type:    notify.EventActionRequired,
+			Title:   "需要手动完成验证",
+			Message: err.Error() + "，请在浏览器中完成",
+		}
+		if tg.concert != nil {
+			event.Concert = tg.concert.Name
+			event.URL = tg.concert.URL
+		}
+		tg.notify(ctx, event)
+	}
+	return err
+}
@@ -0,0 +1,1218 @@
+// Package grabber 抢票器，可以嵌入其他Go程序中使用，不需要启动 ticket_grabber 进程
+//
+// 调用方创建浏览器和API客户端，抢票器按配置完成登录、进入演出页面、等待开售、监控余票、选座和下单，
+// 所有阻塞的操作都随ctx取消而结束，错误通过返回值报告(可用 errors.Is 与 errs 包中的错误比较)，不会退出进程:
+//
+//	b, err := browser.NewBrowser(ctx, browser.WithHeadless(true), browser.WithProfileDir("data/chrome"))
+//	if err != nil {
+//		return err
+//	}
+//	defer b.Close()
+//
+//	tg := grabber.New(b, api.NewClient(api.WithConfig(config)), config, grabber.Options{})
+//	defer tg.Close()
+//	err = tg.Start(ctx, &config.Concerts[0])
+//
+// 同时运行多个演出时，用 task.Manager 管理任务、task.Pool 限制浏览器数量，多个抢票器通过 Options
+// 共用看门狗、订单记录和请求限速器；小众站点可以用 adapter 包的外部适配器接入，只需在站点配置中指定命令
+package grabber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"tickgrabber/pkg/adapter"
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/captcha"
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/gateway"
+	"tickgrabber/pkg/history"
+	"tickgrabber/pkg/leader"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/navwatch"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/scheduler"
+	"tickgrabber/pkg/selectors"
+	"tickgrabber/pkg/session"
+	"tickgrabber/pkg/shared"
+	"tickgrabber/pkg/timeline"
+	"tickgrabber/pkg/tracing"
+	"tickgrabber/pkg/venue"
+	"tickgrabber/pkg/watchdog"
+)
+
+// TicketGrabber 抢票器，一个抢票器同一时间只运行一个任务
+type TicketGrabber struct {
+	browser   browser.Driver
+	apiClient *api.Client
+	config    *models.Config
+	notifier  *notify.Manager
+	solver    *captcha.TrackedSolver
+	watchdog  *watchdog.Watchdog
+	orders    *order.Store
+
+	selectors *selectors.Registry
+	venues    *venue.DB
+	// site 当前任务的票务网站
+	site string
+	// concert 当前任务的演唱会
+	concert *models.Concert
+	// round 当前选中的场次，演唱会没有配置场次时为nil
+	round *models.Round
+	// cookieSession 已从Cookie文件导入会话，下一次登录直接使用该会话
+	cookieSession bool
+	// elector 冗余实例的主备选举，为nil时本实例始终负责购买
+	elector *leader.Elector
+	// shared 多实例共享状态，为nil时不共享
+	shared shared.Store
+	// bridge 浏览器会话到API客户端的同步，登录后创建
+	bridge *session.Bridge
+	// limiter 守护模式下所有任务共用的请求限速器，为nil时不限速
+	limiter *api.RateLimiter
+	// claim 同时在多个网站监控同一演出时，发现有票后抢占购买权，返回false表示其他网站已在购买
+	claim func() bool
+	// release 购买失败时交还claim取得的购买权，其他网站可以继续监控和购买
+	release func()
+	// adapters 已启动的外部站点适配器
+	adapters map[string]*adapter.Process
+
+	// result 前台运行的结果记录，守护模式下为nil
+	result *Result
+	// progress 当前任务的实时进度，没有进度显示时为nil
+	progress *progress.Status
+	// timeline 当前任务各阶段的时间
+	timeline *timeline.Timeline
+	// artifacts 当前任务的产物目录，没有配置artifact_dir时为nil
+	artifacts *artifacts
+	// ownLog 前台只有这一个任务，日志同时写入任务产物目录
+	ownLog bool
+	// clock 等待开售、轮询和等待页面使用的时钟
+	clock clock.Clock
+
+	// selectedSeats 选座完成时页面上已选中的座位，用于购票后核对订单
+	selectedSeats []string
+	// card 解密后的银行卡，没有启用自动填写时为nil
+	card *gateway.Card
+	// blocking 监控阶段的请求阻止是否生效
+	blocking bool
+	// pollInterval 自适应轮询当前的检查间隔
+	pollInterval time.Duration
+	// history 余票状态记录，没有配置history_file时为nil
+	history *history.Log
+	// fastPath 编译好的快速购买流程，没有时为nil
+	fastPath []browser.BatchStep
+	// staged 预先构造的直接占座请求，站点不支持或尚未构造时为nil
+	staged *api.StagedCheckout
+	// concertPage 进入演出页面后实际所在的地址，用于发现页面被跳转
+	concertPage string
+	// maintenanceWait 连续遇到维护公告时的等待时间
+	maintenanceWait time.Duration
+	// apiFallback 接口占座遇到会话过期或验证码后改为在浏览器中选座
+	apiFallback bool
+	// purchaseFailed 已发送购票失败通知，任务结束时不再发送错误事件
+	purchaseFailed bool
+	// heldOrder 确认购买后、支付前采集的订单，支付完成时标记为已付款
+	heldOrder *order.Order
+	// purchasing 已取得购买权，正在占座、购买或支付；看门狗此时刷新页面会丢失占到的座位
+	purchasing atomic.Bool
+}
+
+// Options 创建抢票器的可选设置，零值为单独运行一个任务
+type Options struct {
+	// Clock 等待开售、轮询和等待页面使用的时钟，为nil时使用系统时钟
+	Clock clock.Clock
+	// Watchdog 注册监控循环和浏览器的看门狗，为nil时按 health 配置新建，由调用方运行
+	Watchdog *watchdog.Watchdog
+	// Orders 订单记录，多个抢票器应共用同一个；为nil时打开配置的 orders_file
+	Orders *order.Store
+	// Limiter 多个抢票器共用的请求限速器，为nil时不限速
+	Limiter *api.RateLimiter
+	// Claim 同时在多个网站监控同一演出时，发现有票后抢占购买权，返回false表示其他网站已在购买
+	Claim func() bool
+	// Release 购买失败时调用，交还Claim取得的购买权
+	Release func()
+	// Events 抢票器的通知事件同时转发到这里，为nil时不转发
+	Events *notify.Broadcaster
+	// OwnLog 进程中只有这一个抢票器，日志同时写入任务产物目录
+	OwnLog bool
+}
+
+// New 创建新的抢票器，浏览器和API客户端由调用方创建和关闭
+// 验证码中继、订单记录、场馆文件等不可用时只记录日志，抢票器使用内置数据或不使用该功能
+func New(browser browser.Driver, apiClient *api.Client, config *models.Config, opts Options) *TicketGrabber {
+	solver, err := captcha.NewSolver(config)
+	if err != nil {
+		log.Printf("验证码中继不可用: %v", err)
+	}
+
+	orders := opts.Orders
+	if orders == nil && config.Ticketing.OrdersFile != "" {
+		orders, err = order.OpenStore(config.Ticketing.OrdersFile, ArtifactKey(config))
+		if err != nil {
+			log.Printf("打开订单记录失败: %v", err)
+		}
+	}
+
+	venues, err := venue.Load(config.Ticketing.VenueFile)
+	if err != nil {
+		log.Printf("读取场馆文件失败，使用内置数据: %v", err)
+		venues = venue.Default()
+	}
+
+	store := shared.Open(config.SharedState)
+	if store != nil {
+		apiClient.Cache().Share(store)
+	}
+
+	wd := opts.Watchdog
+	if wd == nil {
+		wd = watchdog.New(
+			time.Duration(config.Health.CheckInterval)*time.Second,
+			config.Health.MaxMemoryMB,
+		)
+	}
+	c := opts.Clock
+	if c == nil {
+		c = clock.Real
+	}
+	notifier := notify.NewManager(config.Notification)
+	if opts.Events != nil {
+		notifier.Tap(opts.Events)
+	}
+
+	return &TicketGrabber{
+		browser:   browser,
+		apiClient: apiClient,
+		config:    config,
+		notifier:  notifier,
+		solver:    solver,
+		watchdog:  wd,
+		orders:    orders,
+		selectors: selectors.NewRegistry(config.Ticketing.SelectorDir),
+		venues:    venues,
+		shared:    store,
+		limiter:   opts.Limiter,
+		claim:     opts.Claim,
+		release:   opts.Release,
+		ownLog:    opts.OwnLog,
+		clock:     c,
+		history:   history.Open(config.Ticketing.HistoryFile),
+	}
+}
+
+// Watchdog 返回抢票器注册组件的看门狗，启用 health 时由调用方运行
+func (tg *TicketGrabber) Watchdog() *watchdog.Watchdog {
+	return tg.watchdog
+}
+
+// Notifier 返回抢票器的通知管理器
+func (tg *TicketGrabber) Notifier() *notify.Manager {
+	return tg.notifier
+}
+
+// Close 打印运行统计并等待未发送的通知发送完毕
+func (tg *TicketGrabber) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if tg.solver != nil {
+		log.Println(tg.solver.Stats())
+	}
+	for site := range tg.adapters {
+		tg.closeAdapter(site)
+	}
+
+	if err := tg.notifier.Close(ctx); err != nil {
+		log.Printf("关闭通知管理器: %v", err)
+	}
+	if tg.shared != nil {
+		tg.shared.Close()
+	}
+}
+
+// Start 开始抢票，结束时生成各阶段的时间线报告
+func (tg *TicketGrabber) Start(ctx context.Context, concert *models.Concert) (err error) {
+	log.Printf("开始为演唱会 %s 抢票", concert.Name)
+
+	tg.concert = concert
+	tg.purchaseFailed = false
+	tg.site = DetectSite(tg.config, concert)
+	tg.timeline = timeline.New(concert.ID, tg.site, concert.SaleOpenTime)
+	tg.progress = progress.FromContext(ctx)
+	tg.result = ResultFrom(ctx)
+	tg.artifacts = tg.openArtifacts(concert)
+	ctx, span := tracing.Start(ctx, "grab",
+		attribute.String("concert.id", concert.ID),
+		attribute.String("site", tg.site),
+	)
+	defer func() {
+		tracing.End(span, err)
+		tg.reportTimeline(err)
+		tg.reportError(ctx, err)
+		tg.closeArtifacts(ctx, err)
+	}()
+	tg.loadSharedSession(ctx)
+	tg.loadCookieFile(ctx)
+
+	// 配置了预热时到开售前几分钟再登录
+	if err := tg.waitForWarmup(ctx, concert); err != nil {
+		return err
+	}
+
+	// 登录票务网站
+	tg.progress.SetStage("登录", tg.site)
+	err = tracing.Run(ctx, "login", tg.login)
+	if err != nil {
+		return errs.Wrap(errs.ErrLoginFailed, "login", err)
+	}
+	tg.timeline.Mark(timeline.StageLoggedIn)
+
+	// 将浏览器会话同步给API客户端
+	interval := time.Duration(tg.config.Browser.CookieSyncInterval) * time.Second
+	tg.bridge = session.NewBridge(tg.browser, tg.apiClient, interval)
+	if err := tg.bridge.Start(ctx); err != nil {
+		log.Printf("同步浏览器会话失败: %v", err)
+	}
+	tg.shareSession(ctx)
+	tg.saveCookieFile(ctx)
+
+	// 预热购票接口的连接，避免开售时再进行TLS握手
+	warmURLs := append([]string{concert.URL}, tg.config.Ticketing.Sites[tg.site].PrewarmURLs...)
+	conns := tg.config.Ticketing.PrewarmConnections
+	if err := tg.apiClient.Prewarm(ctx, warmURLs, conns); err != nil {
+		log.Printf("预热连接失败: %v", err)
+	}
+	go tg.apiClient.KeepWarm(ctx, warmURLs, conns, 30*time.Second)
+	WatchResale(ctx, tg.config, tg.apiClient, tg.notifier, concert)
+
+	// 检查是否已有同一演出的订单，避免崩溃重启后重复购买
+	if err := tg.checkExistingOrders(ctx, concert); err != nil {
+		return err
+	}
+
+	// 进入演唱会页面
+	tg.progress.SetStage("进入演唱会页面", "")
+	err = tracing.Run(ctx, "navigate", func(ctx context.Context) error {
+		return tg.navigateToConcert(ctx, concert)
+	})
+	if err != nil {
+		return fmt.Errorf("进入演唱会页面失败: %w", err)
+	}
+
+	if err := tg.checkAppOnly(ctx, concert); err != nil {
+		return err
+	}
+	tg.checkSaleOpenTime(ctx, concert)
+	tg.warmUp(ctx)
+	tg.prepareFastPath(concert)
+	tg.prepareDirectCheckout(ctx, concert)
+
+	// 按服务器时间等待开售
+	if !concert.SaleOpenTime.IsZero() {
+		tg.progress.SetStage("等待开售", "")
+		tg.progress.SetCountdown("距开售", concert.SaleOpenTime)
+		stopKeepAlive := tg.keepAlive(ctx, concert)
+		err = tracing.Run(ctx, "wait_sale_open", func(ctx context.Context) error {
+			return tg.waitForSaleOpen(ctx, concert)
+		})
+		stopKeepAlive()
+		if err != nil {
+			return err
+		}
+		tg.timeline.Mark(timeline.StageSaleOpen)
+	}
+
+	// 冗余部署时只有主实例购买
+	if err := tg.startElection(ctx, concert); err != nil {
+		return err
+	}
+
+	// 开始监控票务
+	if tg.config.Health.Enabled {
+		tg.registerWatchdog(concert)
+	}
+	return tg.monitorTickets(ctx, concert)
+}
+
+// reportTimeline 输出本次任务的时间线，配置了timeline_dir时同时保存为JSON和文本报告
+func (tg *TicketGrabber) reportTimeline(err error) {
+	tg.timeline.Finish(err)
+	log.Print(tg.timeline)
+
+	dir := tg.config.Ticketing.TimelineDir
+	if dir == "" {
+		return
+	}
+	base, werr := tg.timeline.Write(dir)
+	if werr != nil {
+		log.Printf("保存时间线报告失败: %v", werr)
+		return
+	}
+	log.Printf("时间线报告已保存到 %s.json 和 %s.txt", base, base)
+}
+
+// reportError 任务因错误结束时发送错误事件(on_error 钩子等)，已发送购票失败通知或任务被取消时不发送
+func (tg *TicketGrabber) reportError(ctx context.Context, err error) {
+	if err == nil || tg.purchaseFailed || ctx.Err() != nil {
+		return
+	}
+	tg.notify(context.WithoutCancel(ctx), notify.Event{
+		Type:    notify.EventError,
+		Title:   "抢票任务出错",
+		Message: err.Error(),
+		Concert: tg.concert.Name,
+		URL:     tg.concert.URL,
+	})
+}
+
+// registerWatchdog 向看门狗注册监控循环和浏览器
+func (tg *TicketGrabber) registerWatchdog(concert *models.Concert) {
+	stall := time.Duration(tg.config.Health.StallTimeout) * time.Second
+	if stall <= 0 {
+		stall = time.Minute
+	}
+
+	tg.watchdog.Register(watchdog.Component{
+		Name:       "monitor:" + concert.ID,
+		MaxSilence: stall,
+		Restart: func(ctx context.Context) error {
+			return tg.navigateToConcert(ctx, concert)
+		},
+		Busy: tg.purchasing.Load,
+	})
+	tg.watchdog.Register(watchdog.Component{
+		Name:  "browser:" + concert.ID,
+		Probe: tg.browser.Ping,
+		Restart: func(ctx context.Context) error {
+			return tg.browser.Reload(ctx)
+		},
+		Busy: tg.purchasing.Load,
+	})
+}
+
+// waitForSaleOpen 探测服务器时钟偏移后等待到开售时刻
+func (tg *TicketGrabber) waitForSaleOpen(ctx context.Context, concert *models.Concert) error {
+	var offset time.Duration
+	probe, err := tg.apiClient.ProbeClock(ctx, concert.URL, 8)
+	if err != nil {
+		log.Printf("服务器时钟探测失败，使用本地时间: %v", err)
+	} else {
+		offset = probe.Offset
+	}
+
+	return scheduler.WaitUntil(ctx, tg.clock, concert.SaleOpenTime, offset)
+}
+
+// Login 只运行站点的登录流程(流程文件、适配器或内置表单)，不等待二次验证完成，用于单独检查账号和登录流程
+func (tg *TicketGrabber) Login(ctx context.Context, site string) error {
+	tg.site = site
+	return tg.login(ctx)
+}
+
+// SaveSession 把浏览器的会话写入Cookie文件并共享给其他实例，供之后的任务直接使用
+func (tg *TicketGrabber) SaveSession(ctx context.Context) {
+	tg.saveCookieFile(ctx)
+	tg.shareSession(ctx)
+}
+
+// login 登录票务网站
+func (tg *TicketGrabber) login(ctx context.Context) error {
+	// 导入的会话只代替第一次登录，会话过期后的重新登录照常进行
+	if tg.cookieSession {
+		tg.cookieSession = false
+		log.Println("使用导入的Cookie会话，跳过登录")
+		return nil
+	}
+
+	log.Println("正在登录票务网站...")
+
+	// 根据配置选择登录方式，站点有登录流程文件时优先使用
+	site := tg.site
+	if site == "" {
+		site = tg.config.Ticketing.DefaultSite
+	}
+	if handled, err := tg.runStage(ctx, site, StageLogin); handled {
+		return err
+	}
+	switch site {
+	case "interpark", "interpark_nol", "yes24", "melon", "coupangplay":
+		return tg.loginWithForm(ctx, site)
+	default:
+		return errs.New(errs.ErrUnsupportedSite, "login", site)
+	}
+}
+
+// loginWithForm 打开登录页，按站点的选择器填写账号密码并提交
+func (tg *TicketGrabber) loginWithForm(ctx context.Context, site string) error {
+	cfg := tg.config.Ticketing.Sites[site]
+
+	err := tg.browser.Navigate(ctx, cfg.LoginURL)
+	if err != nil {
+		return err
+	}
+	loginPage, _ := tg.browser.GetCurrentURL(ctx)
+
+	// 填写用户名和密码
+	err = tg.browser.FillForm(ctx, map[string]string{
+		tg.resolve(ctx, tg.selectors.Get(site, selectors.LoginUsername)): tg.config.User.Username,
+		tg.resolve(ctx, tg.selectors.Get(site, selectors.LoginPassword)): tg.config.User.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 提交登录表单
+	if _, err := tg.click(ctx, site, selectors.LoginSubmit); err != nil {
+		return err
+	}
+
+	// 等待提交后跳转的页面加载完成，否则紧接着进入演唱会页面会中断登录请求(net::ERR_ABORTED)
+	budget := waitBudget(tg.config.Ticketing.Waits.PageLoad, 10*time.Second)
+	left := tg.waitUntil(ctx, budget, pollInterval, func(ctx context.Context) bool {
+		current, err := tg.browser.GetCurrentURL(ctx)
+		return err == nil && current != loginPage && tg.pageReady(ctx)
+	})
+	if !left {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		log.Printf("提交登录后 %v 内页面没有跳转，继续", budget)
+	}
+
+	log.Printf("%s登录成功", cfg.Name)
+	return nil
+}
+
+// navigateToConcert 进入演唱会页面
+func (tg *TicketGrabber) navigateToConcert(ctx context.Context, concert *models.Concert) error {
+	log.Printf("正在进入演唱会页面: %s", concert.URL)
+
+	err := tg.browser.Navigate(ctx, concert.URL)
+	if err != nil {
+		return err
+	}
+
+	// 等待页面加载完成，已经完成时立即继续
+	budget := waitBudget(tg.config.Ticketing.Waits.PageLoad, 10*time.Second)
+	if !tg.waitUntil(ctx, budget, pollInterval, tg.pageReady) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		log.Printf("演唱会页面 %v 内没有加载完成，继续监控", budget)
+	}
+
+	// 记录跳转后的实际地址，落在登录页或错误页时仍以配置的地址为准
+	if current, err := tg.browser.GetCurrentURL(ctx); err == nil && current != "" {
+		switch navwatch.Classify(current, concert.URL, tg.config.Ticketing.Sites[tg.site], "") {
+		case navwatch.Login, navwatch.Error, navwatch.Maintenance, navwatch.Waiting:
+		default:
+			tg.concertPage = current
+		}
+	}
+
+	log.Println("已进入演唱会页面")
+	return nil
+}
+
+// monitorTickets 监控票务
+func (tg *TicketGrabber) monitorTickets(ctx context.Context, concert *models.Concert) error {
+	log.Println("开始监控票务...")
+	tg.progress.SetStage("监控中", "")
+	tg.blockRequests(ctx, true)
+	defer tg.blockRequests(context.WithoutCancel(ctx), false)
+
+	refreshInterval := time.Duration(tg.config.Ticketing.RefreshInterval*1000) * time.Millisecond
+	poller := tg.newPoller(concert, refreshInterval)
+	tg.pollInterval = refreshInterval
+	// 每次检查开始时按当前间隔安排下一次检查，与固定间隔的Ticker一致
+	wait := tg.clock.NewTimer(refreshInterval)
+	defer func() { wait.Stop() }()
+
+	// 长时间监控时定期确认登录会话有效，为0时不检查
+	var sessionCheck <-chan time.Time
+	if interval := tg.config.Ticketing.SessionCheckInterval; interval > 0 {
+		sessionTicker := tg.clock.NewTicker(time.Duration(interval * float64(time.Second)))
+		defer sessionTicker.Stop()
+		sessionCheck = sessionTicker.C()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("抢票任务已停止")
+			return nil
+		case <-sessionCheck:
+			if err := tg.checkSession(ctx, concert); err != nil {
+				return err
+			}
+		case <-wait.C():
+			wait = tg.clock.NewTimer(tg.pollInterval)
+			if err := tg.limiter.Wait(ctx); err != nil {
+				log.Println("抢票任务已停止")
+				return nil
+			}
+			// 页面被跳转到登录页、错误页或维护公告时先恢复
+			if recovered, err := tg.checkNavigation(ctx, concert); err != nil {
+				return err
+			} else if recovered {
+				continue
+			}
+
+			// 检查是否有票
+			checkCtx, span := tracing.Start(ctx, "check")
+			available, err := tg.checkRounds(checkCtx, concert)
+			span.SetAttributes(attribute.Bool("available", available))
+			tracing.End(span, err)
+			if err == nil {
+				tg.observePoll(ctx, poller, concert, available)
+				tg.recordAvailability(ctx, concert, available)
+			}
+			tg.progress.Polled(tg.clock.Now().Add(tg.pollInterval))
+			if err == nil {
+				tg.watchdog.Beat("monitor:" + concert.ID)
+			}
+			if err != nil {
+				log.Printf("检查票务状态失败: %v", err)
+				if err := tg.handleFailure(ctx, concert, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if available {
+				tg.timeline.Mark(timeline.StageAvailable)
+			}
+			if available && tg.standby() {
+				log.Println("发现可用票务，本实例为热备实例，由主实例购买")
+				continue
+			}
+			if available {
+				if tg.claim != nil && !tg.claim() {
+					log.Println("其他网站已在购买，停止监控")
+					return nil
+				}
+				if !tg.lockPurchase(ctx, concert) {
+					log.Println("同一账号的其他实例已在购买，停止监控")
+					return nil
+				}
+				tg.purchasing.Store(true)
+				log.Println("发现可用票务！")
+				message := "正在尝试购买，请留意浏览器"
+				if tg.round != nil {
+					message = "场次 " + tg.round.String() + " " + message
+				}
+				tg.notify(ctx, notify.Event{
+					Type:    notify.EventTicketFound,
+					Title:   "发现可用票务",
+					Message: message,
+					Concert: concert.Name,
+					URL:     concert.URL,
+				})
+
+				// 尝试购买
+				tg.blockRequests(ctx, false)
+				err = tg.purchaseTicket(ctx, concert)
+				if err != nil {
+					log.Printf("购买失败: %v", err)
+					tg.purchasing.Store(false)
+					tg.unlockPurchase(concert)
+					if tg.release != nil {
+						tg.release()
+					}
+					tg.blockRequests(ctx, true)
+					if err := tg.handleFailure(ctx, concert, err); err != nil {
+						tg.purchaseFailed = true
+						tg.notify(ctx, notify.Event{
+							Type:    notify.EventPurchaseFailed,
+							Title:   "抢票失败",
+							Message: err.Error(),
+							Concert: concert.Name,
+							URL:     concert.URL,
+						})
+						return err
+					}
+					continue
+				}
+
+				log.Println("购票成功！")
+				tg.progress.SetStage("购票成功", "")
+				o := tg.heldOrder
+				var orderID string
+				if o != nil {
+					orderID = o.ID
+				}
+				tg.markPurchased(ctx, concert, orderID)
+				tg.result.recordPurchase(tg.site, o)
+				tg.artifacts.recordPurchase(tg.site, o)
+				tg.verifySeats(ctx, concert, o)
+				tg.notifyPurchaseSuccess(ctx, concert, o)
+				return nil
+			}
+
+			log.Println("暂无可用票务，继续监控...")
+		}
+	}
+}
+
+// checkSession 检查登录会话，失效时重新登录并回到演唱会页面，返回非nil表示放弃
+func (tg *TicketGrabber) checkSession(ctx context.Context, concert *models.Concert) error {
+	err := tracing.Run(ctx, "check_session", func(ctx context.Context) error {
+		return tg.apiClient.CheckSession(ctx, tg.site)
+	})
+	if err == nil {
+		return nil
+	}
+	if !errs.NeedsRelogin(err) {
+		log.Printf("检查登录会话失败: %v", err)
+		return nil
+	}
+
+	log.Printf("登录会话已失效: %v", err)
+	if err := tg.handleFailure(ctx, concert, err); err != nil {
+		return err
+	}
+	if tg.bridge != nil {
+		if err := tg.bridge.Sync(ctx); err != nil {
+			log.Printf("同步浏览器会话失败: %v", err)
+		}
+	}
+	tg.shareSession(ctx)
+	tg.saveCookieFile(ctx)
+	log.Println("已重新登录，继续监控")
+	return nil
+}
+
+// handleFailure 根据失败原因决定重新登录、继续重试还是放弃，返回非nil表示放弃
+func (tg *TicketGrabber) handleFailure(ctx context.Context, concert *models.Concert, err error) error {
+	switch {
+	case errs.Fatal(err), errors.Is(err, errs.ErrPaymentTimeout):
+		return err
+	case errs.NeedsRelogin(err):
+		log.Println("会话已过期，重新登录...")
+		tg.progress.SetStage("重新登录", "")
+		if err := tracing.Run(ctx, "relogin", tg.login); err != nil {
+			return fmt.Errorf("重新登录失败: %w", err)
+		}
+		defer tg.progress.SetStage("监控中", "")
+		return tg.navigateToConcert(ctx, concert)
+	case errors.Is(err, errs.ErrCaptchaRequired):
+		if err := tg.solveCaptcha(ctx); err != nil {
+			log.Printf("处理验证码失败: %v", err)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// notify 发送通知，失败只记录日志，不影响抢票流程
+func (tg *TicketGrabber) notify(ctx context.Context, event notify.Event) {
+	if err := tg.notifier.Notify(ctx, event); err != nil {
+		log.Printf("发送通知失败: %v", err)
+	}
+}
+
+// checkExistingOrders 检查本地订单记录和站点"我的预订"中是否已有同一演出的订单
+func (tg *TicketGrabber) checkExistingOrders(ctx context.Context, concert *models.Concert) error {
+	mode := tg.config.Ticketing.DuplicateGuard
+	if mode == "off" {
+		return nil
+	}
+
+	var found []string
+	if tg.orders != nil {
+		for _, o := range tg.orders.List() {
+			if o.ConcertID == concert.ID {
+				found = append(found, fmt.Sprintf("本地订单 %s", o.ID))
+			}
+		}
+	}
+
+	if id, ok := tg.sharedPurchase(ctx, concert); ok {
+		found = append(found, fmt.Sprintf("其他实例已购买 %s", id))
+	}
+
+	bookings, err := tg.apiClient.GetBookings(ctx, tg.site)
+	if errors.Is(err, api.ErrNoBookingsURL) {
+		log.Printf("站点 %s 未配置 bookings_url，只检查本地订单记录", tg.site)
+	} else if err != nil {
+		log.Printf("查询已有订单失败: %v", err)
+	}
+	for _, b := range bookings {
+		if !b.Canceled() && bookingMatches(b, concert) {
+			found = append(found, fmt.Sprintf("站点订单 %s (%s %s)", b.OrderID, b.Title, b.Date))
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	detail := strings.Join(found, "; ")
+	log.Printf("已有同一演出的订单: %s", detail)
+	tg.notify(ctx, notify.Event{
+		Type:    notify.EventActionRequired,
+		Title:   "检测到已有订单",
+		Message: detail,
+		Concert: concert.Name,
+		URL:     concert.URL,
+	})
+
+	if mode == "warn" {
+		return nil
+	}
+	return errs.New(errs.ErrDuplicateOrder, "checkExistingOrders", detail)
+}
+
+// bookingMatches 订单标题包含演出名或艺人名，且日期相同(配置了日期时)
+func bookingMatches(b api.Booking, concert *models.Concert) bool {
+	title := strings.ToLower(b.Title)
+	matched := false
+	for _, name := range []string{concert.Name, concert.Artist} {
+		if name != "" && strings.Contains(title, strings.ToLower(name)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if concert.Date == "" {
+		return true
+	}
+	return strings.Contains(digits(b.Date), digits(concert.Date))
+}
+
+// digits 只保留数字，用于比较不同格式的日期
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// captureOrder 解析订单确认页并保存订单记录和收据，失败时返回nil
+// 页面可能还在跳转，在budget内反复读取直到能解析出订单；budget为0时只读取一次
+func (tg *TicketGrabber) captureOrder(ctx context.Context, concert *models.Concert, budget time.Duration) *order.Order {
+	var o *order.Order
+	var err error
+	tg.waitUntil(ctx, budget, pollInterval, func(ctx context.Context) bool {
+		var html string
+		if html, err = tg.browser.PageSource(ctx); err != nil {
+			err = fmt.Errorf("读取确认页失败: %w", err)
+			return false
+		}
+		o, err = order.Parse([]byte(html), tg.config.Ticketing.Sites[tg.site].OrderPage)
+		return err == nil
+	})
+	if o == nil {
+		log.Printf("解析订单失败: %v", err)
+		return nil
+	}
+	o.Site = tg.site
+	o.ConcertID = concert.ID
+	o.ConcertName = concert.Name
+	o.ConcertDate = concert.Date + " " + concert.Time
+	o.URL, _ = tg.browser.GetCurrentURL(ctx)
+
+	pdf, err := tg.browser.PrintPDF(ctx)
+	if err != nil {
+		log.Printf("保存确认页PDF失败: %v", err)
+	}
+	if dir := tg.config.Ticketing.ReceiptDir; dir != "" {
+		if err := order.WriteReceipt(dir, o, pdf, ArtifactKey(tg.config)); err != nil {
+			log.Printf("导出收据失败: %v", err)
+		}
+	}
+
+	if tg.orders != nil {
+		if err := tg.orders.Save(o); err != nil {
+			log.Printf("保存订单记录失败: %v", err)
+		}
+	}
+
+	log.Printf("订单号: %s, 座位: %v, 金额: %d", o.ID, o.Seats, o.Total)
+	if !o.PaymentDeadline.IsZero() {
+		log.Printf("付款期限: %s，守护模式(serve)下会按配置发送付款提醒，付款后执行 mark-paid %s",
+			o.PaymentDeadline.Format("2006-01-02 15:04"), o.ID)
+	}
+	return o
+}
+
+// markOrderPaid 支付完成后把占座时记录的订单标记为已付款，不再发送付款提醒
+func (tg *TicketGrabber) markOrderPaid() {
+	o := tg.heldOrder
+	if o == nil {
+		return
+	}
+	o.Paid = true
+	if tg.orders != nil {
+		if err := tg.orders.Update(o.ID, func(stored *order.Order) { stored.Paid = true }); err != nil {
+			log.Printf("标记订单已付款失败: %v", err)
+		}
+	}
+}
+
+// verifySeats 核对订单中的座位与选中的座位，网站在抢票高峰时可能替换或漏掉座位
+func (tg *TicketGrabber) verifySeats(ctx context.Context, concert *models.Concert, o *order.Order) {
+	if o == nil || len(tg.selectedSeats) == 0 {
+		return
+	}
+
+	check := order.VerifySeats(tg.selectedSeats, o.Seats)
+	o.RequestedSeats = tg.selectedSeats
+	o.SeatCheck = &check
+	if tg.orders != nil {
+		if err := tg.orders.Save(o); err != nil {
+			log.Printf("保存订单记录失败: %v", err)
+		}
+	}
+
+	if check.OK() {
+		log.Println("订单座位与选座一致")
+		return
+	}
+
+	log.Printf("订单座位与选座不一致: 缺少 %v, 多出 %v", check.Missing, check.Unexpected)
+	tg.notify(ctx, notify.Event{
+		Type:    notify.EventSeatMismatch,
+		Title:   "订单座位与选座不一致",
+		Message: "网站可能替换或漏掉了座位，请立即核对订单",
+		Concert: concert.Name,
+		URL:     o.URL,
+		OrderID: o.ID,
+		Details: map[string]string{
+			"选中座位": strings.Join(tg.selectedSeats, ", "),
+			"订单座位": strings.Join(o.Seats, ", "),
+			"缺少":   strings.Join(check.Missing, ", "),
+			"多出":   strings.Join(check.Unexpected, ", "),
+		},
+	})
+}
+
+// notifyPurchaseSuccess 发送购票成功通知，附带订单信息和确认页截图
+func (tg *TicketGrabber) notifyPurchaseSuccess(ctx context.Context, concert *models.Concert, o *order.Order) {
+	event := notify.Event{
+		Type:    notify.EventPurchaseSuccess,
+		Title:   "购票成功",
+		Message: "已完成购票，请尽快确认订单",
+		Concert: concert.Name,
+		URL:     concert.URL,
+		Details: map[string]string{
+			"场馆": concert.Venue,
+			"日期": concert.Date + " " + concert.Time,
+		},
+	}
+
+	if o != nil {
+		event.OrderID = o.ID
+		event.Details["座位"] = strings.Join(o.Seats, ", ")
+		event.Details["金额"] = fmt.Sprintf("%d원", o.Total)
+		if !o.PaymentDeadline.IsZero() {
+			event.Details["付款期限"] = o.PaymentDeadline.Format("2006-01-02 15:04")
+		}
+		if o.DeliveryMethod != "" {
+			event.Details["取票方式"] = o.DeliveryMethod
+		}
+	}
+
+	filename := tg.artifacts.path("screenshots", fmt.Sprintf("confirmation_%s.png", time.Now().Format("20060102_150405")))
+	if err := tg.browser.Screenshot(ctx, filename); err == nil {
+		if data, err := os.ReadFile(filename); err == nil {
+			event.Attachments = append(event.Attachments, notify.Attachment{
+				Filename:    filepath.Base(filename),
+				ContentType: "image/png",
+				Data:        data,
+			})
+		}
+		tg.artifacts.sealFile(filename)
+	}
+
+	tg.notify(ctx, event)
+}
+
+// checkTicketAvailability 检查票务可用性
+func (tg *TicketGrabber) checkTicketAvailability(ctx context.Context) (bool, error) {
+	// 配置了移动版接口时先查接口，接口有票时刷新页面让购买按钮出现
+	// 接口不区分场次，配置了rounds时只检查页面
+	if tg.config.Ticketing.Sites[tg.site].AvailabilitySource == sourceMobile && tg.concert != nil && len(tg.concert.Rounds) == 0 {
+		if available, ok := tg.checkMobileAvailability(ctx); ok {
+			if !available {
+				return false, nil
+			}
+			if err := tg.browser.Reload(ctx); err != nil {
+				return false, err
+			}
+			budget := waitBudget(tg.config.Ticketing.Waits.PageLoad, 10*time.Second)
+			tg.waitUntil(ctx, budget, pollInterval, tg.pageReady)
+		}
+	}
+
+	// 检查页面上的票务状态
+	_, found := tg.find(ctx, tg.selectors.Get(tg.site, selectors.TicketAvailable))
+	return found, nil
+}
+
+// purchaseTicket 购买票务，每个步骤记录为一个追踪span
+func (tg *TicketGrabber) purchaseTicket(ctx context.Context, concert *models.Concert) (err error) {
+	log.Println("开始购买票务...")
+	tg.heldOrder = nil
+	tg.progress.SetStage("购买中", "预售验证")
+	// 购买结束(失败后继续监控)时恢复监控状态
+	defer tg.progress.SetStage("监控中", "")
+	ctx, span := tracing.Start(ctx, "purchase")
+	defer func() { tracing.End(span, err) }()
+
+	// 购买流程的浏览器操作优先执行；到确认购买为止独占浏览器，Cookie同步等后台操作不会插入到流程中间
+	ctx = browser.WithPriority(ctx, browser.PriorityPurchase)
+	ctx, release, err := browser.Exclusive(ctx, tg.browser)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// 条款同意、年龄确认等页面挡在选座之前时先通过
+	err = tracing.Run(ctx, "gates", tg.passGates)
+	if err != nil {
+		return fmt.Errorf("通过条款和确认页面失败: %w", err)
+	}
+
+	// 预售需要先通过会员验证才能选座
+	err = tracing.Run(ctx, "presale_gate", func(ctx context.Context) error {
+		return tg.passPresaleGate(ctx, concert)
+	})
+	if err != nil {
+		return fmt.Errorf("预售验证失败: %w", err)
+	}
+
+	// 开售后最初的几秒内用预先编译的快速流程一次完成选座到确认购买，否则逐步执行
+	if tg.runFastPath(ctx, concert) {
+		tg.timeline.Mark(timeline.StageSeatSelected)
+	} else if err = tg.checkout(ctx, concert); err != nil {
+		return err
+	}
+	tg.timeline.Mark(timeline.StagePurchase)
+	release()
+
+	// 支付前记录订单，无通存款等没有在等待期间完成支付的订单也会收到付款提醒
+	// 此时占座计时已经开始，只读取一次，不等待订单号出现
+	tg.heldOrder = tg.captureOrder(ctx, concert, 0)
+
+	// 处理支付
+	tg.progress.SetDetail("等待支付")
+	err = tracing.Run(ctx, "payment", tg.handlePayment)
+	if err != nil {
+		return fmt.Errorf("处理支付失败: %w", err)
+	}
+	if tg.heldOrder == nil {
+		// 有的站点支付完成后才显示订单号，等待确认页加载
+		tg.heldOrder = tg.captureOrder(ctx, concert, waitBudget(tg.config.Ticketing.Waits.PageLoad, 10*time.Second))
+	}
+	tg.markOrderPaid()
+	tg.timeline.Mark(timeline.StageConfirmed)
+
+	log.Println("票务购买完成！")
+	return nil
+}
+
+// checkout 逐步执行选座、验证码、领取方式、购票限额检查和确认购买
+func (tg *TicketGrabber) checkout(ctx context.Context, concert *models.Concert) error {
+	// 选择座位
+	tg.progress.SetDetail("选座")
+	err := tracing.Run(ctx, "select_seats", func(ctx context.Context) error {
+		return tg.holdSeats(ctx, concert)
+	})
+	if err != nil {
+		return fmt.Errorf("选择座位失败: %w", err)
+	}
+	tg.timeline.Mark(timeline.StageSeatSelected)
+
+	// 选座后可能出现验证码
+	err = tracing.Run(ctx, "captcha", tg.solveCaptcha)
+	if err != nil {
+		return fmt.Errorf("处理验证码失败: %w", err)
+	}
+
+	// 结算页也可能要求同意条款
+	err = tracing.Run(ctx, "checkout_gates", tg.passGates)
+	if err != nil {
+		return fmt.Errorf("通过条款和确认页面失败: %w", err)
+	}
+
+	// 必须选择领取方式的网站在确认购买前选择
+	tg.progress.SetDetail("选择领取方式")
+	err = tracing.Run(ctx, "delivery", func(ctx context.Context) error {
+		return tg.chooseDelivery(ctx, concert)
+	})
+	if err != nil {
+		return fmt.Errorf("选择领取方式失败: %w", err)
+	}
+
+	// 确认购买前检查张数和金额限制
+	tg.progress.SetDetail("检查购票限额")
+	var spend int
+	err = tracing.Run(ctx, "guardrails", func(ctx context.Context) (err error) {
+		spend, err = tg.checkGuardrails(ctx, concert)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("购票限额检查未通过: %w", err)
+	}
+
+	// 确认购买
+	tg.progress.SetDetail("确认购买")
+	err = tracing.Run(ctx, "confirm", tg.confirmPurchase)
+	if err != nil {
+		runSpend.release(spend)
+		return fmt.Errorf("确认购买失败: %w", err)
+	}
+	return nil
+}
+
+// solveCaptcha 页面出现验证码时交给验证码中继求解并提交
+func (tg *TicketGrabber) solveCaptcha(ctx context.Context) error {
+	cfg := tg.config.Captcha
+	if cfg.ImageSelector == "" {
+		return nil
+	}
+
+	present, err := tg.browser.ElementExists(ctx, cfg.ImageSelector)
+	if err != nil || !present {
+		return nil
+	}
+
+	log.Println("检测到验证码")
+	if tg.solver == nil {
+		tg.notify(ctx, notify.Event{
+			Type:    notify.EventActionRequired,
+			Title:   "需要输入验证码",
+			Message: "请在浏览器中完成验证码",
+		})
+		return errs.New(errs.ErrCaptchaRequired, "solveCaptcha", "未配置验证码中继")
+	}
+
+	image, err := tg.browser.ElementScreenshot(ctx, cfg.ImageSelector)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	solveCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	answer, err := tg.solver.Solve(solveCtx, image)
+	if err != nil {
+		return errs.Wrap(errs.ErrCaptchaRequired, "solveCaptcha", err)
+	}
+	log.Printf("收到验证码答案 (%s)", tg.solver.Name())
+
+	err = tg.browser.FillForm(ctx, map[string]string{cfg.InputSelector: answer})
+	if err != nil {
+		return err
+	}
+
+	if cfg.SubmitSelector != "" {
+		_, err = tg.browser.ClickElement(ctx, cfg.SubmitSelector)
+		return err
+	}
+	return nil
+}
+
+// selectSeats 选择座位
+func (tg *TicketGrabber) selectSeats(ctx context.Context, concert *models.Concert) error {
+	log.Println("正在选择座位...")
+
+	if handled, err := tg.runStage(ctx, tg.site, StageSelectSeats); handled {
+		if err == nil {
+			tg.recordSelectedSeats(ctx)
+		}
+		return err
+	}
+
+	// 根据偏好选择座位
+	for _, preference := range concert.PreferredSeats {
+		chain := tg.selectors.Format(tg.site, selectors.SeatPreferred, preference)
+		if _, err := tg.clickChain(ctx, selectors.SeatPreferred, chain); err == nil {
+			log.Printf("已选择座位类型: %s", preference)
+			tg.recordSelectedSeats(ctx)
+			return nil
+		}
+	}
+
+	// 没有偏好座位时，已知场馆按区域评分选择
+	if tg.selectBestSection(ctx, concert) {
+		tg.recordSelectedSeats(ctx)
+		return nil
+	}
+
+	// 如果没有找到偏好座位，选择第一个可用座位
+	if _, err := tg.click(ctx, tg.site, selectors.SeatAvailable); err != nil {
+		return errs.New(errs.ErrElementNotFound, "selectSeats", "无法选择座位")
+	}
+
+	log.Println("座位选择完成")
+	tg.recordSelectedSeats(ctx)
+	return nil
+}
+
+// selectedSeatsScript 读取页面上已选中座位的名称
+const selectedSeatsScript = `Array.from(document.querySelectorAll(%s))
+	.map(e => e.getAttribute("data-seat-name") || e.getAttribute("title") || e.textContent.trim())
+	.filter(Boolean)`
+
+// recordSelectedSeats 记录选中的座位，读取失败时不影响购票
+func (tg *TicketGrabber) recordSelectedSeats(ctx context.Context) {
+	tg.selectedSeats = nil
+
+	chain := tg.selectors.Get(tg.site, selectors.SeatSelected)
+	query, _ := json.Marshal(strings.Join(chain, ", "))
+	result, err := tg.browser.ExecuteScript(ctx, fmt.Sprintf(selectedSeatsScript, query))
+	if err != nil {
+		log.Printf("读取已选座位失败: %v", err)
+		return
+	}
+
+	items, _ := result.([]interface{})
+	for _, item := range items {
+		if name, ok := item.(string); ok {
+			tg.selectedSeats = append(tg.selectedSeats, name)
+		}
+	}
+	log.Printf("已选座位: %v", tg.selectedSeats)
+}
+
+// confirmPurchase 确认购买
+func (tg *TicketGrabber) confirmPurchase(ctx context.Context) error {
+	log.Println("确认购买...")
+
+	if handled, err := tg.runStage(ctx, tg.site, StagePurchase); handled {
+		return err
+	}
+
+	// 点击购买按钮
+	if _, err := tg.click(ctx, tg.site, selectors.PurchaseConfirm); err != nil {
+		return errs.New(errs.ErrElementNotFound, "confirmPurchase", "无法找到购买按钮")
+	}
+
+	log.Println("购买按钮点击成功")
+	return nil
+}
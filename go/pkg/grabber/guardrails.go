@@ -0,0 +1,176 @@
+package grabber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/captcha"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/order"
+)
+
+// spendTracker 本次运行已确认购买的金额，守护模式下所有任务共用
+type spendTracker struct {
+	mu    sync.Mutex
+	total int
+}
+
+// runSpend 进程内累计的购票金额
+var runSpend spendTracker
+
+// reserve 累计金额不超过limit时记入amount，limit为0时不限制
+func (s *spendTracker) reserve(amount, limit int) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit > 0 && s.total+amount > limit {
+		return s.total, false
+	}
+	s.total += amount
+	return s.total, true
+}
+
+// release 购买没有完成时退回记入的金额
+func (s *spendTracker) release(amount int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total -= amount
+}
+
+// checkGuardrails 确认购买前按 tickets.guardrails 检查张数和金额，返回记入本次运行的金额，
+// 购买没有完成时由调用方退回；超出限额或确认被拒绝时返回ErrBudgetExceeded
+func (tg *TicketGrabber) checkGuardrails(ctx context.Context, concert *models.Concert) (int, error) {
+	g := tg.config.Tickets.Guardrails
+	if g == (models.Guardrails{}) {
+		return 0, nil
+	}
+
+	// 没有记录到选中的座位时无法检查张数，也无法按票价估算金额
+	count := len(tg.selectedSeats)
+	if count == 0 {
+		return 0, errs.New(errs.ErrBudgetExceeded, "guardrails", "无法确定购买的张数")
+	}
+	amount := tg.checkoutTotal(ctx)
+	if amount == 0 {
+		maxPrice := concert.MaxPrice
+		if maxPrice == 0 {
+			maxPrice = tg.config.Tickets.MaxPrice
+		}
+		amount = maxPrice * count
+		if amount > 0 {
+			log.Printf("没有读取到结账金额，按最高票价估算为 %d", amount)
+		}
+	}
+	if amount == 0 && (g.MaxSpendPerRun > 0 || g.MaxSpendPerDay > 0 || g.ConfirmAbove > 0) {
+		return 0, errs.New(errs.ErrBudgetExceeded, "guardrails", "无法确定结账金额")
+	}
+	log.Printf("购买前检查: %d 张, 金额 %d", count, amount)
+
+	if g.MaxTicketsPerConcert > 0 {
+		held := tg.concertTickets(concert)
+		if held+count > g.MaxTicketsPerConcert {
+			return 0, errs.New(errs.ErrBudgetExceeded, "guardrails",
+				fmt.Sprintf("已有 %d 张，本次 %d 张，超过每场上限 %d 张", held, count, g.MaxTicketsPerConcert))
+		}
+	}
+
+	if g.MaxSpendPerDay > 0 {
+		spent := tg.spentToday()
+		if spent+amount > g.MaxSpendPerDay {
+			return 0, errs.New(errs.ErrBudgetExceeded, "guardrails",
+				fmt.Sprintf("今天已购 %d，本次 %d，超过每日上限 %d", spent, amount, g.MaxSpendPerDay))
+		}
+	}
+
+	if g.ConfirmAbove > 0 && amount > g.ConfirmAbove {
+		if err := tg.confirmSpend(ctx, concert, count, amount); err != nil {
+			return 0, err
+		}
+	}
+
+	if total, ok := runSpend.reserve(amount, g.MaxSpendPerRun); !ok {
+		return 0, errs.New(errs.ErrBudgetExceeded, "guardrails",
+			fmt.Sprintf("本次运行已购 %d，本次 %d，超过上限 %d", total, amount, g.MaxSpendPerRun))
+	}
+	return amount, nil
+}
+
+// checkoutTotal 读取结账页上的合计金额，读取失败时返回0
+func (tg *TicketGrabber) checkoutTotal(ctx context.Context) int {
+	html, err := tg.browser.PageSource(ctx)
+	if err != nil {
+		return 0
+	}
+	total, err := order.ParseTotal([]byte(html), tg.config.Ticketing.Sites[tg.site].OrderPage)
+	if err != nil {
+		return 0
+	}
+	return total
+}
+
+// concertTickets 订单记录中同一演出已有的张数
+func (tg *TicketGrabber) concertTickets(concert *models.Concert) int {
+	if tg.orders == nil {
+		return 0
+	}
+	held := 0
+	for _, o := range tg.orders.List() {
+		if o.ConcertID == concert.ID {
+			held += max(len(o.Seats), 1)
+		}
+	}
+	return held
+}
+
+// spentToday 订单记录中今天(本机时区)购买的金额
+func (tg *TicketGrabber) spentToday() int {
+	if tg.orders == nil {
+		return 0
+	}
+	now := tg.clock.Now()
+	year, month, day := now.Date()
+	spent := 0
+	for _, o := range tg.orders.List() {
+		y, m, d := o.CapturedAt.In(now.Location()).Date()
+		if y == year && m == month && d == day {
+			spent += o.Total
+		}
+	}
+	return spent
+}
+
+// confirmSpend 在Telegram中询问是否购买，回复 YES 才继续
+func (tg *TicketGrabber) confirmSpend(ctx context.Context, concert *models.Concert, count, amount int) error {
+	cfg := tg.config.Notification.Telegram
+	if !cfg.Enabled || cfg.BotToken == "" || cfg.ChatID == "" {
+		return errs.New(errs.ErrBudgetExceeded, "guardrails",
+			fmt.Sprintf("金额 %d 超过 %d 需要确认，但没有配置Telegram", amount, tg.config.Tickets.Guardrails.ConfirmAbove))
+	}
+
+	timeout := waitBudget(tg.config.Tickets.Guardrails.ConfirmTimeout, 2*time.Minute)
+	askCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tg.progress.SetDetail("等待确认购买")
+	question := fmt.Sprintf("%s\n即将购买 %d 张，合计 %d，超过确认金额 %d。\n回复 YES 确认购买，其他回复或 %s 内没有回复将放弃。",
+		concert.Name, count, amount, tg.config.Tickets.Guardrails.ConfirmAbove, timeout)
+	log.Printf("金额 %d 超过确认金额，等待Telegram回复...", amount)
+
+	reply, err := captcha.NewTelegramRelay(cfg).Ask(askCtx, question)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		return errs.New(errs.ErrBudgetExceeded, "guardrails", "等待确认超时")
+	case err != nil:
+		return err
+	case !strings.EqualFold(strings.TrimSpace(reply), "YES"):
+		return errs.New(errs.ErrBudgetExceeded, "guardrails", "购买未被确认: "+reply)
+	}
+	log.Println("已确认购买")
+	return nil
+}
@@ -0,0 +1,106 @@
+package grabber
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tickgrabber/pkg/browser/fake"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+)
+
+func TestCheckGuardrails(t *testing.T) {
+	const checkout = `<div class="total-price">150,000원</div>`
+	tests := []struct {
+		name       string
+		guardrails models.Guardrails
+		seats      []string
+		html       string
+		maxPrice   int
+		want       int
+		wantErr    bool
+	}{
+		{
+			name:       "没有限制",
+			guardrails: models.Guardrails{},
+			html:       checkout,
+		},
+		{
+			name:       "没有选中的座位",
+			guardrails: models.Guardrails{MaxSpendPerRun: 500000},
+			html:       checkout,
+			wantErr:    true,
+		},
+		{
+			name:       "无法确定金额",
+			guardrails: models.Guardrails{MaxSpendPerRun: 500000},
+			seats:      []string{"A-1"},
+			wantErr:    true,
+		},
+		{
+			name:       "超过本次运行上限",
+			guardrails: models.Guardrails{MaxSpendPerRun: 100000},
+			seats:      []string{"A-1", "A-2"},
+			html:       checkout,
+			wantErr:    true,
+		},
+		{
+			name:       "超过每场张数上限",
+			guardrails: models.Guardrails{MaxTicketsPerConcert: 1},
+			seats:      []string{"A-1", "A-2"},
+			html:       checkout,
+			wantErr:    true,
+		},
+		{
+			name:       "超过确认金额但没有配置Telegram",
+			guardrails: models.Guardrails{ConfirmAbove: 100000},
+			seats:      []string{"A-1"},
+			html:       checkout,
+			wantErr:    true,
+		},
+		{
+			name:       "读取结账金额",
+			guardrails: models.Guardrails{MaxSpendPerRun: 200000},
+			seats:      []string{"A-1", "A-2"},
+			html:       checkout,
+			want:       150000,
+		},
+		{
+			name:       "按最高票价估算",
+			guardrails: models.Guardrails{MaxSpendPerRun: 300000},
+			seats:      []string{"A-1", "A-2"},
+			maxPrice:   120000,
+			want:       240000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSpend = spendTracker{}
+			config := &models.Config{}
+			config.Tickets.Guardrails = tt.guardrails
+			tg := &TicketGrabber{
+				config:        config,
+				browser:       fake.New().SetHTML(tt.html),
+				selectedSeats: tt.seats,
+			}
+
+			got, err := tg.checkGuardrails(context.Background(), &models.Concert{ID: "c1", MaxPrice: tt.maxPrice})
+			if tt.wantErr {
+				if !errors.Is(err, errs.ErrBudgetExceeded) {
+					t.Fatalf("应返回 ErrBudgetExceeded，得到 %v", err)
+				}
+				if runSpend.total != 0 {
+					t.Errorf("被拒绝时不应记入金额，已记入 %d", runSpend.total)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || runSpend.total != tt.want {
+				t.Errorf("记入金额 %d (累计 %d)，期望 %d", got, runSpend.total, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/leader"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/shared"
+)
+
+// startElection 按配置参加该演出的主备选举，没有配置时本实例始终负责购买
+func (tg *TicketGrabber) startElection(ctx context.Context, concert *models.Concert) error {
+	cfg := tg.config.Leader
+	id, _, _ := strings.Cut(concert.ID, "@")
+
+	var lock leader.Lock
+	switch cfg.Backend {
+	case "":
+		return nil
+	case "file":
+		lock = leader.NewFileLock(filepath.Join(cfg.Path, id+".lease"))
+	case "redis":
+		redis, ok := tg.shared.(*shared.Redis)
+		if !ok {
+			return fmt.Errorf("redis选举需要配置 shared_state.redis")
+		}
+		lock = leader.NewRedisLock(redis, "leader:"+id)
+	default:
+		return fmt.Errorf("未知的选举方式: %s", cfg.Backend)
+	}
+
+	tg.elector = leader.NewElector(lock, concert.ID, "", time.Duration(cfg.TTL*float64(time.Second)))
+	go tg.elector.Run(ctx)
+	log.Printf("已参加主备选举(%s)", cfg.Backend)
+	return nil
+}
+
+// standby 本实例是否为热备实例(不执行购买)
+func (tg *TicketGrabber) standby() bool {
+	return tg.elector != nil && !tg.elector.IsLeader()
+}
@@ -0,0 +1,48 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"tickgrabber/pkg/api"
+)
+
+// sourceMobile 通过移动网页版接口监控余票，见 models.SiteConfig.AvailabilitySource
+const sourceMobile = "mobile"
+
+// checkMobileAvailability 通过移动网页版接口检查余票，返回是否有票和接口是否可用
+// 演唱会配置了preferred_seats时只统计这些等级；接口不可用时由调用方检查页面
+func (tg *TicketGrabber) checkMobileAvailability(ctx context.Context) (available bool, ok bool) {
+	site := tg.config.Ticketing.Sites[tg.site]
+	vars := tg.stageVars(tg.site)
+	vars["goods_code"] = api.GoodsCode(tg.concert.URL)
+
+	client := &api.InterParkClient{Client: tg.apiClient}
+	remain, err := client.MobileAvailability(ctx, site.MobileAPI, vars)
+	if err != nil {
+		log.Printf("移动版余票查询失败，检查页面: %v", err)
+		return false, false
+	}
+
+	for grade, n := range remain {
+		if n > 0 && preferredGrade(grade, tg.concert.PreferredSeats) {
+			log.Printf("移动版接口显示 %s 有 %d 张余票", grade, n)
+			available = true
+		}
+	}
+	return available, true
+}
+
+// preferredGrade 判断座位等级是否在偏好中，没有偏好时接受所有等级
+func preferredGrade(grade string, preferred []string) bool {
+	if len(preferred) == 0 {
+		return true
+	}
+	for _, p := range preferred {
+		if strings.Contains(strings.ToUpper(grade), strings.ToUpper(p)) {
+			return true
+		}
+	}
+	return false
+}
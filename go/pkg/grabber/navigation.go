@@ -0,0 +1,77 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/navwatch"
+	"tickgrabber/pkg/notify"
+)
+
+// 遇到维护公告时等待的时间，连续遇到时加倍
+const (
+	maintenanceBackoff    = time.Minute
+	maxMaintenanceBackoff = 10 * time.Minute
+)
+
+// pageTextScript 读取页面标题和正文开头，用于判断跳转到的页面
+const pageTextScript = `(document.title || "") + "\n" + (document.body ? document.body.innerText.slice(0, 1000) : "")`
+
+// checkNavigation 检查页面是否离开了演出页面，按跳转到的位置恢复：登录页重新登录，
+// 错误页和其他页面重新进入演出页面，维护公告等待一段时间后重新进入，排队页面不处理
+// 返回true表示本次检查已做过恢复，调用方应跳过本轮的余票检查；返回非nil错误表示放弃
+func (tg *TicketGrabber) checkNavigation(ctx context.Context, concert *models.Concert) (bool, error) {
+	current, err := tg.browser.GetCurrentURL(ctx)
+	if err != nil || current == "" {
+		return false, nil
+	}
+	expected := tg.concertPage
+	if expected == "" {
+		expected = concert.URL
+	}
+	if navwatch.SamePage(current, expected) {
+		tg.maintenanceWait = 0
+		return false, nil
+	}
+
+	var text string
+	if v, err := tg.browser.ExecuteScript(ctx, pageTextScript); err == nil {
+		text, _ = v.(string)
+	}
+	kind := navwatch.Classify(current, expected, tg.config.Ticketing.Sites[tg.site], text)
+	switch kind {
+	case navwatch.Expected, navwatch.Waiting:
+		return false, nil
+	}
+	log.Printf("页面已跳转到%s: %s", kind, current)
+
+	switch kind {
+	case navwatch.Login:
+		err := errs.New(errs.ErrSessionExpired, "navigation", current)
+		return true, tg.handleFailure(ctx, concert, err)
+	case navwatch.Maintenance:
+		tg.maintenanceWait = min(max(tg.maintenanceWait*2, maintenanceBackoff), maxMaintenanceBackoff)
+		if tg.maintenanceWait == maintenanceBackoff {
+			tg.notify(ctx, notify.Event{
+				Type:    notify.EventActionRequired,
+				Title:   "售票网站维护中",
+				Message: fmt.Sprintf("页面显示维护公告，%v 后重试", tg.maintenanceWait),
+				Concert: concert.Name,
+				URL:     current,
+			})
+		}
+		log.Printf("网站维护中，%v 后重新进入演出页面", tg.maintenanceWait)
+		if err := clock.Sleep(ctx, tg.clock, tg.maintenanceWait); err != nil {
+			return true, nil
+		}
+	}
+	if err := tg.navigateToConcert(ctx, concert); err != nil {
+		log.Printf("重新进入演唱会页面失败: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,297 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"time"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/gateway"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/selectors"
+)
+
+// paymentBookingsInterval 等待支付期间查询订单列表的间隔，页面和地址每秒检查一次
+const paymentBookingsInterval = 30 * time.Second
+
+// handlePayment 等待用户在浏览器中完成支付
+// 页面出现支付成功提示、浏览器跳转到 payment_complete_url 或订单列表显示已付款时视为完成，
+// 等待期间按 waits.payment_reminder 重复提醒
+func (tg *TicketGrabber) handlePayment(ctx context.Context) error {
+	log.Println("处理支付...")
+
+	// 等待支付页面加载完成，已经完成时立即继续
+	tg.waitUntil(ctx, waitBudget(tg.config.Ticketing.Waits.PaymentPage, 10*time.Second), pollInterval, tg.pageReady)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// 这里可以添加自动支付逻辑
+	// 目前只是等待用户手动完成支付
+	log.Println("请在浏览器中手动完成支付...")
+	tg.notify(ctx, notify.Event{
+		Type:    notify.EventActionRequired,
+		Title:   "请完成支付",
+		Message: "座位已锁定，请在浏览器中手动完成支付",
+	})
+
+	waits := tg.config.Ticketing.Waits
+	deadline := tg.clock.Now().Add(waitBudget(waits.Payment, 30*time.Minute))
+	remind := waitBudget(waits.PaymentReminder, 5*time.Minute)
+	tg.progress.SetCountdown("支付剩余", deadline)
+
+	var complete *regexp.Regexp
+	if pattern := tg.config.Ticketing.Sites[tg.site].PaymentCompleteURL; pattern != "" {
+		var err error
+		if complete, err = regexp.Compile(pattern); err != nil {
+			log.Printf("payment_complete_url 无效，不按地址判断: %v", err)
+		}
+	}
+
+	if err := tg.loadCard(); err != nil {
+		log.Printf("不自动填写银行卡: %v", err)
+	}
+
+	// 没有配置订单列表时只按页面和地址判断
+	hasBookings := tg.config.Ticketing.Sites[tg.site].BookingsURL != ""
+	if !hasBookings {
+		log.Printf("站点 %s 未配置 bookings_url，不通过订单列表确认支付", tg.site)
+	}
+	nextBookings := tg.clock.Now().Add(paymentBookingsInterval)
+	nextReminder := tg.clock.Now().Add(remind)
+	windows := make(map[string]*gatewayWindow)
+	paid := tg.waitUntil(ctx, deadline.Sub(tg.clock.Now()), time.Second, func(ctx context.Context) bool {
+		now := tg.clock.Now()
+		// 支付窗口关闭后立即查询订单列表，不等下一个查询周期
+		closed := tg.watchGateways(ctx, windows)
+		checkBookings := hasBookings && (closed || !now.Before(nextBookings))
+		if checkBookings {
+			nextBookings = now.Add(paymentBookingsInterval)
+		}
+		if how, ok := tg.paymentCompleted(ctx, complete, checkBookings); ok {
+			log.Printf("支付成功！(%s)", how)
+			return true
+		}
+
+		if !now.Before(nextReminder) {
+			nextReminder = now.Add(remind)
+			tg.notify(ctx, notify.Event{
+				Type:     notify.EventPaymentReminder,
+				Severity: notify.SeverityWarning,
+				Title:    "请尽快完成支付",
+				Message:  fmt.Sprintf("还没有检测到支付完成，%s 后停止等待", deadline.Sub(now).Round(time.Minute)),
+			})
+		}
+		return false
+	})
+	if paid {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return errs.New(errs.ErrPaymentTimeout, "handlePayment", "")
+}
+
+// paymentCompleted 检查支付是否完成，返回判断的依据；checkBookings 为true时同时查询站点订单列表
+func (tg *TicketGrabber) paymentCompleted(ctx context.Context, complete *regexp.Regexp, checkBookings bool) (string, bool) {
+	if _, found := tg.find(ctx, tg.selectors.Get(tg.site, selectors.PaymentSuccess)); found {
+		return "页面显示支付成功", true
+	}
+
+	if complete != nil {
+		if url, err := tg.browser.GetCurrentURL(ctx); err == nil && complete.MatchString(url) {
+			return "跳转到支付完成页面", true
+		}
+	}
+
+	// 只认占座时记录的订单号，同一演出的其他订单(如之前已付款的订单)不能算作本次支付完成
+	if checkBookings && tg.heldOrder != nil && tg.heldOrder.ID != "" {
+		bookings, err := tg.apiClient.GetBookings(ctx, tg.site)
+		if err != nil {
+			log.Printf("查询订单列表失败: %v", err)
+		}
+		for _, b := range bookings {
+			if b.Paid() && b.OrderID == tg.heldOrder.ID {
+				return "订单列表显示已付款 " + b.OrderID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// gatewayMaxSteps 自动发放虚拟账户最多执行的步骤数，超过后交给用户处理
+const gatewayMaxSteps = 20
+
+// gatewayWindow 支付期间打开的外部支付窗口
+type gatewayWindow struct {
+	name string
+	url  string
+	// popup 为弹出窗口的ID，以页内框架方式打开时为空
+	popup string
+	steps int
+	// done 自动操作已结束(已申请发放、已填写银行卡、超过步骤数或未开启)
+	done bool
+}
+
+// watchGateways 检查支付网关的弹出窗口和页内框架：新打开的窗口发送通知，开启 payment.virtual_account 时自动发放虚拟账户，
+// 返回是否有窗口刚刚关闭
+func (tg *TicketGrabber) watchGateways(ctx context.Context, windows map[string]*gatewayWindow) bool {
+	open := make(map[string]*gatewayWindow)
+	if popups, err := tg.browser.Popups(ctx); err == nil {
+		for _, p := range popups {
+			// 结账期间打开的弹出窗口都按支付窗口处理，未知网关只是没有名称
+			name, ok := gateway.Detect(p.URL)
+			if !ok {
+				name = "支付窗口"
+			}
+			open[p.ID] = &gatewayWindow{name: name, url: p.URL, popup: p.ID}
+		}
+	}
+	if result, err := tg.browser.ExecuteScript(ctx, gateway.FramesScript); err == nil {
+		frames, _ := result.([]interface{})
+		for _, f := range frames {
+			src, _ := f.(string)
+			if name, ok := gateway.Detect(src); ok {
+				open[src] = &gatewayWindow{name: name, url: src}
+			}
+		}
+	}
+
+	for key, w := range open {
+		if _, seen := windows[key]; seen {
+			continue
+		}
+		windows[key] = w
+		w.done = !tg.config.Ticketing.Payment.VirtualAccount && tg.card == nil
+		log.Printf("检测到支付窗口: %s %s", w.name, w.url)
+		tg.notifyGateway(ctx, w)
+	}
+
+	closed := false
+	for key, w := range windows {
+		if _, ok := open[key]; !ok {
+			log.Printf("%s 已关闭，继续确认支付结果", w.name)
+			delete(windows, key)
+			closed = true
+			continue
+		}
+		if tg.config.Ticketing.Payment.VirtualAccount {
+			tg.issueVirtualAccount(ctx, w)
+		} else {
+			tg.fillCard(ctx, w)
+		}
+	}
+	return closed
+}
+
+// notifyGateway 通知用户在支付窗口中完成支付，附窗口截图
+func (tg *TicketGrabber) notifyGateway(ctx context.Context, w *gatewayWindow) {
+	event := notify.Event{
+		Type:    notify.EventActionRequired,
+		Title:   "请在支付窗口中完成支付",
+		Message: fmt.Sprintf("结账已跳转到 %s，请在浏览器中完成支付", w.name),
+		URL:     w.url,
+	}
+	if tg.concert != nil {
+		event.Concert = tg.concert.Name
+	}
+	switch {
+	case w.done:
+	case tg.config.Ticketing.Payment.VirtualAccount:
+		event.Message = fmt.Sprintf("结账已跳转到 %s，正在自动申请发放虚拟账户", w.name)
+	default:
+		event.Message = fmt.Sprintf("结账已跳转到 %s，将自动填写银行卡，请核对后确认付款", w.name)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if w.popup != "" {
+		data, err = tg.browser.PopupScreenshot(ctx, w.popup)
+	} else if host := hostOf(w.url); host != "" {
+		data, err = tg.browser.ElementScreenshot(ctx, fmt.Sprintf("iframe[src*='%s']", host))
+	}
+	if err == nil && len(data) > 0 {
+		event.Attachments = append(event.Attachments, notify.Attachment{
+			Filename:    fmt.Sprintf("payment_%s.png", tg.clock.Now().Format("20060102_150405")),
+			ContentType: "image/png",
+			Data:        data,
+		})
+	}
+	tg.notify(ctx, event)
+}
+
+// issueVirtualAccount 在支付窗口中执行一步虚拟账户发放
+// 页内框架通常与结账页不同源，无法执行脚本，只能由用户操作
+func (tg *TicketGrabber) issueVirtualAccount(ctx context.Context, w *gatewayWindow) {
+	if w.done {
+		return
+	}
+	if w.popup == "" {
+		log.Printf("%s 以页内框架打开，无法自动发放虚拟账户，请手动完成", w.name)
+		w.done = true
+		return
+	}
+
+	result, err := tg.browser.PopupScript(ctx, w.popup, gateway.VirtualAccountScript(tg.config.Ticketing.Payment.Bank))
+	if err != nil {
+		return
+	}
+	step, _ := result.(string)
+	if step == "" {
+		return
+	}
+	w.steps++
+	log.Printf("%s: 虚拟账户发放 %s", w.name, step)
+
+	switch {
+	case step == gateway.StepSubmit:
+		log.Printf("已在 %s 申请发放虚拟账户，请按页面显示的账户入金", w.name)
+		w.done = true
+	case w.steps >= gatewayMaxSteps:
+		log.Printf("%s 自动发放虚拟账户未完成，请在窗口中手动完成", w.name)
+		w.done = true
+	}
+}
+
+// fillCard 在支付窗口中填写银行卡，窗口中出现卡号字段前每秒重试
+func (tg *TicketGrabber) fillCard(ctx context.Context, w *gatewayWindow) {
+	if w.done || tg.card == nil {
+		return
+	}
+	if w.popup == "" {
+		log.Printf("%s 以页内框架打开，无法自动填写银行卡，请手动完成", w.name)
+		w.done = true
+		return
+	}
+
+	result, err := tg.browser.PopupScript(ctx, w.popup, gateway.CardScript(tg.card, tg.config.Ticketing.Payment.Card.Installment))
+	if err != nil {
+		return
+	}
+	if filled, _ := result.(bool); filled {
+		log.Printf("已在 %s 填写银行卡，请核对后确认付款", w.name)
+		w.done = true
+		return
+	}
+	w.steps++
+	if w.steps >= gatewayMaxSteps {
+		log.Printf("%s 中没有可填写的卡号字段，请手动完成", w.name)
+		w.done = true
+	}
+}
+
+// hostOf 返回地址的主机名
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
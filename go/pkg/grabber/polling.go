@@ -0,0 +1,66 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/poll"
+)
+
+// defaultMaxPoll 自适应轮询没有配置max_interval时的最长间隔
+const defaultMaxPoll = 30 * time.Second
+
+// newPoller 创建演唱会的自适应轮询间隔，没有启用时返回nil(固定使用refresh_interval)
+func (tg *TicketGrabber) newPoller(concert *models.Concert, base time.Duration) *poll.Adaptive {
+	cfg := tg.config.Ticketing.AdaptivePolling
+	if !cfg.Enabled {
+		return nil
+	}
+	min := time.Duration(cfg.MinInterval * float64(time.Second))
+	max := waitBudget(cfg.MaxInterval, defaultMaxPoll)
+	return poll.New(base, min, max, concert.SaleOpenTime)
+}
+
+// observePoll 把本次检查的结果和服务器提示交给自适应轮询，更新下一次检查之后使用的间隔
+func (tg *TicketGrabber) observePoll(ctx context.Context, poller *poll.Adaptive, concert *models.Concert, available bool) {
+	if poller == nil {
+		return
+	}
+
+	now := tg.clock.Now()
+	poller.Observe(now, pollFingerprint(available, tg.pageGrades(ctx)))
+	if u, err := url.Parse(concert.URL); err == nil {
+		if hint, ok := tg.apiClient.Hints().Get(u.Hostname()); ok {
+			poller.Hint(hint.RetryAfter, hint.MaxAge)
+		}
+	}
+
+	next := poller.Next(now)
+	if next != tg.pollInterval {
+		log.Printf("轮询间隔调整为 %v", next)
+		tg.pollInterval = next
+	}
+}
+
+// pollFingerprint 由余票状态和各等级的可选座位数生成页面指纹
+// 页面中每次请求都会变化的随机数、CSRF令牌和时间戳不计入，否则每次检查都算作页面变化
+func pollFingerprint(available bool, grades map[string]int) string {
+	names := make([]string, 0, len(grades))
+	for name := range grades {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{strconv.FormatBool(available)}
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, grades[name]))
+	}
+	return strings.Join(parts, ",")
+}
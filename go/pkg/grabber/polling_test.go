@@ -0,0 +1,28 @@
+package grabber
+
+import "testing"
+
+func TestPollFingerprint(t *testing.T) {
+	base := pollFingerprint(true, map[string]int{"R": 3, "S": 10})
+
+	tests := []struct {
+		name      string
+		available bool
+		grades    map[string]int
+		same      bool
+	}{
+		{"等级顺序不同", true, map[string]int{"S": 10, "R": 3}, true},
+		{"余票状态变化", false, map[string]int{"R": 3, "S": 10}, false},
+		{"座位数变化", true, map[string]int{"R": 2, "S": 10}, false},
+		{"等级变化", true, map[string]int{"R": 3}, false},
+	}
+	for _, tt := range tests {
+		if got := pollFingerprint(tt.available, tt.grades); (got == base) != tt.same {
+			t.Errorf("%s: 指纹 %q 与 %q 相同 = %v，期望 %v", tt.name, got, base, got == base, tt.same)
+		}
+	}
+
+	if pollFingerprint(false, nil) != pollFingerprint(false, map[string]int{}) {
+		t.Error("没有座位图时指纹应只取决于余票状态")
+	}
+}
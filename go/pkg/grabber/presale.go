@@ -0,0 +1,88 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/selectors"
+)
+
+// presaleResultTimeout 提交会员验证后等待结果的时间
+const presaleResultTimeout = 5 * time.Second
+
+// passPresaleGate 选座前出现预售会员验证时，按演唱会的presale配置完成验证
+// 页面没有验证时直接返回；验证被拒绝时返回ErrPresaleRejected，重试也不会通过
+func (tg *TicketGrabber) passPresaleGate(ctx context.Context, concert *models.Concert) error {
+	if handled, err := tg.runStage(ctx, tg.site, StagePresale); handled {
+		return err
+	}
+	if _, gate := tg.find(ctx, tg.selectors.Get(tg.site, selectors.PresaleGate)); !gate {
+		return nil
+	}
+	log.Println("检测到预售会员验证")
+
+	cfg := concert.Presale
+	if cfg.Code == "" && cfg.Membership == "" && len(cfg.Fields) == 0 {
+		tg.notify(ctx, notify.Event{
+			Type:    notify.EventActionRequired,
+			Title:   "需要预售会员验证",
+			Message: "演唱会未配置presale，请在浏览器中完成验证",
+			Concert: concert.Name,
+			URL:     concert.URL,
+		})
+		return errs.New(errs.ErrPresaleRejected, "presale", "未配置会员验证信息")
+	}
+
+	if cfg.Membership != "" {
+		chain := tg.selectors.Format(tg.site, selectors.PresaleMembership, cfg.Membership)
+		if _, err := tg.clickChain(ctx, selectors.PresaleMembership, chain); err != nil {
+			return errs.New(errs.ErrPresaleRejected, "presale", "找不到会员类型: "+cfg.Membership)
+		}
+	}
+
+	fields := make(map[string]string, len(cfg.Fields)+1)
+	for selector, value := range cfg.Fields {
+		fields[selector] = value
+	}
+	if cfg.Code != "" {
+		fields[tg.resolve(ctx, tg.selectors.Get(tg.site, selectors.PresaleCode))] = cfg.Code
+	}
+	if len(fields) > 0 {
+		if err := tg.browser.FillForm(ctx, fields); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tg.click(ctx, tg.site, selectors.PresaleSubmit); err != nil {
+		return err
+	}
+	return tg.waitPresaleResult(ctx)
+}
+
+// waitPresaleResult 等待验证框消失，出现错误提示时返回ErrPresaleRejected
+func (tg *TicketGrabber) waitPresaleResult(ctx context.Context) error {
+	deadline := time.Now().Add(presaleResultTimeout)
+	for {
+		if selector, failed := tg.find(ctx, tg.selectors.Get(tg.site, selectors.PresaleError)); failed {
+			message, _ := tg.browser.GetText(ctx, selector)
+			return errs.New(errs.ErrPresaleRejected, "presale", message)
+		}
+		if _, gate := tg.find(ctx, tg.selectors.Get(tg.site, selectors.PresaleGate)); !gate {
+			log.Println("预售会员验证通过")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errs.New(errs.ErrPresaleRejected, "presale", "提交后验证框仍未关闭")
+		}
+
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
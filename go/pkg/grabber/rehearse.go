@@ -0,0 +1,47 @@
+package grabber
+
+import (
+	"context"
+	"fmt"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// Rehearsal 接收演练各步骤的结果
+type Rehearsal interface {
+	// Step 一个步骤结束，err为nil表示通过
+	Step(name string, err error, detail string)
+	// Scores 演出场馆中页面上可选座位的评分
+	Scores(venue string, scores []SectionScore)
+}
+
+// Rehearse 在浏览器当前打开的演出页面(通常是保存的快照)上运行检测余票、确认页面、预售验证、选座和领取方式的逻辑，
+// 最后只定位购买按钮而不点击，每个步骤的结果交给report
+func (tg *TicketGrabber) Rehearse(ctx context.Context, concert *models.Concert, report Rehearsal) {
+	tg.site = DetectSite(tg.config, concert)
+	tg.concert = concert
+
+	if selector, ok := tg.find(ctx, tg.selectors.Get(tg.site, selectors.TicketAvailable)); ok {
+		report.Step("余票检测", nil, selector)
+	} else {
+		report.Step("余票检测", fmt.Errorf("%s 的选择器都没有匹配", selectors.TicketAvailable), "")
+	}
+
+	report.Step("确认页面", tg.passGates(ctx), "")
+	report.Step("预售验证", tg.passPresaleGate(ctx, concert), "")
+
+	if v, scores := tg.scoreSections(ctx, concert); v != nil {
+		report.Scores(v.Name, scores)
+	}
+	err := tg.selectSeats(ctx, concert)
+	report.Step("选座", err, fmt.Sprintf("已选座位: %v", tg.selectedSeats))
+
+	report.Step("领取方式", tg.chooseDelivery(ctx, concert), "")
+
+	if selector, ok := tg.find(ctx, tg.selectors.Get(tg.site, selectors.PurchaseConfirm)); ok {
+		report.Step("购买按钮", nil, selector+" (未点击)")
+	} else {
+		report.Step("购买按钮", fmt.Errorf("%s 的选择器都没有匹配", selectors.PurchaseConfirm), "")
+	}
+}
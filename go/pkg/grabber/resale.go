@@ -0,0 +1,31 @@
+package grabber
+
+import (
+	"context"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/resale"
+)
+
+// WatchResale 演唱会配置了转让页面时在后台跟踪挂单价格，直到ctx取消
+func WatchResale(ctx context.Context, config *models.Config, client *api.Client, notifier *notify.Manager, concert *models.Concert) {
+	if concert.Resale == nil || concert.Resale.URL == "" {
+		return
+	}
+
+	maxPrice := concert.MaxPrice
+	if maxPrice == 0 {
+		maxPrice = config.Tickets.MaxPrice
+	}
+	site := SiteForURL(config, concert.Resale.URL)
+	if site == "" {
+		site = concert.Site
+	}
+	go resale.Watch(ctx, client, notifier, resale.Target{
+		Concert:  *concert,
+		Board:    config.Ticketing.Sites[site].ResaleBoard,
+		MaxPrice: maxPrice,
+	})
+}
@@ -0,0 +1,133 @@
+package grabber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/errs"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/order"
+)
+
+// 前台抢票的进程退出码，供包装脚本和调度器判断结果
+const (
+	ExitSuccess        = 0 // 购票成功
+	ExitError          = 1 // 其他错误(配置错误、浏览器启动失败等)
+	ExitSoldOut        = 2 // 票已售罄
+	ExitLoginFailed    = 3 // 登录失败
+	ExitCaptcha        = 4 // 验证码未能解决
+	ExitPaymentTimeout = 5 // 支付超时
+	ExitStopped        = 6 // 没有购票就停止(收到退出信号、其他网站或实例已购买)
+)
+
+// outcomes 失败原因对应的结果和退出码，按顺序匹配
+var outcomes = []struct {
+	kind    error
+	outcome string
+	code    int
+}{
+	{errs.ErrSoldOut, "sold_out", ExitSoldOut},
+	{errs.ErrLoginFailed, "login_failed", ExitLoginFailed},
+	{errs.ErrCaptchaRequired, "captcha_unsolved", ExitCaptcha},
+	{errs.ErrPaymentTimeout, "payment_timeout", ExitPaymentTimeout},
+	{errs.ErrAppOnly, "app_only", ExitError},
+}
+
+// Result 一次抢票的结果，可写出为JSON供包装脚本和调度器判断
+// Outcome 为 success、sold_out、login_failed、captcha_unsolved、payment_timeout、stopped 或 error
+type Result struct {
+	Outcome    string    `json:"outcome"`
+	ExitCode   int       `json:"exit_code"`
+	ConcertID  string    `json:"concert_id"`
+	Site       string    `json:"site,omitempty"`
+	OrderID    string    `json:"order_id,omitempty"`
+	Seats      []string  `json:"seats,omitempty"`
+	Total      int       `json:"total,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	mu        sync.Mutex
+	purchased bool
+}
+
+// NewResult 开始记录一次抢票的结果
+func NewResult(concert *models.Concert) *Result {
+	return &Result{ConcertID: concert.ID, StartedAt: time.Now()}
+}
+
+// recordPurchase 记录购票成功，o为nil时(没能读取订单信息)只记录站点，r为nil时不记录
+func (r *Result) recordPurchase(site string, o *order.Order) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.purchased = true
+	r.Site = site
+	if o != nil {
+		r.OrderID = o.ID
+		r.Seats = o.Seats
+		r.Total = o.Total
+	}
+}
+
+// Finish 根据抢票返回的错误确定结果和退出码
+func (r *Result) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = time.Now()
+	switch {
+	case err == nil && r.purchased:
+		r.Outcome, r.ExitCode = "success", ExitSuccess
+		return
+	case err == nil:
+		r.Outcome, r.ExitCode = "stopped", ExitStopped
+		return
+	}
+
+	r.Error = err.Error()
+	r.Outcome, r.ExitCode = "error", ExitError
+	for _, o := range outcomes {
+		if errors.Is(err, o.kind) {
+			r.Outcome, r.ExitCode = o.outcome, o.code
+			return
+		}
+	}
+}
+
+// Write 写入结果文件
+func (r *Result) Write(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// resultKey 在ctx中保存结果的键
+type resultKey struct{}
+
+// WithResult 返回带有结果记录的ctx，抢票器购票成功时记录到其中
+func WithResult(ctx context.Context, r *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, r)
+}
+
+// ResultFrom 取出ctx中的结果记录，没有时返回nil
+func ResultFrom(ctx context.Context) *Result {
+	r, _ := ctx.Value(resultKey{}).(*Result)
+	return r
+}
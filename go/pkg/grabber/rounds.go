@@ -0,0 +1,64 @@
+package grabber
+
+import (
+	"context"
+	"log"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// checkRounds 检查是否有票，演唱会配置了多个场次时按优先级依次选择场次并检查
+// 找到有票的场次后停留在该场次，tg.round 记录选中的场次
+func (tg *TicketGrabber) checkRounds(ctx context.Context, concert *models.Concert) (bool, error) {
+	if len(concert.Rounds) == 0 {
+		return tg.checkTicketAvailability(ctx)
+	}
+
+	for i := range concert.Rounds {
+		round := &concert.Rounds[i]
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !tg.selectRound(ctx, round) {
+			log.Printf("页面上找不到场次 %s", round)
+			continue
+		}
+
+		available, err := tg.checkTicketAvailability(ctx)
+		if err != nil {
+			return false, err
+		}
+		if available {
+			log.Printf("场次 %s 有票", round)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// selectRound 在演出页面上选择场次，返回是否选择成功
+// 站点有 select_round 流程或适配器时由它们选择，变量中包含 round_date、round_time 和 round_label
+func (tg *TicketGrabber) selectRound(ctx context.Context, round *models.Round) bool {
+	tg.round = round
+	if handled, err := tg.runStage(ctx, tg.site, StageSelectRound); handled {
+		return err == nil
+	}
+
+	if round.Label != "" {
+		chain := tg.selectors.Format(tg.site, selectors.RoundLabel, round.Label)
+		_, err := tg.clickChain(ctx, selectors.RoundLabel, chain)
+		return err == nil
+	}
+
+	chain := tg.selectors.Format(tg.site, selectors.RoundDate, round.Date)
+	if _, err := tg.clickChain(ctx, selectors.RoundDate, chain); err != nil {
+		return false
+	}
+	if round.Time == "" {
+		return true
+	}
+	chain = tg.selectors.Format(tg.site, selectors.RoundTime, round.Time)
+	_, err := tg.clickChain(ctx, selectors.RoundTime, chain)
+	return err == nil
+}
@@ -0,0 +1,45 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/saleopen"
+)
+
+// saleOpenTolerance 页面公告与配置的开售时间相差超过该值时警告
+const saleOpenTolerance = time.Minute
+
+// checkSaleOpenTime 读取演出页面上公告的开售时间(티켓오픈)
+// 没有配置 sale_open_time 时使用公告的时间，与配置不一致时警告；配置了先行预售验证时优先使用先行预售的时间
+func (tg *TicketGrabber) checkSaleOpenTime(ctx context.Context, concert *models.Concert) {
+	html, err := tg.browser.PageSource(ctx)
+	if err != nil {
+		log.Printf("读取开售公告失败: %v", err)
+		return
+	}
+
+	now := tg.clock.Now()
+	presale := concert.Presale.Code != "" || concert.Presale.Membership != "" || len(concert.Presale.Fields) > 0
+	announced, ok := saleopen.Pick(saleopen.FromHTML(html, now), presale, now)
+	if !ok {
+		return
+	}
+
+	configured := concert.SaleOpenTime
+	diff := announced.Time.Sub(configured)
+	if diff < 0 {
+		diff = -diff
+	}
+	switch {
+	case configured.IsZero():
+		log.Printf("[%s] 页面公告 %s %s，作为开售时间", concert.ID, announced.Label, announced.Time.Format("2006-01-02 15:04 MST"))
+		concert.SaleOpenTime = announced.Time
+		tg.timeline.SetSaleOpenTime(announced.Time)
+	case diff > saleOpenTolerance:
+		log.Printf("[%s] 警告: 页面公告 %s %s，与配置的开售时间 %s 不一致，请确认 sale_open_time",
+			concert.ID, announced.Label, announced.Time.Format("2006-01-02 15:04 MST"), configured.In(announced.Time.Location()).Format("2006-01-02 15:04 MST"))
+	}
+}
@@ -0,0 +1,83 @@
+package grabber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+	"tickgrabber/pkg/venue"
+)
+
+// availableSectionsScript 标记页面上的可选座位并返回每个座位所在区域的名称
+const availableSectionsScript = `Array.from(document.querySelectorAll(%s)).map((e, i) => {
+	e.setAttribute("data-tg-seat", i);
+	const holder = e.closest("[data-section], [data-zone], [data-block]") || e;
+	return holder.getAttribute("data-section") || holder.getAttribute("data-zone") || holder.getAttribute("data-block") ||
+		e.getAttribute("data-seat-grade") || e.getAttribute("title") || e.getAttribute("aria-label") || e.textContent.trim();
+})`
+
+// SectionScore 页面上一个可选座位所在区域的评分，Rated 为该区域在场馆知识库中有评分
+type SectionScore struct {
+	Section string
+	Score   int
+	Rated   bool
+}
+
+// scoreSections 标记页面上的可选座位，按场馆知识库为每个座位所在区域评分
+// 演出场馆不在知识库中或读取失败时返回nil
+func (tg *TicketGrabber) scoreSections(ctx context.Context, concert *models.Concert) (*venue.Venue, []SectionScore) {
+	v := tg.venues.Find(concert.Venue)
+	if v == nil {
+		return nil, nil
+	}
+
+	var css []string
+	for _, selector := range tg.selectors.Get(tg.site, selectors.SeatAvailable) {
+		if _, _, text := selectors.Text(selector); !text {
+			css = append(css, selector)
+		}
+	}
+	if len(css) == 0 {
+		return v, nil
+	}
+	query, _ := json.Marshal(strings.Join(css, ", "))
+	result, err := tg.browser.ExecuteScript(ctx, fmt.Sprintf(availableSectionsScript, query))
+	if err != nil {
+		log.Printf("读取可选区域失败: %v", err)
+		return v, nil
+	}
+
+	items, _ := result.([]interface{})
+	scores := make([]SectionScore, len(items))
+	for i, item := range items {
+		section, _ := item.(string)
+		score, ok := v.Score(section, tg.config.Tickets.SeatPreferences)
+		scores[i] = SectionScore{Section: section, Score: score, Rated: ok}
+	}
+	return v, scores
+}
+
+// selectBestSection 演出场馆在知识库中时，按区域评分选择最好的可选座位，返回是否已选择
+func (tg *TicketGrabber) selectBestSection(ctx context.Context, concert *models.Concert) bool {
+	v, scores := tg.scoreSections(ctx, concert)
+	best := -1
+	for i, s := range scores {
+		if s.Rated && (best < 0 || s.Score > scores[best].Score) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return false
+	}
+
+	clicked, err := tg.browser.ClickElement(ctx, fmt.Sprintf(`[data-tg-seat="%d"]`, best))
+	if err != nil || !clicked {
+		return false
+	}
+	log.Printf("按 %s 的座位评分选择区域: %s (%d分)", v.Name, scores[best].Section, scores[best].Score)
+	return true
+}
@@ -0,0 +1,132 @@
+package grabber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// sharedTimeout 读写共享状态的超时时间
+const sharedTimeout = 3 * time.Second
+
+// sharedConcertKey 共享状态中账号和演出的键，多网站任务(ID为 "<演唱会ID>@<站点>")共用同一个键
+func (tg *TicketGrabber) sharedConcertKey(concert *models.Concert) string {
+	id, _, _ := strings.Cut(concert.ID, "@")
+	return tg.config.User.Username + ":" + id
+}
+
+// loadSharedSession 导入其他实例共享的登录会话，使API请求在浏览器登录前即可使用
+func (tg *TicketGrabber) loadSharedSession(ctx context.Context) {
+	if tg.shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	data, ok, err := tg.shared.Get(ctx, "session:"+tg.site+":"+tg.config.User.Username)
+	if err != nil {
+		log.Printf("读取共享会话失败: %v", err)
+		return
+	}
+	var cookies []*http.Cookie
+	if !ok || json.Unmarshal(data, &cookies) != nil {
+		return
+	}
+	tg.apiClient.ImportCookies(cookies)
+	log.Printf("已导入其他实例共享的登录会话(%d 个Cookie)", len(cookies))
+}
+
+// shareSession 把浏览器登录后的会话共享给其他实例
+func (tg *TicketGrabber) shareSession(ctx context.Context) {
+	if tg.shared == nil {
+		return
+	}
+
+	cookies, err := tg.browser.Cookies(ctx)
+	if err != nil || len(cookies) == 0 {
+		return
+	}
+	data, err := json.Marshal(cookies)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	ttl := time.Duration(tg.config.SharedState.SessionTTL * float64(time.Second))
+	if err := tg.shared.Set(ctx, "session:"+tg.site+":"+tg.config.User.Username, data, ttl); err != nil {
+		log.Printf("共享登录会话失败: %v", err)
+	}
+}
+
+// lockPurchase 取得该账号、该演出的购买锁，其他实例已在购买时返回false
+// 共享存储不可用时记录日志后继续购买，不因为Redis故障错过开售
+func (tg *TicketGrabber) lockPurchase(ctx context.Context, concert *models.Concert) bool {
+	if tg.shared == nil {
+		return true
+	}
+
+	ttl := time.Duration(tg.config.SharedState.LockTTL * float64(time.Second))
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d", host, os.Getpid())
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	locked, err := tg.shared.SetNX(ctx, "purchase:"+tg.sharedConcertKey(concert), []byte(owner), ttl)
+	if err != nil {
+		log.Printf("获取共享购买锁失败，继续购买: %v", err)
+		return true
+	}
+	return locked
+}
+
+// unlockPurchase 购买失败后释放购买锁，让其他实例可以继续尝试
+func (tg *TicketGrabber) unlockPurchase(concert *models.Concert) {
+	if tg.shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+	if err := tg.shared.Delete(ctx, "purchase:"+tg.sharedConcertKey(concert)); err != nil {
+		log.Printf("释放共享购买锁失败: %v", err)
+	}
+}
+
+// markPurchased 标记该账号已购买该演出，其他实例开始抢票前会检查
+func (tg *TicketGrabber) markPurchased(ctx context.Context, concert *models.Concert, orderID string) {
+	if tg.shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	if err := tg.shared.Set(ctx, "purchased:"+tg.sharedConcertKey(concert), []byte(orderID), 0); err != nil {
+		log.Printf("写入共享购买标记失败: %v", err)
+	}
+}
+
+// sharedPurchase 返回其他实例记录的已购买订单号
+func (tg *TicketGrabber) sharedPurchase(ctx context.Context, concert *models.Concert) (string, bool) {
+	if tg.shared == nil {
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+	data, ok, err := tg.shared.Get(ctx, "purchased:"+tg.sharedConcertKey(concert))
+	if err != nil {
+		log.Printf("读取共享购买标记失败: %v", err)
+	}
+	return string(data), ok
+}
@@ -0,0 +1,58 @@
+package grabber
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"tickgrabber/pkg/models"
+)
+
+// DetectSite 确定演唱会所在的票务网站
+// 演唱会地址的域名属于某个站点时以地址为准，这样Interpark迁移到NOL平台的演出
+// 沿用 "interpark" 配置也能选对流程；无法判断时使用演唱会配置的站点或默认站点
+func DetectSite(config *models.Config, concert *models.Concert) string {
+	site := concert.Site
+	if site == "" {
+		site = config.Ticketing.DefaultSite
+	}
+
+	detected := SiteForURL(config, concert.URL)
+	if detected != "" && detected != site {
+		log.Printf("根据演唱会地址使用站点 %s (配置为 %s)", detected, site)
+		return detected
+	}
+	return site
+}
+
+// SiteForURL 返回域名与地址匹配的站点，没有匹配时返回空字符串
+func SiteForURL(config *models.Config, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+
+	// 取最长的匹配，避免 interpark.com 抢先匹配 nol.interpark.com
+	best, bestLen := "", 0
+	for name, site := range config.Ticketing.Sites {
+		for _, h := range siteHosts(site) {
+			if (host == h || strings.HasSuffix(host, "."+h)) && len(h) > bestLen {
+				best, bestLen = name, len(h)
+			}
+		}
+	}
+	return best
+}
+
+// siteHosts 站点的域名，包括站点地址和额外配置的域名
+func siteHosts(site models.SiteConfig) []string {
+	var hosts []string
+	if u, err := url.Parse(site.URL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, strings.ToLower(u.Hostname()))
+	}
+	for _, h := range site.Hosts {
+		hosts = append(hosts, strings.ToLower(h))
+	}
+	return hosts
+}
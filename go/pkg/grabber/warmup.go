@@ -0,0 +1,100 @@
+package grabber
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/browser"
+	"tickgrabber/pkg/clock"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/selectors"
+)
+
+// defaultKeepAlive 没有配置keep_alive时等待开售期间保持活动的间隔
+const defaultKeepAlive = time.Minute
+
+// keepAliveQuiet 开售前这段时间内不再保持活动，避免重新登录等操作与开售时的检查冲突
+const keepAliveQuiet = 30 * time.Second
+
+// keepAliveScript 在页面上产生轻微的滚动和鼠标事件，避免页面脚本因闲置而登出或弹出提示
+const keepAliveScript = `(() => {
+	window.scrollBy(0, 1);
+	window.scrollBy(0, -1);
+	document.dispatchEvent(new MouseEvent('mousemove', {bubbles: true, clientX: 10, clientY: 10}));
+	return true;
+})()`
+
+// waitForWarmup 配置了预热时间时等待到开售前lead_minutes分钟，之前不登录、不访问售票网站
+func (tg *TicketGrabber) waitForWarmup(ctx context.Context, concert *models.Concert) error {
+	lead := time.Duration(tg.config.Ticketing.Warmup.LeadMinutes * float64(time.Minute))
+	if lead <= 0 || concert.SaleOpenTime.IsZero() {
+		return nil
+	}
+	start := concert.SaleOpenTime.Add(-lead)
+	wait := clock.Until(tg.clock, start)
+	if wait <= 0 {
+		return nil
+	}
+
+	log.Printf("开售前 %v 开始预热，等待到 %s (剩余 %v)", lead, start.Format("15:04:05"), wait.Round(time.Second))
+	tg.progress.SetStage("等待预热", "")
+	tg.progress.SetCountdown("距预热", start)
+	return clock.Sleep(ctx, tg.clock, wait)
+}
+
+// warmUp 进入演出页面后关闭Cookie提示、点击配置的同意按钮并通过条款和年龄确认页面，开售时不再被挡住
+func (tg *TicketGrabber) warmUp(ctx context.Context) {
+	if selector, ok := tg.find(ctx, tg.selectors.Get(tg.site, selectors.CookieBanner)); ok {
+		if clicked, err := selectors.Click(ctx, tg.browser, selector); err == nil && clicked {
+			log.Printf("已关闭Cookie提示: %s", selector)
+		}
+	}
+	for _, selector := range tg.config.Ticketing.Warmup.Accept {
+		if clicked, err := selectors.Click(ctx, tg.browser, selector); err == nil && clicked {
+			log.Printf("预热: 已点击 %s", selector)
+		}
+	}
+	if err := tg.passGates(ctx); err != nil {
+		log.Printf("预热: %v", err)
+	}
+}
+
+// keepAlive 等待开售期间定期在页面上活动并检查登录会话，返回的函数停止保持
+func (tg *TicketGrabber) keepAlive(ctx context.Context, concert *models.Concert) (stop func()) {
+	interval := defaultKeepAlive
+	if s := tg.config.Ticketing.Warmup.KeepAlive; s < 0 {
+		return func() {}
+	} else if s > 0 {
+		interval = time.Duration(s * float64(time.Second))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := tg.clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+			if clock.Until(tg.clock, concert.SaleOpenTime) < keepAliveQuiet {
+				return
+			}
+			bg := browser.WithPriority(ctx, browser.PriorityBackground)
+			if _, err := tg.browser.ExecuteScript(bg, keepAliveScript); err != nil && ctx.Err() == nil {
+				log.Printf("保持页面活动失败: %v", err)
+			}
+			if err := tg.checkSession(ctx, concert); err != nil {
+				log.Printf("预热期间重新登录失败: %v", err)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,231 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxGap 相邻观测间隔超过这个时长时视为中间没有监控
+const maxGap = heartbeat + heartbeat/2
+
+// Drop 一次放票：状态从无票变为有票，到再次无票为止
+type Drop struct {
+	Round    string
+	Start    time.Time
+	Duration time.Duration
+	Grades   map[string]int
+}
+
+// HourStat 一天中某个小时的统计
+// Watched 为该小时内处于监控中的总时长，用来区分"这个时段没有放票"和"这个时段没有在监控"
+type HourStat struct {
+	Drops   int
+	Watched time.Duration
+}
+
+// GradeStat 某个座位等级的放票统计，Hours 为放票次数最多的几个小时
+type GradeStat struct {
+	Grade string
+	Drops int
+	Hours []int
+}
+
+// Report 一场演出的放票规律
+type Report struct {
+	ConcertID string
+	From      time.Time
+	To        time.Time
+	Drops     []Drop
+	Hours     [24]HourStat
+	Weekdays  [7]int
+	Grades    []GradeStat
+}
+
+// Analyze 统计observations(同一演出)中的放票，小时和星期按loc计算
+func Analyze(concertID string, observations []Observation, loc *time.Location) *Report {
+	r := &Report{ConcertID: concertID}
+	if len(observations) == 0 {
+		return r
+	}
+	sortByTime(observations)
+	r.From = observations[0].Time.In(loc)
+	r.To = observations[len(observations)-1].Time.In(loc)
+
+	// 按场次分别还原状态变化
+	byRound := make(map[string][]Observation)
+	for _, o := range observations {
+		byRound[o.Round] = append(byRound[o.Round], o)
+	}
+	gradeHours := make(map[string]*[24]int)
+	for round, list := range byRound {
+		var open *Drop
+		for i, o := range list {
+			if i > 0 {
+				prev := list[i-1]
+				if gap := o.Time.Sub(prev.Time); gap <= maxGap {
+					r.addWatched(prev.Time.In(loc), gap)
+				}
+			}
+			switch {
+			case o.Available && open == nil:
+				open = &Drop{Round: round, Start: o.Time.In(loc), Grades: o.Grades}
+			case !o.Available && open != nil:
+				open.Duration = o.Time.Sub(open.Start)
+				r.Drops = append(r.Drops, *open)
+				open = nil
+			}
+		}
+		if open != nil {
+			open.Duration = list[len(list)-1].Time.Sub(open.Start)
+			r.Drops = append(r.Drops, *open)
+		}
+	}
+	sort.Slice(r.Drops, func(i, j int) bool { return r.Drops[i].Start.Before(r.Drops[j].Start) })
+
+	grades := make(map[string]int)
+	for _, d := range r.Drops {
+		r.Hours[d.Start.Hour()].Drops++
+		r.Weekdays[d.Start.Weekday()]++
+		for grade, count := range d.Grades {
+			if count <= 0 {
+				continue
+			}
+			grades[grade]++
+			if gradeHours[grade] == nil {
+				gradeHours[grade] = &[24]int{}
+			}
+			gradeHours[grade][d.Start.Hour()]++
+		}
+	}
+	for grade, drops := range grades {
+		r.Grades = append(r.Grades, GradeStat{Grade: grade, Drops: drops, Hours: topHours(gradeHours[grade], 3)})
+	}
+	sort.Slice(r.Grades, func(i, j int) bool {
+		if r.Grades[i].Drops != r.Grades[j].Drops {
+			return r.Grades[i].Drops > r.Grades[j].Drops
+		}
+		return r.Grades[i].Grade < r.Grades[j].Grade
+	})
+	return r
+}
+
+// addWatched 把从start开始的d计入各小时的监控时长，跨小时时按小时拆分
+func (r *Report) addWatched(start time.Time, d time.Duration) {
+	for d > 0 {
+		next := start.Truncate(time.Hour).Add(time.Hour)
+		part := next.Sub(start)
+		if part > d {
+			part = d
+		}
+		r.Hours[start.Hour()].Watched += part
+		start = start.Add(part)
+		d -= part
+	}
+}
+
+// topHours 返回次数最多的n个小时，次数为0的小时不返回
+func topHours(counts *[24]int, n int) []int {
+	var hours []int
+	for h, c := range counts {
+		if c > 0 {
+			hours = append(hours, h)
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return counts[hours[i]] > counts[hours[j]] })
+	if len(hours) > n {
+		hours = hours[:n]
+	}
+	return hours
+}
+
+// BestHours 按每监控小时的放票次数排序，返回最值得监控的n个小时(至少监控过30分钟的小时)
+func (r *Report) BestHours(n int) []int {
+	var hours []int
+	for h, stat := range r.Hours {
+		if stat.Drops > 0 && stat.Watched >= 30*time.Minute {
+			hours = append(hours, h)
+		}
+	}
+	rate := func(h int) float64 { return float64(r.Hours[h].Drops) / r.Hours[h].Watched.Hours() }
+	sort.SliceStable(hours, func(i, j int) bool { return rate(hours[i]) > rate(hours[j]) })
+	if len(hours) > n {
+		hours = hours[:n]
+	}
+	return hours
+}
+
+// weekdayNames 报告中的星期名称
+var weekdayNames = [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// String 生成文本报告，含按小时的直方图
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "演唱会 %s 放票规律\n", r.ConcertID)
+	if r.From.IsZero() {
+		b.WriteString("没有观测记录\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "观测时间: %s ~ %s\n", r.From.Format("2006-01-02 15:04"), r.To.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "共发现 %d 次放票", len(r.Drops))
+	if len(r.Drops) > 0 {
+		var total time.Duration
+		for _, d := range r.Drops {
+			total += d.Duration
+		}
+		fmt.Fprintf(&b, "，平均持续 %s", (total / time.Duration(len(r.Drops))).Round(time.Second))
+	}
+	b.WriteString("\n\n按小时(放票次数 / 监控时长):\n")
+
+	most := 1
+	for _, stat := range r.Hours {
+		if stat.Drops > most {
+			most = stat.Drops
+		}
+	}
+	for h, stat := range r.Hours {
+		if stat.Drops == 0 && stat.Watched == 0 {
+			continue
+		}
+		bar := strings.Repeat("#", stat.Drops*30/most)
+		fmt.Fprintf(&b, "%02d:00  %-30s %3d  %s\n", h, bar, stat.Drops, stat.Watched.Round(time.Minute))
+	}
+
+	if len(r.Drops) > 0 {
+		b.WriteString("\n按星期: ")
+		var parts []string
+		for d, count := range r.Weekdays {
+			if count > 0 {
+				parts = append(parts, fmt.Sprintf("%s %d", weekdayNames[d], count))
+			}
+		}
+		b.WriteString(strings.Join(parts, "  ") + "\n")
+	}
+
+	if len(r.Grades) > 0 {
+		b.WriteString("\n按座位等级:\n")
+		for _, g := range r.Grades {
+			fmt.Fprintf(&b, "  %-8s %3d 次  多在 %s\n", g.Grade, g.Drops, formatHours(g.Hours))
+		}
+	}
+
+	if best := r.BestHours(3); len(best) > 0 {
+		fmt.Fprintf(&b, "\n建议重点监控: %s\n", formatHours(best))
+	}
+	return b.String()
+}
+
+// formatHours 把小时列表格式化为 "21:00-22:00、09:00-10:00"
+func formatHours(hours []int) string {
+	var parts []string
+	for _, h := range hours {
+		parts = append(parts, fmt.Sprintf("%02d:00-%02d:00", h, (h+1)%24))
+	}
+	return strings.Join(parts, "、")
+}
+
+// sortByTime 按观测时间排序
+func sortByTime(observations []Observation) {
+	sort.SliceStable(observations, func(i, j int) bool { return observations[i].Time.Before(observations[j].Time) })
+}
@@ -0,0 +1,111 @@
+// Package history 记录监控期间观测到的余票状态，用于分析退票、加场放票通常出现的时间
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// heartbeat 状态没有变化时重复记录的间隔，用于统计哪些时段处于监控中
+const heartbeat = 10 * time.Minute
+
+// Observation 一次余票状态观测
+// Grades 为有票时页面上各座位等级的可选座位数，页面上没有座位图时为空
+type Observation struct {
+	Time      time.Time      `json:"time"`
+	ConcertID string         `json:"concert_id"`
+	Site      string         `json:"site"`
+	Round     string         `json:"round,omitempty"`
+	Available bool           `json:"available"`
+	Grades    map[string]int `json:"grades,omitempty"`
+}
+
+// key 同一演出同一场次的观测归为一组
+func (o Observation) key() string {
+	return o.ConcertID + "\x00" + o.Round
+}
+
+// Log 追加写入的观测记录(JSON Lines)
+// 每次检查都可以调用Record，只有状态变化或超过heartbeat时才写入文件，文件不会随轮询频率膨胀
+type Log struct {
+	path string
+
+	mu   sync.Mutex
+	last map[string]Observation
+}
+
+// Open 打开观测记录，path为空时返回nil(不记录)
+func Open(path string) *Log {
+	if path == "" {
+		return nil
+	}
+	return &Log{path: path, last: make(map[string]Observation)}
+}
+
+// Record 记录一次观测，l为nil时不记录
+func (l *Log) Record(o Observation) error {
+	if l == nil {
+		return nil
+	}
+	if o.Time.IsZero() {
+		o.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev, ok := l.last[o.key()]
+	if ok && prev.Available == o.Available && o.Time.Sub(prev.Time) < heartbeat {
+		return nil
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	l.last[o.key()] = o
+	return nil
+}
+
+// Load 读取path中的观测记录，concertID不为空时只返回该演出的记录，结果按时间排序
+func Load(path, concertID string) ([]Observation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var observations []Observation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var o Observation
+		// 进程崩溃时最后一行可能不完整，跳过无法解析的行
+		if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+			continue
+		}
+		if concertID == "" || o.ConcertID == concertID {
+			observations = append(observations, o)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortByTime(observations)
+	return observations, nil
+}
@@ -0,0 +1,216 @@
+// Package httpd 守护进程的HTTP服务: HTTPS(证书文件或自动申请)、反向代理后的路径前缀，
+// 以及只信任指定代理发来的客户端地址转发头，便于放在nginx或Cloudflare Tunnel后面远程查看
+package httpd
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"tickgrabber/pkg/models"
+)
+
+// defaultCacheDir 自动申请的证书默认保存的目录
+const defaultCacheDir = "data/autocert"
+
+// Server 按配置包装的HTTP服务
+type Server struct {
+	server  *http.Server
+	tls     models.TLSConfig
+	trusted []*net.IPNet
+	allow   []*net.IPNet
+	token   string
+}
+
+// New 创建HTTP服务，h 中的路径不含 base_path 前缀
+// 没有配置令牌时只允许监听本机地址，避免任务状态和统计数据无认证地暴露到网络上；
+// 配置了反向代理(trusted_proxies 或 base_path)时即使监听本机地址也需要令牌，
+// 同一台机器上的代理会把服务转发到外部
+func New(config models.HealthConfig, h http.Handler) (*Server, error) {
+	if config.Token == "" {
+		switch {
+		case len(config.TrustedProxies) > 0 || config.BasePath != "":
+			return nil, fmt.Errorf("配置了 trusted_proxies 或 base_path 时需要配置 token")
+		case !Loopback(config.Listen):
+			return nil, fmt.Errorf("监听地址 %s 不是本机地址，需要配置 token", config.Listen)
+		}
+	}
+	trusted, err := parseNets(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted_proxies: %w", err)
+	}
+	allow, err := parseNets(config.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("allow: %w", err)
+	}
+	tc := config.TLS
+	if len(tc.Autocert) > 0 && (tc.CertFile != "" || tc.KeyFile != "") {
+		return nil, fmt.Errorf("tls 中的证书文件和autocert只能配置一种")
+	}
+	if (tc.CertFile == "") != (tc.KeyFile == "") {
+		return nil, fmt.Errorf("tls 需要同时配置 cert_file 和 key_file")
+	}
+
+	s := &Server{tls: tc, trusted: trusted, allow: allow, token: config.Token}
+	if base := strings.TrimRight(config.BasePath, "/"); base != "" {
+		if !strings.HasPrefix(base, "/") {
+			base = "/" + base
+		}
+		h = http.StripPrefix(base, h)
+	}
+	s.server = &http.Server{Addr: config.Listen, Handler: s.wrap(h)}
+	return s, nil
+}
+
+// Handler 返回包装后的处理器
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Serve 开始监听，ctx取消时关闭
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.server.Close()
+	}()
+
+	var err error
+	switch {
+	case len(s.tls.Autocert) > 0:
+		dir := s.tls.CacheDir
+		if dir == "" {
+			dir = defaultCacheDir
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(s.tls.Autocert...),
+			Cache:      autocert.DirCache(dir),
+		}
+		s.server.TLSConfig = manager.TLSConfig()
+		err = s.server.ListenAndServeTLS("", "")
+	case s.tls.CertFile != "":
+		s.server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		err = s.server.ListenAndServeTLS(s.tls.CertFile, s.tls.KeyFile)
+	default:
+		err = s.server.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// wrap 来自可信代理的请求按转发头还原客户端地址和协议，再按allow检查客户端地址和令牌
+func (s *Server) wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := hostIP(r.RemoteAddr)
+		if ip != nil && contains(s.trusted, ip) {
+			if client := s.forwardedFor(r); client != nil {
+				ip = client
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+			}
+			if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+				r.URL.Scheme = proto
+			}
+		}
+		if len(s.allow) > 0 && (ip == nil || !contains(s.allow, ip)) {
+			log.Printf("拒绝来自 %s 的请求 %s", r.RemoteAddr, r.URL.Path)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if s.token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// forwardedFor 从转发头中取客户端地址: Cloudflare的CF-Connecting-IP，
+// 否则X-Forwarded-For中从右往左第一个不是可信代理的地址，最后是X-Real-IP
+func (s *Server) forwardedFor(r *http.Request) net.IP {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("CF-Connecting-IP"))); ip != nil {
+		return ip
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if !contains(s.trusted, ip) || i == 0 {
+				return ip
+			}
+		}
+	}
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+// parseNets 解析CIDR列表，单个IP视为只包含该地址的网段
+func parseNets(list []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, s := range list {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("地址 %q 无效", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// contains 判断ip是否在任一网段中
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostIP 解析RemoteAddr中的IP
+func hostIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
+
+// Loopback 判断监听或连接地址是否只在本机，主机为空(监听所有网卡)时不是本机地址
+func Loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,174 @@
+package httpd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tickgrabber/pkg/models"
+)
+
+func TestForwardedFor(t *testing.T) {
+	trusted, err := parseNets([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{trusted: trusted}
+
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		want    string
+	}{
+		{"CF-Connecting-IP优先", map[string][]string{"CF-Connecting-IP": {"203.0.113.5"}, "X-Forwarded-For": {"198.51.100.1"}}, "203.0.113.5"},
+		{"单个地址", map[string][]string{"X-Forwarded-For": {"198.51.100.1"}}, "198.51.100.1"},
+		{"跳过右侧的可信代理", map[string][]string{"X-Forwarded-For": {"198.51.100.1, 10.0.0.2, 192.168.1.1"}}, "198.51.100.1"},
+		{"客户端伪造的左侧地址不采用", map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1, 10.0.0.2"}}, "198.51.100.1"},
+		{"多个头合并", map[string][]string{"X-Forwarded-For": {"198.51.100.1", "10.0.0.2"}}, "198.51.100.1"},
+		{"全部是可信代理时取最左侧", map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"无效地址时改用X-Real-IP", map[string][]string{"X-Forwarded-For": {"unknown, 10.0.0.2"}, "X-Real-IP": {"198.51.100.9"}}, "198.51.100.9"},
+		{"只有X-Real-IP", map[string][]string{"X-Real-IP": {" 2001:db8::1 "}}, "2001:db8::1"},
+		{"没有转发头", nil, "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, values := range tt.headers {
+				for _, v := range values {
+					r.Header.Add(k, v)
+				}
+			}
+			if got := s.forwardedFor(r).String(); got != tt.want {
+				t.Errorf("forwardedFor = %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  models.HealthConfig
+		wantErr bool
+	}{
+		{"本机地址不需要令牌", models.HealthConfig{Listen: "127.0.0.1:8080"}, false},
+		{"localhost不需要令牌", models.HealthConfig{Listen: "localhost:8080"}, false},
+		{"所有网卡需要令牌", models.HealthConfig{Listen: ":8080"}, true},
+		{"外部地址有令牌", models.HealthConfig{Listen: "0.0.0.0:8080", Token: "t"}, false},
+		{"反向代理需要令牌", models.HealthConfig{Listen: "127.0.0.1:8080", TrustedProxies: []string{"10.0.0.1"}}, true},
+		{"路径前缀需要令牌", models.HealthConfig{Listen: "127.0.0.1:8080", BasePath: "/tg"}, true},
+		{"代理地址无效", models.HealthConfig{Listen: "127.0.0.1:8080", Token: "t", TrustedProxies: []string{"proxy"}}, true},
+		{"证书只配置一半", models.HealthConfig{Listen: "127.0.0.1:8443", TLS: models.TLSConfig{CertFile: "a.pem"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config, http.NotFoundHandler()); (err != nil) != tt.wantErr {
+				t.Errorf("New = %v，期望错误 %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	// 处理器返回还原后的客户端地址和协议
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(hostIP(r.RemoteAddr).String() + " " + r.URL.Scheme + " " + r.URL.Path))
+	})
+	local, err := New(models.HealthConfig{Listen: "127.0.0.1:8080"}, echo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxied, err := New(models.HealthConfig{
+		Listen:         "127.0.0.1:8080",
+		Token:          "secret",
+		BasePath:       "/tg/",
+		TrustedProxies: []string{"127.0.0.1"},
+		Allow:          []string{"198.51.100.0/24"},
+	}, echo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		server   *Server
+		remote   string
+		path     string
+		headers  map[string]string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "本机请求不需要令牌", server: local, remote: "127.0.0.1:50000", path: "/status",
+			wantCode: http.StatusOK, wantBody: "127.0.0.1  /status",
+		},
+		{
+			name: "本机服务不信任转发头", server: local, remote: "127.0.0.1:50000", path: "/status",
+			headers:  map[string]string{"X-Forwarded-For": "203.0.113.5"},
+			wantCode: http.StatusOK, wantBody: "127.0.0.1  /status",
+		},
+		{
+			name: "代理转发的请求没有令牌", server: proxied, remote: "127.0.0.1:50000", path: "/tg/status",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.7"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "令牌错误", server: proxied, remote: "127.0.0.1:50000", path: "/tg/status",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.7", "Authorization": "Bearer wrong"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "令牌正确", server: proxied, remote: "127.0.0.1:50000", path: "/tg/status",
+			headers:  map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Forwarded-Proto": "https", "Authorization": "Bearer secret"},
+			wantCode: http.StatusOK, wantBody: "198.51.100.7 https /status",
+		},
+		{
+			name: "客户端不在allow中", server: proxied, remote: "127.0.0.1:50000", path: "/tg/status",
+			headers:  map[string]string{"X-Forwarded-For": "203.0.113.5", "Authorization": "Bearer secret"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "不可信来源的转发头不采用", server: proxied, remote: "198.51.100.8:50000", path: "/tg/status",
+			headers:  map[string]string{"X-Forwarded-For": "203.0.113.5", "Authorization": "Bearer secret"},
+			wantCode: http.StatusOK, wantBody: "198.51.100.8  /status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			tt.server.Handler().ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("状态码 %d，期望 %d", w.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Error("401 响应缺少 WWW-Authenticate")
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("响应 %q，期望 %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"localhost:8080": true,
+		"localhost":      true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.1:8080":  false,
+		"example.com:80": false,
+	} {
+		if got := Loopback(addr); got != want {
+			t.Errorf("Loopback(%q) = %v，期望 %v", addr, got, want)
+		}
+	}
+}
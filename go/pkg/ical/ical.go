@@ -0,0 +1,205 @@
+// Package ical 读写iCalendar(.ics)文件中的事件，用于导出开售时间和付款期限、从共享日历导入开售时间
+// 只处理VEVENT的常用属性，不支持重复规则(RRULE)
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// kst 没有时区的时间(floating time)按韩国时间处理
+var kst = time.FixedZone("KST", 9*60*60)
+
+// maxLineOctets 内容行的最大字节数，超过时折行
+const maxLineOctets = 75
+
+// Event 日历事件，Alarm 大于0时在开始前该时长提醒
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Alarm       time.Duration
+}
+
+// Encode 写出包含events的日历
+func Encode(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	stamp := formatTime(time.Now())
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//tickgrabber//ticket calendar//KO")
+	line("CALSCALE", "GREGORIAN")
+	if name != "" {
+		line("X-WR-CALNAME", escape(name))
+	}
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", formatTime(e.Start))
+		if !e.End.IsZero() {
+			line("DTEND", formatTime(e.End))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Alarm > 0 {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escape(e.Summary))
+			line("TRIGGER", fmt.Sprintf("-PT%dM", int(e.Alarm.Minutes())))
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// Decode 读取日历中的事件，无法解析开始时间的事件被跳过
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+	depth := 0 // VEVENT内嵌套的组件(如VALARM)层数
+	for _, l := range lines {
+		name, params, value, ok := parseLine(l)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current, depth = &Event{}, 0
+		case current == nil:
+		case name == "BEGIN":
+			depth++
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !current.Start.IsZero() {
+				events = append(events, *current)
+			}
+			current = nil
+		case name == "END":
+			depth--
+		case depth > 0:
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			current.Description = unescape(value)
+		case name == "LOCATION":
+			current.Location = unescape(value)
+		case name == "URL":
+			current.URL = value
+		case name == "DTSTART":
+			current.Start, _ = parseTime(value, params)
+		case name == "DTEND":
+			current.End, _ = parseTime(value, params)
+		}
+	}
+	return events, nil
+}
+
+// unfold 读取内容行并合并折行(以空格或制表符开头的行接续上一行)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		l := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine 拆分内容行为属性名、参数和值，属性名转为大写
+func parseLine(l string) (name string, params map[string]string, value string, ok bool) {
+	head, value, ok := strings.Cut(l, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+	parts := strings.Split(head, ";")
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, found := strings.Cut(p, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value, true
+}
+
+// parseTime 解析DTSTART/DTEND：UTC时间、带TZID的本地时间、没有时区的时间或只有日期
+func parseTime(value string, params map[string]string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	loc := kst
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// formatTime 以UTC格式写出时间
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// writeLine 写出内容行，超过75字节时在UTF-8字符边界折行
+func writeLine(w *bufio.Writer, l string) {
+	limit := maxLineOctets
+	for len(l) > limit {
+		cut := limit
+		for cut > 0 && l[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(l[:cut] + "\r\n ")
+		l = l[cut:]
+		// 接续行开头的空格也计入长度
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(l + "\r\n")
+}
+
+// escape 转义TEXT类型的值
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// unescape 还原TEXT类型的值
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
@@ -0,0 +1,122 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// guardTimeout 修改租约文件时的互斥文件超过该时间视为残留，可以删除
+const guardTimeout = 10 * time.Second
+
+// FileLock 基于共享文件的租约，适用于同一台机器或共享磁盘(如NFS)上的多个实例
+type FileLock struct {
+	path string
+}
+
+var _ Lock = (*FileLock)(nil)
+
+// lease 租约文件内容
+type lease struct {
+	Owner     string    `json:"owner"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFileLock 创建文件租约
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+// TryLock 租约不存在、已过期或属于owner时写入新的租约
+func (l *FileLock) TryLock(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	unlock, err := l.guard(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if current.Owner != "" && current.Owner != owner && time.Now().Before(current.ExpiresAt) {
+		return false, nil
+	}
+	return true, l.write(lease{Owner: owner, ExpiresAt: time.Now().Add(ttl)})
+}
+
+// Unlock 租约属于owner时删除租约文件
+func (l *FileLock) Unlock(ctx context.Context, owner string) error {
+	unlock, err := l.guard(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := l.read()
+	if err != nil || current.Owner != owner {
+		return err
+	}
+	return os.Remove(l.path)
+}
+
+// guard 用独占创建的互斥文件保证读写租约文件的原子性
+func (l *FileLock) guard(ctx context.Context) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return nil, err
+	}
+
+	guard := l.path + ".lck"
+	for {
+		f, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(guard) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		// 持有互斥文件的实例崩溃时删除残留的文件
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > guardTimeout {
+			os.Remove(guard)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// read 读取租约文件，不存在时返回空租约
+func (l *FileLock) read() (lease, error) {
+	var current lease
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return current, nil
+	}
+	if err != nil {
+		return current, err
+	}
+	// 内容损坏时视为没有租约
+	json.Unmarshal(data, &current)
+	return current, nil
+}
+
+// write 写入租约文件
+func (l *FileLock) write(current lease) error {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
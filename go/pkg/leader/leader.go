@@ -0,0 +1,93 @@
+// Package leader 冗余实例之间的主备选举
+// 多个实例同时监控同一场演出时，只有持有租约的主实例执行购买，其他实例作为热备继续监控，
+// 主实例退出或卡住导致租约过期后，由某个热备实例接替
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Lock 带过期时间的租约锁
+type Lock interface {
+	// TryLock 尝试取得租约，owner已持有时续期，取得或续期成功返回true
+	TryLock(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+	// Unlock 释放owner持有的租约
+	Unlock(ctx context.Context, owner string) error
+}
+
+// Elector 定期续期租约并记录当前实例是否为主实例
+type Elector struct {
+	lock  Lock
+	owner string
+	ttl   time.Duration
+	name  string
+
+	leader    atomic.Bool
+	announced bool
+}
+
+// NewElector 创建选举器，name用于日志，owner为空时使用主机名和进程号
+func NewElector(lock Lock, name, owner string, ttl time.Duration) *Elector {
+	if owner == "" {
+		host, _ := os.Hostname()
+		owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	return &Elector{lock: lock, owner: owner, ttl: ttl, name: name}
+}
+
+// Run 每ttl/3续期一次租约，直到ctx取消，退出时释放租约
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.renew(ctx)
+
+		select {
+		case <-ctx.Done():
+			if e.leader.Load() {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+				e.lock.Unlock(releaseCtx, e.owner)
+				cancel()
+				e.leader.Store(false)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// renew 尝试取得或续期租约，出错时视为失去主实例身份，宁可不买也不重复购买
+func (e *Elector) renew(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.ttl/3)
+	defer cancel()
+
+	ok, err := e.lock.TryLock(ctx, e.owner, e.ttl)
+	if err != nil {
+		log.Printf("[%s] 续期主实例租约失败: %v", e.name, err)
+		ok = false
+	}
+
+	// 第一次选举的结果也记录，热备实例启动时即可看到自己的身份
+	if was := e.leader.Swap(ok); was != ok || !e.announced {
+		e.announced = true
+		if ok {
+			log.Printf("[%s] 本实例(%s)成为主实例，负责购买", e.name, e.owner)
+		} else {
+			log.Printf("[%s] 本实例(%s)为热备实例，只监控不购买", e.name, e.owner)
+		}
+	}
+}
+
+// IsLeader 当前是否为主实例
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
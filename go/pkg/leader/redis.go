@@ -0,0 +1,54 @@
+package leader
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"tickgrabber/pkg/shared"
+)
+
+// renewScript 租约属于owner时续期，不存在时创建，返回1表示成功
+const renewScript = `
+local current = redis.call("GET", KEYS[1])
+if current == false or current == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`
+
+// releaseScript 租约属于owner时删除
+const releaseScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+// RedisLock 基于Redis的租约，适用于不同机器上的实例
+type RedisLock struct {
+	redis *shared.Redis
+	key   string
+}
+
+var _ Lock = (*RedisLock)(nil)
+
+// NewRedisLock 创建Redis租约，key为租约的键(会加上存储的前缀)
+func NewRedisLock(redis *shared.Redis, key string) *RedisLock {
+	return &RedisLock{redis: redis, key: key}
+}
+
+// TryLock 取得或续期租约
+func (l *RedisLock) TryLock(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	reply, err := l.redis.Eval(ctx, renewScript, []string{l.key}, owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	n, _ := reply.(int64)
+	return n == 1, nil
+}
+
+// Unlock 释放租约
+func (l *RedisLock) Unlock(ctx context.Context, owner string) error {
+	_, err := l.redis.Eval(ctx, releaseScript, []string{l.key}, owner)
+	return err
+}
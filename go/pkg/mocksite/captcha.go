@@ -0,0 +1,61 @@
+package mocksite
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+)
+
+// digitFont 3x5点阵数字字体
+var digitFont = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{"..#", "..#", "..#", "..#", "..#"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// handleCaptchaImage 输出当前会话验证码的图片
+func (s *Site) handleCaptchaImage(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	answer := s.CaptchaAnswer(cookie.Value)
+	if answer == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	const scale = 8
+	img := image.NewGray(image.Rect(0, 0, (len(answer)*4+1)*scale, 7*scale))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for i, ch := range answer {
+		glyph := digitFont[ch-'0']
+		for y, row := range glyph {
+			for x, dot := range row {
+				if dot != '#' {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetGray((i*4+1+x)*scale+dx, (y+1)*scale+dy, color.Gray{})
+					}
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	png.Encode(w, img)
+}
@@ -0,0 +1,437 @@
+// Package mocksite 模拟票务网站，用于在不访问真实网站的情况下对适配器和完整抢票流程做集成测试
+//
+// 模拟的流程: 登录表单(及JSON登录接口) → 排队页 → 座位图 → 验证码 → 下单 → 订单确认页 → 我的预订
+// 页面使用TicketGrabber默认的选择器，可以直接把站点配置指向模拟网站运行
+package mocksite
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// sessionCookie 登录会话Cookie名
+const sessionCookie = "MOCKSESSID"
+
+// Options 模拟网站选项
+type Options struct {
+	// Username 和 Password 为唯一有效的账号
+	Username string
+	Password string
+	// Seats 每个座位等级的座位数，如 {"R": 10, "S": 20}
+	Seats map[string]int
+	// Price 每个座位等级的价格
+	Price map[string]int
+	// SaleOpen 开售时间，零值表示已开售
+	SaleOpen time.Time
+	// QueueDelay 进入座位图前在排队页等待的时间
+	QueueDelay time.Duration
+	// SellOutRate 下单时座位被其他人抢走的概率(0-1)
+	SellOutRate float64
+	// Captcha 下单前是否要求输入验证码
+	Captcha bool
+	// PaymentDeadline 订单付款期限，相对下单时间
+	PaymentDeadline time.Duration
+	// Seed 随机种子，固定后售罄和验证码可复现
+	Seed int64
+}
+
+// Seat 座位
+type Seat struct {
+	Name  string `json:"name"`
+	Grade string `json:"grade"`
+	Price int    `json:"price"`
+	Sold  bool   `json:"sold"`
+}
+
+// Order 模拟网站生成的订单
+type Order struct {
+	ID       string    `json:"id"`
+	User     string    `json:"user"`
+	Concert  string    `json:"concert"`
+	Seats    []string  `json:"seats"`
+	Total    int       `json:"total"`
+	Deadline time.Time `json:"deadline"`
+	Canceled bool      `json:"canceled"`
+}
+
+// Site 模拟票务网站
+type Site struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	seats    []*Seat
+	sessions map[string]string
+	queued   map[string]time.Time
+	captchas map[string]string
+	orders   []*Order
+}
+
+// New 创建模拟网站
+func New(opts Options) *Site {
+	if opts.Username == "" {
+		opts.Username, opts.Password = "test", "test"
+	}
+	if len(opts.Seats) == 0 {
+		opts.Seats = map[string]int{"R": 10, "S": 20}
+	}
+	if opts.PaymentDeadline == 0 {
+		opts.PaymentDeadline = 24 * time.Hour
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	s := &Site{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		rand:     rand.New(rand.NewSource(opts.Seed)),
+		sessions: make(map[string]string),
+		queued:   make(map[string]time.Time),
+		captchas: make(map[string]string),
+	}
+
+	// 按等级名排序生成座位，固定Seed时座位顺序和售罄结果都可复现
+	for _, grade := range s.grades() {
+		price := opts.Price[grade]
+		if price == 0 {
+			price = 100000
+		}
+		for i := 1; i <= opts.Seats[grade]; i++ {
+			s.seats = append(s.seats, &Seat{
+				Name:  fmt.Sprintf("%s석 %d번", grade, i),
+				Grade: grade,
+				Price: price,
+			})
+		}
+	}
+
+	s.routes()
+	return s
+}
+
+// grades 按名称排序的座位等级
+func (s *Site) grades() []string {
+	grades := make([]string, 0, len(s.opts.Seats))
+	for grade := range s.opts.Seats {
+		grades = append(grades, grade)
+	}
+	sort.Strings(grades)
+	return grades
+}
+
+// Start 在本地随机端口启动模拟网站，调用方负责Close
+func (s *Site) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// ServeHTTP 实现http.Handler
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SiteConfig 返回指向模拟网站的站点配置
+func SiteConfig(baseURL string) models.SiteConfig {
+	return models.SiteConfig{
+		Name:        "Mock",
+		URL:         baseURL,
+		LoginURL:    baseURL + "/user/login",
+		SearchURL:   baseURL + "/search",
+		BookingsURL: baseURL + "/mypage/bookings",
+	}
+}
+
+// ConcertURL 演唱会页面地址
+func ConcertURL(baseURL, concertID string) string {
+	return baseURL + "/concerts/" + concertID
+}
+
+// Orders 返回已生成的订单
+func (s *Site) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]Order, len(s.orders))
+	for i, o := range s.orders {
+		orders[i] = *o
+	}
+	return orders
+}
+
+// SellOut 将所有座位标记为已售
+func (s *Site) SellOut() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, seat := range s.seats {
+		seat.Sold = true
+	}
+}
+
+// CaptchaAnswer 返回会话当前验证码的答案，供测试直接提交
+func (s *Site) CaptchaAnswer(session string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.captchas[session]
+}
+
+// routes 注册路由
+func (s *Site) routes() {
+	s.mux.HandleFunc("/user/login", s.handleLogin)
+	s.mux.HandleFunc("/concerts/", s.handleConcert)
+	s.mux.HandleFunc("/captcha.png", s.handleCaptchaImage)
+	s.mux.HandleFunc("/orders/", s.requireLogin(s.handleOrder))
+	s.mux.HandleFunc("/mypage/bookings", s.requireLogin(s.handleBookings))
+	s.mux.HandleFunc("/api/concerts/", s.handleTicketsAPI)
+}
+
+// user 返回请求的登录用户
+func (s *Site) user(r *http.Request) string {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[cookie.Value]
+}
+
+// requireLogin 未登录时跳转到登录页
+func (s *Site) requireLogin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.user(r) == "" {
+			http.Redirect(w, r, "/user/login", http.StatusFound)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleLogin 登录页和登录接口，JSON请求返回JSON响应
+func (s *Site) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		render(w, loginPage, nil)
+		return
+	}
+
+	var username, password string
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+	if isJSON {
+		var req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		username, password = req.Username, req.Password
+	} else {
+		username, password = r.FormValue("username"), r.FormValue("password")
+	}
+
+	ok := username == s.opts.Username && password == s.opts.Password
+	if ok {
+		s.mu.Lock()
+		session := fmt.Sprintf("%016x", s.rand.Int63())
+		s.sessions[session] = username
+		s.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/", HttpOnly: true})
+	}
+
+	if isJSON {
+		resp := map[string]interface{}{"success": ok, "message": "로그인 성공"}
+		if !ok {
+			resp["message"] = "아이디 또는 비밀번호가 일치하지 않습니다"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		render(w, loginPage, "아이디 또는 비밀번호가 일치하지 않습니다")
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handleConcert 演唱会页面: /concerts/{id} 和 /concerts/{id}/purchase
+func (s *Site) handleConcert(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/concerts/")
+	id, action, _ := strings.Cut(rest, "/")
+
+	user := s.user(r)
+	if user == "" {
+		http.Redirect(w, r, "/user/login", http.StatusFound)
+		return
+	}
+
+	switch action {
+	case "":
+		s.showConcert(w, r, id, user)
+	case "purchase":
+		s.purchase(w, r, id, user)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// showConcert 未开售时显示倒计时，排队中显示排队页，否则显示座位图
+func (s *Site) showConcert(w http.ResponseWriter, r *http.Request, id, user string) {
+	if !s.opts.SaleOpen.IsZero() && time.Now().Before(s.opts.SaleOpen) {
+		render(w, notOpenPage, map[string]interface{}{"ID": id, "Open": s.opts.SaleOpen})
+		return
+	}
+
+	if s.opts.QueueDelay > 0 {
+		s.mu.Lock()
+		since, ok := s.queued[user]
+		if !ok {
+			since = time.Now()
+			s.queued[user] = since
+		}
+		s.mu.Unlock()
+
+		if wait := s.opts.QueueDelay - time.Since(since); wait > 0 {
+			render(w, queuePage, map[string]interface{}{"ID": id, "Wait": wait.Round(time.Second)})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	seats := make([]Seat, len(s.seats))
+	for i, seat := range s.seats {
+		seats[i] = *seat
+	}
+	var captcha bool
+	if s.opts.Captcha {
+		cookie, _ := r.Cookie(sessionCookie)
+		s.captchas[cookie.Value] = fmt.Sprintf("%04d", s.rand.Intn(10000))
+		captcha = true
+	}
+	s.mu.Unlock()
+
+	render(w, seatMapPage, map[string]interface{}{"ID": id, "Seats": seats, "Captcha": captcha})
+}
+
+// purchase 下单，座位可能已被抢走
+func (s *Site) purchase(w http.ResponseWriter, r *http.Request, id, user string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cookie, _ := r.Cookie(sessionCookie)
+	r.ParseForm()
+	names := r.PostForm["seat"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opts.Captcha && r.PostFormValue("captcha") != s.captchas[cookie.Value] {
+		w.WriteHeader(http.StatusBadRequest)
+		render(w, errorPage, "자동입력 방지문자가 일치하지 않습니다")
+		return
+	}
+	if len(names) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		render(w, errorPage, "좌석을 선택해 주세요")
+		return
+	}
+
+	var selected []*Seat
+	for _, name := range names {
+		for _, seat := range s.seats {
+			if seat.Name == name {
+				selected = append(selected, seat)
+			}
+		}
+	}
+	for _, seat := range selected {
+		if seat.Sold || s.rand.Float64() < s.opts.SellOutRate {
+			seat.Sold = true
+			w.WriteHeader(http.StatusConflict)
+			render(w, errorPage, "이미 선택된 좌석입니다")
+			return
+		}
+	}
+
+	order := &Order{
+		ID:       fmt.Sprintf("M%08d", s.rand.Intn(100000000)),
+		User:     user,
+		Concert:  id,
+		Deadline: time.Now().Add(s.opts.PaymentDeadline),
+	}
+	for _, seat := range selected {
+		seat.Sold = true
+		order.Seats = append(order.Seats, seat.Name)
+		order.Total += seat.Price
+	}
+	s.orders = append(s.orders, order)
+
+	http.Redirect(w, r, "/orders/"+order.ID, http.StatusSeeOther)
+}
+
+// handleOrder 订单确认页
+func (s *Site) handleOrder(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/orders/")
+	for _, o := range s.Orders() {
+		if o.ID == id && o.User == s.user(r) {
+			render(w, orderPage, o)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// handleBookings 我的预订
+func (s *Site) handleBookings(w http.ResponseWriter, r *http.Request) {
+	user := s.user(r)
+	var orders []Order
+	for _, o := range s.Orders() {
+		if o.User == user {
+			orders = append(orders, o)
+		}
+	}
+	render(w, bookingsPage, orders)
+}
+
+// handleTicketsAPI 票务接口: /api/concerts/{id}/tickets
+func (s *Site) handleTicketsAPI(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	available := make(map[string]int)
+	price := make(map[string]int)
+	for _, seat := range s.seats {
+		if !seat.Sold {
+			available[seat.Grade]++
+		}
+		price[seat.Grade] = seat.Price
+	}
+	s.mu.Unlock()
+
+	var tickets []map[string]interface{}
+	for _, grade := range s.grades() {
+		tickets = append(tickets, map[string]interface{}{
+			"id":        grade,
+			"name":      grade + "석",
+			"seat_type": grade,
+			"price":     price[grade],
+			"quantity":  available[grade],
+			"available": available[grade] > 0,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tickets)
+}
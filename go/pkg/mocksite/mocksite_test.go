@@ -0,0 +1,186 @@
+package mocksite
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// login 登录模拟网站，返回带会话Cookie的客户端
+func login(t *testing.T, srv *httptest.Server) *http.Client {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+
+	resp, err := client.PostForm(srv.URL+"/user/login", url.Values{"username": {"test"}, "password": {"test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	session(t, client, srv)
+	return client
+}
+
+// session 返回客户端的会话ID
+func session(t *testing.T, client *http.Client, srv *httptest.Server) string {
+	t.Helper()
+	u, _ := url.Parse(srv.URL)
+	for _, c := range client.Jar.Cookies(u) {
+		if c.Name == sessionCookie {
+			return c.Value
+		}
+	}
+	t.Fatal("没有会话Cookie")
+	return ""
+}
+
+// get 读取页面内容
+func get(t *testing.T, client *http.Client, target string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+// purchase 提交下单表单
+func purchase(t *testing.T, client *http.Client, srv *httptest.Server, form url.Values) (int, string) {
+	t.Helper()
+	resp, err := client.PostForm(srv.URL+"/concerts/c1/purchase", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func seatNames(s *Site) []string {
+	var names []string
+	for _, seat := range s.seats {
+		names = append(names, seat.Name)
+	}
+	return names
+}
+
+func TestSeatsOrderedByGrade(t *testing.T) {
+	opts := Options{Seats: map[string]int{"S": 2, "VIP": 1, "R": 2}, Seed: 1}
+	want := []string{"R석 1번", "R석 2번", "S석 1번", "S석 2번", "VIP석 1번"}
+
+	for i := 0; i < 5; i++ {
+		if got := seatNames(New(opts)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("座位顺序 = %v，期望 %v", got, want)
+		}
+	}
+}
+
+func TestSellOutReproducibleWithSeed(t *testing.T) {
+	run := func() []int {
+		srv := New(Options{Seats: map[string]int{"R": 10, "S": 10}, SellOutRate: 0.5, Seed: 42}).Start()
+		defer srv.Close()
+		client := login(t, srv)
+
+		var codes []int
+		for _, name := range []string{"R석 1번", "R석 2번", "R석 3번", "S석 1번", "S석 2번", "S석 3번"} {
+			code, _ := purchase(t, client, srv, url.Values{"seat": {name}})
+			codes = append(codes, code)
+		}
+		return codes
+	}
+
+	first := run()
+	if second := run(); !reflect.DeepEqual(first, second) {
+		t.Fatalf("相同Seed的结果不同: %v / %v", first, second)
+	}
+}
+
+func TestPurchaseFlow(t *testing.T) {
+	site := New(Options{Seats: map[string]int{"R": 2}, Seed: 1})
+	srv := site.Start()
+	defer srv.Close()
+
+	code, body := get(t, http.DefaultClient, srv.URL+"/concerts/c1")
+	if code != http.StatusOK || !strings.Contains(body, `id="username"`) {
+		t.Fatalf("未登录时应跳转到登录页: %d", code)
+	}
+
+	client := login(t, srv)
+	code, body = get(t, client, srv.URL+"/concerts/c1")
+	if code != http.StatusOK || !strings.Contains(body, `data-seat-name="R석 1번"`) {
+		t.Fatalf("座位图缺少座位: %d", code)
+	}
+
+	code, body = purchase(t, client, srv, url.Values{"seat": {"R석 1번"}})
+	if code != http.StatusOK {
+		t.Fatalf("下单失败: %d %s", code, body)
+	}
+	orders := site.Orders()
+	if len(orders) != 1 || orders[0].Total != 100000 || !reflect.DeepEqual(orders[0].Seats, []string{"R석 1번"}) {
+		t.Fatalf("订单 = %+v", orders)
+	}
+	if !strings.Contains(body, orders[0].ID) {
+		t.Fatal("确认页没有订单号")
+	}
+
+	if code, _ = purchase(t, client, srv, url.Values{"seat": {"R석 1번"}}); code != http.StatusConflict {
+		t.Fatalf("重复购买同一座位返回 %d，期望 409", code)
+	}
+
+	_, body = get(t, client, srv.URL+"/mypage/bookings")
+	if !strings.Contains(body, orders[0].ID) {
+		t.Fatal("我的预订中没有订单")
+	}
+}
+
+func TestCaptchaRequired(t *testing.T) {
+	site := New(Options{Captcha: true, Seed: 1})
+	srv := site.Start()
+	defer srv.Close()
+
+	client := login(t, srv)
+	if _, body := get(t, client, srv.URL+"/concerts/c1"); !strings.Contains(body, `id="captcha-input"`) {
+		t.Fatal("座位图缺少验证码")
+	}
+	answer := site.CaptchaAnswer(session(t, client, srv))
+	if len(answer) != 4 {
+		t.Fatalf("验证码 = %q", answer)
+	}
+
+	if code, _ := get(t, client, srv.URL+"/captcha.png"); code != http.StatusOK {
+		t.Fatalf("验证码图片返回 %d", code)
+	}
+
+	wrong := "0000"
+	if answer == wrong {
+		wrong = "1111"
+	}
+	if code, _ := purchase(t, client, srv, url.Values{"seat": {"R석 1번"}, "captcha": {wrong}}); code != http.StatusBadRequest {
+		t.Fatalf("验证码错误时返回 %d，期望 400", code)
+	}
+	if code, body := purchase(t, client, srv, url.Values{"seat": {"R석 1번"}, "captcha": {answer}}); code != http.StatusOK {
+		t.Fatalf("验证码正确时下单失败: %d %s", code, body)
+	}
+}
+
+func TestSellOut(t *testing.T) {
+	site := New(Options{Seed: 1})
+	srv := site.Start()
+	defer srv.Close()
+
+	client := login(t, srv)
+	site.SellOut()
+	if _, body := get(t, client, srv.URL+"/concerts/c1"); strings.Contains(body, `class="seat seat-available"`) {
+		t.Fatal("售罄后仍有可选座位")
+	}
+	if _, body := get(t, client, srv.URL+"/api/concerts/c1/tickets"); strings.Contains(body, `"available":true`) {
+		t.Fatalf("售罄后票务接口仍显示有票: %s", body)
+	}
+}
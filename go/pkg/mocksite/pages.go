@@ -0,0 +1,102 @@
+package mocksite
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// kst 页面上的时间按韩国时间显示
+var kst = time.FixedZone("KST", 9*60*60)
+
+var funcs = template.FuncMap{
+	"kst": func(t time.Time) string { return t.In(kst).Format("2006.01.02 15:04") },
+}
+
+// render 渲染页面
+func render(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl.Execute(w, data)
+}
+
+// page 用公共布局解析页面模板
+func page(body string) *template.Template {
+	return template.Must(template.New("page").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="ko"><head><meta charset="utf-8"><title>Mock Ticket</title></head>
+<body>` + body + `</body></html>`))
+}
+
+var loginPage = page(`
+<form method="post" action="/user/login">
+  {{if .}}<p class="error">{{.}}</p>{{end}}
+  <input id="username" name="username" type="text">
+  <input id="password" name="password" type="password">
+  <button type="submit">로그인</button>
+</form>`)
+
+var notOpenPage = page(`
+<div class="sale-not-open" data-open="{{.Open.Unix}}">{{kst .Open}} 오픈 예정</div>`)
+
+var queuePage = page(`
+<meta http-equiv="refresh" content="1">
+<div class="queue-waiting">대기 중입니다. 예상 대기 시간 {{.Wait}}</div>`)
+
+var seatMapPage = page(`
+<form id="purchase-form" method="post" action="/concerts/{{.ID}}/purchase">
+  <div class="seat-map">
+  {{range .Seats}}
+    {{if .Sold}}<span class="seat seat-sold">{{.Name}}</span>
+    {{else}}<button type="button" class="seat seat-available" data-seat data-seat-type="{{.Grade}}" data-seat-name="{{.Name}}">{{.Name}}</button>{{end}}
+  {{end}}
+  </div>
+  {{if .Captcha}}
+  <div class="captcha">
+    <img id="captcha-image" src="/captcha.png" alt="captcha">
+    <input id="captcha-input" name="captcha" type="text">
+  </div>
+  {{end}}
+  <button type="submit" class="btn-purchase">예매하기</button>
+</form>
+<script>
+document.querySelectorAll(".seat-available").forEach(function (seat) {
+  seat.addEventListener("click", function () {
+    var form = document.getElementById("purchase-form");
+    var name = seat.getAttribute("data-seat-name");
+    if (seat.classList.toggle("seat-selected")) {
+      seat.setAttribute("aria-selected", "true");
+      var input = document.createElement("input");
+      input.type = "hidden";
+      input.name = "seat";
+      input.value = name;
+      form.appendChild(input);
+    } else {
+      seat.removeAttribute("aria-selected");
+      form.querySelectorAll("input[name=seat]").forEach(function (input) {
+        if (input.value === name) input.remove();
+      });
+    }
+  });
+});
+</script>`)
+
+var orderPage = page(`
+<div class="payment-success">예매가 완료되었습니다</div>
+<div class="order-number">{{.ID}}</div>
+<ul class="seat-info">{{range .Seats}}<li>{{.}}</li>{{end}}</ul>
+<table class="price-list"><tr><th>티켓금액</th><td>{{.Total}}원</td></tr></table>
+<div class="total-price">{{.Total}}원</div>
+<div class="payment-deadline">{{kst .Deadline}} 까지</div>
+<div class="payment-method">무통장입금</div>
+<div class="delivery-method">현장수령</div>`)
+
+var bookingsPage = page(`
+<table class="reservation-list">
+{{range .}}<tr>
+  <td class="reserve-no">{{.ID}}</td>
+  <td class="title">{{.Concert}}</td>
+  <td class="play-date">{{kst .Deadline}}</td>
+  <td class="status">{{if .Canceled}}취소{{else}}예매완료{{end}}</td>
+</tr>{{end}}
+</table>`)
+
+var errorPage = page(`<div class="error-message">{{.}}</div>`)
@@ -4,16 +4,26 @@ import "time"
 
 // Config 配置结构
 type Config struct {
-	App          AppConfig          `json:"app"`
-	Browser      BrowserConfig      `json:"browser"`
-	Ticketing    TicketingConfig    `json:"ticketing"`
-	User         UserConfig         `json:"user"`
-	Tickets      TicketsConfig      `json:"tickets"`
-	Proxy        ProxyConfig        `json:"proxy"`
-	Notification NotificationConfig `json:"notification"`
-	Captcha      CaptchaConfig      `json:"captcha"`
-	Logging      LoggingConfig      `json:"logging"`
-	Concerts     []Concert          `json:"concerts"`
+	ConfigVersion int                `json:"config_version"`
+	App           AppConfig          `json:"app"`
+	Browser       BrowserConfig      `json:"browser"`
+	Ticketing     TicketingConfig    `json:"ticketing"`
+	User          UserConfig         `json:"user"`
+	Tickets       TicketsConfig      `json:"tickets"`
+	Proxy         ProxyConfig        `json:"proxy"`
+	Notification  NotificationConfig `json:"notification"`
+	Captcha       CaptchaConfig      `json:"captcha"`
+	Logging       LoggingConfig      `json:"logging"`
+	Health        HealthConfig       `json:"health"`
+	RPC           RPCConfig          `json:"rpc"`
+	Resources     ResourceConfig     `json:"resources"`
+	Distributed   DistributedConfig  `json:"distributed"`
+	SharedState   SharedStateConfig  `json:"shared_state"`
+	Leader        LeaderConfig       `json:"leader"`
+	Tracing       TracingConfig      `json:"tracing"`
+	Egress        EgressConfig       `json:"egress"`
+	Secrets       SecretsConfig      `json:"secrets"`
+	Concerts      []Concert          `json:"concerts"`
 }
 
 // AppConfig 应用配置
@@ -24,36 +34,263 @@ type AppConfig struct {
 }
 
 // BrowserConfig 浏览器配置
+// ExecPath 浏览器可执行文件，为空时自动查找Chrome、Chromium、Edge的常见安装位置；
+// Version 固定浏览器主版本(如 "120")，实际版本不一致时拒绝启动，为空时只检查最低版本
+// RemoteURL 使用已运行的浏览器(如Docker中单独的chrome-headless-shell容器)的ws://或http://地址，
+// 设置时不启动本地浏览器，环境变量CHROME_WS_URL优先
 type BrowserConfig struct {
-	Headless     bool   `json:"headless"`
-	UserAgent    string `json:"user_agent"`
-	Timeout      int    `json:"timeout"`
-	ImplicitWait int    `json:"implicit_wait"`
+	Headless           bool   `json:"headless"`
+	UserAgent          string `json:"user_agent"`
+	Timeout            int    `json:"timeout"`
+	ImplicitWait       int    `json:"implicit_wait"`
+	CookieSyncInterval int    `json:"cookie_sync_interval"`
+	ExecPath           string `json:"exec_path"`
+	Version            string `json:"version"`
+	RemoteURL          string `json:"remote_url"`
 }
 
 // TicketingConfig 票务配置
+// DuplicateGuard 开始抢票前发现同一演出已有订单时的处理: skip(默认，放弃任务)、warn(通知后继续)、off
+// SelectorHealing 选择器失效时的自动修复: log(默认，本次运行使用并记录日志)、persist(写回选择器文件)、off
+// AlertInterval、AlertCooldown 为 --notify-only 模式下每场演出的检查间隔和持续有票时重复提醒的间隔(秒)
+// TimelineDir 保存每次任务时间线报告(各阶段时间和耗时)的目录，为空时只输出到日志
+// ArtifactDir 每次任务在其中创建 <演唱会ID>_<时间> 目录，保存任务日志、截图、失败时的页面源码、时间线和结果，为空时不保存
+// EncryptArtifacts 用环境变量 TICKGRABBER_ARTIFACT_KEY 中的口令加密产物目录中的文件(扩展名.enc)，
+// 截图和页面源码写入后立即加密，任务日志保存在内存中、结束时加密写入(进程被强制结束时没有任务日志)；
+// orders_file、receipt_dir 中的收据(扩展名.enc)和 user.cookie_file 用同一口令加密(没有口令时不加密)，之前未加密的文件仍可读取，下次写入时加密
+// SessionCheckInterval 监控期间检查登录会话是否有效的间隔(秒)，会话失效时自动重新登录，0表示不检查
+// CalendarFeed 共享日历(.ics文件路径或URL)，启动时用其中的开售时间更新演唱会的 sale_open_time
+// HistoryFile 监控期间的余票状态记录(JSON Lines)，analyze 命令据此分析放票规律，为空时不记录
+// FastPathWindow 开售后多少秒内使用快速购买流程(<flow_dir>/<站点>/fast_path.yaml)，0为120秒
 type TicketingConfig struct {
-	Sites           map[string]SiteConfig `json:"sites"`
-	DefaultSite     string                `json:"default_site"`
-	AutoRefresh     bool                  `json:"auto_refresh"`
-	RefreshInterval float64               `json:"refresh_interval"`
-	MaxRetries      int                   `json:"max_retries"`
-	RetryDelay      float64               `json:"retry_delay"`
+	Sites                map[string]SiteConfig    `json:"sites"`
+	DefaultSite          string                   `json:"default_site"`
+	AutoRefresh          bool                     `json:"auto_refresh"`
+	RefreshInterval      float64                  `json:"refresh_interval"`
+	MaxRetries           int                      `json:"max_retries"`
+	RetryDelay           float64                  `json:"retry_delay"`
+	HeaderProfiles       map[string]HeaderProfile `json:"header_profiles"`
+	MaxIdleConnsPerHost  int                      `json:"max_idle_conns_per_host"`
+	PrewarmConnections   int                      `json:"prewarm_connections"`
+	CacheTTL             float64                  `json:"cache_ttl"`
+	CacheFile            string                   `json:"cache_file"`
+	OrdersFile           string                   `json:"orders_file"`
+	ReceiptDir           string                   `json:"receipt_dir"`
+	DuplicateGuard       string                   `json:"duplicate_guard"`
+	SelectorDir          string                   `json:"selector_dir"`
+	SelectorHealing      string                   `json:"selector_healing"`
+	FlowDir              string                   `json:"flow_dir"`
+	VenueFile            string                   `json:"venue_file"`
+	AlertInterval        float64                  `json:"alert_interval"`
+	AlertCooldown        float64                  `json:"alert_cooldown"`
+	SessionCheckInterval float64                  `json:"session_check_interval"`
+	TimelineDir          string                   `json:"timeline_dir"`
+	ArtifactDir          string                   `json:"artifact_dir"`
+	EncryptArtifacts     bool                     `json:"encrypt_artifacts"`
+	HistoryFile          string                   `json:"history_file"`
+	FastPathWindow       float64                  `json:"fast_path_window"`
+	CalendarFeed         string                   `json:"calendar_feed"`
+	Waits                WaitConfig               `json:"waits"`
+	Payment              PaymentConfig            `json:"payment"`
+	AdaptivePolling      AdaptivePollingConfig    `json:"adaptive_polling"`
+	Warmup               WarmupConfig             `json:"warmup"`
+}
+
+// WarmupConfig 开售前的预热。LeadMinutes 大于0时启动后先等待，到开售前 LeadMinutes 分钟才登录并进入演出页面，
+// 进入页面后关闭Cookie提示并点击 Accept 中的同意按钮(年龄确认、条款等)；
+// 等待开售期间每隔 KeepAlive 秒在页面上活动并检查登录会话，0为60秒，负数表示不保持
+type WarmupConfig struct {
+	LeadMinutes float64  `json:"lead_minutes"`
+	KeepAlive   float64  `json:"keep_alive"`
+	Accept      []string `json:"accept"`
+}
+
+// AdaptivePollingConfig 自适应轮询，启用后每场演出按服务器的Retry-After、Cache-Control和页面实际变化的频率
+// 调整检查间隔：长时间没有变化时逐步放慢到 MaxInterval，接近开售或页面频繁变化时加快到 MinInterval(秒)。
+// MinInterval 为0时不快于 refresh_interval，MaxInterval 为0时最长30秒
+type AdaptivePollingConfig struct {
+	Enabled     bool    `json:"enabled"`
+	MinInterval float64 `json:"min_interval"`
+	MaxInterval float64 `json:"max_interval"`
+}
+
+// WaitConfig 关键步骤等待页面的时间上限(秒)，条件满足时立即继续，不再固定等待
+// PageLoad 为进入演唱会页面后等待页面加载完成，PaymentPage 为点击购买后等待支付页面，
+// Payment 为等待用户完成支付(银行转账可能需要较长时间)，PaymentReminder 为等待支付期间重复提醒的间隔
+type WaitConfig struct {
+	PageLoad        float64 `json:"page_load"`
+	PaymentPage     float64 `json:"payment_page"`
+	Payment         float64 `json:"payment"`
+	PaymentReminder float64 `json:"payment_reminder"`
+}
+
+// PaymentConfig 外部支付窗口(KG이니시스、토스페이먼츠、카카오페이等)的处理
+// 检测到支付窗口时发送通知(附窗口地址和截图)，窗口关闭后继续确认支付结果；
+// VirtualAccount 为true时在窗口中自动选择虚拟账户(가상계좌)并申请发放，Bank 为选择的银行名称(如 국민은행)，
+// 卡支付和简单支付需要用户在窗口中完成
+type PaymentConfig struct {
+	VirtualAccount bool       `json:"virtual_account"`
+	Bank           string     `json:"bank"`
+	Card           CardConfig `json:"card"`
+}
+
+// CardConfig 银行卡自动填写，支付窗口是普通表单时填写卡号、有效期和分期，不会替用户点击付款
+// 必须同时设置 IAcceptRisk 才会启用；Encrypted 为 encrypt-card 命令生成的密文，解密口令从环境变量
+// TICKGRABBER_CARD_KEY 读取，卡号不写入日志和录制文件；Installment 为分期月数，0为一次付清
+type CardConfig struct {
+	IAcceptRisk bool   `json:"i_accept_risk"`
+	Encrypted   string `json:"encrypted"`
+	Installment int    `json:"installment"`
 }
 
 // SiteConfig 网站配置
+// Hosts 除URL外属于该站点的域名，用于根据演唱会地址自动选择站点
+// SessionCheckURL 检查登录是否有效的轻量页面(如我的页面)，为空时使用BookingsURL
+// PaymentCompleteURL 支付完成页面地址的正则表达式，浏览器跳转到匹配的地址时视为支付完成
+// Blocking 监控阶段阻止加载的资源，减少刷新的流量和延迟，进入选座前解除
+// ResaleBoard 官方转让(양도)页面的选择器，用于演唱会配置了 resale 时跟踪挂单价格
+// DirectCheckout 通过接口直接占座，开售前预先构造好请求
+// Gates 选座前后插入的条款同意、年龄确认、公告等页面的识别规则，与内置规则同名时替换内置规则
+// Egress 出站白名单中该站点额外需要的域名(如图片CDN、站点使用的支付网关)
+// AvailabilitySource 监控余票的来源: page(默认，浏览器中的演出页面)、mobile(先查 MobileAPI，失败时检查页面)
+// Strategy 占座方式: api(只用接口)、browser(只在页面中选座)、hybrid(先用接口，失败时在页面中选座)，
+// 为空时配置了DirectCheckout为hybrid，否则为browser
 type SiteConfig struct {
-	Name      string `json:"name"`
+	Name               string         `json:"name"`
+	URL                string         `json:"url"`
+	LoginURL           string         `json:"login_url"`
+	SearchURL          string         `json:"search_url"`
+	CSRF               CSRFConfig     `json:"csrf"`
+	HeaderProfile      string         `json:"header_profile"`
+	PrewarmURLs        []string       `json:"prewarm_urls"`
+	OrderPage          OrderPage      `json:"order_page"`
+	BookingsURL        string         `json:"bookings_url"`
+	BookingsPage       BookingsPage   `json:"bookings_page"`
+	SessionCheckURL    string         `json:"session_check_url"`
+	PaymentCompleteURL string         `json:"payment_complete_url"`
+	Adapter            AdapterConfig  `json:"adapter"`
+	Hosts              []string       `json:"hosts"`
+	Blocking           BlockingConfig `json:"blocking"`
+	ResaleBoard        ResaleBoard    `json:"resale_board"`
+	DirectCheckout     DirectCheckout `json:"direct_checkout"`
+	Strategy           string         `json:"strategy"`
+	Gates              []GateRule     `json:"gates"`
+	AvailabilitySource string         `json:"availability_source"`
+	MobileAPI          MobileAPI      `json:"mobile_api"`
+	Egress             []string       `json:"egress"`
+}
+
+// BlockingConfig 请求阻止配置，Images、Media、Fonts 分别阻止图片、音视频和字体，
+// ThirdParty 阻止常见的统计、广告和客服脚本，Patterns 为额外的地址模式(支持*通配符)
+type BlockingConfig struct {
+	Images     bool     `json:"images"`
+	Media      bool     `json:"media"`
+	Fonts      bool     `json:"fonts"`
+	ThirdParty bool     `json:"third_party"`
+	Patterns   []string `json:"patterns"`
+}
+
+// DirectCheckout 直接调用占座接口的配置，HoldURL为空时不使用，在浏览器中逐步选座
+// SeatMapURL 返回座位图JSON，Blocks 为其中区域列表的路径(点分隔)，区域的 Grade 字段与preferred_seats匹配
+// HoldURL 和 Body 为text/template模板，可以引用流程变量和所选区域的字段(如 {{.block.blockId}})
+// Success 为响应JSON中表示占座成功的字段，NextURL 为下一步(领取方式、结算)页面地址的字段
+type DirectCheckout struct {
+	SeatMapURL  string            `json:"seat_map_url"`
+	Blocks      string            `json:"blocks"`
+	Grade       string            `json:"grade"`
+	HoldURL     string            `json:"hold_url"`
+	Method      string            `json:"method"`
+	ContentType string            `json:"content_type"`
+	Body        string            `json:"body"`
+	Headers     map[string]string `json:"headers"`
+	Success     string            `json:"success"`
+	NextURL     string            `json:"next_url"`
+}
+
+// GateRule 条款同意、年龄确认等页面的识别规则，Detect 中任一选择器存在即视为出现，
+// 先点击 Check 中存在的复选框，再点击 Submit 中第一个存在的按钮；Manual 为true表示需要手动完成(如本人认证)
+type GateRule struct {
+	Name   string   `json:"name"`
+	Detect []string `json:"detect"`
+	Check  []string `json:"check"`
+	Submit []string `json:"submit"`
+	Manual bool     `json:"manual"`
+}
+
+// MobileAPI 移动网页版的余票接口，URL 为text/template模板，可以引用流程变量和商品编号 {{.goods_code}}
+// Blocks 为响应JSON中座位等级列表的路径(点分隔)，Grade、Remain 为其中等级名称和余票数的字段
+type MobileAPI struct {
 	URL       string `json:"url"`
-	LoginURL  string `json:"login_url"`
-	SearchURL string `json:"search_url"`
+	Blocks    string `json:"blocks"`
+	Grade     string `json:"grade"`
+	Remain    string `json:"remain"`
+	UserAgent string `json:"user_agent"`
+}
+
+// AdapterConfig 外部站点适配器进程，Command为空时不使用适配器
+type AdapterConfig struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+}
+
+// ResaleBoard 官方转让页面的CSS选择器，未配置的项使用通用默认值
+// Rows 下的每一行为一条挂单，ListingID 为空时用行内链接或文字识别同一条挂单
+type ResaleBoard struct {
+	Rows      string `json:"rows"`
+	ListingID string `json:"listing_id"`
+	Price     string `json:"price"`
+	Seat      string `json:"seat"`
+	Link      string `json:"link"`
+}
+
+// BookingsPage "我的预订"页面的CSS选择器，Rows 下的每一行为一个订单
+type BookingsPage struct {
+	Rows    string `json:"rows"`
+	OrderID string `json:"order_id"`
+	Title   string `json:"title"`
+	Date    string `json:"date"`
+	Status  string `json:"status"`
+}
+
+// OrderPage 订单确认页的CSS选择器，未配置的项使用通用默认值
+// PriceRows 下的每一行取 PriceLabel 和 PriceAmount 作为价格明细
+// DeadlineFormat 为付款期限的Go时间格式，为空时尝试常见格式
+type OrderPage struct {
+	OrderID         string `json:"order_id"`
+	Seats           string `json:"seats"`
+	PriceRows       string `json:"price_rows"`
+	PriceLabel      string `json:"price_label"`
+	PriceAmount     string `json:"price_amount"`
+	Total           string `json:"total"`
+	PaymentDeadline string `json:"payment_deadline"`
+	DeadlineFormat  string `json:"deadline_format"`
+	PaymentMethod   string `json:"payment_method"`
+	DeliveryMethod  string `json:"delivery_method"`
+}
+
+// HeaderProfile 请求头配置
+// Headers 为站点接口要求的自定义请求头(如Referer、X-Requested-With)
+type HeaderProfile struct {
+	AcceptLanguage string            `json:"accept_language"`
+	Headers        map[string]string `json:"headers"`
+}
+
+// CSRFConfig CSRF令牌配置
+// Source 可选 form、meta、cookie、json；Name 为对应的字段名、Cookie名或JSON键
+type CSRFConfig struct {
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	Header string `json:"header"`
 }
 
 // UserConfig 用户配置
+// CookieFile 保存登录会话的Cookie文件，文件存在时用其中的Cookie代替第一次表单登录，登录成功后更新
 type UserConfig struct {
-	Username  string `json:"username"`
-	Password  string `json:"password"`
-	AutoLogin bool   `json:"auto_login"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	AutoLogin  bool   `json:"auto_login"`
+	CookieFile string `json:"cookie_file"`
 }
 
 // TicketsConfig 票务配置
@@ -61,6 +298,34 @@ type TicketsConfig struct {
 	MaxPrice        int             `json:"max_price"`
 	PreferredSeats  []string        `json:"preferred_seats"`
 	SeatPreferences SeatPreferences `json:"seat_preferences"`
+	Delivery        Delivery        `json:"delivery"`
+	Guardrails      Guardrails      `json:"guardrails"`
+}
+
+// Guardrails 确认购买前检查的总量限制，防止数量或票价配置错误时买得远超预期，各项为0时不限制
+// MaxSpendPerRun 本次运行(守护模式下所有任务合计)的金额上限，MaxSpendPerDay 按订单记录统计的当天金额上限，
+// MaxTicketsPerConcert 每场演出的张数上限(含已有订单)；ConfirmAbove 单笔金额超过该值时先在Telegram询问，
+// 回复 YES 才确认购买，ConfirmTimeout 秒内没有回复时放弃(默认120)
+type Guardrails struct {
+	MaxSpendPerRun       int     `json:"max_spend_per_run"`
+	MaxSpendPerDay       int     `json:"max_spend_per_day"`
+	MaxTicketsPerConcert int     `json:"max_tickets_per_concert"`
+	ConfirmAbove         int     `json:"confirm_above"`
+	ConfirmTimeout       float64 `json:"confirm_timeout"`
+}
+
+// Delivery 票的领取方式
+// Method: mobile(모바일티켓，默认)、pickup(현장수령)、mail(배송)；Label 为页面上选项的文字，默认按方式取值
+// 配送时填写收件信息，UseSavedAddress 为true时选择网站保存的默认地址
+type Delivery struct {
+	Method          string `json:"method"`
+	Label           string `json:"label"`
+	Recipient       string `json:"recipient"`
+	Phone           string `json:"phone"`
+	Postcode        string `json:"postcode"`
+	Address         string `json:"address"`
+	AddressDetail   string `json:"address_detail"`
+	UseSavedAddress bool   `json:"use_saved_address"`
 }
 
 // SeatPreferences 座位偏好
@@ -81,19 +346,66 @@ type ProxyConfig struct {
 
 // NotificationConfig 通知配置
 type NotificationConfig struct {
-	Email    EmailConfig    `json:"email"`
-	Telegram TelegramConfig `json:"telegram"`
-	Desktop  DesktopConfig  `json:"desktop"`
+	Email           EmailConfig             `json:"email"`
+	Telegram        TelegramConfig          `json:"telegram"`
+	Desktop         DesktopConfig           `json:"desktop"`
+	Discord         []WebhookTarget         `json:"discord"`
+	Slack           []WebhookTarget         `json:"slack"`
+	Gateways        []GatewayConfig         `json:"gateways"`
+	Callbacks       []CallbackConfig        `json:"callbacks"`
+	Hooks           map[string][]HookConfig `json:"hooks"`
+	Routes          []NotificationRoute     `json:"routes"`
+	QuietHours      QuietHours              `json:"quiet_hours"`
+	Retry           NotificationRetry       `json:"retry"`
+	DeadLetterFile  string                  `json:"dead_letter_file"`
+	PaymentReminder PaymentReminder         `json:"payment_reminder"`
+}
+
+// PaymentReminder 付款期限提醒，Offsets 为截止前多少分钟提醒，越接近截止越紧急
+type PaymentReminder struct {
+	Enabled bool  `json:"enabled"`
+	Offsets []int `json:"offsets"`
+}
+
+// NotificationRetry 通知重试策略，退避时间单位为秒
+type NotificationRetry struct {
+	MaxAttempts    int     `json:"max_attempts"`
+	InitialBackoff float64 `json:"initial_backoff"`
+	MaxBackoff     float64 `json:"max_backoff"`
+}
+
+// NotificationRoute 通知路由规则
+// Events 为事件类型列表("*"表示全部)，Channels 为渠道名("discord"匹配所有Discord渠道)，
+// MinInterval 为同一事件的最小发送间隔(秒)
+type NotificationRoute struct {
+	Events           []string `json:"events"`
+	MinSeverity      string   `json:"min_severity"`
+	Channels         []string `json:"channels"`
+	MinInterval      float64  `json:"min_interval"`
+	IgnoreQuietHours bool     `json:"ignore_quiet_hours"`
+}
+
+// QuietHours 免打扰时段，Start/End 格式为 HH:MM，可跨越午夜
+// 严重程度不低于 AllowSeverity(默认critical)的事件不受影响
+type QuietHours struct {
+	Enabled       bool   `json:"enabled"`
+	Start         string `json:"start"`
+	End           string `json:"end"`
+	Timezone      string `json:"timezone"`
+	AllowSeverity string `json:"allow_severity"`
 }
 
 // EmailConfig 邮件配置
 type EmailConfig struct {
-	Enabled    bool   `json:"enabled"`
-	SMTPServer string `json:"smtp_server"`
-	SMTPPort   int    `json:"smtp_port"`
-	Sender     string `json:"sender"`
-	Password   string `json:"password"`
-	Recipient  string `json:"recipient"`
+	Enabled    bool              `json:"enabled"`
+	SMTPServer string            `json:"smtp_server"`
+	SMTPPort   int               `json:"smtp_port"`
+	Sender     string            `json:"sender"`
+	Password   string            `json:"password"`
+	Recipient  string            `json:"recipient"`
+	Username   string            `json:"username"`
+	Security   string            `json:"security"`
+	Templates  map[string]string `json:"templates"`
 }
 
 // TelegramConfig Telegram配置
@@ -103,26 +415,283 @@ type TelegramConfig struct {
 	ChatID   string `json:"chat_id"`
 }
 
+// WebhookTarget Webhook通知目标
+// Events 为空时接收所有事件，否则只接收列出的事件类型
+type WebhookTarget struct {
+	Enabled    bool     `json:"enabled"`
+	Name       string   `json:"name"`
+	WebhookURL string   `json:"webhook_url"`
+	Events     []string `json:"events"`
+}
+
+// GatewayConfig 通用HTTP网关通知配置(短信、KakaoTalk等)
+// URL 和 BodyTemplate 使用Go text/template语法，模板数据为通知事件
+type GatewayConfig struct {
+	Enabled      bool              `json:"enabled"`
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Method       string            `json:"method"`
+	ContentType  string            `json:"content_type"`
+	Headers      map[string]string `json:"headers"`
+	BodyTemplate string            `json:"body_template"`
+	Events       []string          `json:"events"`
+}
+
+// CallbackConfig 任务完成回调，以JSON POST到URL
+// Secret 非空时对请求体做HMAC-SHA256签名；Events 为空时只发送购票成功和失败事件
+type CallbackConfig struct {
+	Enabled bool     `json:"enabled"`
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Secret  string   `json:"secret"`
+	Events  []string `json:"events"`
+}
+
+// HookConfig 事件发生时执行的外部命令，在 notification.hooks 中按 on_<事件类型> 配置
+// (如 on_ticket_found、on_purchase_success、on_error)
+// Command 和 Args 直接执行，可以用 text/template 引用事件字段(如 {{.Concert}}、{{.OrderID}}、{{index .Details "座位"}})；
+// Shell 为交给系统shell执行的命令行，不做模板替换，事件字段从 TICKGRABBER_* 环境变量读取，
+// 两者只能设置一个。Timeout 为超时秒数，默认30
+type HookConfig struct {
+	Enabled bool     `json:"enabled"`
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Shell   string   `json:"shell"`
+	Timeout float64  `json:"timeout"`
+}
+
 // DesktopConfig 桌面通知配置
 type DesktopConfig struct {
-	Enabled bool `json:"enabled"`
-	Sound   bool `json:"sound"`
+	Enabled     bool     `json:"enabled"`
+	Sound       bool     `json:"sound"`
+	SoundFile   string   `json:"sound_file"`
+	AlarmRepeat int      `json:"alarm_repeat"`
+	AlarmEvents []string `json:"alarm_events"`
 }
 
 // CaptchaConfig 验证码配置
 type CaptchaConfig struct {
-	AutoSolve bool   `json:"auto_solve"`
-	Service   string `json:"service"`
-	APIKey    string `json:"api_key"`
-	Timeout   int    `json:"timeout"`
+	AutoSolve      bool    `json:"auto_solve"`
+	Service        string  `json:"service"`
+	APIKey         string  `json:"api_key"`
+	Timeout        int     `json:"timeout"`
+	Relay          string  `json:"relay"`
+	ImageSelector  string  `json:"image_selector"`
+	InputSelector  string  `json:"input_selector"`
+	SubmitSelector string  `json:"submit_selector"`
+	CostPerSolve   float64 `json:"cost_per_solve"`
+	MaxSpendPerRun float64 `json:"max_spend_per_run"`
 }
 
 // LoggingConfig 日志配置
+// 日志、浏览器录制文件和追踪数据中的密码、令牌、卡号和电话号码等总是脱敏，Mask 为额外需要脱敏的正则表达式
 type LoggingConfig struct {
-	Level       string `json:"level"`
-	File        string `json:"file"`
-	MaxSize     string `json:"max_size"`
-	BackupCount int    `json:"backup_count"`
+	Level       string   `json:"level"`
+	File        string   `json:"file"`
+	MaxSize     string   `json:"max_size"`
+	BackupCount int      `json:"backup_count"`
+	Mask        []string `json:"mask"`
+}
+
+// HealthConfig 健康检查配置
+// StallTimeout 为监控循环多久没有成功检查即视为卡住(秒)
+// BasePath 放在反向代理的子路径下时的路径前缀(如 /tickgrabber)；TrustedProxies 为可信代理的IP或网段，
+// 只有来自这些地址的请求才按 CF-Connecting-IP、X-Forwarded-For、X-Forwarded-Proto 还原客户端；
+// Allow 非空时只接受这些IP或网段的客户端；Token 非空时请求需携带 Authorization: Bearer <token>，
+// 监听非本机地址时必须配置 Token
+type HealthConfig struct {
+	Enabled        bool      `json:"enabled"`
+	Listen         string    `json:"listen"`
+	CheckInterval  int       `json:"check_interval"`
+	StallTimeout   int       `json:"stall_timeout"`
+	MaxMemoryMB    uint64    `json:"max_memory_mb"`
+	TLS            TLSConfig `json:"tls"`
+	BasePath       string    `json:"base_path"`
+	TrustedProxies []string  `json:"trusted_proxies"`
+	Allow          []string  `json:"allow"`
+	Token          string    `json:"token"`
+}
+
+// TLSConfig HTTPS证书，CertFile/KeyFile 和 Autocert 只能配置一种，都为空时使用HTTP
+// Autocert 为自动向Let's Encrypt申请证书的域名，要求从公网能访问监听的443端口，证书保存在 CacheDir(默认 data/autocert)
+type TLSConfig struct {
+	CertFile string   `json:"cert_file"`
+	KeyFile  string   `json:"key_file"`
+	Autocert []string `json:"autocert"`
+	CacheDir string   `json:"cache_dir"`
+}
+
+// RPCConfig 守护模式的gRPC接口配置
+// Token 非空时客户端需在metadata中携带 authorization: Bearer <token>，该令牌拥有全部权限；
+// Tokens 为按权限分开的令牌，如只读令牌用于和朋友共享的进度面板。配置了任一令牌后都需要认证。
+// 客户端(status、worker)使用 token 中的令牌。没有配置令牌时只能监听本机地址
+type RPCConfig struct {
+	Enabled bool         `json:"enabled"`
+	Listen  string       `json:"listen"`
+	Token   string       `json:"token"`
+	Tokens  []RPCToken   `json:"tokens"`
+	TLS     RPCTLSConfig `json:"tls"`
+}
+
+// RPCTLSConfig gRPC的TLS配置，令牌经过网络传输时应启用
+// CertFile、KeyFile 为服务端证书，配置后gRPC接口只接受TLS连接；客户端(status、worker)在配置了 CAFile 或 CertFile 时
+// 使用TLS连接，CAFile 为验证服务端证书的CA(自签名证书填写证书本身)，ServerName 为证书中的域名(默认取连接地址中的主机名)
+type RPCTLSConfig struct {
+	CertFile   string `json:"cert_file"`
+	KeyFile    string `json:"key_file"`
+	CAFile     string `json:"ca_file"`
+	ServerName string `json:"server_name"`
+}
+
+// RPCToken 有权限范围的rpc令牌
+// Role 为 read(查询任务、演唱会和订阅脱敏后的事件)、control(还能提交和取消任务)或 admin(全部权限，包括工作节点连接)
+type RPCToken struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	Role  string `json:"role"`
+}
+
+// ResourceConfig 守护模式下的资源限制，各项为0时不限制
+// MaxBrowsers 为同时运行的浏览器数量上限(每个任务只使用一个标签页，同时也是标签页上限)，超出时按演唱会的
+// priority 排队，高优先级任务抢占低优先级任务的浏览器；TimeSlice 为同优先级任务轮流使用浏览器的时间片(秒，0为不轮换)
+// MaxMemoryMB 为所有浏览器的内存预算，按每个浏览器 BrowserMemoryMB 估算可同时运行的浏览器数量
+// MaxRequestsPerSecond 为所有任务合计的每秒请求数(页面刷新和API请求)
+type ResourceConfig struct {
+	MaxBrowsers          int     `json:"max_browsers"`
+	TimeSlice            float64 `json:"time_slice"`
+	MaxMemoryMB          int     `json:"max_memory_mb"`
+	BrowserMemoryMB      int     `json:"browser_memory_mb"`
+	MaxRequestsPerSecond float64 `json:"max_requests_per_second"`
+}
+
+// DistributedConfig 分布式模式配置
+// Role 为 controller 时守护模式不在本机运行任务，而是通过gRPC接口(需启用rpc)分配给连接的工作节点，
+// 每场演出同时分配给 Replicas 个节点，只有最先发现有票的节点获得购买权
+// 工作节点(worker命令)连接 Controller 地址，用 rpc.token 认证；Name、Region 用于区分节点，
+// Capacity 为节点同时运行的任务数上限(0为不限制)
+type DistributedConfig struct {
+	Role       string `json:"role"`
+	Controller string `json:"controller"`
+	Name       string `json:"name"`
+	Region     string `json:"region"`
+	Capacity   int    `json:"capacity"`
+	Replicas   int    `json:"replicas"`
+}
+
+// SharedStateConfig 多实例共享状态，Redis 为 host:port，为空时不共享
+// 同一账号运行多个实例(如每个代理一个)时，各实例共享登录会话(SessionTTL 秒)和元数据缓存，
+// 发现有票时先取得该账号、该演出的购买锁(LockTTL 秒后自动释放)，购票成功后标记为已购买，其他实例不再购买
+type SharedStateConfig struct {
+	Redis      string  `json:"redis"`
+	Password   string  `json:"password"`
+	DB         int     `json:"db"`
+	Prefix     string  `json:"prefix"`
+	SessionTTL float64 `json:"session_ttl"`
+	LockTTL    float64 `json:"lock_ttl"`
+}
+
+// LeaderConfig 冗余实例的主备选举，每场演出单独选举，只有主实例执行购买，其他实例作为热备继续监控
+// Backend 为 file(Path 为共享的租约目录，每场演出一个文件)或 redis(使用 shared_state 的Redis)，为空时不选举
+// TTL 为租约有效期(秒)，主实例失联超过该时间后由热备实例接替
+type LeaderConfig struct {
+	Backend string  `json:"backend"`
+	Path    string  `json:"path"`
+	TTL     float64 `json:"ttl"`
+}
+
+// SecretsConfig 密钥来源的配置，配置中任何整个值为 secret://<来源>/<引用> 的字符串在启动时替换为密钥，
+// 来源为 keychain(<服务>/<账号>)、vault(API路径，可加 #字段)、aws(密钥名称或ARN，可加 #字段)、env(环境变量名)
+type SecretsConfig struct {
+	Vault VaultConfig      `json:"vault"`
+	AWS   AWSSecretsConfig `json:"aws"`
+}
+
+// VaultConfig HashiCorp Vault，Address 为空时使用环境变量 VAULT_ADDR
+// KubernetesRole 非空时用Pod的服务账号登录(KubernetesMount 默认 kubernetes)，否则使用环境变量 VAULT_TOKEN 或 TokenFile(默认 ~/.vault-token)
+type VaultConfig struct {
+	Address         string `json:"address"`
+	Namespace       string `json:"namespace"`
+	TokenFile       string `json:"token_file"`
+	KubernetesRole  string `json:"kubernetes_role"`
+	KubernetesMount string `json:"kubernetes_mount"`
+}
+
+// AWSSecretsConfig AWS Secrets Manager，Region 为空时使用环境变量 AWS_REGION，Endpoint 可指定VPC端点
+// 凭证使用环境变量、ECS任务角色或EC2实例角色
+type AWSSecretsConfig struct {
+	Region   string `json:"region"`
+	Endpoint string `json:"endpoint"`
+}
+
+// EgressConfig 出站地址白名单，HTTP客户端和浏览器页面的请求都按白名单检查
+// Mode 为 off(默认，不检查)、audit(记录不在白名单中的地址)或 enforce(阻止这些请求)；
+// 白名单自动包含各站点地址及其 egress 域名、演唱会页面、常见支付网关、启用的通知渠道和追踪导出地址，
+// Allow 为额外允许的域名(包括其子域名)
+type EgressConfig struct {
+	Mode  string   `json:"mode"`
+	Allow []string `json:"allow"`
+}
+
+// TracingConfig OpenTelemetry追踪，span通过OTLP/HTTP导出
+// Endpoint 为导出地址(如Jaeger的 http://localhost:4318/v1/traces)，为空时不启用
+// SampleRatio 为采样比例(0到1)，0或1表示全部采样
+type TracingConfig struct {
+	Endpoint    string  `json:"endpoint"`
+	ServiceName string  `json:"service_name"`
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// Listing 同一场演出在其他票务网站的售票页面
+// 演唱会配置了 Listings 时同时监控主页面和这些页面，从最先有票的网站购买，其余网站的任务随即取消
+type Listing struct {
+	Site string `json:"site"`
+	URL  string `json:"url"`
+}
+
+// Resale 官方转让/二次售票页面(如인터파크 공식 양도)的挂单监控，URL 为该演出的转让列表页面
+// 出现价格不超过 max_price(未配置时不限价格)的新挂单时提醒，由用户手动购买；Interval 为检查间隔(秒)，默认60
+type Resale struct {
+	URL      string  `json:"url"`
+	Interval float64 `json:"interval"`
+}
+
+// Round 演出场次，按页面上的写法填写日期和时间，Label 为场次选项的文字(可选)
+// 演唱会的 Rounds 按优先级排列，优先的场次售罄时依次尝试后面的场次
+type Round struct {
+	Date  string `json:"date"`
+	Time  string `json:"time"`
+	Label string `json:"label"`
+}
+
+// String 返回场次描述
+func (r Round) String() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	if r.Time == "" {
+		return r.Date
+	}
+	return r.Date + " " + r.Time
+}
+
+// Presale 粉丝俱乐部预售的会员验证
+// Membership 为会员类型(如 "ARMY 멤버십")，Fields 为站点特有的其他字段，键为选择器
+type Presale struct {
+	Code       string            `json:"code"`
+	Membership string            `json:"membership"`
+	Fields     map[string]string `json:"fields"`
+}
+
+// Schedule 守护模式下的监控时段，时段之外不打开浏览器、不访问售票网站
+// Windows 为每天的固定时段(如 "10:00-23:00"，结束早于开始时跨越午夜)
+// Cron 为开始监控的cron表达式(分 时 日 月 周，如 "50 9 * * *" 每天9:50开始)，Duration 为每次监控的时长(分钟)
+// Timezone 为时段使用的时区，默认韩国时间；正在购买时时段结束不会中断购买
+type Schedule struct {
+	Windows  []string `json:"windows"`
+	Cron     []string `json:"cron"`
+	Duration int      `json:"duration"`
+	Timezone string   `json:"timezone"`
 }
 
 // Concert 演唱会信息
@@ -137,7 +706,15 @@ type Concert struct {
 	URL            string    `json:"url"`
 	MaxPrice       int       `json:"max_price"`
 	PreferredSeats []string  `json:"preferred_seats"`
+	Priority       int       `json:"priority"`
+	Rounds         []Round   `json:"rounds"`
+	Listings       []Listing `json:"listings"`
+	Resale         *Resale   `json:"resale,omitempty"`
+	Presale        Presale   `json:"presale"`
+	Delivery       *Delivery `json:"delivery,omitempty"`
+	Schedule       *Schedule `json:"schedule,omitempty"`
 	Status         string    `json:"status"`
+	SaleOpenTime   time.Time `json:"sale_open_time"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
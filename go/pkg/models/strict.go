@@ -0,0 +1,175 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownField 配置中没有对应字段的键
+type UnknownField struct {
+	// Path 键的位置，如 ticketing.refreshinterval、concerts[0].maxprice
+	Path string
+	// Suggestion 最接近的已知字段，没有相近的字段时为空
+	Suggestion string
+}
+
+// UnknownFieldsError 严格模式下配置中有未知字段
+type UnknownFieldsError struct {
+	Fields []UnknownField
+}
+
+// Error 按行列出所有未知字段和相近的已知字段
+func (e *UnknownFieldsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置中有 %d 个未知字段:", len(e.Fields))
+	for _, f := range e.Fields {
+		b.WriteString("\n  - " + f.Path)
+		if f.Suggestion != "" {
+			b.WriteString("\n  + " + f.Suggestion)
+		}
+	}
+	return b.String()
+}
+
+// FindUnknownFields 列出JSON配置中 Config 没有的键，按出现位置排序
+func FindUnknownFields(data []byte) ([]UnknownField, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var fields []UnknownField
+	walkUnknown(doc, reflect.TypeOf(Config{}), "", &fields)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields, nil
+}
+
+// walkUnknown 按类型t检查v中的键
+func walkUnknown(v interface{}, t reflect.Type, path string, out *[]UnknownField) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		known := jsonFields(t)
+		for key, child := range obj {
+			field, ok := lookupField(known, key)
+			if !ok {
+				*out = append(*out, UnknownField{Path: fieldPath(path, key), Suggestion: suggest(known, key, path)})
+				continue
+			}
+			walkUnknown(child, field.Type, fieldPath(path, key), out)
+		}
+	case reflect.Map:
+		if obj, ok := v.(map[string]interface{}); ok {
+			for key, child := range obj {
+				walkUnknown(child, t.Elem(), fieldPath(path, key), out)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if list, ok := v.([]interface{}); ok {
+			for i, child := range list {
+				walkUnknown(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	}
+}
+
+// jsonFields 结构体各字段的JSON键，包括嵌入结构体的字段
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// lookupField 与encoding/json一致，键不区分大小写匹配字段
+func lookupField(known map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if f, ok := known[key]; ok {
+		return f, true
+	}
+	for name, f := range known {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// suggest 找出与key最接近的已知字段: 去掉下划线后相同，或编辑距离不超过键长的三分之一
+func suggest(known map[string]reflect.StructField, key, path string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	best, bestDist := "", -1
+	for name := range known {
+		d := editDistance(normalize(key), normalize(name))
+		if d == 0 {
+			return fieldPath(path, name)
+		}
+		if d <= (len(key)+2)/3 && (bestDist < 0 || d < bestDist || d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fieldPath(path, best)
+}
+
+// editDistance 两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// fieldPath 拼接键的位置
+func fieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
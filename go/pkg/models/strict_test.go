@@ -0,0 +1,160 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []UnknownField
+	}{
+		{
+			name: "拼写错误",
+			data: `{"ticketing": {"refreshinterval": 1}}`,
+			want: []UnknownField{{Path: "ticketing.refreshinterval", Suggestion: "ticketing.refresh_interval"}},
+		},
+		{
+			name: "切片元素",
+			data: `{"concerts": [{"id": "a"}, {"id": "b", "maxprice": 1}]}`,
+			want: []UnknownField{{Path: "concerts[1].maxprice", Suggestion: "concerts[1].max_price"}},
+		},
+		{
+			name: "map的值",
+			data: `{"ticketing": {"sites": {"interpark": {"loginurl": "x"}}}}`,
+			want: []UnknownField{{Path: "ticketing.sites.interpark.loginurl", Suggestion: "ticketing.sites.interpark.login_url"}},
+		},
+		{
+			name: "大小写不同的已知字段",
+			data: `{"Ticketing": {"Refresh_Interval": 1}, "CONCERTS": [{"Max_Price": 1}]}`,
+		},
+		{
+			name: "没有相近字段",
+			data: `{"ticketing": {"zzzzzzzz": 1}}`,
+			want: []UnknownField{{Path: "ticketing.zzzzzzzz"}},
+		},
+		{
+			name: "按位置排序",
+			data: `{"user": {"pasword": "x"}, "app": {"nmae": "x"}, "extra": 1}`,
+			want: []UnknownField{
+				{Path: "app.nmae", Suggestion: "app.name"},
+				{Path: "extra"},
+				{Path: "user.pasword", Suggestion: "user.password"},
+			},
+		},
+		{
+			name: "类型不符时不继续检查",
+			data: `{"ticketing": "x", "concerts": {"a": 1}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindUnknownFields([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindUnknownFields = %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FindUnknownFields([]byte(`{"app":`)); err == nil {
+		t.Error("无效的JSON应返回错误")
+	}
+}
+
+func TestUnknownFieldsError(t *testing.T) {
+	err := &UnknownFieldsError{Fields: []UnknownField{
+		{Path: "ticketing.refreshinterval", Suggestion: "ticketing.refresh_interval"},
+		{Path: "extra"},
+	}}
+	want := "配置中有 2 个未知字段:\n  - ticketing.refreshinterval\n  + ticketing.refresh_interval\n  - extra"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q，期望 %q", got, want)
+	}
+}
+
+func TestWalkUnknownEmbedded(t *testing.T) {
+	type Base struct {
+		Name string `json:"name"`
+	}
+	type Wrapper struct {
+		Base
+		Skip  string `json:"-"`
+		Plain int
+		inner int
+	}
+	var fields []UnknownField
+	doc := map[string]interface{}{"name": "a", "Plain": 1, "Skip": "x", "inner": 1}
+	walkUnknown(doc, reflect.TypeOf(&Wrapper{}), "", &fields)
+
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, ","); !strings.Contains(got, "Skip") || !strings.Contains(got, "inner") || len(paths) != 2 {
+		t.Errorf("未知字段 %v，期望 Skip 和 inner", paths)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	type target struct {
+		Timeout     int `json:"timeout"`
+		MaxRetries  int `json:"max_retries"`
+		RetryDelay  int `json:"retry_delay"`
+		Proxy       int `json:"proxy"`
+		ProxyServer int `json:"proxy_server"`
+		Cat         int `json:"cat"`
+		Car         int `json:"car"`
+	}
+	known := jsonFields(reflect.TypeOf(target{}))
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		// 去掉下划线、连字符并忽略大小写后相同
+		{"maxretries", "p.max_retries"},
+		{"Max-Retries", "p.max_retries"},
+		// 编辑距离不超过键长的三分之一(向上取整)
+		{"timeot", "p.timeout"}, // 距离1，上限2
+		{"tmeot", "p.timeout"},  // 距离2，上限2
+		{"tmot", ""},            // 距离3，上限2
+		{"retrydelya", "p.retry_delay"},
+		{"proxyserver", "p.proxy_server"},
+		{"proxi", "p.proxy"},
+		// 距离相同时取名字排在前面的字段
+		{"cax", "p.car"},
+		{"zzz", ""},
+	}
+	for _, tt := range tests {
+		if got := suggest(known, tt.key, "p"); got != tt.want {
+			t.Errorf("suggest(%q) = %q，期望 %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"refreshinterval", "refreshinterval", 0},
+		{"timeout", "timeot", 1},
+		{"ab", "ba", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d，期望 %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
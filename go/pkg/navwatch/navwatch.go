@@ -0,0 +1,129 @@
+// Package navwatch 判断监控期间页面被跳转到了哪里(登录页、错误页、维护公告、排队页)，由调用方选择恢复方式
+//
+// 只根据地址和页面开头的文字判断，不依赖站点的页面结构；无法确定的同站页面视为仍在演出页面，
+// 避免选择场次等正常的页面变化被当作跳转
+package navwatch
+
+import (
+	"net/url"
+	"strings"
+
+	"tickgrabber/pkg/models"
+)
+
+// Kind 页面位置
+type Kind int
+
+const (
+	// Expected 仍在演出页面
+	Expected Kind = iota
+	// Login 被跳转到登录页，会话已过期
+	Login
+	// Error 错误页面
+	Error
+	// Maintenance 系统维护公告
+	Maintenance
+	// Waiting 排队(대기열)页面，属于正常流程
+	Waiting
+	// Elsewhere 其他网站或首页
+	Elsewhere
+)
+
+// String 返回页面位置的名称
+func (k Kind) String() string {
+	switch k {
+	case Expected:
+		return "演出页面"
+	case Login:
+		return "登录页"
+	case Error:
+		return "错误页"
+	case Maintenance:
+		return "维护公告"
+	case Waiting:
+		return "排队页面"
+	case Elsewhere:
+		return "其他页面"
+	default:
+		return "未知"
+	}
+}
+
+// 地址和页面文字中的关键字，按Waiting、Maintenance、Error的顺序检查
+var (
+	waitingURL      = []string{"waiting", "netfunnel", "queue"}
+	waitingText     = []string{"대기열", "접속 대기", "대기 중입니다"}
+	maintenanceURL  = []string{"maintenance", "inspection"}
+	maintenanceText = []string{"시스템 점검", "서비스 점검", "점검 중", "점검중", "maintenance"}
+	errorURL        = []string{"error", "/404", "/500", "notfound"}
+	errorText       = []string{"페이지를 찾을 수 없", "오류가 발생", "일시적인 오류", "잘못된 접근", "404 not found"}
+	loginURL        = []string{"login", "signin", "/auth"}
+)
+
+// Classify 按当前地址、演出页面地址、站点配置和页面开头的文字判断页面位置
+func Classify(current, expected string, site models.SiteConfig, text string) Kind {
+	cur, err := url.Parse(current)
+	if err != nil || cur.Host == "" {
+		return Expected
+	}
+	if SamePage(current, expected) {
+		return Expected
+	}
+	exp, _ := url.Parse(expected)
+
+	lowerURL := strings.ToLower(cur.Host + cur.Path)
+	switch {
+	case containsAny(lowerURL, waitingURL) || containsAny(text, waitingText):
+		return Waiting
+	case containsAny(lowerURL, maintenanceURL) || containsAny(text, maintenanceText):
+		return Maintenance
+	case isLoginPage(cur, site):
+		return Login
+	case containsAny(lowerURL, errorURL) || containsAny(text, errorText):
+		return Error
+	case exp == nil || cur.Host != exp.Host || cur.Path == "" || cur.Path == "/":
+		return Elsewhere
+	default:
+		return Expected
+	}
+}
+
+// SamePage 判断两个地址是否为同一页面，只比较主机和路径
+func SamePage(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ua.Host == ub.Host && strings.TrimSuffix(ua.Path, "/") == strings.TrimSuffix(ub.Path, "/")
+}
+
+// IsLogin 判断地址是否为站点的登录页(包括二次验证等登录流程中的页面)
+func IsLogin(current string, site models.SiteConfig) bool {
+	u, err := url.Parse(current)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return isLoginPage(u, site)
+}
+
+// isLoginPage 判断地址是否为站点的登录页
+func isLoginPage(u *url.URL, site models.SiteConfig) bool {
+	if login, err := url.Parse(site.LoginURL); err == nil && login.Host != "" {
+		if u.Host == login.Host && strings.HasPrefix(u.Path, login.Path) {
+			return true
+		}
+	}
+	return containsAny(strings.ToLower(u.Host+u.Path), loginURL)
+}
+
+// containsAny 判断s是否包含任一关键字，忽略大小写
+func containsAny(s string, words []string) bool {
+	lower := strings.ToLower(s)
+	for _, w := range words {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
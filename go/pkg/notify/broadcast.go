@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster 进程内事件广播，供守护模式的API向客户端推送事件
+// 订阅者处理不过来时丢弃事件，不会阻塞通知管理器
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroadcaster 创建事件广播
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[chan Event]struct{})}
+}
+
+// Name 渠道名称
+func (b *Broadcaster) Name() string {
+	return "broadcast"
+}
+
+// Send 将事件分发给所有订阅者
+func (b *Broadcaster) Send(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe 订阅事件，返回的取消函数必须调用以释放订阅
+func (b *Broadcaster) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// callbackEvents 未配置事件类型时默认回调的事件
+var callbackEvents = []string{string(EventPurchaseSuccess), string(EventPurchaseFailed)}
+
+// CallbackNotifier 任务完成回调，供下游自动化(日历、付款提醒、表格等)接收结果
+//
+// 请求头:
+//
+//	X-Tickgrabber-Event: 事件类型
+//	X-Tickgrabber-Delivery: 投递ID，重试时不变，可用于去重
+//	X-Tickgrabber-Timestamp: Unix时间戳(秒)
+//	X-Tickgrabber-Signature: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+type CallbackNotifier struct {
+	config models.CallbackConfig
+}
+
+// callbackPayload 回调请求体
+type callbackPayload struct {
+	ID string `json:"id"`
+	Event
+}
+
+// NewCallbackNotifier 创建回调渠道，返回的渠道已按事件类型过滤
+func NewCallbackNotifier(config models.CallbackConfig) Notifier {
+	events := config.Events
+	if len(events) == 0 {
+		events = callbackEvents
+	}
+	return OnlyEvents(&CallbackNotifier{config: config}, events)
+}
+
+// Name 渠道名称
+func (n *CallbackNotifier) Name() string {
+	if n.config.Name == "" {
+		return "callback"
+	}
+	return "callback:" + n.config.Name
+}
+
+// Send 签名并POST事件
+func (n *CallbackNotifier) Send(ctx context.Context, event Event) error {
+	id := deliveryID(event)
+	body, err := json.Marshal(callbackPayload{ID: id, Event: event})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tickgrabber-Event", string(event.Type))
+	req.Header.Set("X-Tickgrabber-Delivery", id)
+	req.Header.Set("X-Tickgrabber-Timestamp", timestamp)
+	if n.config.Secret != "" {
+		req.Header.Set("X-Tickgrabber-Signature", "sha256="+Sign(n.config.Secret, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(data))
+	}
+	return nil
+}
+
+// Sign 计算回调签名，接收方用同样的方法校验请求
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryID 由事件内容生成的稳定ID
+func deliveryID(event Event) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d", event.Type, event.Concert, event.OrderID, event.Time.UnixNano())))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// defaultAlarmEvents 默认播放警报声的事件
+var defaultAlarmEvents = []string{
+	string(EventTicketFound),
+	string(EventPurchaseSuccess),
+	string(EventActionRequired),
+}
+
+// DesktopNotifier 系统桌面通知，可在关键事件时播放警报声
+type DesktopNotifier struct {
+	config models.DesktopConfig
+}
+
+// NewDesktopNotifier 创建桌面通知渠道
+func NewDesktopNotifier(config models.DesktopConfig) *DesktopNotifier {
+	if config.AlarmRepeat <= 0 {
+		config.AlarmRepeat = 3
+	}
+	if len(config.AlarmEvents) == 0 {
+		config.AlarmEvents = defaultAlarmEvents
+	}
+
+	return &DesktopNotifier{config: config}
+}
+
+// Name 渠道名称
+func (n *DesktopNotifier) Name() string {
+	return "desktop"
+}
+
+// Send 弹出桌面通知，需要时在后台播放警报
+func (n *DesktopNotifier) Send(ctx context.Context, event Event) error {
+	if n.config.Sound && n.shouldAlarm(event.Type) {
+		go n.alarm(context.WithoutCancel(ctx))
+	}
+
+	message := event.Message
+	if event.Concert != "" {
+		message = event.Concert + "\n" + message
+	}
+
+	cmd, err := desktopCommand(ctx, event.Title, message)
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// shouldAlarm 判断事件是否需要播放警报
+func (n *DesktopNotifier) shouldAlarm(t EventType) bool {
+	for _, e := range n.config.AlarmEvents {
+		if e == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// alarm 按配置重复播放警报声
+func (n *DesktopNotifier) alarm(ctx context.Context) {
+	for i := 0; i < n.config.AlarmRepeat; i++ {
+		playCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		cmd := soundCommand(playCtx, n.config.SoundFile)
+		if cmd == nil || cmd.Run() != nil {
+			// 没有可用的播放器时退回终端响铃
+			fmt.Print("\a")
+			time.Sleep(500 * time.Millisecond)
+		}
+		cancel()
+	}
+}
+
+// desktopCommand 返回当前系统弹出通知的命令
+func desktopCommand(ctx context.Context, title, message string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return exec.CommandContext(ctx, "notify-send", "-u", "critical", title, message), nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleQuote(message), appleQuote(title))
+		return exec.CommandContext(ctx, "osascript", "-e", script), nil
+	case "windows":
+		script := fmt.Sprintf(`
+Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.BalloonTipTitle = %s
+$n.BalloonTipText = %s
+$n.Visible = $true
+$n.ShowBalloonTip(10000)
+Start-Sleep -Seconds 10
+$n.Dispose()`, psQuote(title), psQuote(message))
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script), nil
+	default:
+		return nil, fmt.Errorf("不支持的系统: %s", runtime.GOOS)
+	}
+}
+
+// soundCommand 返回当前系统播放声音的命令，file为空时使用系统提示音
+func soundCommand(ctx context.Context, file string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "linux":
+		if file == "" {
+			file = "/usr/share/sounds/freedesktop/stereo/alarm-clock-elapsed.oga"
+		}
+		for _, player := range []string{"paplay", "aplay", "ffplay"} {
+			if path, err := exec.LookPath(player); err == nil {
+				if player == "ffplay" {
+					return exec.CommandContext(ctx, path, "-nodisp", "-autoexit", file)
+				}
+				return exec.CommandContext(ctx, path, file)
+			}
+		}
+		return nil
+	case "darwin":
+		if file == "" {
+			file = "/System/Library/Sounds/Sosumi.aiff"
+		}
+		return exec.CommandContext(ctx, "afplay", file)
+	case "windows":
+		script := "[System.Media.SystemSounds]::Exclamation.Play(); Start-Sleep -Seconds 1"
+		if file != "" {
+			script = fmt.Sprintf("(New-Object System.Media.SoundPlayer %s).PlaySync()", psQuote(file))
+		}
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script)
+	default:
+		return nil
+	}
+}
+
+// appleQuote 转义AppleScript字符串
+func appleQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// psQuote 转义PowerShell单引号字符串
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// queueSize 每个渠道的发送队列长度
+const queueSize = 64
+
+// retryPolicy 重试策略
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// newRetryPolicy 根据配置创建重试策略
+func newRetryPolicy(config models.NotificationRetry) retryPolicy {
+	p := retryPolicy{
+		maxAttempts:    config.MaxAttempts,
+		initialBackoff: time.Duration(config.InitialBackoff * float64(time.Second)),
+		maxBackoff:     time.Duration(config.MaxBackoff * float64(time.Second)),
+	}
+	if p.maxAttempts <= 0 {
+		p.maxAttempts = 5
+	}
+	if p.initialBackoff <= 0 {
+		p.initialBackoff = time.Second
+	}
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = time.Minute
+	}
+	return p
+}
+
+// backoff 第attempt次失败后的等待时间，指数增长
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialBackoff << (attempt - 1)
+	if d <= 0 || d > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return d
+}
+
+// worker 单个渠道的发送队列
+type worker struct {
+	notifier Notifier
+	queue    chan Event
+}
+
+// startWorker 启动渠道的发送协程
+// 同名渠道(如多个未命名的回调)各有自己的队列，所以按添加顺序而不是名称对应
+func (m *Manager) startWorker(n Notifier) {
+	w := &worker{notifier: n, queue: make(chan Event, queueSize)}
+	m.workers = append(m.workers, w)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for event := range w.queue {
+			m.deliver(n, event)
+		}
+	}()
+}
+
+// enqueue 将事件放入渠道队列，队列已满时直接写入死信
+func (m *Manager) enqueue(w *worker, event Event) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return fmt.Errorf("通知管理器已关闭")
+	}
+
+	select {
+	case w.queue <- event:
+		return nil
+	default:
+		err := fmt.Errorf("发送队列已满")
+		m.deadLetter.Record(w.notifier.Name(), event, 0, err)
+		return err
+	}
+}
+
+// deliver 发送事件，失败时按退避策略重试
+func (m *Manager) deliver(n Notifier, event Event) {
+	var err error
+	for attempt := 1; attempt <= m.retry.maxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err = n.Send(ctx, event)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		log.Printf("%s 通知发送失败(第%d次): %v", n.Name(), attempt, err)
+		if attempt < m.retry.maxAttempts {
+			time.Sleep(m.retry.backoff(attempt))
+		}
+	}
+
+	m.deadLetter.Record(n.Name(), event, m.retry.maxAttempts, err)
+}
+
+// Close 停止接收新事件并等待队列中的事件发送完毕或ctx超时
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		for _, w := range m.workers {
+			close(w.queue)
+		}
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		if m.hooks != nil {
+			m.hooks.Wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("仍有通知未发送完毕: %w", ctx.Err())
+	}
+}
+
+// DeadLetter 未能送达的通知记录
+type DeadLetter struct {
+	Time     time.Time `json:"time"`
+	Channel  string    `json:"channel"`
+	Event    Event     `json:"event"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+}
+
+// DeadLetterLog 死信记录，以JSON Lines格式追加写入文件
+type DeadLetterLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewDeadLetterLog 创建死信记录，path为空时只写日志
+func NewDeadLetterLog(path string) *DeadLetterLog {
+	return &DeadLetterLog{path: path}
+}
+
+// Record 记录一条未送达的通知
+func (d *DeadLetterLog) Record(channel string, event Event, attempts int, cause error) {
+	log.Printf("%s 通知最终发送失败，已放弃: %s (%v)", channel, event.Title, cause)
+	if d.path == "" {
+		return
+	}
+
+	entry := DeadLetter{
+		Time:     time.Now(),
+		Channel:  channel,
+		Event:    event,
+		Attempts: attempts,
+		Error:    cause.Error(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		log.Printf("写入死信记录失败: %v", err)
+		return
+	}
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("写入死信记录失败: %v", err)
+		return
+	}
+	defer f.Close()
+
+	f.Write(append(data, '\n'))
+}
@@ -0,0 +1,271 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// 邮件连接安全模式
+const (
+	SecurityAuto     = ""
+	SecurityTLS      = "tls"
+	SecuritySTARTTLS = "starttls"
+	SecurityNone     = "none"
+)
+
+// defaultEmailTemplate 默认的HTML邮件模板
+const defaultEmailTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #333;">
+  <h2 style="color: {{color .Type}};">{{.Title}}</h2>
+  <p style="white-space: pre-line;">{{.Message}}</p>
+  <table style="border-collapse: collapse;">
+    {{if .Concert}}<tr><td style="padding: 4px 12px 4px 0;"><b>演唱会</b></td><td>{{.Concert}}</td></tr>{{end}}
+    {{if .OrderID}}<tr><td style="padding: 4px 12px 4px 0;"><b>订单号</b></td><td>{{.OrderID}}</td></tr>{{end}}
+    {{range $key, $value := .Details}}<tr><td style="padding: 4px 12px 4px 0;"><b>{{$key}}</b></td><td>{{$value}}</td></tr>{{end}}
+    {{if .URL}}<tr><td style="padding: 4px 12px 4px 0;"><b>链接</b></td><td><a href="{{.URL}}">{{.URL}}</a></td></tr>{{end}}
+  </table>
+  <p style="color: #999; font-size: 12px;">{{.Time.Format "2006-01-02 15:04:05"}}</p>
+</body>
+</html>`
+
+// EmailNotifier 邮件通知
+type EmailNotifier struct {
+	config    models.EmailConfig
+	templates map[EventType]*template.Template
+	fallback  *template.Template
+}
+
+// emailFuncs 邮件模板可用的函数
+var emailFuncs = template.FuncMap{"color": eventColor}
+
+// NewEmailNotifier 创建邮件通知渠道
+func NewEmailNotifier(config models.EmailConfig) *EmailNotifier {
+	n := &EmailNotifier{
+		config:    config,
+		templates: make(map[EventType]*template.Template),
+		fallback:  template.Must(template.New("default").Funcs(emailFuncs).Parse(defaultEmailTemplate)),
+	}
+
+	// 按事件类型加载自定义模板，配置加载时已经校验过，这里失败说明文件之后被改动
+	for eventType, path := range config.Templates {
+		tmpl, err := loadEmailTemplate(eventType, path)
+		if err != nil {
+			log.Printf("%v，使用默认模板", err)
+			continue
+		}
+		n.templates[EventType(eventType)] = tmpl
+	}
+
+	return n
+}
+
+// loadEmailTemplate 读取并解析事件的自定义邮件模板
+func loadEmailTemplate(eventType, path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 的邮件模板失败: %w", eventType, err)
+	}
+	tmpl, err := template.New(eventType).Funcs(emailFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s 的邮件模板无效: %w", eventType, err)
+	}
+	return tmpl, nil
+}
+
+// Name 渠道名称
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Send 发送邮件
+func (n *EmailNotifier) Send(ctx context.Context, event Event) error {
+	body, err := n.render(event)
+	if err != nil {
+		return fmt.Errorf("渲染邮件模板失败: %w", err)
+	}
+
+	msg, err := n.buildMessage(event, body)
+	if err != nil {
+		return err
+	}
+
+	return n.deliver(ctx, msg)
+}
+
+// render 使用事件对应的模板渲染HTML正文
+func (n *EmailNotifier) render(event Event) (string, error) {
+	tmpl, ok := n.templates[event.Type]
+	if !ok {
+		tmpl = n.fallback
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// buildMessage 构造带附件的MIME邮件
+func (n *EmailNotifier) buildMessage(event Event, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", n.config.Sender)
+	fmt.Fprintf(&buf, "To: %s\r\n", n.config.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", event.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(html))
+
+	for _, attachment := range event.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition": {
+				mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, attachment.Data)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver 连接SMTP服务器并发送邮件
+func (n *EmailNotifier) deliver(ctx context.Context, msg []byte) error {
+	host := n.config.SMTPServer
+	addr := net.JoinHostPort(host, fmt.Sprint(n.config.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: host}
+
+	security := n.config.Security
+	if security == SecurityAuto && n.config.SMTPPort == 465 {
+		security = SecurityTLS
+	}
+
+	var conn net.Conn
+	var err error
+	if security == SecurityTLS {
+		dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 15 * time.Second}, Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := &net.Dialer{Timeout: 15 * time.Second}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if security == SecurityAuto || security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("STARTTLS失败: %w", err)
+			}
+		} else if security == SecuritySTARTTLS {
+			return fmt.Errorf("SMTP服务器不支持STARTTLS")
+		}
+	}
+
+	if n.config.Password != "" {
+		username := n.config.Username
+		if username == "" {
+			username = n.config.Sender
+		}
+		if err := client.Auth(smtp.PlainAuth("", username, n.config.Password, host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.Sender); err != nil {
+		return err
+	}
+	for _, rcpt := range strings.Split(n.config.Recipient, ",") {
+		if rcpt = strings.TrimSpace(rcpt); rcpt != "" {
+			if err := client.Rcpt(rcpt); err != nil {
+				return err
+			}
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// writeBase64 按每行76字符写入base64编码内容
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+// eventColor 邮件标题颜色
+func eventColor(t EventType) string {
+	switch t {
+	case EventPurchaseSuccess, EventTicketFound:
+		return "#2e7d32"
+	case EventPurchaseFailed, EventError:
+		return "#c62828"
+	default:
+		return "#1565c0"
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+
+	"tickgrabber/pkg/models"
+)
+
+// gatewayFuncs 网关模板可用的函数
+var gatewayFuncs = template.FuncMap{
+	// json 输出JSON字符串字面量，用于在JSON模板中安全地嵌入文本
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"urlquery": url.QueryEscape,
+	"text":     formatText,
+}
+
+// GatewayNotifier 通用HTTP网关通知，用于对接短信、KakaoTalk等第三方服务
+//
+// 请求体由text/template渲染，模板数据为Event，例如:
+//
+//	{"to": "010-0000-0000", "message": {{json .Title}}}
+type GatewayNotifier struct {
+	config models.GatewayConfig
+	url    *template.Template
+	body   *template.Template
+}
+
+// NewGatewayNotifier 创建网关通知渠道
+func NewGatewayNotifier(config models.GatewayConfig) (*GatewayNotifier, error) {
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+
+	urlTmpl, err := template.New("url").Funcs(gatewayFuncs).Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("网关 %s URL模板错误: %w", config.Name, err)
+	}
+	n := &GatewayNotifier{config: config, url: urlTmpl}
+	if config.BodyTemplate != "" {
+		tmpl, err := template.New(config.Name).Funcs(gatewayFuncs).Parse(config.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("网关 %s 模板错误: %w", config.Name, err)
+		}
+		n.body = tmpl
+	}
+
+	return n, nil
+}
+
+// Name 渠道名称
+func (n *GatewayNotifier) Name() string {
+	if n.config.Name == "" {
+		return "gateway"
+	}
+	return "gateway:" + n.config.Name
+}
+
+// Send 渲染请求并发送到网关
+func (n *GatewayNotifier) Send(ctx context.Context, event Event) error {
+	var body bytes.Buffer
+	if n.body != nil {
+		if err := n.body.Execute(&body, event); err != nil {
+			return fmt.Errorf("渲染网关模板失败: %w", err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(event); err != nil {
+		return err
+	}
+
+	targetURL, err := n.renderURL(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, n.config.Method, targetURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", n.config.ContentType)
+	for key, value := range n.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(data))
+	}
+	return nil
+}
+
+// renderURL URL同样支持模板，便于通过查询参数传递消息的网关
+func (n *GatewayNotifier) renderURL(event Event) (string, error) {
+	var buf bytes.Buffer
+	if err := n.url.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("渲染网关 %s URL模板失败: %w", n.config.Name, err)
+	}
+	return buf.String(), nil
+}
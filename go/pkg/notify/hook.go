@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// hookPrefix 钩子配置键的前缀，on_ticket_found 对应 ticket_found 事件
+const hookPrefix = "on_"
+
+// defaultHookTimeout 钩子命令默认的超时时间
+const defaultHookTimeout = 30 * time.Second
+
+// hookEvents 可以配置钩子的事件
+var hookEvents = []EventType{
+	EventTicketFound, EventPurchaseSuccess, EventPurchaseFailed, EventActionRequired,
+	EventPaymentReminder, EventSeatMismatch, EventError, EventTest,
+}
+
+// Hooks 事件发生时执行的外部命令，用于接入指示灯、警报、自定义短信脚本等
+//
+// 钩子不经过通知路由、免打扰时段和重试，命令在后台执行，不阻塞抢票流程；
+// 通知管理器关闭时等待正在执行的命令结束。命令的标准输入为事件的JSON，
+// 环境变量中有 TICKGRABBER_EVENT、TICKGRABBER_CONCERT、TICKGRABBER_ORDER_ID 等事件字段
+type Hooks struct {
+	hooks map[EventType][]*hook
+	wg    sync.WaitGroup
+}
+
+// hook 一个已解析模板的钩子
+type hook struct {
+	name    string
+	command *template.Template
+	args    []*template.Template
+	shell   string
+	timeout time.Duration
+}
+
+// NewHooks 按配置创建钩子，键为 on_<事件类型>，没有启用的钩子时返回nil
+func NewHooks(config map[string][]models.HookConfig) (*Hooks, error) {
+	h := &Hooks{hooks: make(map[EventType][]*hook)}
+	for key, list := range config {
+		event := EventType(strings.TrimPrefix(key, hookPrefix))
+		if !strings.HasPrefix(key, hookPrefix) || !validHookEvent(event) {
+			return nil, fmt.Errorf("未知的钩子 %s，可用: %s", key, strings.Join(hookKeys(), ", "))
+		}
+		for i, c := range list {
+			if !c.Enabled {
+				continue
+			}
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("%s[%d]", key, i)
+			}
+			hk, err := newHook(name, c)
+			if err != nil {
+				return nil, err
+			}
+			h.hooks[event] = append(h.hooks[event], hk)
+		}
+	}
+	if len(h.hooks) == 0 {
+		return nil, nil
+	}
+	return h, nil
+}
+
+// newHook 解析钩子的命令和参数模板
+func newHook(name string, c models.HookConfig) (*hook, error) {
+	if (c.Command == "") == (c.Shell == "") {
+		return nil, fmt.Errorf("钩子 %s 应设置 command 或 shell 其中之一", name)
+	}
+	hk := &hook{name: name, shell: c.Shell, timeout: defaultHookTimeout}
+	if c.Timeout > 0 {
+		hk.timeout = time.Duration(c.Timeout * float64(time.Second))
+	}
+	if c.Shell != "" {
+		if len(c.Args) > 0 {
+			return nil, fmt.Errorf("钩子 %s: shell 命令不使用 args，参数请写在命令行中", name)
+		}
+		return hk, nil
+	}
+
+	var err error
+	if hk.command, err = template.New(name).Funcs(gatewayFuncs).Option("missingkey=zero").Parse(c.Command); err != nil {
+		return nil, fmt.Errorf("钩子 %s 的命令模板无效: %w", name, err)
+	}
+	for i, arg := range c.Args {
+		tmpl, err := template.New(name).Funcs(gatewayFuncs).Option("missingkey=zero").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("钩子 %s 的第 %d 个参数模板无效: %w", name, i+1, err)
+		}
+		hk.args = append(hk.args, tmpl)
+	}
+	return hk, nil
+}
+
+// Name 渠道名称
+func (h *Hooks) Name() string {
+	return "hooks"
+}
+
+// Send 在后台执行事件对应的钩子，立即返回
+func (h *Hooks) Send(ctx context.Context, event Event) error {
+	// 任务结束时发出的事件也要执行完钩子，不随任务取消
+	ctx = context.WithoutCancel(ctx)
+	for _, hk := range h.hooks[event.Type] {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			if err := hk.run(ctx, event); err != nil {
+				log.Printf("钩子 %s 执行失败: %v", hk.name, err)
+			}
+		}()
+	}
+	return nil
+}
+
+// Wait 等待正在执行的钩子结束
+func (h *Hooks) Wait() {
+	h.wg.Wait()
+}
+
+// run 渲染模板并执行命令，超时后结束进程
+func (h *hook) run(ctx context.Context, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if h.shell != "" {
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", h.shell)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", h.shell)
+		}
+	} else {
+		name, err := renderHook(h.command, event)
+		if err != nil {
+			return err
+		}
+		args := make([]string, len(h.args))
+		for i, tmpl := range h.args {
+			if args[i], err = renderHook(tmpl, event); err != nil {
+				return err
+			}
+		}
+		cmd = exec.CommandContext(ctx, name, args...)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), hookEnv(event)...)
+	cmd.WaitDelay = time.Second
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("超过 %s 未结束", h.timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			if r := []rune(out); len(r) > 500 {
+				out = string(r[:500]) + "..."
+			}
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	log.Printf("钩子 %s 执行完成", h.name)
+	return nil
+}
+
+// renderHook 以事件为数据渲染模板
+func renderHook(tmpl *template.Template, event Event) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// hookEnv 传给钩子命令的事件字段
+func hookEnv(event Event) []string {
+	return []string{
+		"TICKGRABBER_EVENT=" + string(event.Type),
+		"TICKGRABBER_SEVERITY=" + event.Severity.String(),
+		"TICKGRABBER_TITLE=" + event.Title,
+		"TICKGRABBER_MESSAGE=" + event.Message,
+		"TICKGRABBER_CONCERT=" + event.Concert,
+		"TICKGRABBER_URL=" + event.URL,
+		"TICKGRABBER_ORDER_ID=" + event.OrderID,
+	}
+}
+
+// validHookEvent 判断事件是否可以配置钩子
+func validHookEvent(event EventType) bool {
+	for _, e := range hookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// hookKeys 返回所有可用的钩子键
+func hookKeys() []string {
+	keys := make([]string, len(hookEvents))
+	for i, e := range hookEvents {
+		keys[i] = hookPrefix + string(e)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,297 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// EventType 通知事件类型
+type EventType string
+
+// 通知事件类型
+const (
+	EventTicketFound     EventType = "ticket_found"
+	EventPurchaseSuccess EventType = "purchase_success"
+	EventPurchaseFailed  EventType = "purchase_failed"
+	EventActionRequired  EventType = "action_required"
+	EventPaymentReminder EventType = "payment_reminder"
+	EventSeatMismatch    EventType = "seat_mismatch"
+	EventError           EventType = "error"
+	EventTest            EventType = "test"
+)
+
+// Attachment 通知附件
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Event 通知事件
+type Event struct {
+	Type        EventType         `json:"type"`
+	Severity    Severity          `json:"severity"`
+	Title       string            `json:"title"`
+	Message     string            `json:"message"`
+	Concert     string            `json:"concert,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	OrderID     string            `json:"order_id,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Time        time.Time         `json:"time"`
+	Attachments []Attachment      `json:"-"`
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// Manager 通知管理器，按路由规则将事件发送到启用的渠道
+// 每个渠道有独立的发送队列和重试，一个渠道故障不会拖慢其他渠道
+type Manager struct {
+	notifiers  []Notifier
+	taps       []Notifier
+	router     *Router
+	workers    []*worker
+	retry      retryPolicy
+	deadLetter *DeadLetterLog
+	hooks      *Hooks
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+	closed     bool
+}
+
+// NewManager 根据配置创建通知管理器
+func NewManager(config models.NotificationConfig) *Manager {
+	m := &Manager{
+		retry:      newRetryPolicy(config.Retry),
+		deadLetter: NewDeadLetterLog(config.DeadLetterFile),
+	}
+
+	router, err := NewRouter(config.Routes, config.QuietHours)
+	if err != nil {
+		log.Printf("通知路由配置无效，发送到全部渠道: %v", err)
+		router, _ = NewRouter(nil, models.QuietHours{})
+	}
+	m.router = router
+
+	if config.Email.Enabled {
+		m.Add(NewEmailNotifier(config.Email))
+	}
+	if config.Desktop.Enabled {
+		m.Add(NewDesktopNotifier(config.Desktop))
+	}
+	if config.Telegram.Enabled {
+		m.Add(NewTelegramNotifier(config.Telegram))
+	}
+	for _, target := range config.Discord {
+		if target.Enabled {
+			m.Add(OnlyEvents(NewDiscordNotifier(target), target.Events))
+		}
+	}
+	for _, target := range config.Slack {
+		if target.Enabled {
+			m.Add(OnlyEvents(NewSlackNotifier(target), target.Events))
+		}
+	}
+	for _, gateway := range config.Gateways {
+		if !gateway.Enabled {
+			continue
+		}
+		n, err := NewGatewayNotifier(gateway)
+		if err != nil {
+			log.Printf("跳过通知网关: %v", err)
+			continue
+		}
+		m.Add(OnlyEvents(n, gateway.Events))
+	}
+	for _, callback := range config.Callbacks {
+		if callback.Enabled {
+			m.Add(NewCallbackNotifier(callback))
+		}
+	}
+	if hooks, err := NewHooks(config.Hooks); err != nil {
+		log.Printf("钩子配置无效，不执行钩子: %v", err)
+	} else if hooks != nil {
+		m.hooks = hooks
+		m.Tap(hooks)
+	}
+
+	return m
+}
+
+// ValidateConfig 检查通知配置中的邮件、网关和钩子模板，在加载配置时发现错误，
+// 避免运行中才因模板无效而发出空消息或不发送
+func ValidateConfig(config models.NotificationConfig) error {
+	if config.Email.Enabled {
+		for eventType, path := range config.Email.Templates {
+			if _, err := loadEmailTemplate(eventType, path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, gateway := range config.Gateways {
+		if !gateway.Enabled {
+			continue
+		}
+		if _, err := NewGatewayNotifier(gateway); err != nil {
+			return err
+		}
+	}
+	if _, err := NewHooks(config.Hooks); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Add 添加通知渠道并启动其发送队列
+func (m *Manager) Add(n Notifier) {
+	m.notifiers = append(m.notifiers, n)
+	m.startWorker(n)
+}
+
+// Tap 添加旁路渠道，接收所有事件，不经过路由规则和重试
+func (m *Manager) Tap(n Notifier) {
+	m.taps = append(m.taps, n)
+}
+
+// Notifiers 返回已启用的通知渠道
+func (m *Manager) Notifiers() []Notifier {
+	return m.notifiers
+}
+
+// Notify 按路由规则将事件放入各渠道的发送队列后立即返回
+// 发送失败会按退避策略重试，最终失败的消息写入死信记录
+func (m *Manager) Notify(ctx context.Context, event Event) error {
+	event = normalize(event)
+	m.tap(ctx, event)
+
+	var errs []error
+	for _, i := range m.route(event) {
+		n := m.notifiers[i]
+		if err := m.enqueue(m.workers[i], event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// NotifySync 按路由规则同步发送事件，不重试，返回各渠道错误的合并
+func (m *Manager) NotifySync(ctx context.Context, event Event) error {
+	_, err := m.Deliver(ctx, event)
+	return err
+}
+
+// Deliver 同NotifySync，另外返回实际送达的渠道数
+// 被路由规则、免打扰时段或渠道事件过滤屏蔽的渠道不计入
+func (m *Manager) Deliver(ctx context.Context, event Event) (int, error) {
+	event = normalize(event)
+	m.tap(ctx, event)
+
+	delivered := 0
+	var errs []error
+	for _, i := range m.route(event) {
+		n := m.notifiers[i]
+		if f, ok := n.(*eventFilter); ok && !f.accepts(event) {
+			continue
+		}
+		if err := n.Send(ctx, event); err != nil {
+			log.Printf("%s 通知发送失败: %v", n.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			continue
+		}
+		delivered++
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// normalize 补全事件的时间和严重程度
+func normalize(event Event) Event {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Severity == 0 {
+		event.Severity = DefaultSeverity(event.Type)
+	}
+	return event
+}
+
+// tap 将事件发送到旁路渠道
+func (m *Manager) tap(ctx context.Context, event Event) {
+	for _, n := range m.taps {
+		if err := n.Send(ctx, event); err != nil {
+			log.Printf("%s 事件转发失败: %v", n.Name(), err)
+		}
+	}
+}
+
+// route 返回事件应发送到的渠道在notifiers中的下标，测试事件总是发送到全部渠道
+func (m *Manager) route(event Event) []int {
+	all := make([]int, len(m.notifiers))
+	names := make([]string, len(m.notifiers))
+	for i, n := range m.notifiers {
+		all[i] = i
+		names[i] = n.Name()
+	}
+	if event.Type == EventTest {
+		return all
+	}
+
+	selected := m.router.Route(event, names)
+	if selected == nil {
+		return all
+	}
+
+	var result []int
+	for i, name := range names {
+		for _, s := range selected {
+			if name == s {
+				result = append(result, i)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// eventFilter 只转发指定类型事件的通知渠道
+type eventFilter struct {
+	Notifier
+	events map[EventType]bool
+}
+
+// OnlyEvents 包装通知渠道，只发送events中列出的事件类型，events为空时不过滤
+// 测试事件总是会被发送
+func OnlyEvents(n Notifier, events []string) Notifier {
+	if len(events) == 0 {
+		return n
+	}
+
+	f := &eventFilter{Notifier: n, events: make(map[EventType]bool)}
+	for _, e := range events {
+		f.events[EventType(e)] = true
+	}
+	return f
+}
+
+// Send 过滤后发送
+func (f *eventFilter) Send(ctx context.Context, event Event) error {
+	if !f.accepts(event) {
+		return nil
+	}
+	return f.Notifier.Send(ctx, event)
+}
+
+// accepts 判断事件是否会被发送
+func (f *eventFilter) accepts(event Event) bool {
+	return event.Type == EventTest || f.events[event.Type]
+}
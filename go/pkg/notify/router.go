@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// Severity 事件严重程度
+type Severity int
+
+// 事件严重程度
+// 零值表示未指定，发送时按事件类型取默认值
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityCritical
+)
+
+// ParseSeverity 解析配置中的严重程度，未识别时返回SeverityInfo
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "warning":
+		return SeverityWarning
+	case "critical":
+		return SeverityCritical
+	default:
+		return SeverityInfo
+	}
+}
+
+// String 返回严重程度名称
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// DefaultSeverity 事件类型的默认严重程度
+func DefaultSeverity(t EventType) Severity {
+	switch t {
+	case EventTicketFound, EventPurchaseSuccess, EventActionRequired, EventSeatMismatch:
+		return SeverityCritical
+	case EventPurchaseFailed, EventPaymentReminder, EventError:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// Router 按规则把事件分发到指定渠道，支持限流和免打扰时段
+type Router struct {
+	routes []models.NotificationRoute
+	quiet  models.QuietHours
+	loc    *time.Location
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	now      func() time.Time
+}
+
+// NewRouter 创建路由器
+func NewRouter(routes []models.NotificationRoute, quiet models.QuietHours) (*Router, error) {
+	loc := time.Local
+	if quiet.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(quiet.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %s: %w", quiet.Timezone, err)
+		}
+	}
+
+	return &Router{
+		routes:   routes,
+		quiet:    quiet,
+		loc:      loc,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}, nil
+}
+
+// Route 返回事件应发送到的渠道名称；没有配置规则时返回nil，表示发送到全部渠道
+func (r *Router) Route(event Event, channels []string) []string {
+	if len(r.routes) == 0 {
+		if r.inQuietHours(event.Severity, false) {
+			return []string{}
+		}
+		return nil
+	}
+
+	selected := make(map[string]bool)
+	for i, route := range r.routes {
+		if !routeMatches(route, event) {
+			continue
+		}
+		if r.inQuietHours(event.Severity, route.IgnoreQuietHours) {
+			continue
+		}
+		if !r.allow(fmt.Sprintf("%d:%s", i, event.Type), route.MinInterval) {
+			continue
+		}
+
+		for _, want := range route.Channels {
+			for _, name := range channels {
+				if channelMatches(want, name) {
+					selected[name] = true
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(selected))
+	for _, name := range channels {
+		if selected[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// routeMatches 判断规则是否匹配事件
+func routeMatches(route models.NotificationRoute, event Event) bool {
+	if event.Severity < ParseSeverity(route.MinSeverity) {
+		return false
+	}
+	if len(route.Events) == 0 {
+		return true
+	}
+	for _, e := range route.Events {
+		if e == "*" || e == string(event.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// channelMatches 渠道名匹配，"discord"匹配所有"discord:xxx"渠道
+func channelMatches(want, name string) bool {
+	return want == "*" || want == name || strings.HasPrefix(name, want+":")
+}
+
+// allow 限流检查，minInterval秒内同一规则同一事件类型只发送一次
+func (r *Router) allow(key string, minInterval float64) bool {
+	if minInterval <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	interval := time.Duration(minInterval * float64(time.Second))
+	if last, ok := r.lastSent[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	r.lastSent[key] = now
+	return true
+}
+
+// inQuietHours 判断事件是否因免打扰时段被屏蔽，默认紧急事件不受影响
+func (r *Router) inQuietHours(severity Severity, ignore bool) bool {
+	if !r.quiet.Enabled || ignore {
+		return false
+	}
+	allow := SeverityCritical
+	if r.quiet.AllowSeverity != "" {
+		allow = ParseSeverity(r.quiet.AllowSeverity)
+	}
+	if severity >= allow {
+		return false
+	}
+
+	start, err1 := time.Parse("15:04", r.quiet.Start)
+	end, err2 := time.Parse("15:04", r.quiet.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	now := r.now().In(r.loc)
+	minutes := now.Hour()*60 + now.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	// 时段可以跨越午夜，如 23:00-07:00
+	if from <= to {
+		return minutes >= from && minutes < to
+	}
+	return minutes >= from || minutes < to
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"tickgrabber/pkg/models"
+)
+
+// telegramAPI Telegram Bot API地址
+const telegramAPI = "https://api.telegram.org/bot"
+
+// TelegramNotifier Telegram通知
+type TelegramNotifier struct {
+	config models.TelegramConfig
+}
+
+// NewTelegramNotifier 创建Telegram通知渠道
+func NewTelegramNotifier(config models.TelegramConfig) *TelegramNotifier {
+	return &TelegramNotifier{config: config}
+}
+
+// Name 渠道名称
+func (n *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Send 发送文本消息，附件以文件形式逐个发送
+func (n *TelegramNotifier) Send(ctx context.Context, event Event) error {
+	err := postJSON(ctx, n.endpoint("sendMessage"), map[string]interface{}{
+		"chat_id": n.config.ChatID,
+		"text":    event.Title + "\n\n" + formatText(event),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range event.Attachments {
+		if err := n.sendDocument(ctx, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendDocument 上传附件
+func (n *TelegramNotifier) sendDocument(ctx context.Context, attachment Attachment) error {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("chat_id", n.config.ChatID)
+
+	part, err := writer.CreateFormFile("document", attachment.Filename)
+	if err != nil {
+		return err
+	}
+	part.Write(attachment.Data)
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint("sendDocument"), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// endpoint 返回Bot API方法地址
+func (n *TelegramNotifier) endpoint(method string) string {
+	return telegramAPI + n.config.BotToken + "/" + method
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// httpClient 通知渠道共用的HTTP客户端
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// DiscordNotifier Discord Webhook通知
+type DiscordNotifier struct {
+	target models.WebhookTarget
+}
+
+// NewDiscordNotifier 创建Discord通知渠道
+func NewDiscordNotifier(target models.WebhookTarget) *DiscordNotifier {
+	return &DiscordNotifier{target: target}
+}
+
+// Name 渠道名称
+func (n *DiscordNotifier) Name() string {
+	return targetName("discord", n.target)
+}
+
+// Send 发送Discord消息
+func (n *DiscordNotifier) Send(ctx context.Context, event Event) error {
+	embed := map[string]interface{}{
+		"title":       event.Title,
+		"description": event.Message,
+		"color":       discordColor(event.Type),
+		"timestamp":   event.Time.Format(time.RFC3339),
+	}
+	if event.URL != "" {
+		embed["url"] = event.URL
+	}
+
+	var fields []map[string]interface{}
+	if event.Concert != "" {
+		fields = append(fields, map[string]interface{}{"name": "演唱会", "value": event.Concert})
+	}
+	if event.OrderID != "" {
+		fields = append(fields, map[string]interface{}{"name": "订单号", "value": event.OrderID, "inline": true})
+	}
+	for key, value := range event.Details {
+		fields = append(fields, map[string]interface{}{"name": key, "value": value, "inline": true})
+	}
+	if len(fields) > 0 {
+		embed["fields"] = fields
+	}
+
+	return postJSON(ctx, n.target.WebhookURL, map[string]interface{}{
+		"embeds": []interface{}{embed},
+	})
+}
+
+// SlackNotifier Slack Incoming Webhook通知
+type SlackNotifier struct {
+	target models.WebhookTarget
+}
+
+// NewSlackNotifier 创建Slack通知渠道
+func NewSlackNotifier(target models.WebhookTarget) *SlackNotifier {
+	return &SlackNotifier{target: target}
+}
+
+// Name 渠道名称
+func (n *SlackNotifier) Name() string {
+	return targetName("slack", n.target)
+}
+
+// Send 发送Slack消息
+func (n *SlackNotifier) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, n.target.WebhookURL, map[string]interface{}{
+		"text": fmt.Sprintf("*%s*\n%s", event.Title, formatText(event)),
+	})
+}
+
+// postJSON 以JSON形式POST到Webhook地址
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP错误: %d, %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// formatText 将事件格式化为纯文本正文
+func formatText(event Event) string {
+	var b strings.Builder
+	b.WriteString(event.Message)
+	if event.Concert != "" {
+		fmt.Fprintf(&b, "\n演唱会: %s", event.Concert)
+	}
+	if event.OrderID != "" {
+		fmt.Fprintf(&b, "\n订单号: %s", event.OrderID)
+	}
+	for key, value := range event.Details {
+		fmt.Fprintf(&b, "\n%s: %s", key, value)
+	}
+	if event.URL != "" {
+		fmt.Fprintf(&b, "\n%s", event.URL)
+	}
+	return b.String()
+}
+
+// targetName 渠道名称，配置了名称时附加在后面以区分多个Webhook
+func targetName(kind string, target models.WebhookTarget) string {
+	if target.Name == "" {
+		return kind
+	}
+	return kind + ":" + target.Name
+}
+
+// discordColor Discord消息侧边颜色
+func discordColor(t EventType) int {
+	switch t {
+	case EventPurchaseSuccess, EventTicketFound:
+		return 0x2e7d32
+	case EventPurchaseFailed, EventError:
+		return 0xc62828
+	default:
+		return 0x1565c0
+	}
+}
@@ -0,0 +1,208 @@
+// Package order 订单确认页解析、订单记录和收据导出
+package order
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"tickgrabber/pkg/models"
+)
+
+// PriceItem 价格明细
+type PriceItem struct {
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
+}
+
+// Order 购票成功后从确认页采集的订单信息
+type Order struct {
+	ID              string      `json:"id"`
+	Site            string      `json:"site"`
+	ConcertID       string      `json:"concert_id"`
+	ConcertName     string      `json:"concert_name"`
+	ConcertDate     string      `json:"concert_date,omitempty"`
+	Seats           []string    `json:"seats"`
+	RequestedSeats  []string    `json:"requested_seats,omitempty"`
+	SeatCheck       *SeatCheck  `json:"seat_check,omitempty"`
+	Prices          []PriceItem `json:"prices,omitempty"`
+	Total           int         `json:"total"`
+	PaymentDeadline time.Time   `json:"payment_deadline,omitempty"`
+	PaymentMethod   string      `json:"payment_method,omitempty"`
+	DeliveryMethod  string      `json:"delivery_method,omitempty"`
+	URL             string      `json:"url,omitempty"`
+	CapturedAt      time.Time   `json:"captured_at"`
+	ReceiptFiles    []string    `json:"receipt_files,omitempty"`
+	Paid            bool        `json:"paid,omitempty"`
+	RemindersSent   []int       `json:"reminders_sent,omitempty"`
+}
+
+// defaultPage 通用的订单确认页选择器
+var defaultPage = models.OrderPage{
+	OrderID:         ".order-number, .reserve-no, [data-order-id]",
+	Seats:           ".seat-info li, .seat-list li, .seat-info",
+	PriceRows:       ".price-list tr, .price-detail li",
+	PriceLabel:      "th, .label",
+	PriceAmount:     "td, .amount",
+	Total:           ".total-price, .total-amount",
+	PaymentDeadline: ".payment-deadline, .deposit-deadline",
+	PaymentMethod:   ".payment-method",
+	DeliveryMethod:  ".delivery-method, .receive-method",
+}
+
+// deadlineFormats 付款期限常见格式
+var deadlineFormats = []string{
+	"2006.01.02 15:04",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04",
+	"2006년 1월 2일 15:04",
+	"2006년 01월 02일 15시 04분",
+	"2006.01.02",
+	"2006-01-02",
+}
+
+// seoul 票务网站使用韩国时间
+var seoul = time.FixedZone("KST", 9*60*60)
+
+// Parse 解析订单确认页HTML，page中未配置的选择器使用默认值
+func Parse(html []byte, page models.OrderPage) (*Order, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
+	page = withDefaults(page)
+
+	o := &Order{CapturedAt: time.Now()}
+
+	id := doc.Find(page.OrderID).First()
+	o.ID = firstNonEmpty(id.AttrOr("data-order-id", ""), clean(id.Text()))
+	if o.ID == "" {
+		return nil, fmt.Errorf("确认页中没有找到订单号")
+	}
+
+	doc.Find(page.Seats).Each(func(_ int, s *goquery.Selection) {
+		// 嵌套匹配时只取最内层元素
+		if s.Find(page.Seats).Length() > 0 {
+			return
+		}
+		if seat := clean(s.Text()); seat != "" {
+			o.Seats = append(o.Seats, seat)
+		}
+	})
+
+	o.Prices, o.Total = parsePrices(doc, page)
+
+	// 付款期限格式不认识时仍保留订单，只是没有付款提醒
+	if text := clean(doc.Find(page.PaymentDeadline).First().Text()); text != "" {
+		if deadline, err := ParseDeadline(text, page.DeadlineFormat); err != nil {
+			log.Printf("订单 %s: %v，请检查 order_page.deadline_format", o.ID, err)
+		} else {
+			o.PaymentDeadline = deadline
+		}
+	}
+
+	o.PaymentMethod = clean(doc.Find(page.PaymentMethod).First().Text())
+	o.DeliveryMethod = clean(doc.Find(page.DeliveryMethod).First().Text())
+
+	return o, nil
+}
+
+// ParseTotal 读取结账页上的合计金额，用于确认购买前检查预算，没有金额时返回0
+func ParseTotal(html []byte, page models.OrderPage) (int, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return 0, err
+	}
+	_, total := parsePrices(doc, withDefaults(page))
+	return total, nil
+}
+
+// parsePrices 解析价格明细和合计，页面没有合计时按明细求和
+func parsePrices(doc *goquery.Document, page models.OrderPage) ([]PriceItem, int) {
+	var prices []PriceItem
+	doc.Find(page.PriceRows).Each(func(_ int, row *goquery.Selection) {
+		label := clean(row.Find(page.PriceLabel).First().Text())
+		amount := ParseAmount(row.Find(page.PriceAmount).Last().Text())
+		if label != "" && amount > 0 {
+			prices = append(prices, PriceItem{Label: label, Amount: amount})
+		}
+	})
+
+	total := ParseAmount(doc.Find(page.Total).First().Text())
+	if total == 0 {
+		for _, p := range prices {
+			total += p.Amount
+		}
+	}
+	return prices, total
+}
+
+// ParseAmount 解析金额文本，如"132,000원"
+func ParseAmount(text string) int {
+	var digits strings.Builder
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	n, _ := strconv.Atoi(digits.String())
+	return n
+}
+
+// ParseDeadline 解析付款期限，layout为空时尝试常见格式，时间按韩国时区解释
+func ParseDeadline(text, layout string) (time.Time, error) {
+	layouts := deadlineFormats
+	if layout != "" {
+		layouts = []string{layout}
+	}
+
+	// 期限常带有"까지"等后缀，逐步截短尝试
+	for _, l := range layouts {
+		for end := len(text); end > 0; end-- {
+			if t, err := time.ParseInLocation(l, strings.TrimSpace(text[:end]), seoul); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析付款期限: %s", text)
+}
+
+// withDefaults 补全未配置的选择器
+func withDefaults(page models.OrderPage) models.OrderPage {
+	fill := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fill(&page.OrderID, defaultPage.OrderID)
+	fill(&page.Seats, defaultPage.Seats)
+	fill(&page.PriceRows, defaultPage.PriceRows)
+	fill(&page.PriceLabel, defaultPage.PriceLabel)
+	fill(&page.PriceAmount, defaultPage.PriceAmount)
+	fill(&page.Total, defaultPage.Total)
+	fill(&page.PaymentDeadline, defaultPage.PaymentDeadline)
+	fill(&page.PaymentMethod, defaultPage.PaymentMethod)
+	fill(&page.DeliveryMethod, defaultPage.DeliveryMethod)
+	return page
+}
+
+// clean 合并连续空白
+func clean(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
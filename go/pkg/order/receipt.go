@@ -0,0 +1,47 @@
+package order
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tickgrabber/pkg/crypt"
+)
+
+// WriteReceipt 将订单导出为JSON收据，pdf非空时同时保存确认页PDF
+// 生成的文件路径记录在ReceiptFiles中；key 非空时加密保存，文件名加 crypt.FileExt
+func WriteReceipt(dir string, o *Order, pdf []byte, key string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	base := filepath.Join(dir, safeName(o.ID))
+	ext := ""
+	if key != "" {
+		ext = crypt.FileExt
+	}
+
+	if len(pdf) > 0 {
+		if err := crypt.WriteFile(key, base+".pdf"+ext, pdf, 0600); err != nil {
+			return err
+		}
+		o.ReceiptFiles = append(o.ReceiptFiles, base+".pdf"+ext)
+	}
+
+	o.ReceiptFiles = append(o.ReceiptFiles, base+".json"+ext)
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	return crypt.WriteFile(key, base+".json"+ext, data, 0600)
+}
+
+// safeName 订单号中可能含有不能用作文件名的字符
+func safeName(id string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, id)
+}
@@ -0,0 +1,91 @@
+package order
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// ReminderFunc 发送付款提醒，left为距离截止的剩余时间，final表示最后一次提醒
+// 返回错误时不记录为已发送，下次检查时重试
+type ReminderFunc func(ctx context.Context, o *Order, left time.Duration, final bool) error
+
+// Reminder 付款期限提醒
+// 已发送的提醒记录在订单中，重启后不会重复发送，也不会漏掉停机期间到期的提醒
+type Reminder struct {
+	store   *Store
+	offsets []int
+	send    ReminderFunc
+}
+
+// NewReminder 创建付款提醒，offsets为截止前多少分钟提醒
+func NewReminder(store *Store, offsets []int, send ReminderFunc) *Reminder {
+	sorted := append([]int(nil), offsets...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	return &Reminder{store: store, offsets: sorted, send: send}
+}
+
+// Run 定期检查到期的提醒，直到ctx取消
+func (r *Reminder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 发送所有已到期但未发送的提醒
+// 同一订单有多个提醒同时到期时(如停机后恢复)只发送最紧急的一个
+func (r *Reminder) Check(ctx context.Context) {
+	now := time.Now()
+
+	for _, o := range r.store.List() {
+		if o.Paid || o.PaymentDeadline.IsZero() || now.After(o.PaymentDeadline) {
+			continue
+		}
+
+		var due []int
+		for _, offset := range r.offsets {
+			at := o.PaymentDeadline.Add(-time.Duration(offset) * time.Minute)
+			if !now.Before(at) && !contains(o.RemindersSent, offset) {
+				due = append(due, offset)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		latest := due[len(due)-1]
+		final := latest == r.offsets[len(r.offsets)-1]
+		if err := r.send(ctx, o, o.PaymentDeadline.Sub(now), final); err != nil {
+			log.Printf("订单 %s 付款提醒发送失败: %v", o.ID, err)
+			continue
+		}
+
+		err := r.store.Update(o.ID, func(o *Order) {
+			o.RemindersSent = append(o.RemindersSent, due...)
+		})
+		if err != nil {
+			log.Printf("保存提醒记录失败: %v", err)
+		}
+	}
+}
+
+// contains 判断切片是否包含值
+func contains(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
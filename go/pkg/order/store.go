@@ -0,0 +1,165 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"tickgrabber/pkg/crypt"
+)
+
+// Store 订单记录，保存在JSON文件中
+type Store struct {
+	mu   sync.Mutex
+	path string
+	// key 加密文件的口令，为空时写入明文
+	key    string
+	orders map[string]*Order
+}
+
+// stores 进程内已打开的订单记录，按文件的绝对路径索引
+var stores = struct {
+	sync.Mutex
+	open map[string]*Store
+}{open: make(map[string]*Store)}
+
+// OpenStore 打开订单记录文件，文件不存在时创建空记录
+// 同一进程中多次打开同一文件返回同一个Store，抢票任务、付款提醒和日历导出看到的是同一份记录
+// key 非空时文件内容加密保存，读取时加密和未加密的文件都可以打开
+func OpenStore(path, key string) (*Store, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stores.Lock()
+	defer stores.Unlock()
+	if s, ok := stores.open[key]; ok {
+		return s, nil
+	}
+	s, err := loadStore(path, key)
+	if err != nil {
+		return nil, err
+	}
+	stores.open[key] = s
+	return s, nil
+}
+
+// loadStore 读取订单记录文件
+func loadStore(path, key string) (*Store, error) {
+	s := &Store{path: path, key: key, orders: make(map[string]*Order)}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// reload 重新读取文件中的订单，调用方需持有锁
+// 守护进程运行期间 mark-paid 等命令会在另一个进程中修改文件，写入前先读取，避免用旧数据覆盖这些修改
+func (s *Store) reload() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if data, err = crypt.Decode(s.key, data); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	var orders []*Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return err
+	}
+	for _, o := range orders {
+		s.orders[o.ID] = o
+	}
+	return nil
+}
+
+// Save 保存或更新订单
+func (s *Store) Save(o *Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+	s.orders[o.ID] = o
+	return s.persist()
+}
+
+// Get 按订单号查询，返回副本
+func (s *Store) Get(id string) (*Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[id]
+	if !ok {
+		return nil, false
+	}
+	cp := *o
+	return &cp, true
+}
+
+// Update 修改订单并保存
+func (s *Store) Update(id string, fn func(o *Order)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+	o, ok := s.orders[id]
+	if !ok {
+		return fmt.Errorf("找不到订单 %s", id)
+	}
+	fn(o)
+	return s.persist()
+}
+
+// List 返回全部订单的副本，按采集时间排序
+func (s *Store) List() []*Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := s.sorted()
+	for i, o := range orders {
+		cp := *o
+		orders[i] = &cp
+	}
+	return orders
+}
+
+// sorted 按采集时间排序的订单列表，调用方需持有锁
+func (s *Store) sorted() []*Order {
+	orders := make([]*Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CapturedAt.Before(orders[j].CapturedAt)
+	})
+	return orders
+}
+
+// persist 写入文件，先写临时文件再替换，避免中途退出损坏记录
+func (s *Store) persist() error {
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := crypt.WriteFile(s.key, tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,66 @@
+package order
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SeatCheck 订单座位与选座时座位的比对结果
+type SeatCheck struct {
+	// Missing 选中了但订单中没有的座位
+	Missing []string `json:"missing,omitempty"`
+	// Unexpected 订单中出现但没有选中的座位
+	Unexpected []string `json:"unexpected,omitempty"`
+}
+
+// OK 座位是否一致
+func (c SeatCheck) OK() bool {
+	return len(c.Missing) == 0 && len(c.Unexpected) == 0
+}
+
+// VerifySeats 比对选中的座位和订单中的座位
+// 两边页面对同一座位的写法可能不同(如是否带座位等级)，忽略空白后互相包含即视为同一座位
+func VerifySeats(requested, booked []string) SeatCheck {
+	var check SeatCheck
+	used := make([]bool, len(booked))
+
+	for _, want := range requested {
+		found := false
+		for i, got := range booked {
+			if !used[i] && sameSeat(want, got) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			check.Missing = append(check.Missing, want)
+		}
+	}
+
+	for i, got := range booked {
+		if !used[i] {
+			check.Unexpected = append(check.Unexpected, got)
+		}
+	}
+	return check
+}
+
+// sameSeat 判断两个座位描述是否指同一座位
+func sameSeat(a, b string) bool {
+	a, b = compact(a), compact(b)
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+// compact 去掉空白并转小写
+func compact(s string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s))
+}
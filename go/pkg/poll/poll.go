@@ -0,0 +1,110 @@
+// Package poll 按服务器提示和页面实际变化的频率调整监控的轮询间隔
+// 长时间没有变化的页面逐步放慢，接近开售或页面频繁变化时加快，服务器要求等待时遵守Retry-After
+package poll
+
+import "time"
+
+const (
+	// backoffEvery 页面连续这么多次没有变化时间隔加倍
+	backoffEvery = 30
+	// maxDoublings 间隔最多加倍的次数
+	maxDoublings = 6
+	// 开售前后这段时间内使用最短间隔
+	saleLead  = time.Minute
+	saleTrail = 10 * time.Minute
+)
+
+// Adaptive 一场演出的自适应轮询间隔，不是并发安全的，由监控循环独占使用
+type Adaptive struct {
+	base     time.Duration
+	min      time.Duration
+	max      time.Duration
+	saleOpen time.Time
+
+	fingerprint string
+	lastChange  time.Time
+	gap         time.Duration
+	unchanged   int
+
+	retryAfter time.Time
+	maxAge     time.Duration
+}
+
+// New 创建自适应间隔，base为配置的刷新间隔，结果限制在[min, max]之间(Retry-After除外)
+// saleOpen为开售时间，零值表示未知
+func New(base, min, max time.Duration, saleOpen time.Time) *Adaptive {
+	if min <= 0 || min > base {
+		min = base
+	}
+	if max < base {
+		max = base
+	}
+	return &Adaptive{base: base, min: min, max: max, saleOpen: saleOpen}
+}
+
+// Observe 记录一次检查得到的页面指纹，指纹变化视为页面内容变化
+func (a *Adaptive) Observe(now time.Time, fingerprint string) {
+	if a.fingerprint == "" {
+		a.fingerprint = fingerprint
+		a.lastChange = now
+		return
+	}
+	if fingerprint == a.fingerprint {
+		a.unchanged++
+		return
+	}
+
+	// 变化间隔取指数平均，偶尔的一次变化不会让间隔剧烈波动
+	gap := now.Sub(a.lastChange)
+	if a.gap == 0 {
+		a.gap = gap
+	} else {
+		a.gap = (a.gap*3 + gap) / 4
+	}
+	a.fingerprint = fingerprint
+	a.lastChange = now
+	a.unchanged = 0
+}
+
+// Hint 记录服务器的提示，retryAfter为零值表示没有要求等待，maxAge为0表示不可缓存
+func (a *Adaptive) Hint(retryAfter time.Time, maxAge time.Duration) {
+	a.retryAfter = retryAfter
+	a.maxAge = maxAge
+}
+
+// Next 返回到下一次检查的间隔
+func (a *Adaptive) Next(now time.Time) time.Duration {
+	d := a.interval(now)
+	if wait := a.retryAfter.Sub(now); wait > d {
+		d = wait
+	}
+	return d
+}
+
+// interval 不考虑Retry-After时的间隔
+func (a *Adaptive) interval(now time.Time) time.Duration {
+	if !a.saleOpen.IsZero() && now.After(a.saleOpen.Add(-saleLead)) && now.Before(a.saleOpen.Add(saleTrail)) {
+		return a.min
+	}
+
+	doublings := a.unchanged / backoffEvery
+	if doublings > maxDoublings {
+		doublings = maxDoublings
+	}
+	d := a.base << doublings
+	// 页面有规律地变化时，每个变化周期内至少检查4次
+	if a.gap > 0 && a.gap/4 < d {
+		d = a.gap / 4
+	}
+	if a.maxAge > d {
+		d = a.maxAge
+	}
+
+	if d < a.min {
+		d = a.min
+	}
+	if d > a.max {
+		d = a.max
+	}
+	return d
+}
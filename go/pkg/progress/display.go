@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames 进度动画
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// logLines 界面下方保留的最近日志行数
+	logLines = 5
+	// maxLineWidth 日志行超过该宽度(列数)时截断，避免折行打乱界面
+	maxLineWidth = 72
+	// refreshInterval 界面刷新间隔
+	refreshInterval = 100 * time.Millisecond
+)
+
+// Display 前台运行时的终端进度界面，代替逐行滚动的日志
+// 显示动画、当前阶段、排队位置、下次检查倒计时和支付剩余时间，下方保留最近几行日志
+// Display 本身是io.Writer，作为日志输出时收集日志行
+type Display struct {
+	out    io.Writer
+	title  string
+	status *Status
+	start  time.Time
+
+	mu      sync.Mutex
+	logs    []string
+	partial string
+	drawn   int
+	frame   int
+}
+
+// NewDisplay 创建进度界面，out通常为os.Stderr
+func NewDisplay(out io.Writer, title string, status *Status) *Display {
+	return &Display{out: out, title: title, status: status, start: time.Now()}
+}
+
+// IsTerminal 判断f是否为交互式终端
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Write 收集日志行
+func (d *Display) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := strings.Split(d.partial+string(p), "\n")
+	d.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			d.logs = append(d.logs, truncate(line))
+		}
+	}
+	if len(d.logs) > logLines {
+		d.logs = d.logs[len(d.logs)-logLines:]
+	}
+	return len(p), nil
+}
+
+// Run 持续刷新界面直到ctx取消，返回前绘制最后一帧
+func (d *Display) Run(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		d.draw()
+		select {
+		case <-ctx.Done():
+			d.draw()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// draw 清除上一帧并绘制当前状态
+func (d *Display) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	snapshot := d.status.Snapshot()
+	spinner := spinnerFrames[d.frame%len(spinnerFrames)]
+	d.frame++
+
+	lines := []string{
+		fmt.Sprintf("%s %s  已运行 %s", spinner, d.title, countdown(now.Sub(d.start))),
+		"  " + snapshot.Summary(now),
+	}
+	for _, line := range d.logs {
+		lines = append(lines, "  │ "+line)
+	}
+
+	var b strings.Builder
+	if d.drawn > 0 {
+		// 光标移到上一帧第一行行首并清除到屏幕末尾
+		fmt.Fprintf(&b, "\x1b[%dF\x1b[J", d.drawn)
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	io.WriteString(d.out, b.String())
+	d.drawn = len(lines)
+}
+
+// truncate 截断过长的日志行，中日韩文字按两列计算
+func truncate(line string) string {
+	width := 0
+	for i, r := range line {
+		if r >= 0x2E80 {
+			width += 2
+		} else {
+			width++
+		}
+		if width > maxLineWidth {
+			return line[:i] + "…"
+		}
+	}
+	return line
+}
@@ -0,0 +1,168 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status 一个抢票任务的实时进度，供前台运行的终端界面和守护模式的status命令显示
+// 各方法对nil安全，没有进度显示时抢票器可以照常调用
+type Status struct {
+	mu       sync.Mutex
+	stage    string
+	detail   string
+	polls    int
+	nextPoll time.Time
+	label    string
+	deadline time.Time
+	queue    func() int
+	updated  time.Time
+}
+
+// Snapshot 进度快照
+// Deadline 为倒计时的截止时刻(如开售时间、支付剩余时间)，CountdownLabel 为倒计时的说明
+type Snapshot struct {
+	Stage          string    `json:"stage"`
+	Detail         string    `json:"detail,omitempty"`
+	Polls          int       `json:"polls"`
+	NextPoll       time.Time `json:"next_poll,omitempty"`
+	QueuePosition  int       `json:"queue_position,omitempty"`
+	CountdownLabel string    `json:"countdown_label,omitempty"`
+	Deadline       time.Time `json:"deadline,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// New 创建进度
+func New() *Status {
+	return &Status{stage: "准备中", updated: time.Now()}
+}
+
+// SetStage 进入新的阶段，清除上一阶段的倒计时和排队信息
+func (s *Status) SetStage(stage, detail string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stage, s.detail = stage, detail
+	s.nextPoll, s.label, s.deadline, s.queue = time.Time{}, "", time.Time{}, nil
+	s.updated = time.Now()
+}
+
+// SetDetail 更新当前阶段的说明(如购买中的具体步骤)
+func (s *Status) SetDetail(detail string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.detail = detail
+	s.updated = time.Now()
+}
+
+// SetQueue 设置排队位置的查询函数，快照时调用
+func (s *Status) SetQueue(position func() int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = position
+}
+
+// SetCountdown 设置倒计时
+func (s *Status) SetCountdown(label string, deadline time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.label, s.deadline = label, deadline
+}
+
+// Polled 记录完成一次余票检查，next为下一次检查的时刻
+func (s *Status) Polled(next time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polls++
+	s.nextPoll = next
+	s.updated = time.Now()
+}
+
+// Snapshot 返回当前进度
+func (s *Status) Snapshot() Snapshot {
+	if s == nil {
+		return Snapshot{}
+	}
+	s.mu.Lock()
+	snapshot := Snapshot{
+		Stage:          s.stage,
+		Detail:         s.detail,
+		Polls:          s.polls,
+		NextPoll:       s.nextPoll,
+		CountdownLabel: s.label,
+		Deadline:       s.deadline,
+		UpdatedAt:      s.updated,
+	}
+	queue := s.queue
+	s.mu.Unlock()
+
+	if queue != nil {
+		snapshot.QueuePosition = queue()
+	}
+	return snapshot
+}
+
+// Summary 生成一行进度说明，如 "监控中 · 已检查 42 次 · 下次检查 0.8s"
+func (s Snapshot) Summary(now time.Time) string {
+	parts := []string{s.Stage}
+	if s.Detail != "" {
+		parts = append(parts, s.Detail)
+	}
+	if s.QueuePosition > 0 {
+		parts = append(parts, fmt.Sprintf("排队第 %d 位", s.QueuePosition))
+	}
+	if s.Polls > 0 {
+		parts = append(parts, fmt.Sprintf("已检查 %d 次", s.Polls))
+	}
+	if s.NextPoll.After(now) {
+		parts = append(parts, fmt.Sprintf("下次检查 %.1fs", s.NextPoll.Sub(now).Seconds()))
+	}
+	if !s.Deadline.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s %s", s.CountdownLabel, countdown(s.Deadline.Sub(now))))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// countdown 把剩余时间格式化为 [时:]分:秒，已过期时显示00:00
+func countdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	h, m, sec := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%02d:%02d", m, sec)
+}
+
+// contextKey 在ctx中保存进度的键
+type contextKey struct{}
+
+// NewContext 返回带有进度的ctx，抢票器从ctx中取出进度并更新
+func NewContext(ctx context.Context, s *Status) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext 取出ctx中的进度，没有时返回nil
+func FromContext(ctx context.Context) *Status {
+	s, _ := ctx.Value(contextKey{}).(*Status)
+	return s
+}
@@ -0,0 +1,164 @@
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// placeholder 替换敏感内容的文字
+const placeholder = "******"
+
+// minSecretLength 短于该长度的已知值不做替换，避免把常见的短词全部替换掉
+const minSecretLength = 4
+
+// rule 按正则匹配的敏感内容，replace为nil时用 ${1}****** 替换(保留第一个分组，如参数名)
+type rule struct {
+	re      *regexp.Regexp
+	replace func(string) string
+}
+
+// builtin 内置规则：密码和令牌参数、Bearer令牌、银行卡号、韩国和中国的手机号
+var builtin = []rule{
+	{re: regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token|api_?key|authorization)["']?\s*[:=]\s*["']?)(?:Bearer\s+)?[^\s"'&,;]+`)},
+	{re: regexp.MustCompile(`(?i)(Bearer\s+)[A-Za-z0-9\-._~+/]+=*`)},
+	{re: regexp.MustCompile(`\b[3-69](?:[ -]?\d){13,15}\b`), replace: maskCard},
+	{re: regexp.MustCompile(`\b01[016789][- .]?\d{3,4}[- .]?\d{4}\b`), replace: maskDigits},
+	{re: regexp.MustCompile(`\+?\b(?:82|86)[- ]?\d{1,2}[- ]?\d{3,4}[- ]?\d{4}\b`), replace: maskDigits},
+	{re: regexp.MustCompile(`\b1[3-9]\d{9}\b`), replace: maskDigits},
+}
+
+// Masker 脱敏器，替换已知的敏感值(配置中的密码、令牌、收件人电话等)和符合内置规则的内容
+type Masker struct {
+	mu      sync.RWMutex
+	secrets []string
+	rules   []rule
+}
+
+// New 创建只带内置规则的脱敏器
+func New() *Masker {
+	return &Masker{rules: append([]rule(nil), builtin...)}
+}
+
+// Default 日志、录制文件和追踪共用的脱敏器
+var Default = New()
+
+// AddSecrets 添加需要替换的已知值，空值和过短的值被忽略
+func (m *Masker) AddSecrets(values ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, value := range values {
+		if len(value) >= minSecretLength {
+			m.secrets = append(m.secrets, value)
+		}
+	}
+	// 先替换较长的值，避免其中包含的较短值替换后长值无法匹配
+	sort.Slice(m.secrets, func(i, j int) bool { return len(m.secrets[i]) > len(m.secrets[j]) })
+}
+
+// AddPattern 添加自定义规则，匹配的内容整体替换
+func (m *Masker) AddPattern(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rule{re: re, replace: func(string) string { return placeholder }})
+	return nil
+}
+
+// String 返回脱敏后的文字
+func (m *Masker) String(s string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, secret := range m.secrets {
+		s = strings.ReplaceAll(s, secret, placeholder)
+	}
+	for _, r := range m.rules {
+		if r.replace == nil {
+			s = r.re.ReplaceAllString(s, "${1}"+placeholder)
+		} else {
+			s = r.re.ReplaceAllStringFunc(s, r.replace)
+		}
+	}
+	return s
+}
+
+// Writer 包装w，写入前脱敏，用于log.SetOutput
+func (m *Masker) Writer(w io.Writer) io.Writer {
+	return writer{m: m, w: w}
+}
+
+// writer 写入前脱敏的io.Writer
+type writer struct {
+	m *Masker
+	w io.Writer
+}
+
+// Write 脱敏后写入，返回原始长度
+func (w writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.m.String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// String 使用Default脱敏
+func String(s string) string {
+	return Default.String(s)
+}
+
+// maskCard 通过Luhn校验的卡号只保留最后4位，其他数字(如订单号)保持不变
+func maskCard(s string) string {
+	if !luhn(s) {
+		return s
+	}
+	return maskDigits(s)
+}
+
+// maskDigits 把除最后4位以外的数字替换为*，保留分隔符
+func maskDigits(s string) string {
+	digits := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits--
+			if digits >= 4 {
+				c = '*'
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// luhn 数字串是否通过Luhn校验，忽略分隔符
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
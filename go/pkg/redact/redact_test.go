@@ -0,0 +1,70 @@
+package redact
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestMaskerString(t *testing.T) {
+	m := New()
+	m.AddSecrets("hunter2", "abc", "", "hunter2-extended")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"已知值", "登录 user:hunter2 失败", "登录 user:****** 失败"},
+		{"先替换较长的值", "key=hunter2-extended", "key=******"},
+		{"过短的值不替换", "abc def", "abc def"},
+		{"密码参数", "password=s3cr3t&id=1", "password=******&id=1"},
+		{"JSON中的令牌", `{"api_key": "k-123"}`, `{"api_key": "******"}`},
+		{"Bearer令牌", "Authorization: Bearer eyJhbGciOi.x-y", "Authorization: ******"},
+		{"单独的Bearer令牌", "header Bearer eyJhbGciOi.x-y", "header Bearer ******"},
+		{"银行卡号", "card 4111 1111 1111 1111", "card **** **** **** 1111"},
+		{"不通过校验的数字", "order 4111111111111112", "order 4111111111111112"},
+		{"韩国手机号", "tel 010-1234-5678", "tel ***-****-5678"},
+		{"中国手机号", "tel 13812345678", "tel *******5678"},
+		{"国际区号", "tel +82 10 1234 5678", "tel +** ** **** 5678"},
+		{"普通文字", "没有敏感内容", "没有敏感内容"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q，期望 %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskerPattern(t *testing.T) {
+	m := New()
+	if err := m.AddPattern(`ORD-\d+`); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.String("订单 ORD-12345 已付款"); got != "订单 ****** 已付款" {
+		t.Errorf("自定义规则没有生效: %q", got)
+	}
+	if err := m.AddPattern(`(`); err == nil {
+		t.Error("无效的规则应返回错误")
+	}
+}
+
+func TestMaskerWriter(t *testing.T) {
+	m := New()
+	m.AddSecrets("https://discord.com/api/webhooks/1/tok")
+
+	var buf bytes.Buffer
+	logger := log.New(m.Writer(&buf), "", 0)
+	logger.Printf("发送到 https://discord.com/api/webhooks/1/tok 失败, token=abcd")
+
+	got := buf.String()
+	if strings.Contains(got, "webhooks/1/tok") || strings.Contains(got, "abcd") {
+		t.Errorf("日志中仍有敏感内容: %q", got)
+	}
+	if want := "发送到 ****** 失败, token=******\n"; got != want {
+		t.Errorf("日志输出 %q，期望 %q", got, want)
+	}
+}
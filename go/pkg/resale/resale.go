@@ -0,0 +1,221 @@
+// Package resale 跟踪官方转让(양도)页面上的挂单价格
+//
+// 与 alert 一样用HTTP获取页面，不启动浏览器。每场演出记录已见过的挂单和最低价，
+// 新出现价格不超过上限的挂单时发送通知，由用户在转让页面手动购买
+package resale
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/order"
+)
+
+// defaultInterval 没有配置检查间隔时每场演出的检查间隔
+const defaultInterval = time.Minute
+
+// defaultBoard 通用的转让列表选择器
+var defaultBoard = models.ResaleBoard{
+	Rows:      "[data-listing-id], .resale-list li, .transfer-list li, table.resale tbody tr",
+	ListingID: "[data-listing-id]",
+	Price:     "[data-price], .price, .amount",
+	Seat:      ".seat, .grade, .seat-info",
+	Link:      "a[href]",
+}
+
+// Listing 转让页面上的一条挂单
+type Listing struct {
+	ID    string `json:"id"`
+	Seat  string `json:"seat"`
+	Price int    `json:"price"`
+	URL   string `json:"url"`
+}
+
+// Parse 解析转让页面，board中未配置的选择器使用默认值，base用于补全相对链接
+func Parse(doc *goquery.Document, board models.ResaleBoard, base *url.URL) []Listing {
+	board = withDefaults(board)
+
+	var listings []Listing
+	doc.Find(board.Rows).Each(func(_ int, row *goquery.Selection) {
+		// 嵌套匹配时只取最内层的行
+		if row.Find(board.Rows).Length() > 0 {
+			return
+		}
+		price := row.Find(board.Price).First()
+		l := Listing{
+			Seat:  clean(row.Find(board.Seat).First().Text()),
+			Price: order.ParseAmount(price.AttrOr("data-price", price.Text())),
+		}
+		if l.Price == 0 {
+			return
+		}
+		if href, ok := row.Find(board.Link).First().Attr("href"); ok {
+			if u, err := base.Parse(href); err == nil {
+				l.URL = u.String()
+			}
+		}
+
+		l.ID = row.AttrOr("data-listing-id", row.Find(board.ListingID).First().AttrOr("data-listing-id", ""))
+		if l.ID == "" {
+			l.ID = l.URL
+		}
+		if l.ID == "" {
+			sum := sha1.Sum([]byte(clean(row.Text())))
+			l.ID = hex.EncodeToString(sum[:8])
+		}
+		listings = append(listings, l)
+	})
+	return listings
+}
+
+// Tracker 一场演出的挂单记录
+type Tracker struct {
+	seen   map[string]int
+	lowest int
+}
+
+// NewTracker 创建挂单记录
+func NewTracker() *Tracker {
+	return &Tracker{seen: make(map[string]int)}
+}
+
+// Update 用本次看到的挂单更新记录，返回新出现(或降价)且不超过maxPrice的挂单，maxPrice为0时不限价格
+// 第一次检查时已有的挂单也会返回，启动监控时就存在的低价挂单同样值得提醒
+func (t *Tracker) Update(listings []Listing, maxPrice int) []Listing {
+	current := make(map[string]int, len(listings))
+	lowest := 0
+	var matched []Listing
+	for _, l := range listings {
+		current[l.ID] = l.Price
+		if lowest == 0 || l.Price < lowest {
+			lowest = l.Price
+		}
+		if maxPrice > 0 && l.Price > maxPrice {
+			continue
+		}
+		if prev, ok := t.seen[l.ID]; ok && l.Price >= prev {
+			continue
+		}
+		matched = append(matched, l)
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Price < matched[j].Price })
+
+	if lowest != t.lowest {
+		if lowest == 0 {
+			log.Printf("转让页面已没有挂单")
+		} else {
+			log.Printf("转让最低价: %d원 (%d 条挂单)", lowest, len(listings))
+		}
+		t.lowest = lowest
+	}
+	t.seen = current
+	return matched
+}
+
+// Lowest 返回当前最低价，没有挂单时返回0
+func (t *Tracker) Lowest() int {
+	return t.lowest
+}
+
+// Target 跟踪的演出
+type Target struct {
+	Concert  models.Concert
+	Board    models.ResaleBoard
+	MaxPrice int
+}
+
+// Watch 定期检查target的转让页面直到ctx取消，新出现符合价格的挂单时通过notifier提醒
+func Watch(ctx context.Context, client *api.Client, notifier *notify.Manager, target Target) {
+	resale := target.Concert.Resale
+	if resale == nil || resale.URL == "" {
+		return
+	}
+	base, err := url.Parse(resale.URL)
+	if err != nil {
+		log.Printf("[%s] 转让页面地址无效: %v", target.Concert.ID, err)
+		return
+	}
+	interval := defaultInterval
+	if resale.Interval > 0 {
+		interval = time.Duration(resale.Interval * float64(time.Second))
+	}
+
+	log.Printf("[%s] 跟踪转让挂单，每 %s 检查一次: %s", target.Concert.ID, interval, resale.URL)
+	tracker := NewTracker()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		doc, err := client.GetDocument(ctx, resale.URL)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[%s] 获取转让页面失败: %v", target.Concert.ID, err)
+			}
+		} else {
+			for _, l := range tracker.Update(Parse(doc, target.Board, base), target.MaxPrice) {
+				notifyListing(ctx, notifier, target, l, resale.URL)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyListing 提醒一条符合价格的挂单
+func notifyListing(ctx context.Context, notifier *notify.Manager, target Target, l Listing, board string) {
+	log.Printf("[%s] 发现转让挂单: %s %d원", target.Concert.ID, l.Seat, l.Price)
+	link := l.URL
+	if link == "" {
+		link = board
+	}
+	message := fmt.Sprintf("%s %d원，请尽快在转让页面手动购买", l.Seat, l.Price)
+	details := map[string]string{"金额": fmt.Sprintf("%d원", l.Price)}
+	if target.MaxPrice > 0 {
+		details["价格上限"] = fmt.Sprintf("%d원", target.MaxPrice)
+	}
+	notifier.Notify(ctx, notify.Event{
+		Type:    notify.EventTicketFound,
+		Title:   "发现转让挂单",
+		Message: strings.TrimSpace(message),
+		Concert: target.Concert.Name,
+		URL:     link,
+		Details: details,
+	})
+}
+
+// withDefaults 补全未配置的选择器
+func withDefaults(board models.ResaleBoard) models.ResaleBoard {
+	fill := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	fill(&board.Rows, defaultBoard.Rows)
+	fill(&board.ListingID, defaultBoard.ListingID)
+	fill(&board.Price, defaultBoard.Price)
+	fill(&board.Seat, defaultBoard.Seat)
+	fill(&board.Link, defaultBoard.Link)
+	return board
+}
+
+// clean 合并连续空白
+func clean(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
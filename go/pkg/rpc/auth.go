@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/redact"
+	"tickgrabber/pkg/rpc/pb"
+)
+
+// Role 令牌的权限，权限高的包含权限低的全部操作
+type Role int
+
+const (
+	// RoleRead 查询任务、演唱会和订阅事件，事件内容脱敏
+	RoleRead Role = iota + 1
+	// RoleControl 还能提交和取消任务
+	RoleControl
+	// RoleAdmin 全部权限，包括工作节点连接和抢占购买权
+	RoleAdmin
+)
+
+// ParseRole 解析配置中的权限名称: read、control、admin
+func ParseRole(name string) (Role, bool) {
+	switch strings.ToLower(name) {
+	case "read":
+		return RoleRead, true
+	case "control":
+		return RoleControl, true
+	case "admin":
+		return RoleAdmin, true
+	}
+	return 0, false
+}
+
+// methodRoles 各接口需要的权限，没有列出的接口需要admin
+var methodRoles = map[string]Role{
+	pb.TicketGrabber_GetTask_FullMethodName:      RoleRead,
+	pb.TicketGrabber_ListTasks_FullMethodName:    RoleRead,
+	pb.TicketGrabber_ListConcerts_FullMethodName: RoleRead,
+	pb.TicketGrabber_StreamEvents_FullMethodName: RoleRead,
+	pb.TicketGrabber_SubmitTask_FullMethodName:   RoleControl,
+	pb.TicketGrabber_CancelTask_FullMethodName:   RoleControl,
+}
+
+// credential 一个令牌及其权限
+type credential struct {
+	token []byte
+	role  Role
+}
+
+// roleKey context中保存调用方权限的键
+type roleKey struct{}
+
+// roleFrom 返回调用方的权限，没有启用认证时为admin
+func roleFrom(ctx context.Context) Role {
+	if role, ok := ctx.Value(roleKey{}).(Role); ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// credentials 由配置生成令牌列表，token 为admin令牌，权限名称无效或令牌为空时返回错误
+func credentials(config models.RPCConfig) ([]credential, error) {
+	var creds []credential
+	if config.Token != "" {
+		creds = append(creds, credential{token: []byte(config.Token), role: RoleAdmin})
+	}
+	for _, t := range config.Tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("rpc令牌 %s 为空", t.Name)
+		}
+		role, ok := ParseRole(t.Role)
+		if !ok {
+			return nil, fmt.Errorf("rpc令牌 %s 的权限 %q 无效，应为 read、control 或 admin", t.Name, t.Role)
+		}
+		creds = append(creds, credential{token: []byte(t.Token), role: role})
+	}
+	return creds, nil
+}
+
+// tokenAuth 校验metadata中的Bearer令牌和调用接口需要的权限，返回带有调用方权限的context
+func tokenAuth(creds []credential) func(ctx context.Context, method string) (context.Context, error) {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var role Role
+		for _, value := range md.Get("authorization") {
+			got := []byte(strings.TrimPrefix(value, "Bearer "))
+			for _, c := range creds {
+				if subtle.ConstantTimeCompare(got, c.token) == 1 && c.role > role {
+					role = c.role
+				}
+			}
+		}
+		if role == 0 {
+			return nil, status.Error(codes.Unauthenticated, "令牌无效")
+		}
+
+		need, ok := methodRoles[method]
+		if !ok {
+			need = RoleAdmin
+		}
+		if role < need {
+			return nil, status.Error(codes.PermissionDenied, "令牌没有调用该接口的权限")
+		}
+		return context.WithValue(ctx, roleKey{}, role), nil
+	}
+}
+
+// authInterceptors 生成校验令牌的拦截器
+func authInterceptors(creds []credential) []grpc.ServerOption {
+	auth := tokenAuth(creds)
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := auth(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(s interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := auth(ss.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(s, authStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+// authStream 带有调用方权限的服务端流
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回带有调用方权限的context
+func (s authStream) Context() context.Context {
+	return s.ctx
+}
+
+// maskEvent 给只读令牌的事件脱敏，去掉订单号，标题、内容和详情按日志脱敏规则处理
+func maskEvent(e notify.Event) notify.Event {
+	e.Title = redact.String(e.Title)
+	e.Message = redact.String(e.Message)
+	e.OrderID = ""
+	if len(e.Details) > 0 {
+		details := make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			details[k] = redact.String(v)
+		}
+		e.Details = details
+	}
+	return e
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/rpc/pb"
+)
+
+func TestTokenAuth(t *testing.T) {
+	creds, err := credentials(models.RPCConfig{
+		Token: "admin-token",
+		Tokens: []models.RPCToken{
+			{Name: "面板", Token: "read-token", Role: "read"},
+			{Name: "脚本", Token: "control-token", Role: "control"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := tokenAuth(creds)
+	roles := map[string]Role{"read-token": RoleRead, "control-token": RoleControl, "admin-token": RoleAdmin}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		want   codes.Code
+	}{
+		{"只读令牌查询任务", "read-token", pb.TicketGrabber_GetTask_FullMethodName, codes.OK},
+		{"只读令牌列出任务", "read-token", pb.TicketGrabber_ListTasks_FullMethodName, codes.OK},
+		{"只读令牌列出演唱会", "read-token", pb.TicketGrabber_ListConcerts_FullMethodName, codes.OK},
+		{"只读令牌订阅事件", "read-token", pb.TicketGrabber_StreamEvents_FullMethodName, codes.OK},
+		{"只读令牌提交任务", "read-token", pb.TicketGrabber_SubmitTask_FullMethodName, codes.PermissionDenied},
+		{"只读令牌取消任务", "read-token", pb.TicketGrabber_CancelTask_FullMethodName, codes.PermissionDenied},
+		{"控制令牌查询任务", "control-token", pb.TicketGrabber_GetTask_FullMethodName, codes.OK},
+		{"控制令牌提交任务", "control-token", pb.TicketGrabber_SubmitTask_FullMethodName, codes.OK},
+		{"控制令牌取消任务", "control-token", pb.TicketGrabber_CancelTask_FullMethodName, codes.OK},
+		{"控制令牌连接工作节点", "control-token", pb.Controller_Connect_FullMethodName, codes.PermissionDenied},
+		{"控制令牌抢占购买权", "control-token", pb.Controller_ClaimPurchase_FullMethodName, codes.PermissionDenied},
+		{"未列出的接口需要admin", "control-token", "/tickgrabber.v1.TicketGrabber/Unknown", codes.PermissionDenied},
+		{"只读令牌调用未列出的接口", "read-token", "/tickgrabber.v1.TicketGrabber/Unknown", codes.PermissionDenied},
+		{"admin令牌连接工作节点", "admin-token", pb.Controller_Connect_FullMethodName, codes.OK},
+		{"admin令牌抢占购买权", "admin-token", pb.Controller_ClaimPurchase_FullMethodName, codes.OK},
+		{"admin令牌调用未列出的接口", "admin-token", "/tickgrabber.v1.TicketGrabber/Unknown", codes.OK},
+		{"admin令牌提交任务", "admin-token", pb.TicketGrabber_SubmitTask_FullMethodName, codes.OK},
+		{"令牌错误", "wrong-token", pb.TicketGrabber_GetTask_FullMethodName, codes.Unauthenticated},
+		{"没有令牌", "", pb.TicketGrabber_GetTask_FullMethodName, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+			got, err := auth(ctx, tt.method)
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("%s 调用 %s 返回 %v，期望 %v", tt.token, tt.method, code, tt.want)
+			}
+			if err == nil && roleFrom(got) != roles[tt.token] {
+				t.Errorf("context中的权限 %v，期望 %v", roleFrom(got), roles[tt.token])
+			}
+		})
+	}
+}
+
+func TestMethodRoles(t *testing.T) {
+	// 工作节点使用的接口没有列出，需要admin
+	for _, method := range []string{pb.Controller_Connect_FullMethodName, pb.Controller_ClaimPurchase_FullMethodName} {
+		if role, ok := methodRoles[method]; ok {
+			t.Errorf("%s 的权限为 %v，期望不列出(需要admin)", method, role)
+		}
+	}
+	if roleFrom(context.Background()) != RoleAdmin {
+		t.Error("没有启用认证时应为admin")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  models.RPCConfig
+		want    int
+		wantErr bool
+	}{
+		{"没有令牌", models.RPCConfig{}, 0, false},
+		{"只有admin令牌", models.RPCConfig{Token: "a"}, 1, false},
+		{"权限名称不区分大小写", models.RPCConfig{Tokens: []models.RPCToken{{Name: "x", Token: "b", Role: "Read"}}}, 1, false},
+		{"权限名称无效", models.RPCConfig{Tokens: []models.RPCToken{{Name: "x", Token: "b", Role: "owner"}}}, 0, true},
+		{"令牌为空", models.RPCConfig{Tokens: []models.RPCToken{{Name: "x", Role: "read"}}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := credentials(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("credentials = %v，期望错误 %v", err, tt.wantErr)
+			}
+			if len(creds) != tt.want {
+				t.Errorf("令牌数 %d，期望 %d", len(creds), tt.want)
+			}
+		})
+	}
+}
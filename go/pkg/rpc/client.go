@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/rpc/pb"
+	"tickgrabber/pkg/task"
+)
+
+// Client 守护进程gRPC接口的客户端，供命令行查询任务状态
+type Client struct {
+	conn  *grpc.ClientConn
+	api   pb.TicketGrabberClient
+	token string
+}
+
+// Dial 连接守护进程，使用config中的rpc.token和TLS设置
+func Dial(addr string, config models.RPCConfig) (*Client, error) {
+	creds, err := dialCredentials(addr, config)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(addr, creds)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, api: pb.NewTicketGrabberClient(conn), token: config.Token}, nil
+}
+
+// ListTasks 返回守护进程中的所有任务，运行中的任务附带进度
+func (c *Client) ListTasks(ctx context.Context) ([]task.Task, error) {
+	resp, err := c.api.ListTasks(c.auth(ctx), &pb.ListTasksRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]task.Task, 0, len(resp.GetTasks()))
+	for _, t := range resp.GetTasks() {
+		tasks = append(tasks, taskFromProto(t))
+	}
+	return tasks, nil
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// auth 附加令牌
+func (c *Client) auth(ctx context.Context) context.Context {
+	if c.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+}
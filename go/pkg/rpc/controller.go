@@ -0,0 +1,405 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/rpc/pb"
+)
+
+// errWorkerLost 工作节点断开连接，任务需要重新分配
+var errWorkerLost = errors.New("工作节点断开连接")
+
+// winnerReconnectWait 获得购买权的节点断开后等待它重连上报结果的时间
+// 节点上的任务可能正在付款，不会随连接断开而取消
+const winnerReconnectWait = 30 * time.Minute
+
+// Controller 分布式模式的控制器
+// 把任务分配给连接的工作节点(每场演出同时分配给replicas个节点)，汇总节点上报的事件，
+// 购买权只授予最先发现有票的节点；节点断开时把它的任务重新分配给其他节点，
+// 但获得购买权的任务保留，等待同名节点重连后上报结果
+type Controller struct {
+	pb.UnimplementedControllerServer
+
+	events   *notify.Broadcaster
+	replicas int
+
+	mu          sync.Mutex
+	workers     map[*worker]struct{}
+	assignments map[string]*assignment
+	seq         int
+	joined      chan struct{}
+}
+
+// worker 已连接的工作节点
+type worker struct {
+	name     string
+	region   string
+	capacity int
+	running  int
+
+	send chan *pb.ControllerMessage
+	gone chan struct{}
+}
+
+// post 向节点发送消息，节点已断开时丢弃
+func (w *worker) post(msg *pb.ControllerMessage) {
+	select {
+	case w.send <- msg:
+	case <-w.gone:
+	}
+}
+
+// String 返回节点描述
+func (w *worker) String() string {
+	if w.region == "" {
+		return w.name
+	}
+	return w.name + "(" + w.region + ")"
+}
+
+// assignment 分配给某个节点的任务
+type assignment struct {
+	id     string
+	run    *run
+	worker *worker
+	lost   *time.Timer // 获得购买权的节点断开后，等待重连超时
+}
+
+// outcome 节点上任务的结束结果
+type outcome struct {
+	a   *assignment
+	err error
+}
+
+// run 一场演出在控制器上的一次运行，可能同时分配给多个节点
+type run struct {
+	concert models.Concert
+	active  map[string]*assignment
+	failed  map[*worker]bool
+	winner  string
+	results chan outcome
+}
+
+// NewController 创建控制器，replicas为每场演出同时运行的节点数
+func NewController(events *notify.Broadcaster, replicas int) *Controller {
+	return &Controller{
+		events:      events,
+		replicas:    max(replicas, 1),
+		workers:     make(map[*worker]struct{}),
+		assignments: make(map[string]*assignment),
+		joined:      make(chan struct{}),
+	}
+}
+
+// Run 把演唱会分配给工作节点运行并等待结果，可作为task.Runner使用
+// 没有可用节点时等待节点连接；ctx取消时通知节点取消任务
+func (c *Controller) Run(ctx context.Context, concert *models.Concert) error {
+	r := &run{
+		concert: *concert,
+		active:  make(map[string]*assignment),
+		failed:  make(map[*worker]bool),
+		results: make(chan outcome, 16),
+	}
+	var failures []error
+	status := progress.FromContext(ctx)
+
+	for {
+		c.mu.Lock()
+		// 已有节点获得购买权时不再分配，只等待它的结果
+		for r.winner == "" && len(r.active) < c.replicas {
+			w := c.pick(r)
+			if w == nil {
+				break
+			}
+			if err := c.assign(r, w); err != nil {
+				c.mu.Unlock()
+				return err
+			}
+		}
+		idle := len(r.active) == 0
+		var names []string
+		for _, a := range r.active {
+			names = append(names, a.worker.String())
+		}
+		joined := c.joined
+		c.mu.Unlock()
+
+		if idle {
+			log.Printf("[%s] 等待工作节点连接", concert.ID)
+			status.SetStage("等待工作节点连接", "")
+		} else {
+			sort.Strings(names)
+			status.SetStage("在工作节点上运行", strings.Join(names, ", "))
+		}
+
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.cancelAll(r, "")
+			c.mu.Unlock()
+			return ctx.Err()
+		case <-joined:
+			continue
+		case o := <-r.results:
+			c.mu.Lock()
+			delete(r.active, o.a.id)
+			winner := r.winner
+			if winner == o.a.id {
+				c.cancelAll(r, "")
+			}
+			remaining := len(r.active)
+			c.mu.Unlock()
+
+			switch {
+			case winner == o.a.id:
+				return o.err
+			case winner != "":
+				// 其他节点已在购买，这个节点的任务已结束，等待购买结果
+				c.mu.Lock()
+				r.failed[o.a.worker] = true
+				c.mu.Unlock()
+				continue
+			case errors.Is(o.err, errWorkerLost):
+				log.Printf("[%s] 工作节点 %s 断开，重新分配任务", concert.ID, o.a.worker)
+				continue
+			case o.err == nil:
+				c.mu.Lock()
+				c.cancelAll(r, "")
+				c.mu.Unlock()
+				return nil
+			}
+
+			c.mu.Lock()
+			r.failed[o.a.worker] = true
+			c.mu.Unlock()
+			failures = append(failures, fmt.Errorf("%s: %w", o.a.worker, o.err))
+			if remaining == 0 {
+				return errors.Join(failures...)
+			}
+		}
+	}
+}
+
+// pick 选择负载最低、还没有运行这场演出且没有失败过的节点，调用方持有锁
+func (c *Controller) pick(r *run) *worker {
+	busy := make(map[*worker]bool)
+	for _, a := range r.active {
+		busy[a.worker] = true
+	}
+
+	var best *worker
+	for w := range c.workers {
+		if busy[w] || r.failed[w] || (w.capacity > 0 && w.running >= w.capacity) {
+			continue
+		}
+		if best == nil || w.running < best.running {
+			best = w
+		}
+	}
+	return best
+}
+
+// assign 把任务分配给节点，调用方持有锁
+func (c *Controller) assign(r *run, w *worker) error {
+	data, err := json.Marshal(r.concert)
+	if err != nil {
+		return err
+	}
+
+	c.seq++
+	a := &assignment{id: fmt.Sprintf("assignment-%d", c.seq), run: r, worker: w}
+	c.assignments[a.id] = a
+	r.active[a.id] = a
+	w.running++
+
+	log.Printf("[%s] 分配给工作节点 %s", r.concert.ID, w)
+	go w.post(&pb.ControllerMessage{Message: &pb.ControllerMessage_Assign{
+		Assign: &pb.Assignment{Id: a.id, ConcertJson: data},
+	}})
+	return nil
+}
+
+// cancelAll 取消除keep以外正在运行的任务，调用方持有锁
+func (c *Controller) cancelAll(r *run, keep string) {
+	for id, a := range r.active {
+		if id == keep {
+			continue
+		}
+		c.release(a)
+		delete(r.active, id)
+		go a.worker.post(&pb.ControllerMessage{Message: &pb.ControllerMessage_Cancel{
+			Cancel: &pb.CancelAssignment{Id: id},
+		}})
+	}
+}
+
+// release 移除任务记录，调用方持有锁
+func (c *Controller) release(a *assignment) {
+	if a.lost != nil {
+		a.lost.Stop()
+		a.lost = nil
+	}
+	if _, ok := c.assignments[a.id]; ok {
+		delete(c.assignments, a.id)
+		a.worker.running--
+	}
+}
+
+// finish 记录节点上报的任务结果，调用方持有锁
+func (c *Controller) finish(id string, err error) {
+	a, ok := c.assignments[id]
+	if !ok {
+		return
+	}
+	c.release(a)
+	a.run.results <- outcome{a: a, err: err}
+}
+
+// orphan 获得购买权的节点断开，保留任务等待节点重连，超时后按节点丢失处理，调用方持有锁
+func (c *Controller) orphan(a *assignment) {
+	log.Printf("[%s] 获得购买权的工作节点 %s 断开，等待重连上报结果", a.run.concert.ID, a.worker)
+	var t *time.Timer
+	t = time.AfterFunc(winnerReconnectWait, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if a.lost != t {
+			return
+		}
+		a.lost = nil
+		c.finish(a.id, errWorkerLost)
+	})
+	a.lost = t
+}
+
+// adopt 把同名节点断开时保留的任务交给重连的节点，调用方持有锁
+func (c *Controller) adopt(w *worker) {
+	for _, a := range c.assignments {
+		if a.lost == nil || a.worker.name != w.name {
+			continue
+		}
+		a.lost.Stop()
+		a.lost = nil
+		a.worker = w
+		w.running++
+		log.Printf("[%s] 工作节点 %s 已重连，继续等待购买结果", a.run.concert.ID, w)
+	}
+}
+
+// Connect 工作节点连接，直到连接断开
+func (c *Controller) Connect(stream pb.Controller_ConnectServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	hello := first.GetHello()
+	if hello == nil || hello.GetName() == "" {
+		return status.Error(codes.InvalidArgument, "连接后需要先发送节点信息")
+	}
+
+	w := &worker{
+		name:     hello.GetName(),
+		region:   hello.GetRegion(),
+		capacity: int(hello.GetCapacity()),
+		send:     make(chan *pb.ControllerMessage, 16),
+		gone:     make(chan struct{}),
+	}
+	c.mu.Lock()
+	c.workers[w] = struct{}{}
+	c.adopt(w)
+	close(c.joined)
+	c.joined = make(chan struct{})
+	c.mu.Unlock()
+	log.Printf("工作节点 %s 已连接", w)
+
+	defer func() {
+		close(w.gone)
+		c.mu.Lock()
+		delete(c.workers, w)
+		for id, a := range c.assignments {
+			if a.worker != w {
+				continue
+			}
+			if a.run.winner == id {
+				c.orphan(a)
+				continue
+			}
+			c.finish(id, errWorkerLost)
+		}
+		c.mu.Unlock()
+		log.Printf("工作节点 %s 已断开", w)
+	}()
+
+	ctx := stream.Context()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-w.send:
+				if err := stream.Send(msg); err != nil {
+					log.Printf("向工作节点 %s 发送消息失败: %v", w, err)
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+
+		switch {
+		case msg.GetEvent() != nil:
+			event := eventFromProto(msg.GetEvent())
+			if event.Details == nil {
+				event.Details = make(map[string]string)
+			}
+			event.Details["worker"] = w.String()
+			c.events.Send(ctx, event)
+		case msg.GetResult() != nil:
+			result := msg.GetResult()
+			var err error
+			if !result.GetSucceeded() {
+				err = errors.New(result.GetError())
+			}
+			c.mu.Lock()
+			c.finish(result.GetAssignmentId(), err)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// ClaimPurchase 授予购买权，同一场演出只有最先申请的节点获得购买权，其他节点的任务随即取消
+func (c *Controller) ClaimPurchase(ctx context.Context, req *pb.ClaimPurchaseRequest) (*pb.ClaimPurchaseResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a, ok := c.assignments[req.GetAssignmentId()]
+	if !ok {
+		return &pb.ClaimPurchaseResponse{}, nil
+	}
+
+	r := a.run
+	if r.winner == "" {
+		r.winner = a.id
+		log.Printf("[%s] 工作节点 %s 最先发现有票，取消其他节点的任务", r.concert.ID, a.worker)
+		c.cancelAll(r, a.id)
+	}
+	return &pb.ClaimPurchaseResponse{Granted: r.winner == a.id}, nil
+}
@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/progress"
+	"tickgrabber/pkg/rpc/pb"
+	"tickgrabber/pkg/task"
+)
+
+// taskStates 任务状态与protobuf枚举的对应关系
+var taskStates = map[task.State]pb.TaskState{
+	task.StatePending:   pb.TaskState_TASK_STATE_PENDING,
+	task.StateRunning:   pb.TaskState_TASK_STATE_RUNNING,
+	task.StateSucceeded: pb.TaskState_TASK_STATE_SUCCEEDED,
+	task.StateFailed:    pb.TaskState_TASK_STATE_FAILED,
+	task.StateCanceled:  pb.TaskState_TASK_STATE_CANCELED,
+}
+
+// timestamp 零值时间转换为nil
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// timeOf nil时间戳转换为零值时间
+func timeOf(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime().Local()
+}
+
+// taskToProto 转换任务
+func taskToProto(t task.Task) *pb.Task {
+	return &pb.Task{
+		Id:         t.ID,
+		Concert:    concertToProto(t.Concert),
+		State:      taskStates[t.State],
+		Error:      t.Error,
+		CreatedAt:  timestamp(t.CreatedAt),
+		StartedAt:  timestamp(t.StartedAt),
+		FinishedAt: timestamp(t.FinishedAt),
+		Progress:   progressToProto(t.Progress),
+	}
+}
+
+// taskFromProto 转换守护进程返回的任务
+func taskFromProto(t *pb.Task) task.Task {
+	result := task.Task{
+		ID:         t.GetId(),
+		Concert:    concertFromProto(t.GetConcert()),
+		Error:      t.GetError(),
+		CreatedAt:  timeOf(t.GetCreatedAt()),
+		StartedAt:  timeOf(t.GetStartedAt()),
+		FinishedAt: timeOf(t.GetFinishedAt()),
+	}
+	for state, value := range taskStates {
+		if value == t.GetState() {
+			result.State = state
+		}
+	}
+	if p := t.GetProgress(); p != nil {
+		result.Progress = &progress.Snapshot{
+			Stage:          p.GetStage(),
+			Detail:         p.GetDetail(),
+			Polls:          int(p.GetPolls()),
+			NextPoll:       timeOf(p.GetNextPoll()),
+			QueuePosition:  int(p.GetQueuePosition()),
+			CountdownLabel: p.GetCountdownLabel(),
+			Deadline:       timeOf(p.GetDeadline()),
+			UpdatedAt:      timeOf(p.GetUpdatedAt()),
+		}
+	}
+	return result
+}
+
+// progressToProto 转换任务进度，nil时返回nil
+func progressToProto(p *progress.Snapshot) *pb.TaskProgress {
+	if p == nil {
+		return nil
+	}
+	return &pb.TaskProgress{
+		Stage:          p.Stage,
+		Detail:         p.Detail,
+		Polls:          int32(p.Polls),
+		NextPoll:       timestamp(p.NextPoll),
+		QueuePosition:  int32(p.QueuePosition),
+		CountdownLabel: p.CountdownLabel,
+		Deadline:       timestamp(p.Deadline),
+		UpdatedAt:      timestamp(p.UpdatedAt),
+	}
+}
+
+// concertToProto 转换演唱会
+func concertToProto(c models.Concert) *pb.Concert {
+	return &pb.Concert{
+		Id:             c.ID,
+		Name:           c.Name,
+		Artist:         c.Artist,
+		Venue:          c.Venue,
+		Date:           c.Date,
+		Time:           c.Time,
+		Site:           c.Site,
+		Url:            c.URL,
+		MaxPrice:       int32(c.MaxPrice),
+		PreferredSeats: c.PreferredSeats,
+		Status:         c.Status,
+		SaleOpenTime:   timestamp(c.SaleOpenTime),
+	}
+}
+
+// concertFromProto 转换客户端提交的演唱会
+func concertFromProto(c *pb.Concert) models.Concert {
+	concert := models.Concert{
+		ID:             c.GetId(),
+		Name:           c.GetName(),
+		Artist:         c.GetArtist(),
+		Venue:          c.GetVenue(),
+		Date:           c.GetDate(),
+		Time:           c.GetTime(),
+		Site:           c.GetSite(),
+		URL:            c.GetUrl(),
+		MaxPrice:       int(c.GetMaxPrice()),
+		PreferredSeats: c.GetPreferredSeats(),
+		Status:         c.GetStatus(),
+		CreatedAt:      time.Now(),
+	}
+	if c.GetSaleOpenTime() != nil {
+		concert.SaleOpenTime = c.GetSaleOpenTime().AsTime()
+	}
+	return concert
+}
+
+// eventToProto 转换通知事件
+func eventToProto(e notify.Event) *pb.Event {
+	return &pb.Event{
+		Type:     string(e.Type),
+		Severity: e.Severity.String(),
+		Title:    e.Title,
+		Message:  e.Message,
+		Concert:  e.Concert,
+		Url:      e.URL,
+		OrderId:  e.OrderID,
+		Details:  e.Details,
+		Time:     timestamp(e.Time),
+	}
+}
+
+// eventFromProto 转换工作节点上报的事件
+func eventFromProto(e *pb.Event) notify.Event {
+	event := notify.Event{
+		Type:     notify.EventType(e.GetType()),
+		Severity: notify.ParseSeverity(e.GetSeverity()),
+		Title:    e.GetTitle(),
+		Message:  e.GetMessage(),
+		Concert:  e.GetConcert(),
+		URL:      e.GetUrl(),
+		OrderID:  e.GetOrderId(),
+		Details:  e.GetDetails(),
+	}
+	if e.GetTime() != nil {
+		event.Time = e.GetTime().AsTime()
+	}
+	return event
+}
@@ -0,0 +1,196 @@
+// Package rpc 守护模式的gRPC接口，供其他程序提交抢票任务并订阅事件
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=tickgrabber/pkg/rpc --go-grpc_out=. --go-grpc_opt=module=tickgrabber/pkg/rpc tickgrabber/v1/tickgrabber.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tickgrabber/pkg/httpd"
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/rpc/pb"
+	"tickgrabber/pkg/task"
+)
+
+// Server gRPC服务实现
+type Server struct {
+	pb.UnimplementedTicketGrabberServer
+
+	tasks      *task.Manager
+	concerts   []models.Concert
+	events     *notify.Broadcaster
+	controller *Controller
+}
+
+// NewServer 创建gRPC服务
+func NewServer(tasks *task.Manager, concerts []models.Concert, events *notify.Broadcaster) *Server {
+	return &Server{
+		tasks:    tasks,
+		concerts: concerts,
+		events:   events,
+	}
+}
+
+// SetController 启用分布式模式，在同一地址上接受工作节点连接
+func (s *Server) SetController(c *Controller) {
+	s.controller = c
+}
+
+// Serve 在listen地址上启动gRPC服务，ctx取消时优雅停止
+// 没有配置令牌时只允许监听本机地址，否则任何能连上的人都可以提交和取消任务
+func Serve(ctx context.Context, config models.RPCConfig, srv *Server) error {
+	creds, err := credentials(config)
+	if err != nil {
+		return err
+	}
+	if len(creds) == 0 && !httpd.Loopback(config.Listen) {
+		return fmt.Errorf("rpc.listen %s 不是本机地址，需要配置 rpc.token 或 rpc.tokens", config.Listen)
+	}
+	var opts []grpc.ServerOption
+	if len(creds) > 0 {
+		opts = authInterceptors(creds)
+	}
+	tlsOpt, err := serverTLS(config.TLS)
+	if err != nil {
+		return err
+	}
+	if tlsOpt != nil {
+		opts = append(opts, tlsOpt)
+	}
+
+	lis, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		return err
+	}
+
+	gs := grpc.NewServer(opts...)
+	pb.RegisterTicketGrabberServer(gs, srv)
+	if srv.controller != nil {
+		pb.RegisterControllerServer(gs, srv.controller)
+	}
+
+	go func() {
+		<-ctx.Done()
+		gs.GracefulStop()
+	}()
+
+	return gs.Serve(lis)
+}
+
+// SubmitTask 提交并启动抢票任务
+func (s *Server) SubmitTask(ctx context.Context, req *pb.SubmitTaskRequest) (*pb.Task, error) {
+	var concert models.Concert
+	switch {
+	case req.GetConcert() != nil:
+		concert = concertFromProto(req.GetConcert())
+	case req.GetConcertId() != "":
+		c, ok := s.findConcert(req.GetConcertId())
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "找不到ID为 %s 的演唱会", req.GetConcertId())
+		}
+		concert = c
+	default:
+		return nil, status.Error(codes.InvalidArgument, "需要concert_id或concert")
+	}
+
+	t, err := s.tasks.Submit(concert)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return taskToProto(t), nil
+}
+
+// GetTask 查询任务
+func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	t, err := s.tasks.Get(req.GetId())
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return taskToProto(t), nil
+}
+
+// ListTasks 列出所有任务
+func (s *Server) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	resp := &pb.ListTasksResponse{}
+	for _, t := range s.tasks.List() {
+		resp.Tasks = append(resp.Tasks, taskToProto(t))
+	}
+	return resp, nil
+}
+
+// CancelTask 取消任务
+func (s *Server) CancelTask(ctx context.Context, req *pb.CancelTaskRequest) (*pb.Task, error) {
+	t, err := s.tasks.Cancel(req.GetId())
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return taskToProto(t), nil
+}
+
+// ListConcerts 列出配置中的演唱会
+func (s *Server) ListConcerts(ctx context.Context, req *pb.ListConcertsRequest) (*pb.ListConcertsResponse, error) {
+	resp := &pb.ListConcertsResponse{}
+	for _, c := range s.concerts {
+		resp.Concerts = append(resp.Concerts, concertToProto(c))
+	}
+	return resp, nil
+}
+
+// StreamEvents 推送通知事件，直到客户端断开
+func (s *Server) StreamEvents(req *pb.StreamEventsRequest, stream pb.TicketGrabber_StreamEventsServer) error {
+	events, unsubscribe := s.events.Subscribe(64)
+	defer unsubscribe()
+
+	// 只读令牌看到的事件经过脱敏，不包含账号、付款等信息
+	masked := roleFrom(stream.Context()) < RoleControl
+	types := make(map[string]bool)
+	for _, t := range req.GetTypes() {
+		types[t] = true
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if len(types) > 0 && !types[string(event.Type)] {
+				continue
+			}
+			if req.GetConcert() != "" && event.Concert != req.GetConcert() {
+				continue
+			}
+			if masked {
+				event = maskEvent(event)
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findConcert 按ID查找配置中的演唱会
+func (s *Server) findConcert(id string) (models.Concert, bool) {
+	for _, c := range s.concerts {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return models.Concert{}, false
+}
+
+// taskError 将任务错误转换为gRPC状态
+func taskError(err error) error {
+	if errors.Is(err, task.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+
+	"google.golang.org/grpc"
+	grpccreds "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"tickgrabber/pkg/httpd"
+	"tickgrabber/pkg/models"
+)
+
+// serverTLS 配置了证书时返回只接受TLS连接的服务端选项，没有配置时为nil
+func serverTLS(config models.RPCTLSConfig) (grpc.ServerOption, error) {
+	if config.CertFile == "" && config.KeyFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载rpc证书失败: %w", err)
+	}
+	return grpc.Creds(grpccreds.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})), nil
+}
+
+// dialCredentials 客户端连接addr使用的传输凭证，没有配置TLS时使用明文连接
+func dialCredentials(addr string, config models.RPCConfig) (grpc.DialOption, error) {
+	tc := config.TLS
+	caFile := tc.CAFile
+	if caFile == "" {
+		// 和守护进程共用配置文件时(如status命令)信任服务端自己的证书
+		caFile = tc.CertFile
+	}
+	if caFile == "" {
+		if config.Token != "" && !httpd.Loopback(addr) {
+			log.Printf("警告: rpc令牌将以明文发送到 %s，建议配置 rpc.tls", addr)
+		}
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取rpc CA证书失败: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s 中没有有效的PEM证书", caFile)
+	}
+	return grpc.WithTransportCredentials(grpccreds.NewTLS(&tls.Config{
+		RootCAs:    pool,
+		ServerName: tc.ServerName,
+		MinVersion: tls.VersionTLS12,
+	})), nil
+}
@@ -0,0 +1,252 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/notify"
+	"tickgrabber/pkg/rpc/pb"
+)
+
+// reconnectDelay 与控制器断开后重连的间隔
+const reconnectDelay = 5 * time.Second
+
+// WorkerRunner 在工作节点上运行控制器分配的任务
+// claim 在发现有票后向控制器申请购买权，任务的通知事件发送到events后上报给控制器
+type WorkerRunner func(ctx context.Context, concert *models.Concert, claim func() bool, events *notify.Broadcaster) error
+
+// RunWorker 作为工作节点连接控制器并运行分配的任务，直到ctx取消，rpcConfig提供令牌和TLS设置
+// 与控制器断开时取消本节点上还没有获得购买权的任务(控制器会把它们分配给其他节点)，然后重连；
+// 已获得购买权的任务可能正在占座或付款，继续运行，结果在重连后上报
+func RunWorker(ctx context.Context, config models.DistributedConfig, rpcConfig models.RPCConfig, run WorkerRunner) error {
+	if config.Controller == "" {
+		return fmt.Errorf("没有配置控制器地址")
+	}
+	creds, err := dialCredentials(config.Controller, rpcConfig)
+	if err != nil {
+		return err
+	}
+	if rpcConfig.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+rpcConfig.Token)
+	}
+
+	node := &workerNode{
+		events: notify.NewBroadcaster(),
+		tasks:  make(map[string]*workerTask),
+	}
+	for {
+		err := node.session(ctx, config, creds, run)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("与控制器 %s 的连接断开: %v，%v 后重连", config.Controller, err, reconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// workerNode 工作节点在多次连接之间保持的状态
+type workerNode struct {
+	events *notify.Broadcaster
+
+	mu      sync.Mutex
+	tasks   map[string]*workerTask
+	send    func(msg *pb.WorkerMessage) error // 当前连接，断开时为nil
+	pending []*pb.WorkerMessage               // 断开期间完成的任务结果
+}
+
+// workerTask 节点上运行的任务
+type workerTask struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	claimed bool // 已获得购买权，断开连接时不取消
+	dropped bool // 因断开连接被取消，不再申请购买权
+}
+
+// report 上报任务结果，没有连接或发送失败时保存到重连后再上报
+func (n *workerNode) report(msg *pb.WorkerMessage) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.send != nil && n.send(msg) == nil {
+		return
+	}
+	n.pending = append(n.pending, msg)
+}
+
+// attach 使用新的连接，并上报断开期间完成的任务结果
+func (n *workerNode) attach(send func(msg *pb.WorkerMessage) error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.send = send
+	pending := n.pending
+	n.pending = nil
+	for _, msg := range pending {
+		if err := send(msg); err != nil {
+			n.pending = append(n.pending, msg)
+		}
+	}
+}
+
+// detach 连接断开，取消还没有获得购买权的任务并返回它们，已获得购买权的任务继续运行
+func (n *workerNode) detach() []*workerTask {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.send = nil
+	var dropped []*workerTask
+	for id, t := range n.tasks {
+		if t.claimed {
+			log.Printf("[%s] 已获得购买权，连接断开后继续运行", id)
+			continue
+		}
+		t.dropped = true
+		t.cancel()
+		delete(n.tasks, id)
+		dropped = append(dropped, t)
+	}
+	return dropped
+}
+
+// session 一次与控制器的连接
+func (n *workerNode) session(ctx context.Context, config models.DistributedConfig, creds grpc.DialOption, run WorkerRunner) error {
+	conn, err := grpc.NewClient(config.Controller, creds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// 任务使用外层ctx，连接断开时不随连接一起取消
+	taskParent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := pb.NewControllerClient(conn)
+	stream, err := client.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// gRPC流不允许并发发送
+	var sendMu sync.Mutex
+	send := func(msg *pb.WorkerMessage) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if err := stream.Send(msg); err != nil {
+			log.Printf("向控制器发送消息失败: %v", err)
+			return err
+		}
+		return nil
+	}
+
+	if err := send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Hello{Hello: &pb.WorkerHello{
+		Name:     config.Name,
+		Region:   config.Region,
+		Capacity: int32(config.Capacity),
+	}}}); err != nil {
+		return err
+	}
+	log.Printf("已连接控制器 %s", config.Controller)
+	n.attach(send)
+
+	updates, unsubscribe := n.events.Subscribe(64)
+	defer unsubscribe()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-updates:
+				send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Event{Event: eventToProto(event)}})
+			}
+		}
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			cancel()
+			for _, t := range n.detach() {
+				<-t.done
+			}
+			return err
+		}
+
+		switch {
+		case msg.GetAssign() != nil:
+			assign := msg.GetAssign()
+			var concert models.Concert
+			if err := json.Unmarshal(assign.GetConcertJson(), &concert); err != nil {
+				send(resultMessage(assign.GetId(), fmt.Errorf("解析演唱会配置失败: %w", err)))
+				continue
+			}
+
+			id := assign.GetId()
+			taskCtx, taskCancel := context.WithCancel(taskParent)
+			t := &workerTask{cancel: taskCancel, done: make(chan struct{})}
+			n.mu.Lock()
+			n.tasks[id] = t
+			n.mu.Unlock()
+
+			claim := func() bool {
+				resp, err := client.ClaimPurchase(ctx, &pb.ClaimPurchaseRequest{AssignmentId: id})
+				if err != nil {
+					// 无法确认时不购买，避免与其他节点重复购买
+					log.Printf("[%s] 申请购买权失败: %v", concert.ID, err)
+					return false
+				}
+
+				n.mu.Lock()
+				defer n.mu.Unlock()
+				if t.dropped {
+					return false
+				}
+				t.claimed = resp.GetGranted()
+				return t.claimed
+			}
+
+			log.Printf("[%s] 收到控制器分配的任务", concert.ID)
+			go func() {
+				defer close(t.done)
+				defer taskCancel()
+
+				err := run(taskCtx, &concert, claim, n.events)
+				n.mu.Lock()
+				delete(n.tasks, id)
+				n.mu.Unlock()
+				// 被控制器取消或因断开连接取消的任务不再上报
+				if taskCtx.Err() == nil {
+					n.report(resultMessage(id, err))
+				}
+			}()
+		case msg.GetCancel() != nil:
+			n.mu.Lock()
+			if t, ok := n.tasks[msg.GetCancel().GetId()]; ok {
+				t.cancel()
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+// resultMessage 任务结果消息
+func resultMessage(id string, err error) *pb.WorkerMessage {
+	result := &pb.AssignmentResult{AssignmentId: id, Succeeded: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return &pb.WorkerMessage{Message: &pb.WorkerMessage_Result{Result: result}}
+}
@@ -0,0 +1,156 @@
+// Package saleopen 从演出页面的公告中解析开售时间(티켓오픈)
+package saleopen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// kst 票务网站公告使用韩国时间
+var kst = time.FixedZone("KST", 9*60*60)
+
+// Announcement 页面上公告的一个开售时间，Presale 表示粉丝俱乐部等先行预售
+type Announcement struct {
+	Label   string
+	Time    time.Time
+	Presale bool
+}
+
+// keywords 开售时间前常见的标题，先行预售的标题排在前面，避免 "선예매 오픈" 被当作一般开售
+var keywords = []struct {
+	word    string
+	presale bool
+}{
+	{"팬클럽 선예매", true},
+	{"팬클럽선예매", true},
+	{"선예매", true},
+	{"선오픈", true},
+	{"일반예매", false},
+	{"일반 예매", false},
+	{"티켓오픈", false},
+	{"티켓 오픈", false},
+	{"예매오픈", false},
+	{"예매 오픈", false},
+	{"오픈일시", false},
+}
+
+// lookahead 标题之后查找日期时间的字符数
+const lookahead = 48
+
+// dateTime 公告中的日期时间，如 "2026.11.20(목) 20:00"、"2026년 11월 20일 오후 8시"、"11/20 20시 30분"
+var dateTime = regexp.MustCompile(
+	`(?:(\d{4})\s*[.\-/년]\s*)?(\d{1,2})\s*[.\-/월]\s*(\d{1,2})\s*일?\.?\s*(?:\([^)]{1,4}\))?\s*` +
+		`(오전|오후|AM|PM|am|pm)?\s*(\d{1,2})\s*(?::\s*(\d{2})|시(?:\s*(\d{1,2})\s*분)?)`)
+
+// FromHTML 提取页面文字后解析开售时间
+func FromHTML(html string, now time.Time) []Announcement {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil
+	}
+	doc.Find("script, style").Remove()
+	return Parse(doc.Text(), now)
+}
+
+// Parse 在文字中查找开售标题后面的日期时间
+// 没有年份时取now之后最近的该日期；同一时间被多个标题匹配时只保留一次
+func Parse(text string, now time.Time) []Announcement {
+	text = strings.Join(strings.Fields(text), " ")
+
+	var found []Announcement
+	seen := make(map[time.Time]bool)
+	covered := make([]bool, len(text))
+	for _, kw := range keywords {
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], kw.word)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			offset = start + len(kw.word)
+			if covered[start] {
+				continue
+			}
+			for j := start; j < offset; j++ {
+				covered[j] = true
+			}
+
+			window := []rune(text[offset:])
+			if len(window) > lookahead {
+				window = window[:lookahead]
+			}
+			t, ok := parseDateTime(string(window), now)
+			if !ok || seen[t] {
+				continue
+			}
+			seen[t] = true
+			found = append(found, Announcement{Label: kw.word, Time: t, Presale: kw.presale})
+		}
+	}
+	return found
+}
+
+// Pick 选择任务使用的开售时间：有先行预售验证配置时优先先行预售，否则优先一般开售
+// 同类有多个时取now之后最早的，都已过去时返回false
+func Pick(announcements []Announcement, presale bool, now time.Time) (Announcement, bool) {
+	var best Announcement
+	ok := false
+	for _, a := range announcements {
+		if !a.Time.After(now) {
+			continue
+		}
+		better := !ok ||
+			a.Presale == presale && best.Presale != presale ||
+			a.Presale == best.Presale && a.Time.Before(best.Time)
+		if better {
+			best, ok = a, true
+		}
+	}
+	return best, ok
+}
+
+// parseDateTime 解析窗口开头附近的第一个日期时间
+func parseDateTime(s string, now time.Time) (time.Time, bool) {
+	m := dateTime.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	hour, _ := strconv.Atoi(m[5])
+	minute := 0
+	if m[6] != "" {
+		minute, _ = strconv.Atoi(m[6])
+	} else if m[7] != "" {
+		minute, _ = strconv.Atoi(m[7])
+	}
+	switch strings.ToLower(m[4]) {
+	case "오후", "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "오전", "am":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 {
+		return time.Time{}, false
+	}
+
+	local := now.In(kst)
+	year := local.Year()
+	if m[1] != "" {
+		year, _ = strconv.Atoi(m[1])
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, kst)
+	// 没有年份的日期已经过去超过一个月时视为明年
+	if m[1] == "" && t.Before(local.AddDate(0, -1, 0)) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// kst 监控时段的默认时区
+var kst = time.FixedZone("KST", 9*60*60)
+
+// daily 每天的一个时段，以当天零点起的分钟数表示，end不大于start时跨越午夜
+type daily struct {
+	start, end int
+}
+
+// Calendar 演唱会的监控时段表，由每天的固定时段和cron表达式触发的时段组成
+type Calendar struct {
+	windows  []daily
+	crons    []*cronExpr
+	duration time.Duration
+	loc      *time.Location
+}
+
+// NewCalendar 解析监控时段配置，没有配置任何时段时返回nil(始终监控)
+func NewCalendar(s *models.Schedule) (*Calendar, error) {
+	if s == nil || (len(s.Windows) == 0 && len(s.Cron) == 0) {
+		return nil, nil
+	}
+
+	c := &Calendar{loc: kst, duration: time.Duration(s.Duration) * time.Minute}
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %s: %w", s.Timezone, err)
+		}
+		c.loc = loc
+	}
+
+	for _, w := range s.Windows {
+		start, end, ok := strings.Cut(w, "-")
+		from, err1 := parseClock(strings.TrimSpace(start))
+		to, err2 := parseClock(strings.TrimSpace(end))
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("无效的监控时段 %q，格式应为 HH:MM-HH:MM", w)
+		}
+		c.windows = append(c.windows, daily{start: from, end: to})
+	}
+
+	if len(s.Cron) > 0 && c.duration <= 0 {
+		return nil, fmt.Errorf("使用cron表达式时需要配置每次监控的时长 duration")
+	}
+	for _, expr := range s.Cron {
+		cron, err := parseCron(expr)
+		if err != nil {
+			return nil, err
+		}
+		c.crons = append(c.crons, cron)
+	}
+	return c, nil
+}
+
+// Next 返回now所在或之后最早的监控时段，start不晚于now表示当前正在时段内
+// 找不到时段(如cron表达式永远不会触发)时返回零值
+func (c *Calendar) Next(now time.Time) (start, end time.Time) {
+	now = now.In(c.loc)
+	consider := func(s, e time.Time) {
+		if s.IsZero() || !e.After(now) {
+			return
+		}
+		// 已经开始的时段优先，其次是最早开始的时段；同时开始时取结束较晚的
+		if start.IsZero() || s.Before(start) && start.After(now) || !s.After(now) && e.After(end) {
+			start, end = s, e
+		}
+	}
+
+	y, m, d := now.Date()
+	for _, w := range c.windows {
+		// 前一天开始的跨午夜时段可能还没有结束
+		for offset := -1; offset <= 1; offset++ {
+			day := time.Date(y, m, d+offset, 0, 0, 0, 0, c.loc)
+			s := day.Add(time.Duration(w.start) * time.Minute)
+			e := day.Add(time.Duration(w.end) * time.Minute)
+			if w.end <= w.start {
+				e = e.AddDate(0, 0, 1)
+			}
+			consider(s, e)
+		}
+	}
+
+	for _, cron := range c.crons {
+		// 时长之内触发过的时段可能还在进行
+		if s := cron.next(now.Add(-c.duration)); !s.IsZero() && !s.After(now) {
+			consider(s, s.Add(c.duration))
+		}
+		if s := cron.next(now); !s.IsZero() {
+			consider(s, s.Add(c.duration))
+		}
+	}
+	return start, end
+}
+
+// parseClock 解析 HH:MM，返回零点起的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/clock"
+)
+
+// spinWindow 距离目标时刻小于该值时改为忙等，避免定时器唤醒误差
+const spinWindow = 20 * time.Millisecond
+
+// WaitUntil 按时钟c等待到服务器时间target，offset为服务器时间减本地时间
+// 先用定时器睡眠到目标前spinWindow，再忙等到精确时刻；模拟时钟没有唤醒误差，直接睡眠到目标时刻
+func WaitUntil(ctx context.Context, c clock.Clock, target time.Time, offset time.Duration) error {
+	local := target.Add(-offset)
+
+	if wait := clock.Until(c, local); wait > 0 {
+		log.Printf("等待开售: 服务器时间 %s (本地 %s, 剩余 %v)",
+			target.Format("15:04:05.000"), local.Format("15:04:05.000"), wait.Round(time.Millisecond))
+	}
+
+	if !clock.IsReal(c) {
+		if wait := clock.Until(c, local); wait > 0 {
+			return clock.Sleep(ctx, c, wait)
+		}
+		return ctx.Err()
+	}
+
+	if wait := clock.Until(c, local) - spinWindow; wait > 0 {
+		if err := clock.Sleep(ctx, c, wait); err != nil {
+			return err
+		}
+	}
+
+	for c.Now().Before(local) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
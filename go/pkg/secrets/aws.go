@@ -0,0 +1,225 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// EC2实例元数据和ECS任务凭证的地址
+const (
+	imdsEndpoint          = "http://169.254.169.254"
+	containerCredEndpoint = "http://169.254.170.2"
+)
+
+// AWS AWS Secrets Manager，引用为密钥名称或ARN，SecretString为JSON时用 #字段 选择
+// 凭证依次取: 环境变量 AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY、ECS任务角色、EC2实例角色(IMDSv2)，
+// 服务器上使用实例角色时不需要任何凭证文件或环境变量
+type AWS struct {
+	region   string
+	endpoint string
+	client   *http.Client
+}
+
+// awsCredentials 访问凭证
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+// NewAWS 创建Secrets Manager来源，区域为空时使用环境变量 AWS_REGION
+func NewAWS(config models.AWSSecretsConfig) *AWS {
+	region := config.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	return &AWS{region: region, endpoint: config.Endpoint, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Lookup 读取密钥的SecretString
+func (a *AWS) Lookup(ctx context.Context, ref string) (string, error) {
+	if a.region == "" {
+		return "", fmt.Errorf("没有配置 secrets.aws.region")
+	}
+	creds, err := a.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", a.region)
+	}
+	body, _ := json.Marshal(map[string]string{"SecretId": ref})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, creds, a.region, "secretsmanager", time.Now().UTC())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Secrets Manager返回 %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", err
+	}
+	if out.SecretString == "" {
+		return "", fmt.Errorf("密钥没有SecretString")
+	}
+	return out.SecretString, nil
+}
+
+// credentials 按环境变量、ECS任务角色、EC2实例角色的顺序取得凭证
+func (a *AWS) credentials(ctx context.Context) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			Token:           os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		var creds awsCredentials
+		err := a.getJSON(ctx, containerCredEndpoint+uri, nil, &creds)
+		return creds, err
+	}
+
+	// IMDSv2: 先取会话令牌，再取实例角色名和凭证
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imdsEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "300")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("没有AWS凭证(环境变量、ECS任务角色、EC2实例角色都不可用): %w", err)
+	}
+	token, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	header := map[string]string{"X-aws-ec2-metadata-token": string(token)}
+
+	const rolePath = "/latest/meta-data/iam/security-credentials/"
+	role, err := a.get(ctx, imdsEndpoint+rolePath, header)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("读取EC2实例角色失败: %w", err)
+	}
+	role = strings.TrimSpace(strings.SplitN(role, "\n", 2)[0])
+	var creds awsCredentials
+	err = a.getJSON(ctx, imdsEndpoint+rolePath+role, header, &creds)
+	return creds, err
+}
+
+// get 读取元数据地址
+func (a *AWS) get(ctx context.Context, url string, header map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s 返回 %s", url, resp.Status)
+	}
+	return string(data), nil
+}
+
+// getJSON 读取元数据地址并解析JSON
+func (a *AWS) getJSON(ctx context.Context, url string, header map[string]string, out interface{}) error {
+	data, err := a.get(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), out)
+}
+
+// signV4 按AWS Signature Version 4签名请求
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.Token != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.Token)
+	}
+
+	signed := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if creds.Token != "" {
+		signed = append(signed, "x-amz-security-token")
+	}
+	// 签名的请求头按名称排序
+	sort.Strings(signed)
+	var canonicalHeaders strings.Builder
+	for _, h := range signed {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// sha256Hex 计算SHA-256并转为十六进制
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
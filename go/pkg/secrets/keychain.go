@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Keychain 系统钥匙串，引用为 <服务>/<账号>
+// macOS使用 security 命令读取登录钥匙串，Linux使用 secret-tool(GNOME Keyring、KWallet等Secret Service)
+type Keychain struct{}
+
+// Lookup 读取钥匙串中的密码
+func (Keychain) Lookup(ctx context.Context, ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", fmt.Errorf("钥匙串引用应为 <服务>/<账号>")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux", "freebsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", fmt.Errorf("%s 上不支持读取系统钥匙串", runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	value := strings.TrimRight(string(out), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("钥匙串中没有 %s/%s", service, account)
+	}
+	return value, nil
+}
+
+// Env 环境变量，引用为变量名
+type Env struct{}
+
+// Lookup 读取环境变量
+func (Env) Lookup(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("没有环境变量 %s", ref)
+	}
+	return value, nil
+}
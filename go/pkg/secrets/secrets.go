@@ -0,0 +1,175 @@
+// Package secrets 解析配置中的密钥占位符，服务器部署时密码、令牌不必写在配置文件或环境变量中
+// 占位符是整个字符串值为 secret://<来源>/<引用> 的配置项，来源为 keychain(系统钥匙串)、vault(HashiCorp Vault)、
+// aws(AWS Secrets Manager)或 env(环境变量)；引用以 #字段 结尾时取JSON格式密钥中的该字段
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"tickgrabber/pkg/models"
+)
+
+// Scheme 密钥占位符的前缀
+const Scheme = "secret://"
+
+// Provider 密钥来源
+type Provider interface {
+	// Lookup 按引用(不含来源和#字段)读取密钥
+	Lookup(ctx context.Context, ref string) (string, error)
+}
+
+// FieldProvider 能按字段读取的来源，引用带 #字段 时使用，
+// 不必先把密钥转换成单个字符串(如Vault中只有一个字段的密钥会直接返回该字段的值)
+type FieldProvider interface {
+	LookupField(ctx context.Context, ref, field string) (string, error)
+}
+
+// Providers 按配置创建各来源，名称为占位符中的来源
+func Providers(config models.SecretsConfig) map[string]Provider {
+	return map[string]Provider{
+		"keychain": Keychain{},
+		"vault":    NewVault(config.Vault),
+		"aws":      NewAWS(config.AWS),
+		"env":      Env{},
+	}
+}
+
+// IsPlaceholder 判断s是否为密钥占位符
+func IsPlaceholder(s string) bool {
+	return strings.HasPrefix(s, Scheme)
+}
+
+// Resolve 替换JSON配置中的所有占位符，同一占位符只读取一次
+// 配置中的 secrets 部分用于创建来源，本身不能使用占位符
+func Resolve(ctx context.Context, data []byte) ([]byte, error) {
+	if !strings.Contains(string(data), Scheme) {
+		return data, nil
+	}
+	// 数字保持原样，避免大整数经float64转换后失真
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var head struct {
+		Secrets models.SecretsConfig `json:"secrets"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	r := &resolver{providers: Providers(head.Secrets), cache: make(map[string]string)}
+	resolved, err := r.walk(ctx, doc, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resolved)
+}
+
+// resolver 遍历配置替换占位符
+type resolver struct {
+	providers map[string]Provider
+	cache     map[string]string
+}
+
+// walk 递归替换v中的占位符，path用于错误信息
+func (r *resolver) walk(ctx context.Context, v interface{}, path string) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if path == "" && k == "secrets" {
+				continue
+			}
+			resolved, err := r.walk(ctx, child, join(path, k))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = resolved
+		}
+	case []interface{}:
+		for i, child := range v {
+			resolved, err := r.walk(ctx, child, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	case string:
+		if !IsPlaceholder(v) {
+			return v, nil
+		}
+		value, err := r.lookup(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return value, nil
+	}
+	return v, nil
+}
+
+// lookup 读取一个占位符
+func (r *resolver) lookup(ctx context.Context, placeholder string) (string, error) {
+	if value, ok := r.cache[placeholder]; ok {
+		return value, nil
+	}
+	source, ref, ok := strings.Cut(strings.TrimPrefix(placeholder, Scheme), "/")
+	if !ok || ref == "" {
+		return "", fmt.Errorf("密钥占位符 %q 格式应为 secret://<来源>/<引用>", placeholder)
+	}
+	p, ok := r.providers[source]
+	if !ok {
+		return "", fmt.Errorf("不支持的密钥来源 %q", source)
+	}
+	ref, field, _ := strings.Cut(ref, "#")
+
+	if fp, ok := p.(FieldProvider); ok && field != "" {
+		value, err := fp.LookupField(ctx, ref, field)
+		if err != nil {
+			return "", fmt.Errorf("读取密钥 %s/%s 失败: %w", source, ref, err)
+		}
+		r.cache[placeholder] = value
+		return value, nil
+	}
+
+	value, err := p.Lookup(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥 %s/%s 失败: %w", source, ref, err)
+	}
+	if field != "" {
+		if value, err = jsonField(value, field); err != nil {
+			return "", fmt.Errorf("密钥 %s/%s: %w", source, ref, err)
+		}
+	}
+	r.cache[placeholder] = value
+	return value, nil
+}
+
+// jsonField 取JSON对象中的字段
+func jsonField(value, field string) (string, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return "", fmt.Errorf("密钥不是JSON对象，不能取字段 %s", field)
+	}
+	v, ok := obj[field]
+	if !ok {
+		return "", fmt.Errorf("没有字段 %s", field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, _ := json.Marshal(v)
+	return string(data), nil
+}
+
+// join 拼接配置路径
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
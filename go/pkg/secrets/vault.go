@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// kubernetesTokenFile Kubernetes中服务账号令牌的默认位置
+const kubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Vault HashiCorp Vault 的KV密钥，引用为API路径(KV v2为 <挂载点>/data/<路径>，如 secret/data/tickgrabber)
+// 令牌依次取: 配置了 kubernetes_role 时用Kubernetes服务账号登录，环境变量 VAULT_TOKEN，token_file(默认 ~/.vault-token)
+type Vault struct {
+	config models.VaultConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVault 创建Vault来源，地址为空时使用环境变量 VAULT_ADDR
+func NewVault(config models.VaultConfig) *Vault {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	return &Vault{config: config, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Lookup 读取密钥，只有一个字段时返回该字段的值，否则返回JSON对象，用 #字段 选择
+func (v *Vault) Lookup(ctx context.Context, ref string) (string, error) {
+	data, err := v.read(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 1 {
+		for _, value := range data {
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+		}
+	}
+	out, err := json.Marshal(data)
+	return string(out), err
+}
+
+// LookupField 读取密钥中的字段，只有一个字段的密钥也按字段名选择
+func (v *Vault) LookupField(ctx context.Context, ref, field string) (string, error) {
+	data, err := v.read(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("没有字段 %s", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	out, err := json.Marshal(value)
+	return string(out), err
+}
+
+// read 读取密钥的所有字段
+func (v *Vault) read(ctx context.Context, ref string) (map[string]interface{}, error) {
+	if v.config.Address == "" {
+		return nil, fmt.Errorf("没有配置 secrets.vault.address")
+	}
+	token, err := v.loginToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.call(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(ref, "/"), token, nil, &resp); err != nil {
+		return nil, err
+	}
+	data := resp.Data
+	// KV v2 的密钥在 data.data 中
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	return data, nil
+}
+
+// loginToken 返回访问令牌，Kubernetes登录得到的令牌在进程内复用
+func (v *Vault) loginToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != "" {
+		return v.token, nil
+	}
+
+	if role := v.config.KubernetesRole; role != "" {
+		jwt, err := os.ReadFile(kubernetesTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("读取Kubernetes服务账号令牌失败: %w", err)
+		}
+		mount := v.config.KubernetesMount
+		if mount == "" {
+			mount = "kubernetes"
+		}
+		var resp struct {
+			Auth struct {
+				ClientToken string `json:"client_token"`
+			} `json:"auth"`
+		}
+		body := map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))}
+		if err := v.call(ctx, http.MethodPost, "/v1/auth/"+mount+"/login", "", body, &resp); err != nil {
+			return "", fmt.Errorf("Vault Kubernetes登录失败: %w", err)
+		}
+		v.token = resp.Auth.ClientToken
+		return v.token, nil
+	}
+
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	path := v.config.TokenFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, ".vault-token")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("没有Vault令牌: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// call 调用Vault API
+func (v *Vault) call(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(v.config.Address, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Vault返回 %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,43 @@
+package selectors
+
+import (
+	"github.com/PuerkitoBio/goquery"
+
+	"tickgrabber/pkg/browser"
+)
+
+// textCandidates 按文字定位时在静态页面中检查的元素
+const textCandidates = "button, a, input[type='submit'], input[type='button'], [role='button'], span, div, li"
+
+// MatchDocument 检查用HTTP获取的静态页面中是否有匹配选择器的元素，不需要浏览器
+// 按文字定位时只比较元素自身的文字，禁用的元素不算匹配
+func MatchDocument(doc *goquery.Document, selector string) bool {
+	text, fuzzy, ok := Text(selector)
+	if !ok {
+		return doc.Find(selector).Length() > 0
+	}
+
+	found := false
+	doc.Find(textCandidates).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if _, disabled := s.Attr("disabled"); disabled || s.AttrOr("aria-disabled", "") == "true" {
+			return true
+		}
+		content := s.Text()
+		if goquery.NodeName(s) == "input" {
+			content = s.AttrOr("value", "")
+		}
+		found = browser.MatchText(content, text, textMatch(fuzzy))
+		return !found
+	})
+	return found
+}
+
+// MatchDocumentChain 检查选择器链中是否有任一选择器匹配
+func MatchDocumentChain(doc *goquery.Document, chain Chain) bool {
+	for _, selector := range chain {
+		if MatchDocument(doc, selector) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package selectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Hint 选择器失效时用于重新定位元素的线索
+type Hint struct {
+	// Tags 候选元素
+	Tags string
+	// Words 按优先级排列的关键字，匹配元素文字或属性值
+	Words []string
+}
+
+// clickable 可点击的候选元素
+const clickable = "button, a, input[type='submit'], input[type='button'], [role='button'], [onclick]"
+
+// hints 支持自动修复的元素
+// 只对点击类元素修复：轮询类元素(如余票、支付结果)找不到是正常情况，不能据此判断选择器失效
+var hints = map[string]Hint{
+	LoginSubmit:     {Tags: clickable, Words: []string{"로그인", "login"}},
+	PurchaseConfirm: {Tags: clickable, Words: []string{"결제하기", "구매하기", "예매하기", "다음단계", "purchase", "buy"}},
+}
+
+// healScript 在页面中查找文字或属性包含关键字的可见元素，返回能唯一定位它的选择器
+const healScript = `(() => {
+	const words = %s;
+	const visible = e => { const r = e.getBoundingClientRect(); return r.width > 0 && r.height > 0; };
+	const norm = s => (s || "").replace(/\s+/g, "").toLowerCase();
+	const text = e => norm([e.innerText, e.value, e.title, e.getAttribute("aria-label"), e.getAttribute("alt")].join(" "));
+	const attrs = e => norm(Array.from(e.attributes).map(a => a.value).join(" "));
+	const candidates = Array.from(document.querySelectorAll(%s)).filter(visible);
+
+	let match = null;
+	for (const w of words) {
+		const k = norm(w);
+		match = candidates.find(e => text(e).includes(k)) || candidates.find(e => attrs(e).includes(k));
+		if (match) break;
+	}
+	if (!match) return "";
+
+	const unique = s => document.querySelectorAll(s).length === 1;
+	if (match.id && unique("#" + CSS.escape(match.id))) return "#" + CSS.escape(match.id);
+	const tag = match.tagName.toLowerCase();
+	for (const a of ["name", "data-action", "aria-label", "title", "value"]) {
+		const v = match.getAttribute(a);
+		if (v && unique(tag + "[" + a + "=" + JSON.stringify(v) + "]")) return tag + "[" + a + "=" + JSON.stringify(v) + "]";
+	}
+
+	const path = [];
+	for (let e = match; e && e !== document.body; e = e.parentElement) {
+		let i = 1;
+		for (let s = e.previousElementSibling; s; s = s.previousElementSibling) if (s.tagName === e.tagName) i++;
+		path.unshift(e.tagName.toLowerCase() + ":nth-of-type(" + i + ")");
+	}
+	return "body > " + path.join(" > ");
+})()`
+
+// HealScript 返回修复某个元素选择器的页面脚本，元素不支持修复时返回false
+// 脚本执行结果为新的选择器，找不到候选元素时为空字符串
+func HealScript(key string) (string, bool) {
+	hint, ok := hints[key]
+	if !ok {
+		return "", false
+	}
+	words, _ := json.Marshal(hint.Words)
+	tags, _ := json.Marshal(hint.Tags)
+	return fmt.Sprintf(healScript, words, tags), true
+}
+
+// Remember 记录修复后的选择器，之后 Get 会优先返回它
+// persist为true时同时写回站点选择器文件，放在原有备选链的最前面
+func (r *Registry) Remember(site, key, selector string, persist bool) error {
+	r.mu.Lock()
+	if r.healed[site] == nil {
+		r.healed[site] = make(map[string]string)
+	}
+	r.healed[site][key] = selector
+	r.mu.Unlock()
+
+	if !persist || r.dir == "" {
+		return nil
+	}
+
+	profile := make(Profile)
+	if data, err := os.ReadFile(r.Path(site)); err == nil {
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return fmt.Errorf("选择器文件 %s 格式错误: %w", r.Path(site), err)
+		}
+	}
+
+	chain := profile[key]
+	if len(chain) == 0 {
+		chain = r.defaultChain(site, key)
+	}
+	profile[key] = append(Chain{selector}, slices.DeleteFunc(slices.Clone(chain), func(s string) bool {
+		return s == selector
+	})...)
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Path(site)), 0755); err != nil {
+		return err
+	}
+	tmp := r.Path(site) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.Path(site))
+}
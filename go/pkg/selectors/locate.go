@@ -0,0 +1,64 @@
+package selectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"tickgrabber/pkg/browser"
+)
+
+// Locate 检查选择器在页面上是否存在，按文字定位的选择器返回找到元素的CSS选择器
+func Locate(ctx context.Context, b browser.Driver, selector string) (string, bool) {
+	if text, fuzzy, ok := Text(selector); ok {
+		found, err := b.FindByText(ctx, text, textMatch(fuzzy))
+		return found, err == nil
+	}
+	exists, err := b.ElementExists(ctx, selector)
+	return selector, err == nil && exists
+}
+
+// Click 点击元素，支持按文字定位的选择器
+func Click(ctx context.Context, b browser.Driver, selector string) (bool, error) {
+	if text, fuzzy, ok := Text(selector); ok {
+		return b.ClickByText(ctx, text, textMatch(fuzzy))
+	}
+	return b.ClickElement(ctx, selector)
+}
+
+// textMatch 返回文字匹配方式
+func textMatch(fuzzy bool) browser.TextMatch {
+	if fuzzy {
+		return browser.MatchFuzzy
+	}
+	return browser.MatchExact
+}
+
+// IDOrName 把登录字段中只写了id或name的值(如 userId、login:id)转换为按id或name匹配的CSS选择器，
+// 含空白的值不是单个标识符，返回空字符串
+func IDOrName(name string) string {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return ""
+	}
+	value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+	return fmt.Sprintf(`#%s, [name="%s"]`, escapeIdent(name), value)
+}
+
+// escapeIdent 按CSS.escape的规则转义标识符
+func escapeIdent(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9' && (i == 0 || i == 1 && s[0] == '-'):
+			// 标识符不能以数字或"-数字"开头，用十六进制转义
+			fmt.Fprintf(&b, `\%x `, r)
+		case r == '-' || r == '_' || r >= 0x80 || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
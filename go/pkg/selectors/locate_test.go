@@ -0,0 +1,20 @@
+package selectors
+
+import "testing"
+
+func TestIDOrName(t *testing.T) {
+	for name, want := range map[string]string{
+		"userId":   `#userId, [name="userId"]`,
+		"login:id": `#login\:id, [name="login:id"]`,
+		"user.pw":  `#user\.pw, [name="user.pw"]`,
+		"1st":      `#\31 st, [name="1st"]`,
+		`a"b`:      `#a\"b, [name="a\"b"]`,
+		"아이디":      `#아이디, [name="아이디"]`,
+		"a b":      "",
+		"":         "",
+	} {
+		if got := IDOrName(name); got != want {
+			t.Errorf("IDOrName(%q) = %s，期望 %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Package selectors 按站点管理页面元素的CSS选择器
+//
+// 每个元素对应一条有序的备选链，依次尝试直到有选择器匹配，网站改版时只需修改选择器文件。
+// 选择器文件为 <目录>/<站点>.json，内容如:
+//
+//	{"purchase.confirm": [".btn-purchase", "[data-action='purchase']"]}
+//
+// 没有稳定class或id的按钮可以按文字定位: "text=예매하기" 完全匹配，"text~=좌석선택" 模糊匹配。
+// 文件中没有的键使用内置默认值，文件修改后自动重新加载
+package selectors
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 抢票流程使用的元素
+const (
+	LoginUsername   = "login.username"
+	LoginPassword   = "login.password"
+	LoginSubmit     = "login.submit"
+	TicketAvailable = "ticket.available"
+	SeatPreferred   = "seat.preferred"
+	SeatAvailable   = "seat.available"
+	SeatSelected    = "seat.selected"
+	PurchaseConfirm = "purchase.confirm"
+	PaymentSuccess  = "payment.success"
+
+	PresaleGate       = "presale.gate"
+	PresaleCode       = "presale.code"
+	PresaleMembership = "presale.membership"
+	PresaleSubmit     = "presale.submit"
+	PresaleError      = "presale.error"
+
+	RoundDate  = "round.date"
+	RoundTime  = "round.time"
+	RoundLabel = "round.label"
+
+	DeliverySection       = "delivery.section"
+	DeliveryMethod        = "delivery.method"
+	DeliverySavedAddress  = "delivery.saved_address"
+	DeliveryRecipient     = "delivery.recipient"
+	DeliveryPhone         = "delivery.phone"
+	DeliveryPostcode      = "delivery.postcode"
+	DeliveryAddress       = "delivery.address"
+	DeliveryAddressDetail = "delivery.address_detail"
+
+	CookieBanner = "warmup.cookie_banner"
+	SaleAppOnly  = "sale.app_only"
+)
+
+// Chain 有序的备选选择器
+type Chain []string
+
+// 按文字定位的选择器前缀
+const (
+	textExact = "text="
+	textFuzzy = "text~="
+)
+
+// Text 解析按文字定位的选择器，返回关键字和是否模糊匹配，普通CSS选择器返回ok为false
+func Text(selector string) (text string, fuzzy bool, ok bool) {
+	if rest, found := strings.CutPrefix(selector, textFuzzy); found {
+		return rest, true, true
+	}
+	if rest, found := strings.CutPrefix(selector, textExact); found {
+		return rest, false, true
+	}
+	return "", false, false
+}
+
+// Profile 站点的选择器配置，键为元素名
+type Profile map[string]Chain
+
+// defaults 所有站点共用的默认选择器
+// seat.preferred 中的 %s 会被替换为偏好的座位等级
+var defaults = Profile{
+	LoginSubmit:     {"input[type='submit']", "button[type='submit']"},
+	TicketAvailable: {".ticket-available", ".btn-buy", "[data-status='available']", ".seat-available"},
+	SeatPreferred:   {"[data-seat-type='%s']"},
+	SeatAvailable:   {".seat-available"},
+	SeatSelected:    {".seat-selected", ".seat.selected", "[data-seat][aria-selected='true']"},
+	PurchaseConfirm: {".btn-purchase", ".btn-buy", "[data-action='purchase']"},
+	PaymentSuccess:  {".payment-success"},
+
+	// presale.membership 中的 %s 会被替换为会员类型
+	PresaleGate:       {"[data-presale-gate]", ".presale-auth", "#fanclubAuth"},
+	PresaleCode:       {"input[name='presaleCode']", "input[name='authCode']", ".presale-auth input[type='text']"},
+	PresaleMembership: {"[data-membership='%s']", "text~=%s"},
+	PresaleSubmit:     {".presale-auth button[type='submit']", "text=인증하기", "text=확인"},
+	PresaleError:      {".presale-error", ".presale-auth .error"},
+
+	// round.* 中的 %s 会被替换为场次的日期、时间或文字
+	RoundDate:  {"[data-play-date='%s']", "[data-date='%s']", "text~=%s"},
+	RoundTime:  {"[data-play-time='%s']", "[data-time='%s']", "text~=%s"},
+	RoundLabel: {"text~=%s"},
+
+	// delivery.method 中的 %s 会被替换为领取方式在页面上的文字
+	DeliverySection:       {"[data-delivery-section]", "#deliveryMethod", ".delivery-method"},
+	DeliveryMethod:        {"[data-delivery='%s']", "text~=%s"},
+	DeliverySavedAddress:  {"[data-address='default']", "text~=기본 배송지", "text~=최근 배송지"},
+	DeliveryRecipient:     {"input[name='recipient']", "input[name='rcvName']"},
+	DeliveryPhone:         {"input[name='phone']", "input[name='rcvPhone']"},
+	DeliveryPostcode:      {"input[name='postcode']", "input[name='zipCode']"},
+	DeliveryAddress:       {"input[name='address']", "input[name='addr1']"},
+	DeliveryAddressDetail: {"input[name='addressDetail']", "input[name='addr2']"},
+
+	SaleAppOnly:  {"[data-sale-channel='app']"},
+	CookieBanner: {"#onetrust-accept-btn-handler", "[data-cookie-accept]", ".cookie-banner button", "text=쿠키 허용", "text=모두 허용"},
+}
+
+// siteDefaults 各站点不同的默认选择器
+var siteDefaults = map[string]Profile{
+	"interpark": {LoginUsername: {"username"}, LoginPassword: {"password"}},
+	"yes24":     {LoginUsername: {"userId"}, LoginPassword: {"userPw"}},
+	// 멜론티켓 部分演出只在APP中开放预约，页面上只有APP下载提示而没有预约按钮
+	"melon": {
+		LoginUsername: {"id"},
+		LoginPassword: {"pw"},
+		SaleAppOnly:   {"[data-sale-channel='app']", ".box_app_only", ".app_only", "text~=앱 전용 예매", "text~=앱에서만 예매"},
+	},
+	// NOL 티켓 使用NOL账号登录，页面结构与旧版Interpark不同
+	"interpark_nol": {
+		LoginUsername:   {"input[name='email']", "input[type='email']", "input[name='id']"},
+		LoginPassword:   {"input[type='password']"},
+		LoginSubmit:     {"button[type='submit']", "text=로그인"},
+		TicketAvailable: {"[data-status='available']", "text=예매하기"},
+		SeatPreferred:   {"[data-seat-type='%s']", "text~=%s"},
+		PurchaseConfirm: {"text~=결제하기", "text=예매하기"},
+		PaymentSuccess:  {"text~=예매가 완료", "text~=예매 완료"},
+	},
+	// 쿠팡플레이 使用쿠팡账号登录，预约页面的按钮大多没有稳定的class，按文字定位
+	"coupangplay": {
+		LoginUsername:   {"#login-email-input"},
+		LoginPassword:   {"#login-password-input"},
+		LoginSubmit:     {".login__button", "button[type='submit']", "text=로그인"},
+		TicketAvailable: {"[data-status='available']", "text=예매하기"},
+		SeatPreferred:   {"[data-seat-type='%s']", "text~=%s"},
+		SeatAvailable:   {"[data-seat-status='available']", ".seat-available"},
+		PurchaseConfirm: {"text~=결제하기", "text=예매하기"},
+		PaymentSuccess:  {"text~=예매가 완료", "text~=결제가 완료"},
+	},
+}
+
+// reloadInterval 检查选择器文件是否修改的最短间隔
+const reloadInterval = 2 * time.Second
+
+// siteFile 已加载的站点选择器文件
+type siteFile struct {
+	profile Profile
+	modTime time.Time
+	checked time.Time
+}
+
+// Registry 选择器注册表
+type Registry struct {
+	dir string
+
+	mu     sync.Mutex
+	sites  map[string]*siteFile
+	healed map[string]map[string]string
+}
+
+// NewRegistry 创建注册表，dir为空时只使用内置默认值
+func NewRegistry(dir string) *Registry {
+	return &Registry{
+		dir:    dir,
+		sites:  make(map[string]*siteFile),
+		healed: make(map[string]map[string]string),
+	}
+}
+
+// Get 返回站点某个元素的选择器链，站点文件优先，其次是站点默认值和通用默认值
+// 本次运行中自动修复得到的选择器排在最前面
+func (r *Registry) Get(site, key string) Chain {
+	chain := r.load(site)[key]
+	if len(chain) == 0 {
+		chain = r.defaultChain(site, key)
+	}
+
+	r.mu.Lock()
+	healed, ok := r.healed[site][key]
+	r.mu.Unlock()
+	if ok && !slices.Contains(chain, healed) {
+		chain = append(Chain{healed}, chain...)
+	}
+	return chain
+}
+
+// defaultChain 返回内置的选择器链
+func (r *Registry) defaultChain(site, key string) Chain {
+	if chain := siteDefaults[site][key]; len(chain) > 0 {
+		return chain
+	}
+	return defaults[key]
+}
+
+// Format 返回替换了%s占位符的选择器链
+// 只替换%s，没有占位符的选择器原样保留，其中的%等字符不会被当作格式化指令
+func (r *Registry) Format(site, key string, arg string) Chain {
+	chain := r.Get(site, key)
+	formatted := make(Chain, len(chain))
+	for i, selector := range chain {
+		formatted[i] = strings.ReplaceAll(selector, "%s", arg)
+	}
+	return formatted
+}
+
+// Keys 返回站点所有已知的元素名
+func (r *Registry) Keys(site string) []string {
+	seen := make(map[string]bool)
+	for _, p := range []Profile{defaults, siteDefaults[site], r.load(site)} {
+		for key := range p {
+			seen[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Path 返回站点选择器文件路径
+func (r *Registry) Path(site string) string {
+	return filepath.Join(r.dir, site+".json")
+}
+
+// load 返回站点文件中的选择器，文件修改后重新加载，解析失败时保留上一次的内容
+func (r *Registry) load(site string) Profile {
+	if r.dir == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f := r.sites[site]
+	if f == nil {
+		f = &siteFile{}
+		r.sites[site] = f
+	}
+	if time.Since(f.checked) < reloadInterval {
+		return f.profile
+	}
+	f.checked = time.Now()
+
+	info, err := os.Stat(r.Path(site))
+	if err != nil {
+		f.profile, f.modTime = nil, time.Time{}
+		return nil
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return f.profile
+	}
+
+	data, err := os.ReadFile(r.Path(site))
+	if err != nil {
+		return f.profile
+	}
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		log.Printf("选择器文件 %s 格式错误: %v", r.Path(site), err)
+		return f.profile
+	}
+
+	if !f.modTime.IsZero() {
+		log.Printf("已重新加载选择器文件: %s", r.Path(site))
+	}
+	f.profile = profile
+	f.modTime = info.ModTime()
+	return profile
+}
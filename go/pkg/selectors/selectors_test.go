@@ -0,0 +1,29 @@
+package selectors
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	dir := t.TempDir()
+	profile := `{
+		"seat.preferred": [".seat-vip", "[data-seat-type='%s']"],
+		"round.label": ["[style*='width:50%']", "text~=%s"]
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "test.json"), []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry(dir)
+
+	for key, want := range map[string]Chain{
+		SeatPreferred: {".seat-vip", "[data-seat-type='VIP']"},
+		RoundLabel:    {"[style*='width:50%']", "text~=VIP"},
+	} {
+		if got := r.Format("test", key, "VIP"); !slices.Equal(got, want) {
+			t.Errorf("Format(%s) = %q，期望 %q", key, got, want)
+		}
+	}
+}
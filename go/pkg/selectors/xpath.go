@@ -0,0 +1,41 @@
+package selectors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XPath 把按文字定位的选择器转换为XPath，普通CSS选择器原样返回
+// 用于不经过页面脚本、直接交给CDP查询的场景(如快速购买流程)；只归一化空白，
+// 模糊匹配不像 browser.MatchText 那样忽略标点和大小写
+func XPath(selector string) string {
+	text, fuzzy, ok := Text(selector)
+	if !ok {
+		return selector
+	}
+
+	literal := xpathLiteral(strings.Join(strings.Fields(text), " "))
+	match := fmt.Sprintf("normalize-space(.)=%s", literal)
+	value := fmt.Sprintf("@value=%s", literal)
+	if fuzzy {
+		match = fmt.Sprintf("contains(normalize-space(.), %s)", literal)
+		value = fmt.Sprintf("contains(@value, %s)", literal)
+	}
+	// 取文字匹配的最内层元素，按钮形式的input比较value
+	return fmt.Sprintf("//*[%s][not(.//*[%s])] | //input[%s]", match, match, value)
+}
+
+// xpathLiteral 把字符串转换为XPath字符串字面量，同时含有单双引号时用concat拼接
+func xpathLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	for i, p := range parts {
+		parts[i] = "'" + p + "'"
+	}
+	return "concat(" + strings.Join(parts, `, "'", `) + ")"
+}
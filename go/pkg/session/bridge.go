@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tickgrabber/pkg/api"
+	"tickgrabber/pkg/browser"
+)
+
+// Bridge 浏览器与API客户端之间的会话桥接
+type Bridge struct {
+	browser  browser.Driver
+	client   *api.Client
+	interval time.Duration
+}
+
+// NewBridge 创建会话桥接
+func NewBridge(browser browser.Driver, client *api.Client, interval time.Duration) *Bridge {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &Bridge{
+		browser:  browser,
+		client:   client,
+		interval: interval,
+	}
+}
+
+// Sync 将浏览器当前的Cookie同步到API客户端
+func (b *Bridge) Sync(ctx context.Context) error {
+	cookies, err := b.browser.Cookies(browser.WithPriority(ctx, browser.PriorityBackground))
+	if err != nil {
+		return err
+	}
+
+	b.client.ImportCookies(cookies)
+	log.Printf("已同步 %d 个Cookie到API客户端", len(cookies))
+	return nil
+}
+
+// Start 立即同步一次，然后在后台定期刷新，直到ctx取消
+func (b *Bridge) Start(ctx context.Context) error {
+	if err := b.Sync(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := b.Sync(ctx); err != nil {
+					log.Printf("同步Cookie失败: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatJSON     = "json"
+	FormatNetscape = "netscape"
+)
+
+// httpOnlyPrefix Netscape格式中HttpOnly Cookie的域名前缀(curl、浏览器扩展的写法)
+const httpOnlyPrefix = "#HttpOnly_"
+
+// jsonCookie 浏览器扩展(Cookie-Editor、EditThisCookie等)导出的Cookie
+// expirationDate 为Unix秒(可带小数)，session 为true或没有过期时间的是会话Cookie
+type jsonCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Secure         bool    `json:"secure"`
+	HTTPOnly       bool    `json:"httpOnly"`
+	HostOnly       bool    `json:"hostOnly,omitempty"`
+	Session        bool    `json:"session,omitempty"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+}
+
+// ParseCookies 解析Cookie文件，自动识别JSON数组和Netscape cookies.txt格式
+func ParseCookies(data []byte) ([]*http.Cookie, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSON(trimmed)
+	}
+	return parseNetscape(bytes.NewReader(data))
+}
+
+// parseJSON 解析浏览器扩展导出的JSON
+func parseJSON(data []byte) ([]*http.Cookie, error) {
+	var raw []jsonCookie
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析JSON Cookie失败: %w", err)
+	}
+
+	cookies := make([]*http.Cookie, 0, len(raw))
+	for _, c := range raw {
+		if c.Name == "" {
+			continue
+		}
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		// hostOnly的Cookie只对该主机有效，域名不带前导点
+		if !c.HostOnly && c.Domain != "" && !strings.HasPrefix(c.Domain, ".") {
+			cookie.Domain = "." + c.Domain
+		}
+		if !c.Session && c.ExpirationDate > 0 {
+			sec, frac := math.Modf(c.ExpirationDate)
+			cookie.Expires = time.Unix(int64(sec), int64(frac*1e9))
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// parseNetscape 解析Netscape cookies.txt，每行7列，以制表符分隔:
+// 域名、是否包含子域、路径、是否secure、过期时间(Unix秒，0为会话Cookie)、名称、值
+func parseNetscape(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("第 %d 行格式错误: 需要7列，实际 %d 列", lineNo, len(fields))
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(cookie.Domain, ".") {
+			cookie.Domain = "." + cookie.Domain
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("没有找到Cookie")
+	}
+	return cookies, nil
+}
+
+// WriteCookies 按format(json或netscape)写出Cookie
+func WriteCookies(w io.Writer, cookies []*http.Cookie, format string) error {
+	switch format {
+	case FormatJSON, "":
+		return writeJSON(w, cookies)
+	case FormatNetscape:
+		return writeNetscape(w, cookies)
+	default:
+		return fmt.Errorf("不支持的Cookie格式: %s", format)
+	}
+}
+
+// writeJSON 按浏览器扩展的JSON格式写出，可以再导入浏览器
+func writeJSON(w io.Writer, cookies []*http.Cookie) error {
+	raw := make([]jsonCookie, 0, len(cookies))
+	for _, c := range cookies {
+		jc := jsonCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+			HostOnly: !strings.HasPrefix(c.Domain, "."),
+			Session:  c.Expires.IsZero(),
+		}
+		if !c.Expires.IsZero() {
+			jc.ExpirationDate = float64(c.Expires.Unix())
+		}
+		raw = append(raw, jc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(raw)
+}
+
+// writeNetscape 按Netscape cookies.txt格式写出
+func writeNetscape(w io.Writer, cookies []*http.Cookie) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("# Netscape HTTP Cookie File\n")
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, netscapeBool(strings.HasPrefix(c.Domain, ".")), path,
+			netscapeBool(c.Secure), expires, c.Name, c.Value)
+	}
+	return bw.Flush()
+}
+
+// netscapeBool Netscape格式的布尔值
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,225 @@
+package shared
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dialTimeout 连接Redis的超时时间
+const dialTimeout = 5 * time.Second
+
+// Redis 基于Redis的共享状态，只使用GET/SET/DEL/EVAL命令，实现了RESP协议中需要的部分
+// 连接出错时断开，下次调用时重新连接
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+var _ Store = (*Redis)(nil)
+
+// NewRedis 创建Redis存储，所有键加上prefix前缀，首次使用时才连接
+func NewRedis(addr, password string, db int, prefix string) *Redis {
+	return &Redis{addr: addr, password: password, db: db, prefix: prefix}
+}
+
+// Get 读取键
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis GET返回了意外的类型 %T", reply)
+	}
+	return value, true, nil
+}
+
+// Set 写入键
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", r.prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// SetNX 仅在键不存在时写入
+func (r *Redis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	args := []string{"SET", r.prefix + key, string(value), "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	reply, err := r.do(ctx, args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// Delete 删除键
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	_, err := r.do(ctx, "DEL", r.prefix+key)
+	return err
+}
+
+// Eval 执行Lua脚本，keys会加上前缀，用于需要原子执行的多步操作
+func (r *Redis) Eval(ctx context.Context, script string, keys []string, args ...string) (interface{}, error) {
+	cmd := []string{"EVAL", script, strconv.Itoa(len(keys))}
+	for _, key := range keys {
+		cmd = append(cmd, r.prefix+key)
+	}
+	return r.do(ctx, append(cmd, args...)...)
+}
+
+// Close 关闭连接
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	return err
+}
+
+// do 发送命令并读取回复，命令串行执行
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		if err := r.connect(ctx); err != nil {
+			return nil, fmt.Errorf("连接Redis失败: %w", err)
+		}
+	}
+
+	reply, err := r.roundTrip(ctx, args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// 网络错误后连接状态未知，丢弃连接
+		r.conn.Close()
+		r.conn = nil
+	}
+	return reply, err
+}
+
+// connect 建立连接并完成认证和选库，调用方持有锁
+func (r *Redis) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	r.r = bufio.NewReader(conn)
+
+	var setup [][]string
+	if r.password != "" {
+		setup = append(setup, []string{"AUTH", r.password})
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := r.roundTrip(ctx, args); err != nil {
+			conn.Close()
+			r.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// roundTrip 写入一条命令并读取回复，调用方持有锁
+func (r *Redis) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		r.conn.SetDeadline(deadline)
+	} else {
+		r.conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+
+	cmd := make([]byte, 0, 64)
+	cmd = append(cmd, '*')
+	cmd = strconv.AppendInt(cmd, int64(len(args)), 10)
+	cmd = append(cmd, '\r', '\n')
+	for _, arg := range args {
+		cmd = append(cmd, '$')
+		cmd = strconv.AppendInt(cmd, int64(len(arg)), 10)
+		cmd = append(cmd, '\r', '\n')
+		cmd = append(cmd, arg...)
+		cmd = append(cmd, '\r', '\n')
+	}
+	if _, err := r.conn.Write(cmd); err != nil {
+		return nil, err
+	}
+	return readReply(r.r)
+}
+
+// redisError Redis返回的错误回复，不影响连接
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply 读取一条RESP回复：字符串、整数、批量字符串(不存在时为nil)或数组
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis回复格式错误: %q", line)
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis回复格式错误: %q", line)
+	}
+}
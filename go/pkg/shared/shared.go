@@ -0,0 +1,32 @@
+// Package shared 多个实例之间共享的状态(登录会话、元数据缓存、购买标记)
+// 同一账号在多台机器或多个代理上运行时，用它协调各实例，避免重复购买同一场演出
+package shared
+
+import (
+	"context"
+	"time"
+
+	"tickgrabber/pkg/models"
+)
+
+// Store 共享状态存储，值为任意字节，ttl为0时不过期
+type Store interface {
+	// Get 读取键，不存在时返回false
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set 写入键
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX 仅在键不存在时写入，写入成功返回true，用于实例之间的互斥
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Delete 删除键
+	Delete(ctx context.Context, key string) error
+	// Close 关闭连接
+	Close() error
+}
+
+// Open 按配置打开共享状态存储，没有配置时返回nil(不共享)
+func Open(config models.SharedStateConfig) Store {
+	if config.Redis == "" {
+		return nil
+	}
+	return NewRedis(config.Redis, config.Password, config.DB, config.Prefix)
+}
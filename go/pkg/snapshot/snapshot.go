@@ -0,0 +1,194 @@
+// Package snapshot 在本地提供保存的演出页面，供开售前的演练使用
+//
+// 支持两种快照：
+//   - 浏览器 "另存为网页" 保存的HTML(渲染后的DOM)，同目录下的资源文件一并提供，页面中的脚本被移除，
+//     避免脚本重新渲染页面或向真实网站发送请求
+//   - 开发者工具导出的HAR，按请求路径回放记录的响应，页面和脚本中真实网站的地址被替换为本地地址
+package snapshot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Server 提供快照的本地服务器
+type Server struct {
+	// URL 快照中演出页面的本地地址
+	URL string
+
+	server *httptest.Server
+}
+
+// Open 按扩展名读取HTML或HAR快照并启动本地服务器
+func Open(path string) (*Server, error) {
+	if strings.EqualFold(filepath.Ext(path), ".har") {
+		return openHAR(path)
+	}
+	return openHTML(path)
+}
+
+// Close 关闭本地服务器
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// openHTML 提供HTML快照及其所在目录中的资源文件
+func openHTML(path string) (*Server, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("解析快照失败: %w", err)
+	}
+	doc.Find("script").Remove()
+	page, err := doc.Html()
+	if err != nil {
+		return nil, err
+	}
+
+	name := "/" + filepath.Base(path)
+	files := http.FileServer(http.Dir(filepath.Dir(path)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != name {
+			files.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	return &Server{URL: server.URL + (&url.URL{Path: name}).EscapedPath(), server: server}, nil
+}
+
+// har HAR文件中用到的部分
+type har struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method string `json:"method"`
+				URL    string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// response 回放的响应
+type response struct {
+	status   int
+	mimeType string
+	body     []byte
+}
+
+// openHAR 按方法和路径回放HAR中记录的响应，第一个HTML响应作为演出页面
+func openHAR(path string) (*Server, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var archive har
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("解析HAR失败: %w", err)
+	}
+
+	responses := make(map[string]response)
+	origins := make(map[string]bool)
+	entry := ""
+	for _, e := range archive.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		origins[u.Scheme+"://"+u.Host] = true
+
+		content := e.Response.Content
+		body := []byte(content.Text)
+		if content.Encoding == "base64" {
+			if body, err = base64.StdEncoding.DecodeString(content.Text); err != nil {
+				continue
+			}
+		}
+		resp := response{status: e.Response.Status, mimeType: content.MimeType, body: body}
+		// 同时按不含查询参数的路径记录，查询参数常含时间戳，回放时可能不同
+		for _, key := range []string{e.Request.Method + " " + u.RequestURI(), e.Request.Method + " " + u.Path} {
+			if _, ok := responses[key]; !ok {
+				responses[key] = resp
+			}
+		}
+		if entry == "" && e.Request.Method == http.MethodGet && strings.HasPrefix(content.MimeType, "text/html") && e.Response.Status == http.StatusOK {
+			entry = u.RequestURI()
+		}
+	}
+	if entry == "" {
+		return nil, fmt.Errorf("HAR中没有HTML页面")
+	}
+
+	var (
+		once     sync.Once
+		replacer *strings.Replacer
+	)
+	server := httptest.NewUnstartedServer(nil)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			resp, ok = responses[r.Method+" "+r.URL.Path]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		body := resp.body
+		if isText(resp.mimeType) {
+			once.Do(func() { replacer = originReplacer(origins, "http://"+r.Host) })
+			body = []byte(replacer.Replace(string(body)))
+		}
+		if resp.mimeType != "" {
+			w.Header().Set("Content-Type", resp.mimeType)
+		}
+		if resp.status > 0 {
+			w.WriteHeader(resp.status)
+		}
+		w.Write(body)
+	})
+	server.Start()
+	return &Server{URL: server.URL + entry, server: server}, nil
+}
+
+// originReplacer 把真实网站的地址(含协议相对地址)替换为本地地址
+func originReplacer(origins map[string]bool, local string) *strings.Replacer {
+	var pairs []string
+	for origin := range origins {
+		host := strings.SplitN(origin, "://", 2)[1]
+		pairs = append(pairs, origin, local, "//"+host, "//"+strings.TrimPrefix(local, "http://"))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// isText 是否为需要替换地址的文本响应
+func isText(mimeType string) bool {
+	for _, prefix := range []string{"text/", "application/javascript", "application/json", "application/x-javascript"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package task
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Pool 浏览器资源池，限制同时运行的浏览器数量(每个浏览器带有自己的代理和验证码识别)
+// 资源不足时按优先级分配：高优先级任务(如即将开售的演出)抢占正在运行的低优先级任务(如捡漏监控)，
+// 同优先级的任务轮流运行，每个任务连续占用不超过slice；已发现有票正在购买的任务不会被抢占
+type Pool struct {
+	slots int
+	slice time.Duration
+
+	mu      sync.Mutex
+	holders map[*Lease]struct{}
+	waiters []*waiter
+	seq     int
+	timer   *time.Timer
+}
+
+// waiter 等待资源的任务
+type waiter struct {
+	ctx      context.Context
+	id       string
+	priority int
+	seq      int
+	ready    chan *Lease
+}
+
+// Lease 任务占用的一份资源，被抢占时Context取消
+type Lease struct {
+	ID       string
+	Priority int
+
+	pool      *Pool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	acquired  time.Time
+	held      bool
+	preempted bool
+	released  bool
+}
+
+// NewPool 创建资源池，slots为0时不限制数量，slice为0时同优先级不轮换
+func NewPool(slots int, slice time.Duration) *Pool {
+	return &Pool{
+		slots:   slots,
+		slice:   slice,
+		holders: make(map[*Lease]struct{}),
+	}
+}
+
+// Acquire 等待并占用一份资源，返回的Lease在任务结束后必须Release
+// 池为nil或不限制数量时立即返回
+func (p *Pool) Acquire(ctx context.Context, id string, priority int) (*Lease, error) {
+	if p == nil || p.slots <= 0 {
+		leaseCtx, cancel := context.WithCancel(ctx)
+		return &Lease{ID: id, Priority: priority, ctx: leaseCtx, cancel: cancel}, nil
+	}
+
+	p.mu.Lock()
+	p.seq++
+	w := &waiter{ctx: ctx, id: id, priority: priority, seq: p.seq, ready: make(chan *Lease, 1)}
+	p.waiters = append(p.waiters, w)
+	p.dispatch()
+	p.mu.Unlock()
+
+	select {
+	case lease := <-w.ready:
+		return lease, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.removeWaiter(w)
+		p.mu.Unlock()
+		// 取消与分配同时发生时归还已分配的资源
+		select {
+		case lease := <-w.ready:
+			lease.Release()
+		default:
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch 把空闲资源分配给等待中的任务，资源不足时抢占，调用方持有锁
+func (p *Pool) dispatch() {
+	sort.SliceStable(p.waiters, func(i, j int) bool {
+		if p.waiters[i].priority != p.waiters[j].priority {
+			return p.waiters[i].priority > p.waiters[j].priority
+		}
+		return p.waiters[i].seq < p.waiters[j].seq
+	})
+
+	for len(p.waiters) > 0 && len(p.holders) < p.slots {
+		w := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		p.grant(w)
+	}
+
+	// 已被抢占、正在退出的任务释放的资源留给排在最前面的等待者
+	pending := 0
+	for lease := range p.holders {
+		if lease.preempted {
+			pending++
+		}
+	}
+	for i := pending; i < len(p.waiters); i++ {
+		w := p.waiters[i]
+		victim := p.victim()
+		if victim == nil {
+			return
+		}
+		switch {
+		case victim.Priority < w.priority:
+		case victim.Priority == w.priority && p.slice > 0:
+			if remaining := p.slice - time.Since(victim.acquired); remaining > 0 {
+				p.schedule(remaining)
+				return
+			}
+		default:
+			return
+		}
+		victim.preempted = true
+		victim.cancel()
+	}
+}
+
+// grant 为等待者创建Lease
+func (p *Pool) grant(w *waiter) {
+	leaseCtx, cancel := context.WithCancel(w.ctx)
+	lease := &Lease{
+		ID:       w.id,
+		Priority: w.priority,
+		pool:     p,
+		ctx:      leaseCtx,
+		cancel:   cancel,
+		acquired: time.Now(),
+	}
+	p.holders[lease] = struct{}{}
+	w.ready <- lease
+}
+
+// victim 返回最适合被抢占的任务：优先级最低、占用时间最长，且没有在购买中
+func (p *Pool) victim() *Lease {
+	var victim *Lease
+	for lease := range p.holders {
+		if lease.held || lease.preempted {
+			continue
+		}
+		if victim == nil || lease.Priority < victim.Priority ||
+			(lease.Priority == victim.Priority && lease.acquired.Before(victim.acquired)) {
+			victim = lease
+		}
+	}
+	return victim
+}
+
+// schedule 在同优先级任务的时间片用完时重新分配
+func (p *Pool) schedule(after time.Duration) {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(after, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.dispatch()
+	})
+}
+
+// Position 返回任务在等待队列中的位置(从1开始)，不在等待时返回0
+func (p *Pool) Position(id string) int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, w := range p.waiters {
+		if w.id == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// removeWaiter 移除放弃等待的任务
+func (p *Pool) removeWaiter(w *waiter) {
+	for i, other := range p.waiters {
+		if other == w {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Context 返回资源的上下文，被抢占或释放时取消
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Hold 标记任务正在购买，此后不再被抢占
+func (l *Lease) Hold() {
+	if l.pool == nil {
+		return
+	}
+	l.pool.mu.Lock()
+	defer l.pool.mu.Unlock()
+	l.held = true
+}
+
+// Preempted 资源是否被其他任务抢占
+func (l *Lease) Preempted() bool {
+	if l.pool == nil {
+		return false
+	}
+	l.pool.mu.Lock()
+	defer l.pool.mu.Unlock()
+	return l.preempted
+}
+
+// Release 归还资源，可重复调用
+func (l *Lease) Release() {
+	l.cancel()
+	if l.pool == nil {
+		return
+	}
+
+	p := l.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l.released {
+		return
+	}
+	l.released = true
+	delete(p.holders, l)
+	p.dispatch()
+}
@@ -0,0 +1,243 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// acquireAsync 在后台申请资源，返回后任务已进入等待队列或已获得资源
+func acquireAsync(t *testing.T, ctx context.Context, p *Pool, id string, priority int) <-chan *Lease {
+	t.Helper()
+	ch := make(chan *Lease, 1)
+	go func() {
+		lease, err := p.Acquire(ctx, id, priority)
+		if err == nil {
+			ch <- lease
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for p.Position(id) == 0 && len(ch) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s 没有进入等待队列", id)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return ch
+}
+
+// receive 等待获得资源
+func receive(t *testing.T, ch <-chan *Lease, id string) *Lease {
+	t.Helper()
+	select {
+	case lease := <-ch:
+		return lease
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s 没有获得资源", id)
+		return nil
+	}
+}
+
+func TestPoolUnlimited(t *testing.T) {
+	for _, p := range []*Pool{nil, NewPool(0, 0)} {
+		lease, err := p.Acquire(context.Background(), "a", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lease.Hold()
+		if lease.Preempted() {
+			t.Error("不限制数量时不应被抢占")
+		}
+		lease.Release()
+		if lease.Context().Err() == nil {
+			t.Error("Release 后 Context 应取消")
+		}
+	}
+}
+
+func TestPoolPriorityOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int
+		want       []string
+	}{
+		{"高优先级先分配", []int{1, 5, 3}, []string{"w1", "w2", "w0"}},
+		{"同优先级先来先得", []int{2, 2, 2}, []string{"w0", "w1", "w2"}},
+		{"混合", []int{1, 5, 5, 3}, []string{"w1", "w2", "w3", "w0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p := NewPool(1, 0)
+
+			// 占用者正在购买，不会被抢占，等待者只能按顺序获得资源
+			current, err := p.Acquire(ctx, "holder", 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current.Hold()
+
+			chans := make(map[string]<-chan *Lease)
+			for i, priority := range tt.priorities {
+				id := fmt.Sprintf("w%d", i)
+				chans[id] = acquireAsync(t, ctx, p, id, priority)
+			}
+			if got := p.Position(tt.want[0]); got != 1 {
+				t.Errorf("%s 在队列中的位置为 %d，期望 1", tt.want[0], got)
+			}
+
+			for _, id := range tt.want {
+				current.Release()
+				current = receive(t, chans[id], id)
+				if current.ID != id {
+					t.Fatalf("获得资源的是 %s，期望 %s", current.ID, id)
+				}
+				current.Hold()
+			}
+			current.Release()
+		})
+	}
+}
+
+func TestPoolPreemption(t *testing.T) {
+	type holder struct {
+		priority int
+		held     bool
+	}
+	tests := []struct {
+		name    string
+		holders []holder
+		waiter  int
+		// preempted 被抢占的占用者下标，-1表示没有
+		preempted int
+	}{
+		{"高优先级抢占低优先级", []holder{{priority: 0}}, 1, 0},
+		{"低优先级不抢占高优先级", []holder{{priority: 5}}, 1, -1},
+		{"没有时间片时同优先级不抢占", []holder{{priority: 1}}, 1, -1},
+		{"购买中的任务不被抢占", []holder{{priority: 0, held: true}}, 9, -1},
+		{"抢占优先级最低的任务", []holder{{priority: 2}, {priority: 0}}, 3, 1},
+		{"同优先级抢占占用最久的任务", []holder{{priority: 1}, {priority: 1}}, 2, 0},
+		{"跳过购买中的任务", []holder{{priority: 0, held: true}, {priority: 1}}, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p := NewPool(len(tt.holders), 0)
+
+			leases := make([]*Lease, len(tt.holders))
+			for i, h := range tt.holders {
+				lease, err := p.Acquire(ctx, fmt.Sprintf("h%d", i), h.priority)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if h.held {
+					lease.Hold()
+				}
+				leases[i] = lease
+				// 保证占用时间先后可区分
+				time.Sleep(2 * time.Millisecond)
+			}
+
+			ch := acquireAsync(t, ctx, p, "waiter", tt.waiter)
+			for i, lease := range leases {
+				want := i == tt.preempted
+				if lease.Preempted() != want {
+					t.Errorf("h%d 被抢占 = %v，期望 %v", i, lease.Preempted(), want)
+				}
+				if (lease.Context().Err() != nil) != want {
+					t.Errorf("h%d 的 Context 取消 = %v，期望 %v", i, lease.Context().Err() != nil, want)
+				}
+			}
+			if tt.preempted < 0 {
+				return
+			}
+
+			// 被抢占的任务退出后资源交给等待者
+			leases[tt.preempted].Release()
+			if lease := receive(t, ch, "waiter"); lease.ID != "waiter" {
+				t.Errorf("获得资源的是 %s", lease.ID)
+			}
+		})
+	}
+}
+
+func TestPoolTimeSlice(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const slice = 50 * time.Millisecond
+	p := NewPool(1, slice)
+
+	first, err := p.Acquire(ctx, "first", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	ch := acquireAsync(t, ctx, p, "second", 1)
+	if first.Preempted() {
+		t.Fatal("时间片用完前不应被抢占")
+	}
+
+	select {
+	case <-first.Context().Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("时间片用完后没有被抢占")
+	}
+	if elapsed := time.Since(start); elapsed < slice/2 {
+		t.Errorf("%v 后就被抢占，时间片为 %v", elapsed, slice)
+	}
+	if !first.Preempted() {
+		t.Error("Preempted 应为 true")
+	}
+
+	first.Release()
+	second := receive(t, ch, "second")
+
+	// 购买中的任务时间片用完也不被抢占
+	second.Hold()
+	acquireAsync(t, ctx, p, "third", 1)
+	time.Sleep(2 * slice)
+	if second.Preempted() {
+		t.Error("购买中的任务不应被抢占")
+	}
+	second.Release()
+}
+
+func TestPoolAcquireCancel(t *testing.T) {
+	p := NewPool(1, 0)
+	holder, err := p.Acquire(context.Background(), "holder", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder.Hold()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := p.Acquire(ctx, "waiter", 0)
+		errs <- err
+	}()
+	for p.Position("waiter") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("取消等待应返回 context.Canceled，得到 %v", err)
+	}
+	if pos := p.Position("waiter"); pos != 0 {
+		t.Errorf("取消后仍在队列中，位置 %d", pos)
+	}
+
+	// 取消的等待者不占用资源，释放后新的任务可以立即获得
+	holder.Release()
+	holder.Release()
+	next, err := p.Acquire(context.Background(), "next", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.Release()
+}
@@ -0,0 +1,196 @@
+// Package task 守护模式下的抢票任务管理和浏览器资源分配
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/progress"
+)
+
+// State 任务状态
+type State string
+
+// 任务状态
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+// Done 任务是否已结束
+func (s State) Done() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCanceled
+}
+
+// ErrNotFound 任务不存在
+var ErrNotFound = errors.New("任务不存在")
+
+// Task 抢票任务快照
+type Task struct {
+	ID         string         `json:"id"`
+	Concert    models.Concert `json:"concert"`
+	State      State          `json:"state"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  time.Time      `json:"started_at,omitempty"`
+	FinishedAt time.Time      `json:"finished_at,omitempty"`
+	// Progress 运行中任务的实时进度，已结束的任务为nil
+	Progress *progress.Snapshot `json:"progress,omitempty"`
+}
+
+// Runner 执行单个演唱会的抢票，ctx取消时应尽快返回
+type Runner func(ctx context.Context, concert *models.Concert) error
+
+// entry 运行中的任务
+type entry struct {
+	task     Task
+	cancel   context.CancelFunc
+	progress *progress.Status
+}
+
+// Manager 守护模式下的任务管理器，负责启动、查询和取消抢票任务
+type Manager struct {
+	ctx context.Context
+	run Runner
+
+	mu    sync.RWMutex
+	tasks map[string]*entry
+	seq   int
+	wg    sync.WaitGroup
+}
+
+// NewManager 创建任务管理器，ctx取消时所有任务随之停止
+func NewManager(ctx context.Context, run Runner) *Manager {
+	return &Manager{
+		ctx:   ctx,
+		run:   run,
+		tasks: make(map[string]*entry),
+	}
+}
+
+// Submit 提交并立即启动一个抢票任务
+func (m *Manager) Submit(concert models.Concert) (Task, error) {
+	if concert.ID == "" {
+		return Task{}, fmt.Errorf("演唱会ID不能为空")
+	}
+
+	m.mu.Lock()
+	for _, e := range m.tasks {
+		if e.task.Concert.ID == concert.ID && !e.task.State.Done() {
+			m.mu.Unlock()
+			return Task{}, fmt.Errorf("演唱会 %s 已有运行中的任务 %s", concert.ID, e.task.ID)
+		}
+	}
+
+	m.seq++
+	ctx, cancel := context.WithCancel(m.ctx)
+	e := &entry{
+		task: Task{
+			ID:        fmt.Sprintf("task-%d", m.seq),
+			Concert:   concert,
+			State:     StatePending,
+			CreatedAt: time.Now(),
+		},
+		cancel:   cancel,
+		progress: progress.New(),
+	}
+	m.tasks[e.task.ID] = e
+	snapshot := e.task
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go m.execute(ctx, e)
+
+	return snapshot, nil
+}
+
+// execute 运行任务并记录结果
+func (m *Manager) execute(ctx context.Context, e *entry) {
+	defer m.wg.Done()
+	defer e.cancel()
+
+	m.mu.Lock()
+	e.task.State = StateRunning
+	e.task.StartedAt = time.Now()
+	concert := e.task.Concert
+	m.mu.Unlock()
+
+	err := m.run(progress.NewContext(ctx, e.progress), &concert)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.task.FinishedAt = time.Now()
+	switch {
+	case ctx.Err() != nil:
+		e.task.State = StateCanceled
+	case err != nil:
+		e.task.State = StateFailed
+		e.task.Error = err.Error()
+	default:
+		e.task.State = StateSucceeded
+	}
+}
+
+// snapshot 返回任务快照，运行中的任务附带进度，调用方持有锁
+func (e *entry) snapshot() Task {
+	t := e.task
+	if !t.State.Done() {
+		p := e.progress.Snapshot()
+		t.Progress = &p
+	}
+	return t
+}
+
+// Get 返回任务快照
+func (m *Manager) Get(id string) (Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return e.snapshot(), nil
+}
+
+// List 返回所有任务快照，按创建时间排序
+func (m *Manager) List() []Task {
+	m.mu.RLock()
+	tasks := make([]Task, 0, len(m.tasks))
+	for _, e := range m.tasks {
+		tasks = append(tasks, e.snapshot())
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// Cancel 取消任务，已结束的任务不受影响
+func (m *Manager) Cancel(id string) (Task, error) {
+	m.mu.RLock()
+	e, ok := m.tasks[id]
+	m.mu.RUnlock()
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+
+	e.cancel()
+	return m.Get(id)
+}
+
+// Wait 等待所有任务结束
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
@@ -0,0 +1,180 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stage 抢票过程中的阶段
+type Stage string
+
+const (
+	StageStart        Stage = "start"
+	StageLoggedIn     Stage = "logged_in"
+	StageSaleOpen     Stage = "sale_open"
+	StageAvailable    Stage = "available"
+	StageSeatSelected Stage = "seat_selected"
+	StagePurchase     Stage = "purchase_clicked"
+	StageConfirmed    Stage = "confirmed"
+)
+
+// stageNames 报告中显示的阶段名称
+var stageNames = map[Stage]string{
+	StageStart:        "任务开始",
+	StageLoggedIn:     "登录完成",
+	StageSaleOpen:     "开售",
+	StageAvailable:    "首次发现有票",
+	StageSeatSelected: "选座完成",
+	StagePurchase:     "点击购买",
+	StageConfirmed:    "购买确认",
+}
+
+// Event 某个阶段第一次到达的时刻
+// Elapsed 为距上一阶段的耗时，SinceOpen 为距开售的耗时(开售前的阶段为0)
+type Event struct {
+	Stage     Stage         `json:"stage"`
+	Time      time.Time     `json:"time"`
+	Elapsed   time.Duration `json:"elapsed_ns"`
+	SinceOpen time.Duration `json:"since_open_ns"`
+}
+
+// Timeline 记录一次抢票任务各阶段的时间，任务结束后生成报告，用于找出耗时的环节
+// 每个阶段只记录第一次到达的时刻(如监控中多次发现有票只记第一次)
+type Timeline struct {
+	ConcertID string `json:"concert_id"`
+	Site      string `json:"site"`
+	// SaleOpenTime 配置的开售时间，与实际开售阶段的差为等待误差
+	SaleOpenTime time.Time `json:"sale_open_time,omitempty"`
+	Result       string    `json:"result"`
+	Events       []Event   `json:"events"`
+
+	mu   sync.Mutex
+	seen map[Stage]bool
+}
+
+// New 创建时间线并记录任务开始
+func New(concertID, site string, saleOpen time.Time) *Timeline {
+	t := &Timeline{
+		ConcertID:    concertID,
+		Site:         site,
+		SaleOpenTime: saleOpen,
+		seen:         make(map[Stage]bool),
+	}
+	t.Mark(StageStart)
+	return t
+}
+
+// SetSaleOpenTime 更新计划开售时间(如从页面公告中读取到)，t为nil时不记录
+func (t *Timeline) SetSaleOpenTime(open time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SaleOpenTime = open
+}
+
+// Mark 记录到达某个阶段，同一阶段只记录第一次，t为nil时不记录
+func (t *Timeline) Mark(stage Stage) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seen[stage] {
+		return
+	}
+	t.seen[stage] = true
+
+	now := time.Now()
+	event := Event{Stage: stage, Time: now}
+	if n := len(t.Events); n > 0 {
+		event.Elapsed = now.Sub(t.Events[n-1].Time)
+	}
+	if open, ok := t.openTime(); ok {
+		event.SinceOpen = now.Sub(open)
+	}
+	t.Events = append(t.Events, event)
+}
+
+// openTime 实际开售时刻，调用方持有锁
+func (t *Timeline) openTime() (time.Time, bool) {
+	for _, event := range t.Events {
+		if event.Stage == StageSaleOpen {
+			return event.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Finish 记录任务结果，err为nil表示成功
+func (t *Timeline) Finish(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.Result = err.Error()
+	} else if t.seen[StageConfirmed] {
+		t.Result = "购票成功"
+	} else {
+		t.Result = "已停止"
+	}
+}
+
+// String 生成便于阅读的文本报告
+func (t *Timeline) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "演唱会 %s (%s) 抢票时间线\n", t.ConcertID, t.Site)
+	if !t.SaleOpenTime.IsZero() {
+		fmt.Fprintf(&b, "计划开售: %s\n", t.SaleOpenTime.Format("2006-01-02 15:04:05.000"))
+	}
+	for _, event := range t.Events {
+		name := stageNames[event.Stage]
+		if name == "" {
+			name = string(event.Stage)
+		}
+		fmt.Fprintf(&b, "%s  %-8s  +%-12s", event.Time.Format("15:04:05.000"), name, event.Elapsed.Round(time.Millisecond))
+		if event.SinceOpen > 0 {
+			fmt.Fprintf(&b, "  开售后 %s", event.SinceOpen.Round(time.Millisecond))
+		}
+		b.WriteString("\n")
+	}
+	if t.Result != "" {
+		fmt.Fprintf(&b, "结果: %s\n", t.Result)
+	}
+	return b.String()
+}
+
+// Write 将报告写入dir，生成同名的.json和.txt文件，返回不带扩展名的路径
+func (t *Timeline) Write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	start := time.Now()
+	if len(t.Events) > 0 {
+		start = t.Events[0].Time
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", t.ConcertID, start.Format("20060102-150405")))
+	if err := os.WriteFile(base+".json", data, 0644); err != nil {
+		return "", err
+	}
+	return base, os.WriteFile(base+".txt", []byte(t.String()), 0644)
+}
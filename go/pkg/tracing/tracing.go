@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"tickgrabber/pkg/models"
+	"tickgrabber/pkg/redact"
+)
+
+// instrumentation 创建span时使用的tracer名称
+const instrumentation = "tickgrabber"
+
+// defaultServiceName 没有配置服务名时上报的服务名
+const defaultServiceName = "tickgrabber"
+
+// Setup 按配置启用OpenTelemetry追踪，span通过OTLP/HTTP导出(Jaeger等均支持)
+// 没有配置endpoint时不启用，Start创建的span不做任何事；返回的函数在退出前调用以导出剩余的span
+func Setup(ctx context.Context, config models.TracingConfig) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.Endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	name := config.ServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+	sampler := sdktrace.AlwaysSample()
+	if config.SampleRatio > 0 && config.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(config.SampleRatio)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	log.Printf("已启用追踪，导出到 %s", config.Endpoint)
+	return provider.Shutdown, nil
+}
+
+// Start 创建子span，返回带有该span的ctx，同一ctx下的后续操作成为它的子span
+// 文字属性在记录前脱敏
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	for i, attr := range attrs {
+		if attr.Value.Type() == attribute.STRING {
+			attrs[i] = attr.Key.String(redact.String(attr.Value.AsString()))
+		}
+	}
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 记录脱敏后的错误并结束span
+func End(span trace.Span, err error) {
+	if err != nil {
+		message := redact.String(err.Error())
+		span.RecordError(errors.New(message))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+// Run 在名为name的span中执行fn，fn返回的错误记录到span
+func Run(ctx context.Context, name string, fn func(context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := Start(ctx, name, attrs...)
+	err := fn(ctx)
+	End(span, err)
+	return err
+}
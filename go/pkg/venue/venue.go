@@ -0,0 +1,157 @@
+// Package venue 演出场馆的座位知识库
+//
+// 每个场馆按区域名称(正则表达式)给出座位质量评分和到舞台的大致距离，
+// 选座时据此在页面上的可选区域中挑选最好的区域，使"中间区域"、"前排"等偏好在具体场馆有实际含义。
+// 内置数据为常见场馆的大致布局，可以用 venue_file 补充或覆盖
+package venue
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"tickgrabber/pkg/models"
+)
+
+//go:embed venues.json
+var builtin []byte
+
+// Section 场馆中的一类区域
+type Section struct {
+	// Match 匹配区域名称的正则表达式，按顺序取第一个匹配的
+	Match string `json:"match"`
+	// Quality 座位质量，0-100
+	Quality int `json:"quality"`
+	// Distance 到舞台的大致距离(米)
+	Distance int `json:"distance"`
+	// Center 是否为正对舞台的中间区域
+	Center bool `json:"center"`
+
+	re *regexp.Regexp
+}
+
+// Venue 场馆
+type Venue struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Aliases  []string  `json:"aliases"`
+	Sections []Section `json:"sections"`
+}
+
+// DB 场馆知识库
+type DB struct {
+	venues []*Venue
+}
+
+// Default 返回内置的场馆知识库
+func Default() *DB {
+	db, err := parse(builtin)
+	if err != nil {
+		panic(fmt.Sprintf("内置场馆数据错误: %v", err))
+	}
+	return db
+}
+
+// Load 读取场馆文件，与内置数据合并，ID相同的场馆以文件为准；path为空时只使用内置数据
+func Load(path string) (*DB, error) {
+	db := Default()
+	if path == "" {
+		return db, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("场馆文件 %s: %w", path, err)
+	}
+
+	for _, v := range extra.venues {
+		replaced := false
+		for i, existing := range db.venues {
+			if existing.ID == v.ID {
+				db.venues[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			db.venues = append(db.venues, v)
+		}
+	}
+	return db, nil
+}
+
+// parse 解析场馆数据并编译区域规则
+func parse(data []byte) (*DB, error) {
+	var venues []*Venue
+	if err := json.Unmarshal(data, &venues); err != nil {
+		return nil, err
+	}
+	for _, v := range venues {
+		for i := range v.Sections {
+			re, err := regexp.Compile(v.Sections[i].Match)
+			if err != nil {
+				return nil, fmt.Errorf("场馆 %s 的区域规则 %q 无效: %w", v.ID, v.Sections[i].Match, err)
+			}
+			v.Sections[i].re = re
+		}
+	}
+	return &DB{venues: venues}, nil
+}
+
+// Find 按演唱会的场馆名称查找场馆，名称中包含场馆名或别名即可
+func (db *DB) Find(name string) *Venue {
+	name = normalize(name)
+	if name == "" {
+		return nil
+	}
+	for _, v := range db.venues {
+		for _, n := range append([]string{v.ID, v.Name}, v.Aliases...) {
+			if n = normalize(n); n != "" && strings.Contains(name, n) {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// Rate 返回区域名称对应的区域信息
+func (v *Venue) Rate(section string) (Section, bool) {
+	for _, s := range v.Sections {
+		if s.re.MatchString(section) {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Score 按座位偏好计算区域得分，场馆中没有的区域返回false
+func (v *Venue) Score(section string, prefs models.SeatPreferences) (int, bool) {
+	s, ok := v.Rate(section)
+	if !ok {
+		return 0, false
+	}
+
+	score := s.Quality
+	if prefs.CenterSection && s.Center {
+		score += 15
+	}
+	if prefs.FrontRow {
+		// 距离越近加分越多，100米以外不加分
+		if bonus := 20 - s.Distance/5; bonus > 0 {
+			score += bonus
+		}
+	}
+	return score, true
+}
+
+// normalize 去掉空白并转为小写，便于比较场馆名称
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), ""))
+}
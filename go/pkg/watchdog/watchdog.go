@@ -0,0 +1,242 @@
+package watchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Component 被监控的组件
+type Component struct {
+	// Name 组件名称
+	Name string
+	// MaxSilence 超过该时间没有心跳即视为卡住，为0时只使用Probe
+	MaxSilence time.Duration
+	// Probe 主动探测组件是否响应，可为nil
+	Probe func(ctx context.Context) error
+	// Restart 组件卡住时调用，可为nil
+	Restart func(ctx context.Context) error
+	// Busy 返回true时组件正在执行不能打断的操作(如持有座位、支付中)，只记录异常不重启，可为nil
+	Busy func() bool
+}
+
+// componentState 组件运行状态
+type componentState struct {
+	Component
+	lastBeat    time.Time
+	lastError   string
+	restarts    int
+	lastRestart time.Time
+}
+
+// ComponentStatus 组件健康状态
+type ComponentStatus struct {
+	Healthy  bool      `json:"healthy"`
+	LastBeat time.Time `json:"last_beat,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Restarts int       `json:"restarts"`
+}
+
+// Status 整体健康状态
+type Status struct {
+	Healthy    bool                       `json:"healthy"`
+	Components map[string]ComponentStatus `json:"components"`
+	MemoryMB   uint64                     `json:"memory_mb"`
+	Uptime     string                     `json:"uptime"`
+}
+
+// Watchdog 监控组件心跳、内存和响应情况，必要时重启卡住的组件
+type Watchdog struct {
+	mu          sync.Mutex
+	components  map[string]*componentState
+	interval    time.Duration
+	maxMemoryMB uint64
+	started     time.Time
+}
+
+// New 创建看门狗，interval为检查间隔，maxMemoryMB为0时不检查内存
+func New(interval time.Duration, maxMemoryMB uint64) *Watchdog {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	return &Watchdog{
+		components:  make(map[string]*componentState),
+		interval:    interval,
+		maxMemoryMB: maxMemoryMB,
+		started:     time.Now(),
+	}
+}
+
+// Register 注册组件，注册时视为刚收到心跳
+func (w *Watchdog) Register(c Component) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.components[c.Name] = &componentState{Component: c, lastBeat: time.Now()}
+}
+
+// Unregister 移除组件，组件暂停运行时(如任务的浏览器被抢占)不再检查
+func (w *Watchdog) Unregister(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.components, name)
+}
+
+// Beat 记录组件的心跳(如监控循环完成了一次成功检查)
+func (w *Watchdog) Beat(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if state, ok := w.components[name]; ok {
+		state.lastBeat = time.Now()
+		state.lastError = ""
+	}
+}
+
+// Run 定期检查所有组件，直到ctx取消
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+// check 检查所有组件，卡住的组件尝试重启
+func (w *Watchdog) check(ctx context.Context) {
+	w.mu.Lock()
+	states := make([]*componentState, 0, len(w.components))
+	for _, state := range w.components {
+		states = append(states, state)
+	}
+	w.mu.Unlock()
+
+	for _, state := range states {
+		err := w.checkComponent(ctx, state)
+		busy := err != nil && state.Busy != nil && state.Busy()
+
+		w.mu.Lock()
+		if err == nil {
+			state.lastError = ""
+			w.mu.Unlock()
+			continue
+		}
+		state.lastError = err.Error()
+		restart := state.Restart
+		// 两次重启至少间隔MaxSilence，避免组件恢复期间被反复重启
+		if time.Since(state.lastRestart) < max(state.MaxSilence, w.interval) || busy {
+			restart = nil
+		}
+		if restart != nil {
+			state.restarts++
+			state.lastRestart = time.Now()
+		}
+		w.mu.Unlock()
+
+		log.Printf("看门狗: %s 异常: %v", state.Name, err)
+		if busy && state.Restart != nil {
+			log.Printf("看门狗: %s 正在购买或支付，暂不重启", state.Name)
+		}
+		if restart != nil {
+			log.Printf("看门狗: 正在重启 %s", state.Name)
+			if err := restart(ctx); err != nil {
+				log.Printf("看门狗: 重启 %s 失败: %v", state.Name, err)
+			} else {
+				w.Beat(state.Name)
+			}
+		}
+	}
+
+	if w.maxMemoryMB > 0 {
+		if mb := memoryMB(); mb > w.maxMemoryMB {
+			log.Printf("看门狗: 内存占用 %dMB 超过上限 %dMB，触发垃圾回收", mb, w.maxMemoryMB)
+			runtime.GC()
+		}
+	}
+}
+
+// checkComponent 检查单个组件的心跳和探测结果
+func (w *Watchdog) checkComponent(ctx context.Context, state *componentState) error {
+	w.mu.Lock()
+	silence := time.Since(state.lastBeat)
+	w.mu.Unlock()
+
+	if state.MaxSilence > 0 && silence > state.MaxSilence {
+		return fmt.Errorf("已 %v 没有心跳", silence.Round(time.Second))
+	}
+
+	if state.Probe != nil {
+		probeCtx, cancel := context.WithTimeout(ctx, w.interval)
+		defer cancel()
+		if err := state.Probe(probeCtx); err != nil {
+			return fmt.Errorf("探测失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Status 返回当前健康状态
+func (w *Watchdog) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := Status{
+		Healthy:    true,
+		Components: make(map[string]ComponentStatus),
+		MemoryMB:   memoryMB(),
+		Uptime:     time.Since(w.started).Round(time.Second).String(),
+	}
+
+	for name, state := range w.components {
+		healthy := state.lastError == "" &&
+			(state.MaxSilence == 0 || time.Since(state.lastBeat) <= state.MaxSilence)
+		status.Components[name] = ComponentStatus{
+			Healthy:  healthy,
+			LastBeat: state.lastBeat,
+			Error:    state.lastError,
+			Restarts: state.restarts,
+		}
+		if !healthy {
+			status.Healthy = false
+		}
+	}
+
+	if w.maxMemoryMB > 0 && status.MemoryMB > w.maxMemoryMB {
+		status.Healthy = false
+	}
+	return status
+}
+
+// Handler 返回/healthz处理器，健康时返回200，否则返回503
+func (w *Watchdog) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		status := w.Status()
+
+		rw.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(rw).Encode(status)
+	})
+}
+
+// memoryMB 当前进程从系统获取的内存(MB)
+func memoryMB() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys / 1024 / 1024
+}